@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
 	"electronics-store/internal/api"
 	"electronics-store/internal/config"
 	"electronics-store/internal/database"
+	"electronics-store/internal/repository"
+	"electronics-store/internal/scheduler"
+	"electronics-store/internal/services"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
@@ -20,11 +29,110 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	// Initialize and start server
-	server := api.NewServer(cfg, db)
-	
-	log.Printf("Starting server on port %s", cfg.Server.Port)
-	if err := server.Start(); err != nil {
-		log.Fatal("Failed to start server:", err)
+	// Load the keys access and refresh tokens are signed with
+	jwtKeys, err := services.LoadJWTKeySet(cfg.JWT)
+	if err != nil {
+		log.Fatal("Failed to load JWT keys:", err)
+	}
+
+	// Connect the store rate limits and login lockouts are counted in
+	rateLimitStore, err := services.NewRateLimitStore(cfg.RateLimit, cfg.Redis)
+	if err != nil {
+		log.Fatal("Failed to create rate limit store:", err)
+	}
+	rateLimiter := services.NewRateLimiter(rateLimitStore, cfg.RateLimit)
+
+	// Background jobs take a database lock per run and record the run in the
+	// database, so that only one replica runs each scheduled time
+	jobLocker, err := scheduler.NewMySQLLocker(db.DB)
+	if err != nil {
+		log.Fatal("Failed to create job locker:", err)
+	}
+	jobs := scheduler.New(jobLocker, repository.NewJobRunRepository(db.DB))
+
+	// Email templates are parsed and test rendered up front so a broken
+	// template stops the server rather than an email
+	emailService, err := services.NewEmailService(&cfg.Email)
+	if err != nil {
+		log.Fatal("Failed to load email templates:", err)
+	}
+
+	// Emails go out over SMTP, or to a mailbox in development
+	mailer, err := services.NewMailer(&cfg.Email)
+	if err != nil {
+		log.Fatal("Failed to create mailer:", err)
+	}
+
+	// Emails and webhook calls are written to the outbox table and sent by
+	// background workers
+	channels := map[string]services.OutboxChannel{
+		services.NotificationChannelEmail: services.NewEmailChannel(mailer),
+	}
+	if cfg.Notify.WebhookURL != "" {
+		channels[services.NotificationChannelWebhook] = services.NewWebhookChannel(cfg.Notify)
+	}
+	emailQueue := services.NewEmailQueue(repository.NewEmailOutboxRepository(db.DB), channels, cfg.Outbox)
+	notifier := services.NewNotificationService(emailQueue, cfg.Notify, cfg.Email.StoreURL)
+
+	// Payments go through the configured provider; the mock one only in
+	// dev mode. Without a provider the store runs without online payments.
+	paymentGateway, err := services.NewPaymentGateway(&cfg.Payment, cfg.Server.DevMode)
+	if err != nil {
+		log.Fatal("Failed to create payment gateway:", err)
+	}
+	if paymentGateway == nil {
+		log.Println("No payment provider configured; online payments are disabled")
+	}
+
+	// One-time codes are stored as HMACs; outside dev mode the key must be
+	// a real secret
+	otpService, err := services.NewOTPService(repository.NewOTPRepository(db.DB), emailService, notifier, cfg.OTP, cfg.Server.DevMode)
+	if err != nil {
+		log.Fatal("Failed to create OTP service:", err)
+	}
+
+	// Guest cart cookies are signed; outside dev mode the secret must be a
+	// real one
+	cartTokens, err := services.NewCartTokenSigner(cfg.Cart.TokenSecret, cfg.Server.DevMode)
+	if err != nil {
+		log.Fatal("Failed to create cart token signer:", err)
+	}
+
+	// Initialize the server and migrate the database before anything that
+	// uses it starts
+	server := api.NewServer(cfg, db, jwtKeys, rateLimiter, jobs, emailService, emailQueue, notifier, paymentGateway, otpService, cartTokens)
+	if err := server.Migrate(context.Background()); err != nil {
+		log.Fatal("Failed to prepare database:", err)
+	}
+	if err := server.RegisterJobs(); err != nil {
+		log.Fatal("Failed to register background jobs:", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Starting server on port %s", cfg.Server.Port)
+		serverErr <- server.Start()
+	}()
+	if cfg.Jobs.Enabled {
+		jobs.Start(ctx)
+	}
+	emailQueue.Start(ctx)
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server:", err)
+		}
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Shutdown did not complete cleanly: %v", err)
 	}
 }
@@ -0,0 +1,8 @@
+-- Migration: Track whether an order still holds reserved stock
+-- Checkout decrements product/variant stock and sets this flag; moving the
+-- order to cancelled or refunded restores the stock and clears it again.
+
+ALTER TABLE orders ADD COLUMN inventory_reserved BOOLEAN DEFAULT FALSE AFTER notes;
+
+-- Orders placed before this migration never decremented stock, so they are
+-- left unreserved and will not be restocked on cancellation.
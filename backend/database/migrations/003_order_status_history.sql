@@ -0,0 +1,25 @@
+-- Migration: Order status state machine
+-- Adds the 'confirmed' status, shipment/delivery timestamps and an
+-- append-only history of every status transition.
+
+ALTER TABLE orders
+    MODIFY COLUMN status ENUM('pending', 'confirmed', 'processing', 'shipped', 'delivered', 'cancelled', 'refunded') DEFAULT 'pending',
+    ADD COLUMN shipped_at TIMESTAMP NULL AFTER billing_address,
+    ADD COLUMN delivered_at TIMESTAMP NULL AFTER shipped_at;
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    order_id INT UNSIGNED NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor_id INT UNSIGNED NULL,
+    actor_role VARCHAR(20) NOT NULL,
+    note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL,
+    INDEX idx_order_status_history_order_id (order_id),
+    INDEX idx_order_status_history_actor_id (actor_id)
+);
@@ -0,0 +1,15 @@
+-- Migration: Order payment status
+-- The Order model has always carried a payment status but the column was
+-- missing from the schema. It is now driven by the payment gateway flow.
+
+ALTER TABLE orders
+    ADD COLUMN payment_status ENUM('pending', 'paid', 'failed', 'refunded') DEFAULT 'pending' AFTER status,
+    ADD INDEX idx_orders_payment_status (payment_status);
+
+-- Orders with a completed payment are already paid
+UPDATE orders o
+SET o.payment_status = 'paid'
+WHERE EXISTS (
+    SELECT 1 FROM payments p
+    WHERE p.order_id = o.id AND p.payment_status = 'completed'
+);
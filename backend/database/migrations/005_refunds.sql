@@ -0,0 +1,42 @@
+-- Migration: Refunds
+-- Refunds issued against a payment, optionally per order line, and the
+-- partially_refunded order payment status.
+
+ALTER TABLE orders
+    MODIFY COLUMN payment_status ENUM('pending', 'paid', 'failed', 'partially_refunded', 'refunded') DEFAULT 'pending';
+
+CREATE TABLE IF NOT EXISTS refunds (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    order_id INT UNSIGNED NOT NULL,
+    payment_id INT UNSIGNED NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    currency VARCHAR(3) DEFAULT 'USD',
+    reason TEXT,
+    transaction_id VARCHAR(255),
+    gateway_response JSON,
+    restocked BOOLEAN DEFAULT FALSE,
+    actor_id INT UNSIGNED NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL,
+    INDEX idx_refunds_order_id (order_id),
+    INDEX idx_refunds_payment_id (payment_id)
+);
+
+CREATE TABLE IF NOT EXISTS refund_items (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    refund_id INT UNSIGNED NOT NULL,
+    order_item_id INT UNSIGNED NOT NULL,
+    quantity INT NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (refund_id) REFERENCES refunds(id) ON DELETE CASCADE,
+    FOREIGN KEY (order_item_id) REFERENCES order_items(id) ON DELETE CASCADE,
+    INDEX idx_refund_items_refund_id (refund_id),
+    INDEX idx_refund_items_order_item_id (order_item_id)
+);
@@ -0,0 +1,15 @@
+-- Migration: Discount codes in cart and checkout
+-- Adds the free_shipping discount type, stores the code applied to a cart
+-- and the code redeemed by an order.
+
+ALTER TABLE discounts
+    MODIFY COLUMN type ENUM('percentage', 'fixed_amount', 'free_shipping') NOT NULL,
+    MODIFY COLUMN minimum_amount DECIMAL(10,2) DEFAULT 0;
+
+UPDATE discounts SET minimum_amount = 0 WHERE minimum_amount IS NULL;
+
+ALTER TABLE cart
+    ADD COLUMN discount_code VARCHAR(50) NULL AFTER session_id;
+
+ALTER TABLE orders
+    ADD COLUMN discount_code VARCHAR(50) NULL AFTER discount_amount;
@@ -0,0 +1,12 @@
+-- Migration: Promotions management
+-- Aligns the promotions table with the Promotion model: the name column
+-- becomes title, content is optional and every promotion has a position.
+
+ALTER TABLE promotions
+    CHANGE COLUMN name title VARCHAR(200) NOT NULL,
+    MODIFY COLUMN content TEXT NULL,
+    ADD COLUMN position ENUM('top', 'bottom', 'left', 'right', 'center') NOT NULL DEFAULT 'top' AFTER type,
+    ADD INDEX idx_promotions_position (position);
+
+UPDATE promotions SET position = 'center' WHERE type = 'popup';
+UPDATE promotions SET position = 'right' WHERE type = 'sidebar';
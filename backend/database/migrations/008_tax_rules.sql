@@ -0,0 +1,41 @@
+-- Migration: Tax rules
+-- Adds admin managed tax rates per country/state, the tax charged on each
+-- order line and the per-rule tax breakdown recorded at checkout.
+
+CREATE TABLE tax_rules (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    country VARCHAR(100) NOT NULL,
+    state VARCHAR(100) NOT NULL DEFAULT '',
+    rate DECIMAL(7,4) NOT NULL,
+    inclusive BOOLEAN DEFAULT FALSE,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_tax_rules_region (country, state),
+    INDEX idx_tax_rules_is_active (is_active)
+);
+
+CREATE TABLE order_taxes (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    order_id INT UNSIGNED NOT NULL,
+    tax_rule_id INT UNSIGNED NULL,
+    name VARCHAR(100) NOT NULL,
+    country VARCHAR(100) NOT NULL,
+    state VARCHAR(100),
+    rate DECIMAL(7,4) NOT NULL,
+    inclusive BOOLEAN DEFAULT FALSE,
+    taxable_amount DECIMAL(10,2) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (tax_rule_id) REFERENCES tax_rules(id) ON DELETE SET NULL,
+    INDEX idx_order_taxes_order_id (order_id),
+    INDEX idx_order_taxes_tax_rule_id (tax_rule_id)
+);
+
+ALTER TABLE order_items
+    ADD COLUMN tax_amount DECIMAL(10,2) DEFAULT 0 AFTER total_price;
@@ -0,0 +1,57 @@
+-- Migration: Shipping rates
+-- Adds admin managed shipping zones and methods (flat rate, weight based
+-- tiers, free over a threshold) and records the method chosen at checkout.
+
+CREATE TABLE shipping_zones (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE shipping_zone_regions (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    zone_id INT UNSIGNED NOT NULL,
+    country VARCHAR(100) NOT NULL,
+    state VARCHAR(100) NOT NULL DEFAULT '',
+
+    FOREIGN KEY (zone_id) REFERENCES shipping_zones(id) ON DELETE CASCADE,
+    INDEX idx_shipping_zone_regions_zone_id (zone_id),
+    INDEX idx_shipping_zone_regions_country (country)
+);
+
+CREATE TABLE shipping_methods (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    zone_id INT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    type ENUM('flat_rate', 'weight_based', 'free_over') NOT NULL,
+    rate DECIMAL(10,2) DEFAULT 0,
+    free_threshold DECIMAL(10,2) NULL,
+    estimated_days VARCHAR(50),
+    sort_order INT DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (zone_id) REFERENCES shipping_zones(id) ON DELETE CASCADE,
+    INDEX idx_shipping_methods_zone_id (zone_id)
+);
+
+CREATE TABLE shipping_rate_tiers (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    method_id INT UNSIGNED NOT NULL,
+    min_weight DECIMAL(8,2) NOT NULL DEFAULT 0,
+    max_weight DECIMAL(8,2) NULL,
+    rate DECIMAL(10,2) NOT NULL,
+
+    FOREIGN KEY (method_id) REFERENCES shipping_methods(id) ON DELETE CASCADE,
+    INDEX idx_shipping_rate_tiers_method_id (method_id)
+);
+
+ALTER TABLE orders
+    ADD COLUMN shipping_method_id INT UNSIGNED NULL AFTER shipping_amount,
+    ADD COLUMN shipping_method VARCHAR(100) NULL AFTER shipping_method_id,
+    ADD INDEX idx_orders_shipping_method_id (shipping_method_id);
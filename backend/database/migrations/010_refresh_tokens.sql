@@ -0,0 +1,23 @@
+-- Migration: Refresh tokens
+-- Stores hashed refresh tokens so sessions can be rotated, revoked on logout
+-- and killed when a rotated token is reused.
+
+CREATE TABLE refresh_tokens (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id INT UNSIGNED NOT NULL,
+    family_id CHAR(36) NOT NULL,
+    token_id CHAR(36) NOT NULL UNIQUE,
+    token_hash CHAR(64) NOT NULL,
+    user_agent VARCHAR(255),
+    ip_address VARCHAR(45),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL,
+    revoked_reason VARCHAR(20),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_refresh_tokens_user_id (user_id),
+    INDEX idx_refresh_tokens_family_id (family_id),
+    INDEX idx_refresh_tokens_expires_at (expires_at)
+);
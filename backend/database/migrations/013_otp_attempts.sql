@@ -0,0 +1,6 @@
+-- Migration: OTP attempt counter
+-- Wrong guesses are counted per code; a code is invalidated once
+-- OTP_MAX_ATTEMPTS of them have been made.
+
+ALTER TABLE otp_verifications
+    ADD COLUMN attempts INT NOT NULL DEFAULT 0 AFTER is_used;
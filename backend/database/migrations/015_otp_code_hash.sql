@@ -0,0 +1,12 @@
+-- Migration: Hash OTP codes at rest
+-- Codes are stored as an HMAC keyed with OTP_HASH_KEY and bound to the
+-- record's resource_id, which clients now send back as the challenge ID.
+-- Outstanding plaintext codes are dropped; they expire within 15 minutes
+-- anyway and can be requested again.
+
+DELETE FROM otp_verifications;
+
+ALTER TABLE otp_verifications
+    DROP INDEX idx_otp_code,
+    DROP COLUMN otp_code,
+    ADD COLUMN code_hash CHAR(64) NOT NULL AFTER phone;
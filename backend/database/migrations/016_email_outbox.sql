@@ -0,0 +1,28 @@
+-- Migration: Email outbox
+-- Emails are no longer sent over SMTP inside requests. They are written to
+-- this table in the same transaction as the change that caused them and
+-- delivered by background workers, which retry failures with backoff and
+-- dead-letter them after the last attempt.
+
+CREATE TABLE email_outbox (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    kind VARCHAR(50) NOT NULL,
+    to_email VARCHAR(255) NOT NULL,
+    to_name VARCHAR(255),
+    subject VARCHAR(255) NOT NULL,
+    html_body MEDIUMTEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP NULL,
+    expires_at TIMESTAMP NULL,
+    last_error TEXT,
+    sent_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_email_outbox_kind (kind),
+    INDEX idx_email_outbox_due (status, next_attempt_at),
+    INDEX idx_email_outbox_created_at (created_at)
+);
@@ -0,0 +1,25 @@
+-- Migration: Transactional emails
+-- Emails are rendered from templates into a plain text and an HTML part,
+-- shipped orders record their tracking details for the shipping email, and
+-- customers can ask to be emailed when an out of stock product is back.
+
+ALTER TABLE orders
+    ADD COLUMN carrier VARCHAR(50) AFTER billing_address,
+    ADD COLUMN tracking_number VARCHAR(100) AFTER carrier,
+    ADD COLUMN tracking_url VARCHAR(500) AFTER tracking_number;
+
+ALTER TABLE email_outbox
+    ADD COLUMN text_body MEDIUMTEXT AFTER subject;
+
+CREATE TABLE stock_alerts (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    user_id INT UNSIGNED NOT NULL,
+    product_id INT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    UNIQUE KEY idx_stock_alerts_user_product (user_id, product_id),
+    INDEX idx_stock_alerts_product_id (product_id)
+);
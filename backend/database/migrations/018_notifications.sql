@@ -0,0 +1,22 @@
+-- Migration: Notification channels
+-- The outbox also queues webhook calls, told apart from emails by their
+-- channel, and users get an in-app inbox of the notifications about them.
+
+ALTER TABLE email_outbox
+    ADD COLUMN channel VARCHAR(20) NOT NULL DEFAULT 'email' AFTER resource_id,
+    ADD COLUMN payload MEDIUMTEXT AFTER html_body;
+
+CREATE TABLE notifications (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    user_id INT UNSIGNED NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT,
+    link VARCHAR(500),
+    read_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_notifications_user_created (user_id, created_at)
+);
@@ -0,0 +1,50 @@
+-- Migration: Product options
+-- Products can come in options such as Color or Storage, each with a set
+-- of values; a variant is one combination of those values with its own
+-- SKU, price, stock and images.
+
+CREATE TABLE product_options (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    product_id INT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    position INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    UNIQUE KEY idx_product_options_product_name (product_id, name)
+);
+
+CREATE TABLE product_option_values (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    option_id INT UNSIGNED NOT NULL,
+    value VARCHAR(100) NOT NULL,
+    position INT DEFAULT 0,
+
+    FOREIGN KEY (option_id) REFERENCES product_options(id) ON DELETE CASCADE,
+    UNIQUE KEY idx_product_option_values_option_value (option_id, value)
+);
+
+CREATE TABLE variant_option_values (
+    variant_id INT UNSIGNED NOT NULL,
+    option_value_id INT UNSIGNED NOT NULL,
+
+    PRIMARY KEY (variant_id, option_value_id),
+    FOREIGN KEY (variant_id) REFERENCES variants(id) ON DELETE CASCADE,
+    FOREIGN KEY (option_value_id) REFERENCES product_option_values(id) ON DELETE CASCADE
+);
+
+CREATE TABLE variant_images (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    variant_id INT UNSIGNED NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    alt_text VARCHAR(200),
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (variant_id) REFERENCES variants(id) ON DELETE CASCADE,
+    INDEX idx_variant_images_variant_id (variant_id)
+);
@@ -0,0 +1,6 @@
+-- Migration: Cart line price snapshot
+-- Records the unit price a line had when it was added or last changed, so
+-- the cart can flag lines whose price moved since. Lines from before this
+-- migration have no snapshot and are never flagged.
+
+ALTER TABLE cart_items ADD COLUMN price DECIMAL(10,2) NOT NULL DEFAULT 0 AFTER quantity;
@@ -0,0 +1,9 @@
+-- Migration: Guest carts
+-- Shoppers who are not signed in get a cart without a user, identified by a
+-- signed cookie. It expires once unused for a while and is merged into the
+-- user's cart when they sign in.
+
+ALTER TABLE cart
+    MODIFY COLUMN user_id INT UNSIGNED NULL,
+    ADD COLUMN expires_at TIMESTAMP NULL AFTER discount_code,
+    ADD INDEX idx_cart_expires_at (expires_at);
@@ -0,0 +1,8 @@
+-- Migration: Refund status
+-- A refund is recorded as pending before the payment gateway is asked to
+-- return the money, and marked completed or failed once it answered.
+-- Refunds from before this migration were only recorded on success.
+
+ALTER TABLE refunds
+    ADD COLUMN status ENUM('pending', 'completed', 'failed') NOT NULL DEFAULT 'completed' AFTER reason,
+    ADD INDEX idx_refunds_status (status);
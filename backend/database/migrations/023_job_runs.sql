@@ -0,0 +1,10 @@
+-- Migration: Job runs
+-- The scheduled time each background job last ran for. The job lock only
+-- keeps runs from overlapping; a replica whose timer fires just after
+-- another one finished checks this table and skips the run.
+
+CREATE TABLE job_runs (
+    name VARCHAR(64) NOT NULL PRIMARY KEY,
+    last_slot TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
@@ -39,9 +39,10 @@ CREATE TABLE otp_verifications (
     user_id INT UNSIGNED,
     email VARCHAR(100) NOT NULL,
     phone VARCHAR(20),
-    otp_code VARCHAR(10) NOT NULL,
+    code_hash CHAR(64) NOT NULL,
     otp_type ENUM('email_verification', 'phone_verification', 'password_reset', 'login') NOT NULL,
     is_used BOOLEAN DEFAULT FALSE,
+    attempts INT NOT NULL DEFAULT 0,
     expires_at TIMESTAMP NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
@@ -50,10 +51,93 @@ CREATE TABLE otp_verifications (
     INDEX idx_otp_resource_id (resource_id),
     INDEX idx_otp_email (email),
     INDEX idx_otp_phone (phone),
-    INDEX idx_otp_code (otp_code),
     INDEX idx_otp_expires_at (expires_at)
 );
 
+-- Refresh tokens (one row per issued token, grouped into login sessions)
+CREATE TABLE refresh_tokens (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id INT UNSIGNED NOT NULL,
+    family_id CHAR(36) NOT NULL,
+    token_id CHAR(36) NOT NULL UNIQUE,
+    token_hash CHAR(64) NOT NULL,
+    user_agent VARCHAR(255),
+    ip_address VARCHAR(45),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL,
+    revoked_reason VARCHAR(20),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_refresh_tokens_user_id (user_id),
+    INDEX idx_refresh_tokens_family_id (family_id),
+    INDEX idx_refresh_tokens_expires_at (expires_at)
+);
+
+-- TOTP two-factor authentication. An enrollment is pending until
+-- confirmed_at is set; recovery codes are stored as SHA-256 hashes.
+CREATE TABLE two_factor_auths (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id INT UNSIGNED NOT NULL UNIQUE,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMP NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE two_factor_recovery_codes (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id INT UNSIGNED NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_recovery_codes_user_hash (user_id, code_hash)
+);
+
+-- Staff roles and the permissions they grant on the admin API. The built-in
+-- roles are created by the server at startup.
+CREATE TABLE permissions (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE roles (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE role_permissions (
+    role_id INT UNSIGNED NOT NULL,
+    permission_id INT UNSIGNED NOT NULL,
+
+    PRIMARY KEY (role_id, permission_id),
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE
+);
+
+-- users.is_admin is kept set for users holding any role
+CREATE TABLE user_roles (
+    user_id INT UNSIGNED NOT NULL,
+    role_id INT UNSIGNED NOT NULL,
+
+    PRIMARY KEY (user_id, role_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    INDEX idx_user_roles_role_id (role_id)
+);
+
 -- Addresses table
 CREATE TABLE addresses (
     id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
@@ -210,6 +294,55 @@ CREATE TABLE variants (
     INDEX idx_variants_is_active (is_active)
 );
 
+-- Product options (e.g. Color, Storage) and their values
+CREATE TABLE product_options (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    product_id INT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    position INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    UNIQUE KEY idx_product_options_product_name (product_id, name)
+);
+
+CREATE TABLE product_option_values (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    option_id INT UNSIGNED NOT NULL,
+    value VARCHAR(100) NOT NULL,
+    position INT DEFAULT 0,
+    
+    FOREIGN KEY (option_id) REFERENCES product_options(id) ON DELETE CASCADE,
+    UNIQUE KEY idx_product_option_values_option_value (option_id, value)
+);
+
+-- The option values a variant is a combination of
+CREATE TABLE variant_option_values (
+    variant_id INT UNSIGNED NOT NULL,
+    option_value_id INT UNSIGNED NOT NULL,
+    
+    PRIMARY KEY (variant_id, option_value_id),
+    FOREIGN KEY (variant_id) REFERENCES variants(id) ON DELETE CASCADE,
+    FOREIGN KEY (option_value_id) REFERENCES product_option_values(id) ON DELETE CASCADE
+);
+
+-- Variant images table
+CREATE TABLE variant_images (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    variant_id INT UNSIGNED NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    alt_text VARCHAR(200),
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    FOREIGN KEY (variant_id) REFERENCES variants(id) ON DELETE CASCADE,
+    INDEX idx_variant_images_variant_id (variant_id)
+);
+
 -- Reviews table
 CREATE TABLE reviews (
     id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
@@ -258,16 +391,26 @@ CREATE TABLE orders (
     resource_id CHAR(36) NOT NULL UNIQUE,
     user_id INT UNSIGNED NOT NULL,
     order_number VARCHAR(50) NOT NULL UNIQUE,
-    status ENUM('pending', 'processing', 'shipped', 'delivered', 'cancelled', 'refunded') DEFAULT 'pending',
+    status ENUM('pending', 'confirmed', 'processing', 'shipped', 'delivered', 'cancelled', 'refunded') DEFAULT 'pending',
+    payment_status ENUM('pending', 'paid', 'failed', 'partially_refunded', 'refunded') DEFAULT 'pending',
     subtotal DECIMAL(10,2) NOT NULL,
     tax_amount DECIMAL(10,2) DEFAULT 0,
     shipping_amount DECIMAL(10,2) DEFAULT 0,
+    shipping_method_id INT UNSIGNED NULL,
+    shipping_method VARCHAR(100),
     discount_amount DECIMAL(10,2) DEFAULT 0,
+    discount_code VARCHAR(50),
     total_amount DECIMAL(10,2) NOT NULL,
     currency VARCHAR(3) DEFAULT 'USD',
     notes TEXT,
+    inventory_reserved BOOLEAN DEFAULT FALSE,
     shipping_address JSON,
     billing_address JSON,
+    carrier VARCHAR(50),
+    tracking_number VARCHAR(100),
+    tracking_url VARCHAR(500),
+    shipped_at TIMESTAMP NULL,
+    delivered_at TIMESTAMP NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     
@@ -276,6 +419,8 @@ CREATE TABLE orders (
     INDEX idx_orders_user_id (user_id),
     INDEX idx_orders_order_number (order_number),
     INDEX idx_orders_status (status),
+    INDEX idx_orders_payment_status (payment_status),
+    INDEX idx_orders_shipping_method_id (shipping_method_id),
     INDEX idx_orders_created_at (created_at)
 );
 
@@ -289,6 +434,7 @@ CREATE TABLE order_items (
     quantity INT NOT NULL,
     unit_price DECIMAL(10,2) NOT NULL,
     total_price DECIMAL(10,2) NOT NULL,
+    tax_amount DECIMAL(10,2) DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     
     FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
@@ -299,6 +445,114 @@ CREATE TABLE order_items (
     INDEX idx_order_items_product_id (product_id)
 );
 
+-- Shipping Zones table
+CREATE TABLE shipping_zones (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- Shipping Zone Regions table
+CREATE TABLE shipping_zone_regions (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    zone_id INT UNSIGNED NOT NULL,
+    country VARCHAR(100) NOT NULL,
+    state VARCHAR(100) NOT NULL DEFAULT '',
+
+    FOREIGN KEY (zone_id) REFERENCES shipping_zones(id) ON DELETE CASCADE,
+    INDEX idx_shipping_zone_regions_zone_id (zone_id),
+    INDEX idx_shipping_zone_regions_country (country)
+);
+
+-- Shipping Methods table
+CREATE TABLE shipping_methods (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    zone_id INT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    type ENUM('flat_rate', 'weight_based', 'free_over') NOT NULL,
+    rate DECIMAL(10,2) DEFAULT 0,
+    free_threshold DECIMAL(10,2) NULL,
+    estimated_days VARCHAR(50),
+    sort_order INT DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (zone_id) REFERENCES shipping_zones(id) ON DELETE CASCADE,
+    INDEX idx_shipping_methods_zone_id (zone_id)
+);
+
+-- Shipping Rate Tiers table
+CREATE TABLE shipping_rate_tiers (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    method_id INT UNSIGNED NOT NULL,
+    min_weight DECIMAL(8,2) NOT NULL DEFAULT 0,
+    max_weight DECIMAL(8,2) NULL,
+    rate DECIMAL(10,2) NOT NULL,
+
+    FOREIGN KEY (method_id) REFERENCES shipping_methods(id) ON DELETE CASCADE,
+    INDEX idx_shipping_rate_tiers_method_id (method_id)
+);
+
+-- Tax Rules table
+CREATE TABLE tax_rules (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    country VARCHAR(100) NOT NULL,
+    state VARCHAR(100) NOT NULL DEFAULT '',
+    rate DECIMAL(7,4) NOT NULL,
+    inclusive BOOLEAN DEFAULT FALSE,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_tax_rules_region (country, state),
+    INDEX idx_tax_rules_is_active (is_active)
+);
+
+-- Order Taxes table
+CREATE TABLE order_taxes (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    order_id INT UNSIGNED NOT NULL,
+    tax_rule_id INT UNSIGNED NULL,
+    name VARCHAR(100) NOT NULL,
+    country VARCHAR(100) NOT NULL,
+    state VARCHAR(100),
+    rate DECIMAL(7,4) NOT NULL,
+    inclusive BOOLEAN DEFAULT FALSE,
+    taxable_amount DECIMAL(10,2) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (tax_rule_id) REFERENCES tax_rules(id) ON DELETE SET NULL,
+    INDEX idx_order_taxes_order_id (order_id),
+    INDEX idx_order_taxes_tax_rule_id (tax_rule_id)
+);
+
+-- Order Status History table
+CREATE TABLE order_status_history (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    order_id INT UNSIGNED NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor_id INT UNSIGNED NULL,
+    actor_role VARCHAR(20) NOT NULL,
+    note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL,
+    INDEX idx_order_status_history_order_id (order_id),
+    INDEX idx_order_status_history_actor_id (actor_id)
+);
+
 -- Payments table
 CREATE TABLE payments (
     id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
@@ -321,19 +575,62 @@ CREATE TABLE payments (
     INDEX idx_payments_status (payment_status)
 );
 
+-- Refunds table
+CREATE TABLE refunds (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    order_id INT UNSIGNED NOT NULL,
+    payment_id INT UNSIGNED NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    currency VARCHAR(3) DEFAULT 'USD',
+    reason TEXT,
+    status ENUM('pending', 'completed', 'failed') NOT NULL DEFAULT 'pending',
+    transaction_id VARCHAR(255),
+    gateway_response JSON,
+    restocked BOOLEAN DEFAULT FALSE,
+    actor_id INT UNSIGNED NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL,
+    INDEX idx_refunds_order_id (order_id),
+    INDEX idx_refunds_payment_id (payment_id),
+    INDEX idx_refunds_status (status)
+);
+
+-- Refund Items table
+CREATE TABLE refund_items (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    refund_id INT UNSIGNED NOT NULL,
+    order_item_id INT UNSIGNED NOT NULL,
+    quantity INT NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (refund_id) REFERENCES refunds(id) ON DELETE CASCADE,
+    FOREIGN KEY (order_item_id) REFERENCES order_items(id) ON DELETE CASCADE,
+    INDEX idx_refund_items_refund_id (refund_id),
+    INDEX idx_refund_items_order_item_id (order_item_id)
+);
+
 -- Cart table
 CREATE TABLE cart (
     id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
     resource_id CHAR(36) NOT NULL UNIQUE,
-    user_id INT UNSIGNED NOT NULL,
+    user_id INT UNSIGNED NULL, -- NULL for a guest's cart
     session_id VARCHAR(255),
+    discount_code VARCHAR(50),
+    expires_at TIMESTAMP NULL, -- guest carts are removed after this
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
     INDEX idx_cart_resource_id (resource_id),
     INDEX idx_cart_user_id (user_id),
-    INDEX idx_cart_session_id (session_id)
+    INDEX idx_cart_session_id (session_id),
+    INDEX idx_cart_expires_at (expires_at)
 );
 
 -- Cart Items table
@@ -344,6 +641,7 @@ CREATE TABLE cart_items (
     product_id INT UNSIGNED NOT NULL,
     variant_id INT UNSIGNED NULL,
     quantity INT NOT NULL,
+    price DECIMAL(10,2) NOT NULL DEFAULT 0, -- unit price when the line was added or last changed
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     
@@ -378,9 +676,9 @@ CREATE TABLE discounts (
     code VARCHAR(50) NOT NULL UNIQUE,
     name VARCHAR(255) NOT NULL,
     description TEXT,
-    type ENUM('percentage', 'fixed_amount') NOT NULL,
+    type ENUM('percentage', 'fixed_amount', 'free_shipping') NOT NULL,
     value DECIMAL(10,2) NOT NULL,
-    minimum_amount DECIMAL(10,2),
+    minimum_amount DECIMAL(10,2) DEFAULT 0,
     maximum_discount DECIMAL(10,2),
     usage_limit INT,
     used_count INT DEFAULT 0,
@@ -401,10 +699,11 @@ CREATE TABLE discounts (
 CREATE TABLE promotions (
     id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
     resource_id CHAR(36) NOT NULL UNIQUE,
-    name VARCHAR(255) NOT NULL,
+    title VARCHAR(200) NOT NULL,
     description TEXT,
     type ENUM('banner', 'popup', 'sidebar') NOT NULL,
-    content TEXT NOT NULL,
+    position ENUM('top', 'bottom', 'left', 'right', 'center') NOT NULL DEFAULT 'top',
+    content TEXT NULL,
     image VARCHAR(500),
     link_url VARCHAR(500),
     is_active BOOLEAN DEFAULT TRUE,
@@ -415,7 +714,104 @@ CREATE TABLE promotions (
     
     INDEX idx_promotions_resource_id (resource_id),
     INDEX idx_promotions_type (type),
+    INDEX idx_promotions_position (position),
     INDEX idx_promotions_is_active (is_active),
     INDEX idx_promotions_starts_at (starts_at),
     INDEX idx_promotions_expires_at (expires_at)
-);
\ No newline at end of file
+);
+-- Admin audit log (append-only: updates and deletes are rejected)
+CREATE TABLE audit_logs (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    actor_id INT UNSIGNED NULL,
+    action VARCHAR(100) NOT NULL,
+    entity_type VARCHAR(50),
+    entity_id VARCHAR(100),
+    `before` JSON NULL,
+    `after` JSON NULL,
+    method VARCHAR(10),
+    path VARCHAR(255),
+    status_code INT,
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(255),
+    request_id VARCHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_audit_logs_actor_id (actor_id),
+    INDEX idx_audit_logs_action (action),
+    INDEX idx_audit_logs_entity (entity_type, entity_id),
+    INDEX idx_audit_logs_request_id (request_id),
+    INDEX idx_audit_logs_created_at (created_at)
+);
+
+CREATE TRIGGER audit_logs_no_update BEFORE UPDATE ON audit_logs
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
+
+CREATE TRIGGER audit_logs_no_delete BEFORE DELETE ON audit_logs
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_logs is append-only';
+
+-- Outbox of emails and webhook calls waiting to be sent, written in the same
+-- transaction as the change that caused them and delivered by background
+-- workers
+CREATE TABLE email_outbox (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    channel VARCHAR(20) NOT NULL DEFAULT 'email',
+    kind VARCHAR(50) NOT NULL,
+    to_email VARCHAR(255) NOT NULL,
+    to_name VARCHAR(255),
+    subject VARCHAR(255) NOT NULL,
+    text_body MEDIUMTEXT,
+    html_body MEDIUMTEXT,
+    payload MEDIUMTEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP NULL,
+    expires_at TIMESTAMP NULL,
+    last_error TEXT,
+    sent_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_email_outbox_kind (kind),
+    INDEX idx_email_outbox_due (status, next_attempt_at),
+    INDEX idx_email_outbox_created_at (created_at)
+);
+
+-- Customers waiting to hear that an out of stock product can be ordered
+-- again. A row is removed when its email is queued.
+CREATE TABLE stock_alerts (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    user_id INT UNSIGNED NOT NULL,
+    product_id INT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    UNIQUE KEY idx_stock_alerts_user_product (user_id, product_id),
+    INDEX idx_stock_alerts_product_id (product_id)
+);
+
+-- In-app notification inbox of each user
+CREATE TABLE notifications (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    resource_id CHAR(36) NOT NULL UNIQUE,
+    user_id INT UNSIGNED NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT,
+    link VARCHAR(500),
+    read_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_notifications_user_created (user_id, created_at)
+);
+
+-- Scheduled time each background job last ran for, shared by all replicas
+CREATE TABLE job_runs (
+    name VARCHAR(64) NOT NULL PRIMARY KEY,
+    last_slot TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
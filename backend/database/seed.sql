@@ -126,6 +126,12 @@ INSERT INTO users (resource_id, username, email, password, first_name, last_name
 ('user-004', 'mike_wilson', 'mike@example.com', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', 'Mike', 'Wilson', '+1-555-0103', true, false, false, NOW(), NOW()),
 ('user-005', 'sarah_jones', 'sarah@example.com', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', 'Sarah', 'Jones', '+1-555-0104', true, false, true, NOW(), NOW());
 
+-- Make the admin user a super admin (the other built-in roles are created at server startup)
+INSERT INTO permissions (name, description) VALUES ('*', 'Every permission');
+INSERT INTO roles (resource_id, name, description) VALUES ('role-001', 'super_admin', 'Full access to the admin panel');
+INSERT INTO role_permissions (role_id, permission_id) VALUES (1, 1);
+INSERT INTO user_roles (user_id, role_id) VALUES (1, 1);
+
 -- Insert sample addresses
 INSERT INTO addresses (resource_id, user_id, type, first_name, last_name, company, address_line_1, address_line_2, city, state, postal_code, country, phone, is_default, created_at, updated_at) VALUES
 ('addr-001', 2, 'shipping', 'John', 'Doe', 'Tech Corp', '123 Main St', 'Apt 4B', 'New York', 'NY', '10001', 'USA', '+1-555-0101', true, NOW(), NOW()),
@@ -194,16 +200,18 @@ INSERT INTO discounts (resource_id, code, name, description, type, value, minimu
 ('disc-004', 'STUDENT10', 'Student Discount', '10% off for students', 'percentage', 10.00, 50.00, 100.00, 100, 12, true, NOW(), DATE_ADD(NOW(), INTERVAL 90 DAY), NOW(), NOW());
 
 -- Insert sample promotions
-INSERT INTO promotions (resource_id, name, description, type, content, image, link_url, is_active, starts_at, expires_at, created_at, updated_at) VALUES
-('promo-001', 'New iPhone 15 Pro Available!', 'Get the latest iPhone 15 Pro with titanium design and A17 Pro chip', 'banner', 'Shop the new iPhone 15 Pro with titanium design and A17 Pro chip. Available now!', 'https://images.unsplash.com/photo-1592899677977-9c10ca588bbd?w=1200', '/products/iphone-15-pro', true, NOW(), DATE_ADD(NOW(), INTERVAL 30 DAY), NOW(), NOW()),
-('promo-002', 'Free Shipping on Orders Over $50', 'Enjoy free shipping on all orders over $50', 'banner', 'Free shipping on all orders over $50. No minimum purchase required for premium members!', 'https://images.unsplash.com/photo-1556742049-0cfed4f6a45d?w=1200', '/shipping-info', true, NOW(), DATE_ADD(NOW(), INTERVAL 60 DAY), NOW(), NOW()),
-('promo-003', 'Gaming Sale - Up to 30% Off', 'Up to 30% off on gaming consoles and accessories', 'popup', 'Don\'t miss our gaming sale! Up to 30% off on PlayStation 5, Xbox Series X, and gaming accessories.', 'https://images.unsplash.com/photo-1606144042614-b2417e99c4e3?w=1200', '/categories/gaming', false, DATE_ADD(NOW(), INTERVAL 1 DAY), DATE_ADD(NOW(), INTERVAL 7 DAY), NOW(), NOW()),
-('promo-004', 'MacBook Pro M3 - Professional Power', 'MacBook Pro with M3 Pro chip for professionals', 'sidebar', 'MacBook Pro with M3 Pro chip delivers incredible performance for professionals. Order now!', 'https://images.unsplash.com/photo-1496181133206-80ce9b88a853?w=800', '/products/macbook-pro-16', true, NOW(), DATE_ADD(NOW(), INTERVAL 45 DAY), NOW(), NOW());
-
--- Insert sample OTP verifications (for testing)
-INSERT INTO otp_verifications (resource_id, user_id, email, otp_code, otp_type, is_used, expires_at, created_at, updated_at) VALUES
-('otp-001', 4, 'mike@example.com', '123456', 'email_verification', false, DATE_ADD(NOW(), INTERVAL 15 MINUTE), NOW(), NOW()),
-('otp-002', 2, 'john@example.com', '654321', 'password_reset', false, DATE_ADD(NOW(), INTERVAL 15 MINUTE), NOW(), NOW());
+INSERT INTO promotions (resource_id, title, description, type, position, content, image, link_url, is_active, starts_at, expires_at, created_at, updated_at) VALUES
+('promo-001', 'New iPhone 15 Pro Available!', 'Get the latest iPhone 15 Pro with titanium design and A17 Pro chip', 'banner', 'top', 'Shop the new iPhone 15 Pro with titanium design and A17 Pro chip. Available now!', 'https://images.unsplash.com/photo-1592899677977-9c10ca588bbd?w=1200', '/products/iphone-15-pro', true, NOW(), DATE_ADD(NOW(), INTERVAL 30 DAY), NOW(), NOW()),
+('promo-002', 'Free Shipping on Orders Over $50', 'Enjoy free shipping on all orders over $50', 'banner', 'bottom', 'Free shipping on all orders over $50. No minimum purchase required for premium members!', 'https://images.unsplash.com/photo-1556742049-0cfed4f6a45d?w=1200', '/shipping-info', true, NOW(), DATE_ADD(NOW(), INTERVAL 60 DAY), NOW(), NOW()),
+('promo-003', 'Gaming Sale - Up to 30% Off', 'Up to 30% off on gaming consoles and accessories', 'popup', 'center', 'Don\'t miss our gaming sale! Up to 30% off on PlayStation 5, Xbox Series X, and gaming accessories.', 'https://images.unsplash.com/photo-1606144042614-b2417e99c4e3?w=1200', '/categories/gaming', false, DATE_ADD(NOW(), INTERVAL 1 DAY), DATE_ADD(NOW(), INTERVAL 7 DAY), NOW(), NOW()),
+('promo-004', 'MacBook Pro M3 - Professional Power', 'MacBook Pro with M3 Pro chip for professionals', 'sidebar', 'right', 'MacBook Pro with M3 Pro chip delivers incredible performance for professionals. Order now!', 'https://images.unsplash.com/photo-1496181133206-80ce9b88a853?w=800', '/products/macbook-pro-16', true, NOW(), DATE_ADD(NOW(), INTERVAL 45 DAY), NOW(), NOW());
+
+-- Insert sample OTP verifications (for testing). The codes are 123456 for
+-- challenge otp-001 and 654321 for otp-002, hashed under the default
+-- OTP_HASH_KEY (change-me-otp-hash-key).
+INSERT INTO otp_verifications (resource_id, user_id, email, code_hash, otp_type, is_used, expires_at, created_at, updated_at) VALUES
+('otp-001', 4, 'mike@example.com', 'a4c11d33718bbc72b1b0b8273882183005e100ae52f2f247d014d84c9092a341', 'email_verification', false, DATE_ADD(NOW(), INTERVAL 15 MINUTE), NOW(), NOW()),
+('otp-002', 2, 'john@example.com', '1e65c7ce3b133002b19e61a124a25a0021053f8e51cc4b9f1558a76e1c58643f', 'password_reset', false, DATE_ADD(NOW(), INTERVAL 15 MINUTE), NOW(), NOW());
 
 -- Reset auto-increment values
 ALTER TABLE users AUTO_INCREMENT = 6;
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AddressHandler struct {
+	addressUsecase usecase.AddressUsecase
+}
+
+func NewAddressHandler(addressUsecase usecase.AddressUsecase) *AddressHandler {
+	return &AddressHandler{
+		addressUsecase: addressUsecase,
+	}
+}
+
+// List godoc
+// @Summary List addresses
+// @Description Get the current user's address book, default addresses first
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.AddressResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /addresses [get]
+func (h *AddressHandler) List(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	addresses, err := h.addressUsecase.List(c.Request.Context(), userID.(uint))
+	if err != nil {
+		respondAddressError(c, "Failed to get addresses", err)
+		return
+	}
+
+	responses := make([]dto.AddressResponse, 0, len(addresses))
+	for _, address := range addresses {
+		responses = append(responses, newAddressResponse(address))
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// Get godoc
+// @Summary Get an address
+// @Description Get a single address of the current user
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param id path string true "Address Resource ID"
+// @Success 200 {object} dto.AddressResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /addresses/{id} [get]
+func (h *AddressHandler) Get(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	address, err := h.addressUsecase.Get(c.Request.Context(), userID.(uint), c.Param("id"))
+	if err != nil {
+		respondAddressError(c, "Failed to get address", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newAddressResponse(address))
+}
+
+// Create godoc
+// @Summary Create an address
+// @Description Add an address to the current user's address book. The first address of a type becomes its default; is_default makes it the default and unsets the previous one.
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAddressRequest true "Address data"
+// @Success 201 {object} dto.AddressResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /addresses [post]
+func (h *AddressHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req dto.CreateAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	address, err := h.addressUsecase.Create(c.Request.Context(), userID.(uint), req)
+	if err != nil {
+		respondAddressError(c, "Failed to create address", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newAddressResponse(address))
+}
+
+// Update godoc
+// @Summary Update an address
+// @Description Update an address of the current user. Orders already placed keep the address they were placed with.
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param id path string true "Address Resource ID"
+// @Param request body dto.UpdateAddressRequest true "Address data"
+// @Success 200 {object} dto.AddressResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /addresses/{id} [put]
+func (h *AddressHandler) Update(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req dto.UpdateAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	address, err := h.addressUsecase.Update(c.Request.Context(), userID.(uint), c.Param("id"), req)
+	if err != nil {
+		respondAddressError(c, "Failed to update address", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newAddressResponse(address))
+}
+
+// Delete godoc
+// @Summary Delete an address
+// @Description Delete an address of the current user. Deleting a default address makes the most recently updated address of the same type the default.
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param id path string true "Address Resource ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /addresses/{id} [delete]
+func (h *AddressHandler) Delete(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.addressUsecase.Delete(c.Request.Context(), userID.(uint), c.Param("id")); err != nil {
+		respondAddressError(c, "Failed to delete address", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Address deleted successfully",
+	})
+}
+
+// respondAddressError maps address usecase errors to HTTP responses
+func respondAddressError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrAddressNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidAddress):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
+
+// newAddressResponse converts an address book entry to its API representation
+func newAddressResponse(address *models.Address) dto.AddressResponse {
+	return dto.AddressResponse{
+		ResourceID:   address.ResourceID,
+		Type:         address.Type,
+		FirstName:    address.FirstName,
+		LastName:     address.LastName,
+		Company:      address.Company,
+		AddressLine1: address.AddressLine1,
+		AddressLine2: address.AddressLine2,
+		City:         address.City,
+		State:        address.State,
+		PostalCode:   address.PostalCode,
+		Country:      address.Country,
+		Phone:        address.Phone,
+		IsDefault:    address.IsDefault,
+		CreatedAt:    address.CreatedAt,
+		UpdatedAt:    address.UpdatedAt,
+	}
+}
+
+// newOrderAddressResponse converts the address copied onto an order
+func newOrderAddressResponse(snapshot *models.AddressSnapshot) *dto.OrderAddressResponse {
+	if snapshot == nil {
+		return nil
+	}
+	return &dto.OrderAddressResponse{
+		FirstName:    snapshot.FirstName,
+		LastName:     snapshot.LastName,
+		Company:      snapshot.Company,
+		AddressLine1: snapshot.AddressLine1,
+		AddressLine2: snapshot.AddressLine2,
+		City:         snapshot.City,
+		State:        snapshot.State,
+		PostalCode:   snapshot.PostalCode,
+		Country:      snapshot.Country,
+		Phone:        snapshot.Phone,
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+
+	"electronics-store/internal/dto"
+	"electronics-store/internal/repository"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminAuditLogsHandler struct {
+	auditUsecase usecase.AuditUsecase
+	userRepo     repository.UserRepository
+}
+
+func NewAdminAuditLogsHandler(auditUsecase usecase.AuditUsecase, userRepo repository.UserRepository) *AdminAuditLogsHandler {
+	return &AdminAuditLogsHandler{
+		auditUsecase: auditUsecase,
+		userRepo:     userRepo,
+	}
+}
+
+// ListAuditLogs godoc
+// @Summary List the admin audit log (Admin)
+// @Description Get changes made through the admin API, newest first. Before and after hold only the fields that changed.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param actor query string false "Resource ID of the user who made the change"
+// @Param action query string false "Action, e.g. order.status_update"
+// @Param entity_type query string false "Entity type, e.g. product"
+// @Param entity_id query string false "Entity resource ID"
+// @Param request_id query string false "Request ID"
+// @Param from query string false "Earliest time (RFC 3339)"
+// @Param to query string false "Latest time, exclusive (RFC 3339)"
+// @Success 200 {object} dto.AuditLogListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/audit-logs [get]
+func (h *AdminAuditLogsHandler) ListAuditLogs(c *gin.Context) {
+	var req dto.AdminAuditLogListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Set defaults
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	ctx := c.Request.Context()
+	response := dto.AuditLogListResponse{
+		AuditLogs: []dto.AuditLogResponse{},
+		Page:      req.Page,
+		Limit:     req.Limit,
+	}
+
+	filters := make(map[string]interface{})
+	if req.Actor != "" {
+		actor, err := h.userRepo.GetByResourceID(ctx, req.Actor)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Failed to get audit logs",
+				Message: err.Error(),
+			})
+			return
+		}
+		if actor == nil {
+			c.JSON(http.StatusOK, response)
+			return
+		}
+		filters["actor_id"] = actor.ID
+	}
+	if req.Action != "" {
+		filters["action"] = req.Action
+	}
+	if req.EntityType != "" {
+		filters["entity_type"] = req.EntityType
+	}
+	if req.EntityID != "" {
+		filters["entity_id"] = req.EntityID
+	}
+	if req.RequestID != "" {
+		filters["request_id"] = req.RequestID
+	}
+	if req.From != nil {
+		filters["from"] = *req.From
+	}
+	if req.To != nil {
+		filters["to"] = *req.To
+	}
+
+	entries, total, err := h.auditUsecase.List(ctx, req.Page, req.Limit, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to get audit logs",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	for _, entry := range entries {
+		item := dto.AuditLogResponse{
+			ID:         entry.ID,
+			Action:     entry.Action,
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID,
+			Before:     entry.Before,
+			After:      entry.After,
+			Method:     entry.Method,
+			Path:       entry.Path,
+			StatusCode: entry.StatusCode,
+			IPAddress:  entry.IPAddress,
+			UserAgent:  entry.UserAgent,
+			RequestID:  entry.RequestID,
+			CreatedAt:  entry.CreatedAt,
+		}
+		if entry.Actor != nil {
+			item.Actor = &dto.UserSummary{
+				ID:        entry.Actor.ID,
+				FirstName: entry.Actor.FirstName,
+				LastName:  entry.Actor.LastName,
+				Email:     entry.Actor.Email,
+			}
+		}
+		response.AuditLogs = append(response.AuditLogs, item)
+	}
+	response.Total = total
+
+	c.JSON(http.StatusOK, response)
+}
@@ -7,6 +7,7 @@ import (
 	"electronics-store/internal/dto"
 	"electronics-store/internal/domain/models"
 	"electronics-store/internal/repository"
+	"electronics-store/internal/usecase"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -173,6 +174,12 @@ func (h *AdminCategoriesHandler) CreateCategory(c *gin.Context) {
 		})
 		return
 	}
+	usecase.RecordAudit(ctx, usecase.AuditChange{
+		Action:     "category.create",
+		EntityType: "category",
+		EntityID:   category.ResourceID,
+		After:      usecase.AuditSnapshot(category),
+	})
 
 	c.JSON(http.StatusCreated, dto.CategoryResponse{
 		ResourceID:  category.ResourceID,
@@ -238,6 +245,7 @@ func (h *AdminCategoriesHandler) UpdateCategory(c *gin.Context) {
 		})
 		return
 	}
+	before := usecase.AuditSnapshot(category)
 
 	// Update fields
 	if req.Name != nil {
@@ -286,6 +294,13 @@ func (h *AdminCategoriesHandler) UpdateCategory(c *gin.Context) {
 		})
 		return
 	}
+	usecase.RecordAudit(ctx, usecase.AuditChange{
+		Action:     "category.update",
+		EntityType: "category",
+		EntityID:   category.ResourceID,
+		Before:     before,
+		After:      usecase.AuditSnapshot(category),
+	})
 
 	c.JSON(http.StatusOK, dto.CategoryResponse{
 		ResourceID:  category.ResourceID,
@@ -355,6 +370,12 @@ func (h *AdminCategoriesHandler) DeleteCategory(c *gin.Context) {
 		})
 		return
 	}
+	usecase.RecordAudit(ctx, usecase.AuditChange{
+		Action:     "category.delete",
+		EntityType: "category",
+		EntityID:   category.ResourceID,
+		Before:     usecase.AuditSnapshot(category),
+	})
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Message: "Category deleted successfully",
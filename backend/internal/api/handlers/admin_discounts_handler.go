@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminDiscountsHandler struct {
+	discountUsecase usecase.DiscountUsecase
+}
+
+func NewAdminDiscountsHandler(discountUsecase usecase.DiscountUsecase) *AdminDiscountsHandler {
+	return &AdminDiscountsHandler{
+		discountUsecase: discountUsecase,
+	}
+}
+
+// ListDiscounts godoc
+// @Summary List all discounts (Admin)
+// @Description Get a paginated list of discount codes
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} dto.DiscountListResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/discounts [get]
+func (h *AdminDiscountsHandler) ListDiscounts(c *gin.Context) {
+	var req dto.AdminDiscountListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Set defaults
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	discounts, total, err := h.discountUsecase.List(c.Request.Context(), req.Page, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to get discounts",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	discountResponses := make([]dto.DiscountResponse, 0, len(discounts))
+	for _, discount := range discounts {
+		discountResponses = append(discountResponses, newDiscountResponse(discount))
+	}
+
+	c.JSON(http.StatusOK, dto.DiscountListResponse{
+		Discounts: discountResponses,
+		Total:     total,
+		Page:      req.Page,
+		Limit:     req.Limit,
+	})
+}
+
+// GetDiscount godoc
+// @Summary Get a discount (Admin)
+// @Description Get a single discount code
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Discount Resource ID"
+// @Success 200 {object} dto.DiscountResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/discounts/{id} [get]
+func (h *AdminDiscountsHandler) GetDiscount(c *gin.Context) {
+	discount, err := h.discountUsecase.GetByResourceID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondDiscountError(c, "Failed to get discount", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newDiscountResponse(discount))
+}
+
+// CreateDiscount godoc
+// @Summary Create a discount
+// @Description Create a new discount code (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateDiscountRequest true "Discount data"
+// @Success 201 {object} dto.DiscountResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/discounts [post]
+func (h *AdminDiscountsHandler) CreateDiscount(c *gin.Context) {
+	var req dto.CreateDiscountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	discount, err := h.discountUsecase.Create(c.Request.Context(), req)
+	if err != nil {
+		respondDiscountError(c, "Failed to create discount", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newDiscountResponse(discount))
+}
+
+// UpdateDiscount godoc
+// @Summary Update a discount
+// @Description Update an existing discount code (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Discount Resource ID"
+// @Param request body dto.UpdateDiscountRequest true "Discount data"
+// @Success 200 {object} dto.DiscountResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/discounts/{id} [put]
+func (h *AdminDiscountsHandler) UpdateDiscount(c *gin.Context) {
+	var req dto.UpdateDiscountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	discount, err := h.discountUsecase.Update(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondDiscountError(c, "Failed to update discount", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newDiscountResponse(discount))
+}
+
+// DeleteDiscount godoc
+// @Summary Delete a discount
+// @Description Delete a discount code (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Discount Resource ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/discounts/{id} [delete]
+func (h *AdminDiscountsHandler) DeleteDiscount(c *gin.Context) {
+	if err := h.discountUsecase.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		respondDiscountError(c, "Failed to delete discount", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Discount deleted successfully",
+	})
+}
+
+// respondDiscountError maps discount usecase errors to HTTP responses
+func respondDiscountError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrDiscountNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "Discount not found",
+			Message: "Discount with the given ID does not exist",
+		})
+		return
+	case errors.Is(err, usecase.ErrInvalidDiscount):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrDiscountCodeTaken):
+		status = http.StatusConflict
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
+
+// newDiscountResponse converts a discount model to its API representation
+func newDiscountResponse(discount *models.Discount) dto.DiscountResponse {
+	return dto.DiscountResponse{
+		ResourceID:      discount.ResourceID,
+		Name:            discount.Name,
+		Code:            discount.Code,
+		Type:            discount.Type,
+		Value:           discount.Value,
+		MinimumAmount:   discount.MinimumAmount,
+		MaximumDiscount: discount.MaximumDiscount,
+		UsageLimit:      discount.UsageLimit,
+		UsedCount:       discount.UsedCount,
+		IsActive:        discount.IsActive,
+		StartsAt:        discount.StartsAt,
+		ExpiresAt:       discount.ExpiresAt,
+		CreatedAt:       discount.CreatedAt,
+		UpdatedAt:       discount.UpdatedAt,
+	}
+}
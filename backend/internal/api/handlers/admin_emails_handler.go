@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/repository"
+	"electronics-store/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminEmailsHandler struct {
+	emailOutboxRepo repository.EmailOutboxRepository
+	emailQueue      *services.EmailQueue
+	emailService    *services.EmailService
+}
+
+func NewAdminEmailsHandler(emailOutboxRepo repository.EmailOutboxRepository, emailQueue *services.EmailQueue, emailService *services.EmailService) *AdminEmailsHandler {
+	return &AdminEmailsHandler{
+		emailOutboxRepo: emailOutboxRepo,
+		emailQueue:      emailQueue,
+		emailService:    emailService,
+	}
+}
+
+// ListEmails godoc
+// @Summary List queued emails (Admin)
+// @Description Get emails and webhook calls in the outbox with their delivery status, newest first
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param status query string false "Delivery status" Enums(pending, sending, sent, dead)
+// @Param channel query string false "Delivery channel" Enums(email, webhook)
+// @Success 200 {object} dto.OutboxEmailListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/emails [get]
+func (h *AdminEmailsHandler) ListEmails(c *gin.Context) {
+	var req dto.AdminEmailListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Set defaults
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	emails, total, err := h.emailOutboxRepo.List(c.Request.Context(), req.Status, req.Channel, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to get emails",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	emailResponses := make([]dto.OutboxEmailResponse, 0, len(emails))
+	for _, email := range emails {
+		emailResponses = append(emailResponses, newOutboxEmailResponse(email))
+	}
+
+	c.JSON(http.StatusOK, dto.OutboxEmailListResponse{
+		Emails: emailResponses,
+		Total:  total,
+		Page:   req.Page,
+		Limit:  req.Limit,
+	})
+}
+
+// GetEmail godoc
+// @Summary Get a queued email (Admin)
+// @Description Get the delivery status of one email
+// @Tags admin
+// @Produce json
+// @Param id path string true "Email resource ID"
+// @Success 200 {object} dto.OutboxEmailResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/emails/{id} [get]
+func (h *AdminEmailsHandler) GetEmail(c *gin.Context) {
+	email, err := h.emailOutboxRepo.GetByResourceID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondEmailError(c, "Failed to get email", err)
+		return
+	}
+	if email == nil {
+		respondEmailError(c, "Failed to get email", services.ErrOutboxEmailNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, newOutboxEmailResponse(email))
+}
+
+// RetryEmail godoc
+// @Summary Retry a dead email (Admin)
+// @Description Put an email that ran out of attempts back in the queue
+// @Tags admin
+// @Produce json
+// @Param id path string true "Email resource ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/emails/{id}/retry [post]
+func (h *AdminEmailsHandler) RetryEmail(c *gin.Context) {
+	if err := h.emailQueue.Retry(c.Request.Context(), c.Param("id")); err != nil {
+		respondEmailError(c, "Failed to retry email", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Email queued for another attempt",
+	})
+}
+
+// ListTemplates godoc
+// @Summary List email templates (Admin)
+// @Description Get the emails of the template registry
+// @Tags admin
+// @Produce json
+// @Success 200 {object} dto.EmailTemplateListResponse
+// @Router /admin/email-templates [get]
+func (h *AdminEmailsHandler) ListTemplates(c *gin.Context) {
+	templates := services.EmailTemplateList()
+	responses := make([]dto.EmailTemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		responses = append(responses, dto.EmailTemplateResponse{
+			Name:        template.Name,
+			Description: template.Description,
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.EmailTemplateListResponse{
+		Templates: responses,
+	})
+}
+
+// PreviewTemplate godoc
+// @Summary Preview an email template (Admin)
+// @Description Render an email template with sample data. With format=html or format=text the body is returned as is, e.g. to open it in a browser.
+// @Tags admin
+// @Produce json,html,plain
+// @Param name path string true "Template name"
+// @Param format query string false "Response format" Enums(json, html, text) default(json)
+// @Success 200 {object} dto.EmailPreviewResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/email-templates/{name}/preview [get]
+func (h *AdminEmailsHandler) PreviewTemplate(c *gin.Context) {
+	var req dto.EmailPreviewRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	name := c.Param("name")
+	email, err := h.emailService.Preview(name)
+	if err != nil {
+		respondEmailError(c, "Failed to preview email", err)
+		return
+	}
+
+	switch req.Format {
+	case "html":
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(email.HTMLBody))
+	case "text":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(email.TextBody))
+	default:
+		c.JSON(http.StatusOK, dto.EmailPreviewResponse{
+			Name:    name,
+			Subject: email.Subject,
+			Text:    email.TextBody,
+			HTML:    email.HTMLBody,
+		})
+	}
+}
+
+func respondEmailError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrOutboxEmailNotFound), errors.Is(err, services.ErrEmailTemplateNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrOutboxEmailNotDead):
+		status = http.StatusConflict
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
+
+func newOutboxEmailResponse(email *models.OutboxEmail) dto.OutboxEmailResponse {
+	response := dto.OutboxEmailResponse{
+		ResourceID: email.ResourceID,
+		Channel:    email.Channel,
+		Kind:       email.Kind,
+		ToEmail:    email.ToEmail,
+		Subject:    email.Subject,
+		Status:     email.Status,
+		Attempts:   email.Attempts,
+		LastError:  email.LastError,
+		ExpiresAt:  email.ExpiresAt,
+		SentAt:     email.SentAt,
+		CreatedAt:  email.CreatedAt,
+	}
+	if email.Status == models.OutboxEmailPending {
+		response.NextAttemptAt = &email.NextAttemptAt
+	}
+	return response
+}
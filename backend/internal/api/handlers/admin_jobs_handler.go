@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"electronics-store/internal/dto"
+	"electronics-store/internal/scheduler"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminJobsHandler struct {
+	jobs    *scheduler.Scheduler
+	enabled bool
+}
+
+func NewAdminJobsHandler(jobs *scheduler.Scheduler, enabled bool) *AdminJobsHandler {
+	return &AdminJobsHandler{
+		jobs:    jobs,
+		enabled: enabled,
+	}
+}
+
+// ListJobs godoc
+// @Summary List background jobs (Admin)
+// @Description Get the schedule and run metrics of every background job. Metrics are those of the instance serving the request; skipped counts runs another instance took.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} dto.JobListResponse
+// @Router /admin/jobs [get]
+func (h *AdminJobsHandler) ListJobs(c *gin.Context) {
+	response := dto.JobListResponse{
+		Enabled: h.enabled,
+		Jobs:    []dto.JobStatusResponse{},
+	}
+	for _, stats := range h.jobs.Stats() {
+		response.Jobs = append(response.Jobs, dto.JobStatusResponse{
+			Name:           stats.Name,
+			Schedule:       stats.Schedule,
+			Running:        stats.Running,
+			Runs:           stats.Runs,
+			Failures:       stats.Failures,
+			Skipped:        stats.Skipped,
+			LastRunAt:      stats.LastRunAt,
+			LastSuccessAt:  stats.LastSuccessAt,
+			LastDurationMs: stats.LastDuration.Milliseconds(),
+			LastError:      stats.LastError,
+			NextRunAt:      stats.NextRunAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"electronics-store/internal/dto"
 	"electronics-store/internal/domain/models"
 	"electronics-store/internal/repository"
+	"electronics-store/internal/usecase"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,59 +21,17 @@ func getPaymentStatus(status string) string {
 	return status
 }
 
-// calculateOrderPaymentStatus determines the order payment status from payments array
-// Priority: refunded > failed > completed > processing > pending
-func calculateOrderPaymentStatus(payments []models.Payment) string {
-	if len(payments) == 0 {
-		return "pending"
-	}
-
-	// Check for refunded payments first (highest priority)
-	for _, payment := range payments {
-		if payment.Status == "refunded" {
-			return "refunded"
-		}
-	}
-
-	// Check for failed payments
-	for _, payment := range payments {
-		if payment.Status == "failed" {
-			return "failed"
-		}
-	}
-
-	// Check if all payments are completed
-	allCompleted := true
-	hasProcessing := false
-	for _, payment := range payments {
-		if payment.Status == "processing" {
-			hasProcessing = true
-			allCompleted = false
-		}
-		if payment.Status != "completed" && payment.Status != "processing" {
-			allCompleted = false
-		}
-	}
-
-	if allCompleted && !hasProcessing {
-		return "completed"
-	}
-
-	if hasProcessing {
-		return "processing"
-	}
-
-	// Default to pending if any payment is pending or unknown
-	return "pending"
-}
-
 type AdminOrdersHandler struct {
-	orderRepo repository.OrderRepository
+	orderRepo      repository.OrderRepository
+	orderUsecase   usecase.OrderUsecase
+	paymentUsecase usecase.PaymentUsecase
 }
 
-func NewAdminOrdersHandler(orderRepo repository.OrderRepository) *AdminOrdersHandler {
+func NewAdminOrdersHandler(orderRepo repository.OrderRepository, orderUsecase usecase.OrderUsecase, paymentUsecase usecase.PaymentUsecase) *AdminOrdersHandler {
 	return &AdminOrdersHandler{
-		orderRepo: orderRepo,
+		orderRepo:      orderRepo,
+		orderUsecase:   orderUsecase,
+		paymentUsecase: paymentUsecase,
 	}
 }
 
@@ -233,20 +193,13 @@ func (h *AdminOrdersHandler) ListOrders(c *gin.Context) {
 			})
 		}
 
-		// Calculate order payment status from payments array (takes precedence over order.payment_status)
-		calculatedPaymentStatus := calculateOrderPaymentStatus(order.Payments)
-		// Fallback to order.PaymentStatus if no payments exist
-		if calculatedPaymentStatus == "pending" && len(order.Payments) == 0 {
-			calculatedPaymentStatus = getPaymentStatus(order.PaymentStatus)
-		}
-
 		orderResponses = append(orderResponses, dto.AdminOrderDetailResponse{
 			OrderResponse: dto.OrderResponse{
 				ResourceID:     order.ResourceID,
 				OrderNumber:    order.OrderNumber,
 				UserID:         order.UserID,
 				Status:         order.Status,
-				PaymentStatus:  calculatedPaymentStatus,
+				PaymentStatus:  getPaymentStatus(order.PaymentStatus),
 				Subtotal:       order.Subtotal,
 				TaxAmount:      order.TaxAmount,
 				ShippingCost:   order.ShippingCost,
@@ -315,117 +268,203 @@ func (h *AdminOrdersHandler) UpdateOrderStatus(c *gin.Context) {
 		return
 	}
 
-	// Update status
-	order.Status = req.Status
-	if req.Notes != "" {
-		order.Notes = req.Notes
+	// Apply the transition (validated by the order state machine)
+	change := usecase.StatusChange{
+		Status:         req.Status,
+		Note:           req.Notes,
+		ActorRole:      usecase.OrderActorAdmin,
+		Carrier:        req.Carrier,
+		TrackingNumber: req.TrackingNumber,
+		TrackingURL:    req.TrackingURL,
+	}
+	if adminID, ok := c.Get("user_id"); ok {
+		id := adminID.(uint)
+		change.ActorID = &id
 	}
 
-	// Update order
-	if err := h.orderRepo.Update(ctx, order); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+	updatedOrder, err := h.orderUsecase.UpdateStatus(ctx, order.ID, change)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidStatusTransition) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, dto.ErrorResponse{
 			Error:   "Failed to update order",
 			Message: err.Error(),
 		})
 		return
 	}
 
-	// Fetch updated order
-	updatedOrder, err := h.orderRepo.GetByResourceID(ctx, resourceID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "Failed to fetch updated order",
+	c.JSON(http.StatusOK, newAdminOrderDetailResponse(updatedOrder))
+}
+
+// GetOrder godoc
+// @Summary Get order details (Admin)
+// @Description Get a single order with line items, payments and status history
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Order Resource ID"
+// @Success 200 {object} dto.AdminOrderDetailResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/orders/{id} [get]
+func (h *AdminOrdersHandler) GetOrder(c *gin.Context) {
+	order, err := h.orderRepo.GetByResourceID(c.Request.Context(), c.Param("id"))
+	if err != nil || order == nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "Order not found",
+			Message: "Order with the given ID does not exist",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, newAdminOrderDetailResponse(order))
+}
+
+// CreateRefund godoc
+// @Summary Refund an order payment
+// @Description Refund part or all of a payment, optionally per line item and with restocking (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Order Resource ID"
+// @Param request body dto.CreateRefundRequest true "Refund details"
+// @Success 201 {object} dto.CreateRefundResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 502 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /admin/orders/{id}/refunds [post]
+func (h *AdminOrdersHandler) CreateRefund(c *gin.Context) {
+	var req dto.CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
 			Message: err.Error(),
 		})
 		return
 	}
 
-	// Convert to response
-	var items []dto.OrderItemResponse
-	for _, item := range updatedOrder.OrderItems {
-		// Get primary product image
-		imageURL := ""
-		for _, img := range item.Product.Images {
-			if img.IsPrimary {
-				imageURL = img.URL
-				break
-			}
-		}
-		// Fallback to first image if no primary
-		if imageURL == "" && len(item.Product.Images) > 0 {
-			imageURL = item.Product.Images[0].URL
-		}
+	ctx := c.Request.Context()
 
-		items = append(items, dto.OrderItemResponse{
-			ResourceID: item.ResourceID,
-			Product: dto.ProductSummaryResponse{
-				ResourceID: item.Product.ResourceID,
-				Name:       item.Product.Name,
-				SKU:        item.Product.SKU,
-				Price:      item.Price,
-				Image:      imageURL,
-			},
-			Quantity: item.Quantity,
-			Price:    item.Price,
-			Total:    item.Total,
+	order, err := h.orderRepo.GetByResourceID(ctx, c.Param("id"))
+	if err != nil || order == nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "Order not found",
+			Message: "Order with the given ID does not exist",
 		})
+		return
+	}
+
+	var actorID *uint
+	if adminID, ok := c.Get("user_id"); ok {
+		id := adminID.(uint)
+		actorID = &id
 	}
 
+	updatedOrder, refund, err := h.paymentUsecase.RefundOrder(ctx, order.ID, actorID, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, usecase.ErrOrderNotFound), errors.Is(err, usecase.ErrPaymentNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, usecase.ErrRefundAmountRequired), errors.Is(err, usecase.ErrInvalidRefundItem):
+			status = http.StatusBadRequest
+		case errors.Is(err, usecase.ErrPaymentNotRefundable), errors.Is(err, usecase.ErrRefundExceedsPayment):
+			status = http.StatusConflict
+		case errors.Is(err, usecase.ErrRefundFailed):
+			status = http.StatusBadGateway
+		case errors.Is(err, usecase.ErrPaymentsUnavailable):
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, dto.ErrorResponse{
+			Error:   "Failed to refund order",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.CreateRefundResponse{
+		Refund: newRefundResponse(updatedOrder, refund),
+		Order:  newAdminOrderDetailResponse(updatedOrder),
+	})
+}
+
+// newAdminOrderDetailResponse converts an order into the admin detail view
+func newAdminOrderDetailResponse(order *models.Order) dto.AdminOrderDetailResponse {
 	customer := dto.UserSummary{
-		ID:        updatedOrder.User.ID,
-		FirstName: updatedOrder.User.FirstName,
-		LastName:  updatedOrder.User.LastName,
-		Email:     updatedOrder.User.Email,
+		ID:        order.User.ID,
+		FirstName: order.User.FirstName,
+		LastName:  order.User.LastName,
+		Email:     order.User.Email,
 	}
 
 	// Convert payments
 	var payments []dto.PaymentResponse
-	for _, payment := range updatedOrder.Payments {
-		// Ensure Status is read from database column payment_status
-		paymentStatus := payment.Status
-		if paymentStatus == "" {
-			paymentStatus = "pending"
-		}
-		
+	for _, payment := range order.Payments {
 		payments = append(payments, dto.PaymentResponse{
 			ResourceID:    payment.ResourceID,
 			Method:        payment.Method,
 			Amount:        payment.Amount,
 			Currency:      payment.Currency,
-			Status:        paymentStatus,
+			Status:        getPaymentStatus(payment.Status),
 			TransactionID: payment.TransactionID,
 			ProcessedAt:   payment.ProcessedAt,
-			CreatedAt:    payment.CreatedAt,
+			CreatedAt:     payment.CreatedAt,
 		})
 	}
 
-	// Calculate order payment status from payments array (takes precedence over order.payment_status)
-	calculatedPaymentStatus := calculateOrderPaymentStatus(updatedOrder.Payments)
-	// Fallback to order.PaymentStatus if no payments exist
-	if calculatedPaymentStatus == "pending" && len(updatedOrder.Payments) == 0 {
-		calculatedPaymentStatus = getPaymentStatus(updatedOrder.PaymentStatus)
+	var refunds []dto.RefundResponse
+	for i := range order.Refunds {
+		refunds = append(refunds, newRefundResponse(order, &order.Refunds[i]))
 	}
 
-	c.JSON(http.StatusOK, dto.AdminOrderDetailResponse{
-		OrderResponse: dto.OrderResponse{
-			ResourceID:     updatedOrder.ResourceID,
-			OrderNumber:    updatedOrder.OrderNumber,
-			UserID:         updatedOrder.UserID,
-			Status:         updatedOrder.Status,
-			PaymentStatus:  calculatedPaymentStatus,
-			Subtotal:       updatedOrder.Subtotal,
-			TaxAmount:      updatedOrder.TaxAmount,
-			ShippingCost:   updatedOrder.ShippingCost,
-			DiscountAmount: updatedOrder.DiscountAmount,
-			Total:          updatedOrder.Total,
-			Currency:       updatedOrder.Currency,
-			Notes:          updatedOrder.Notes,
-			CreatedAt:      updatedOrder.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt:      updatedOrder.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		},
-		Customer: customer,
-		Items:    items,
-		Payments: payments,
-	})
+	resp := dto.AdminOrderDetailResponse{
+		OrderResponse: newOrderResponse(order),
+		Customer:      customer,
+		Items:         newOrderItemResponses(order.OrderItems),
+		Payments:      payments,
+		Refunds:       refunds,
+		StatusHistory: newOrderStatusHistoryResponses(order.StatusHistory),
+	}
+	resp.PaymentStatus = getPaymentStatus(order.PaymentStatus)
+	resp.CreatedAt = order.CreatedAt.Format("2006-01-02T15:04:05Z07:00")
+	resp.UpdatedAt = order.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")
+	return resp
 }
 
+// newRefundResponse converts a refund, resolving payment and line item IDs
+// to the resource IDs exposed by the API
+func newRefundResponse(order *models.Order, refund *models.Refund) dto.RefundResponse {
+	resp := dto.RefundResponse{
+		ResourceID:    refund.ResourceID,
+		Amount:        refund.Amount,
+		Currency:      refund.Currency,
+		Reason:        refund.Reason,
+		Status:        refund.Status,
+		TransactionID: refund.TransactionID,
+		Restocked:     refund.Restocked,
+		Items:         []dto.RefundItemResponse{},
+		CreatedAt:     refund.CreatedAt,
+	}
+	for _, payment := range order.Payments {
+		if payment.ID == refund.PaymentID {
+			resp.PaymentID = payment.ResourceID
+			break
+		}
+	}
+
+	lineIDs := make(map[uint]string, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		lineIDs[item.ID] = item.ResourceID
+	}
+	for _, item := range refund.Items {
+		resp.Items = append(resp.Items, dto.RefundItemResponse{
+			OrderItemID: lineIDs[item.OrderItemID],
+			Quantity:    item.Quantity,
+			Amount:      item.Amount,
+		})
+	}
+	return resp
+}
@@ -395,6 +395,12 @@ func (h *AdminProductsHandler) CreateProduct(c *gin.Context) {
 	} else {
 		gin.DefaultWriter.Write([]byte("[ERROR] Created product is nil after fetch\n"))
 	}
+	usecase.RecordAudit(ctx, usecase.AuditChange{
+		Action:     "product.create",
+		EntityType: "product",
+		EntityID:   product.ResourceID,
+		After:      usecase.AuditSnapshot(createdProduct),
+	})
 
 	// Convert to response
 	var images []dto.ImageResponse
@@ -514,6 +520,7 @@ func (h *AdminProductsHandler) UpdateProduct(c *gin.Context) {
 		})
 		return
 	}
+	before := usecase.AuditSnapshot(product)
 
 	// Update fields
 	if req.Name != nil {
@@ -695,6 +702,13 @@ func (h *AdminProductsHandler) UpdateProduct(c *gin.Context) {
 		})
 		return
 	}
+	usecase.RecordAudit(ctx, usecase.AuditChange{
+		Action:     "product.update",
+		EntityType: "product",
+		EntityID:   resourceID,
+		Before:     before,
+		After:      usecase.AuditSnapshot(updatedProduct),
+	})
 
 	// Convert to response
 	var images []dto.ImageResponse
@@ -806,6 +820,12 @@ func (h *AdminProductsHandler) DeleteProduct(c *gin.Context) {
 		})
 		return
 	}
+	usecase.RecordAudit(ctx, usecase.AuditChange{
+		Action:     "product.delete",
+		EntityType: "product",
+		EntityID:   resourceID,
+		Before:     usecase.AuditSnapshot(product),
+	})
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Message: "Product deleted successfully",
@@ -76,6 +76,7 @@ func (h *AdminProductsHandler) GetProduct(c *gin.Context) {
 	if !product.IsFeatured {
 		status = "inactive"
 	}
+	options, variants := newVariantMatrix(product, true)
 
 	c.JSON(http.StatusOK, dto.ProductResponse{
 		ID:           product.ID,
@@ -98,6 +99,8 @@ func (h *AdminProductsHandler) GetProduct(c *gin.Context) {
 		CategoryID:   product.CategoryID,
 		Category:     category,
 		Images:       images,
+		Options:      options,
+		Variants:     variants,
 		CreatedAt:    product.CreatedAt,
 		UpdatedAt:    product.UpdatedAt,
 	})
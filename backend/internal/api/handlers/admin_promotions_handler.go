@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminPromotionsHandler struct {
+	promotionUsecase usecase.PromotionUsecase
+}
+
+func NewAdminPromotionsHandler(promotionUsecase usecase.PromotionUsecase) *AdminPromotionsHandler {
+	return &AdminPromotionsHandler{
+		promotionUsecase: promotionUsecase,
+	}
+}
+
+// ListPromotions godoc
+// @Summary List all promotions (Admin)
+// @Description Get a paginated list of promotions, including inactive and scheduled ones
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param type query string false "Filter by type (banner, popup, sidebar)"
+// @Param position query string false "Filter by position (top, bottom, left, right, center)"
+// @Param is_active query bool false "Filter by active flag"
+// @Success 200 {object} dto.PromotionListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/promotions [get]
+func (h *AdminPromotionsHandler) ListPromotions(c *gin.Context) {
+	var req dto.AdminPromotionListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Set defaults
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	filters := make(map[string]interface{})
+	if req.Type != "" {
+		filters["type"] = req.Type
+	}
+	if req.Position != "" {
+		filters["position"] = req.Position
+	}
+	if req.IsActive != nil {
+		filters["is_active"] = *req.IsActive
+	}
+
+	promotions, total, err := h.promotionUsecase.List(c.Request.Context(), req.Page, req.Limit, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to get promotions",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PromotionListResponse{
+		Promotions: newPromotionResponses(promotions),
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	})
+}
+
+// GetPromotion godoc
+// @Summary Get a promotion (Admin)
+// @Description Get a single promotion
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion Resource ID"
+// @Success 200 {object} dto.PromotionResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/promotions/{id} [get]
+func (h *AdminPromotionsHandler) GetPromotion(c *gin.Context) {
+	promotion, err := h.promotionUsecase.GetByResourceID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondPromotionError(c, "Failed to get promotion", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newPromotionResponse(promotion))
+}
+
+// CreatePromotion godoc
+// @Summary Create a promotion
+// @Description Create a new banner, popup or sidebar promotion (Admin only). Upload the image first through /admin/upload/image with type=promotions and pass the returned URL.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreatePromotionRequest true "Promotion data"
+// @Success 201 {object} dto.PromotionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /admin/promotions [post]
+func (h *AdminPromotionsHandler) CreatePromotion(c *gin.Context) {
+	var req dto.CreatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	promotion, err := h.promotionUsecase.Create(c.Request.Context(), req)
+	if err != nil {
+		respondPromotionError(c, "Failed to create promotion", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newPromotionResponse(promotion))
+}
+
+// UpdatePromotion godoc
+// @Summary Update a promotion
+// @Description Update an existing promotion (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion Resource ID"
+// @Param request body dto.UpdatePromotionRequest true "Promotion data"
+// @Success 200 {object} dto.PromotionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/promotions/{id} [put]
+func (h *AdminPromotionsHandler) UpdatePromotion(c *gin.Context) {
+	var req dto.UpdatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	promotion, err := h.promotionUsecase.Update(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondPromotionError(c, "Failed to update promotion", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newPromotionResponse(promotion))
+}
+
+// DeletePromotion godoc
+// @Summary Delete a promotion
+// @Description Delete a promotion (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion Resource ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/promotions/{id} [delete]
+func (h *AdminPromotionsHandler) DeletePromotion(c *gin.Context) {
+	if err := h.promotionUsecase.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		respondPromotionError(c, "Failed to delete promotion", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Promotion deleted successfully",
+	})
+}
+
+// respondPromotionError maps promotion usecase errors to HTTP responses
+func respondPromotionError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrPromotionNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "Promotion not found",
+			Message: "Promotion with the given ID does not exist",
+		})
+		return
+	case errors.Is(err, usecase.ErrInvalidPromotion):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
+
+// newPromotionResponse converts a promotion model to its API representation
+func newPromotionResponse(promotion *models.Promotion) dto.PromotionResponse {
+	return dto.PromotionResponse{
+		ResourceID:  promotion.ResourceID,
+		Title:       promotion.Title,
+		Description: promotion.Description,
+		Image:       promotion.Image,
+		LinkURL:     promotion.LinkURL,
+		Type:        promotion.Type,
+		Position:    promotion.Position,
+		IsActive:    promotion.IsActive,
+		StartsAt:    promotion.StartsAt,
+		ExpiresAt:   promotion.ExpiresAt,
+		CreatedAt:   promotion.CreatedAt,
+		UpdatedAt:   promotion.UpdatedAt,
+	}
+}
+
+func newPromotionResponses(promotions []*models.Promotion) []dto.PromotionResponse {
+	responses := make([]dto.PromotionResponse, 0, len(promotions))
+	for _, promotion := range promotions {
+		responses = append(responses, newPromotionResponse(promotion))
+	}
+	return responses
+}
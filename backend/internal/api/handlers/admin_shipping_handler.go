@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminShippingHandler struct {
+	shippingUsecase usecase.ShippingUsecase
+}
+
+func NewAdminShippingHandler(shippingUsecase usecase.ShippingUsecase) *AdminShippingHandler {
+	return &AdminShippingHandler{
+		shippingUsecase: shippingUsecase,
+	}
+}
+
+// ListZones godoc
+// @Summary List shipping zones (Admin)
+// @Description Get all shipping zones with their regions and methods
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.ShippingZoneResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/shipping/zones [get]
+func (h *AdminShippingHandler) ListZones(c *gin.Context) {
+	zones, err := h.shippingUsecase.ListZones(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to get shipping zones",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	responses := make([]dto.ShippingZoneResponse, 0, len(zones))
+	for _, zone := range zones {
+		responses = append(responses, newShippingZoneResponse(zone))
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetZone godoc
+// @Summary Get a shipping zone (Admin)
+// @Description Get a single shipping zone with its regions and methods
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Zone Resource ID"
+// @Success 200 {object} dto.ShippingZoneResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/shipping/zones/{id} [get]
+func (h *AdminShippingHandler) GetZone(c *gin.Context) {
+	zone, err := h.shippingUsecase.GetZone(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondShippingError(c, "Failed to get shipping zone", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newShippingZoneResponse(zone))
+}
+
+// CreateZone godoc
+// @Summary Create a shipping zone
+// @Description Create a shipping zone covering countries or states (Admin only). Country "*" covers every destination without a more specific zone.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateShippingZoneRequest true "Zone data"
+// @Success 201 {object} dto.ShippingZoneResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /admin/shipping/zones [post]
+func (h *AdminShippingHandler) CreateZone(c *gin.Context) {
+	var req dto.CreateShippingZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	zone, err := h.shippingUsecase.CreateZone(c.Request.Context(), req)
+	if err != nil {
+		respondShippingError(c, "Failed to create shipping zone", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newShippingZoneResponse(zone))
+}
+
+// UpdateZone godoc
+// @Summary Update a shipping zone
+// @Description Update a shipping zone; regions, when given, replace the existing ones (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Zone Resource ID"
+// @Param request body dto.UpdateShippingZoneRequest true "Zone data"
+// @Success 200 {object} dto.ShippingZoneResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/shipping/zones/{id} [put]
+func (h *AdminShippingHandler) UpdateZone(c *gin.Context) {
+	var req dto.UpdateShippingZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	zone, err := h.shippingUsecase.UpdateZone(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondShippingError(c, "Failed to update shipping zone", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newShippingZoneResponse(zone))
+}
+
+// DeleteZone godoc
+// @Summary Delete a shipping zone
+// @Description Delete a shipping zone together with its methods (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Zone Resource ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/shipping/zones/{id} [delete]
+func (h *AdminShippingHandler) DeleteZone(c *gin.Context) {
+	if err := h.shippingUsecase.DeleteZone(c.Request.Context(), c.Param("id")); err != nil {
+		respondShippingError(c, "Failed to delete shipping zone", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Shipping zone deleted successfully",
+	})
+}
+
+// CreateMethod godoc
+// @Summary Create a shipping method
+// @Description Add a flat rate, weight based or free over threshold method to a zone (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Zone Resource ID"
+// @Param request body dto.CreateShippingMethodRequest true "Method data"
+// @Success 201 {object} dto.ShippingMethodResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/shipping/zones/{id}/methods [post]
+func (h *AdminShippingHandler) CreateMethod(c *gin.Context) {
+	var req dto.CreateShippingMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	method, err := h.shippingUsecase.CreateMethod(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondShippingError(c, "Failed to create shipping method", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newShippingMethodResponse(method))
+}
+
+// UpdateMethod godoc
+// @Summary Update a shipping method
+// @Description Update a shipping method; tiers, when given, replace the existing ones (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Method Resource ID"
+// @Param request body dto.UpdateShippingMethodRequest true "Method data"
+// @Success 200 {object} dto.ShippingMethodResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/shipping/methods/{id} [put]
+func (h *AdminShippingHandler) UpdateMethod(c *gin.Context) {
+	var req dto.UpdateShippingMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	method, err := h.shippingUsecase.UpdateMethod(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondShippingError(c, "Failed to update shipping method", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newShippingMethodResponse(method))
+}
+
+// DeleteMethod godoc
+// @Summary Delete a shipping method
+// @Description Delete a shipping method (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Method Resource ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/shipping/methods/{id} [delete]
+func (h *AdminShippingHandler) DeleteMethod(c *gin.Context) {
+	if err := h.shippingUsecase.DeleteMethod(c.Request.Context(), c.Param("id")); err != nil {
+		respondShippingError(c, "Failed to delete shipping method", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Shipping method deleted successfully",
+	})
+}
+
+// respondShippingError maps shipping usecase errors to HTTP responses
+func respondShippingError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrShippingZoneNotFound),
+		errors.Is(err, usecase.ErrShippingMethodNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidShippingMethod):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
+
+// newShippingZoneResponse converts a shipping zone with its regions and methods
+func newShippingZoneResponse(zone *models.ShippingZone) dto.ShippingZoneResponse {
+	resp := dto.ShippingZoneResponse{
+		ResourceID: zone.ResourceID,
+		Name:       zone.Name,
+		IsActive:   zone.IsActive,
+		Regions:    make([]dto.ShippingRegionResponse, 0, len(zone.Regions)),
+		Methods:    make([]dto.ShippingMethodResponse, 0, len(zone.Methods)),
+		CreatedAt:  zone.CreatedAt,
+		UpdatedAt:  zone.UpdatedAt,
+	}
+	for _, region := range zone.Regions {
+		resp.Regions = append(resp.Regions, dto.ShippingRegionResponse{
+			Country: region.Country,
+			State:   region.State,
+		})
+	}
+	for i := range zone.Methods {
+		resp.Methods = append(resp.Methods, newShippingMethodResponse(&zone.Methods[i]))
+	}
+	return resp
+}
+
+// newShippingMethodResponse converts a shipping method with its rate tiers
+func newShippingMethodResponse(method *models.ShippingMethod) dto.ShippingMethodResponse {
+	resp := dto.ShippingMethodResponse{
+		ResourceID:    method.ResourceID,
+		Name:          method.Name,
+		Type:          method.Type,
+		Rate:          method.Rate,
+		FreeThreshold: method.FreeThreshold,
+		EstimatedDays: method.EstimatedDays,
+		SortOrder:     method.SortOrder,
+		IsActive:      method.IsActive,
+		CreatedAt:     method.CreatedAt,
+		UpdatedAt:     method.UpdatedAt,
+	}
+	for _, tier := range method.Tiers {
+		resp.Tiers = append(resp.Tiers, dto.ShippingRateTierResponse{
+			MinWeight: tier.MinWeight,
+			MaxWeight: tier.MaxWeight,
+			Rate:      tier.Rate,
+		})
+	}
+	return resp
+}
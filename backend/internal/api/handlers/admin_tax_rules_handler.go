@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminTaxRulesHandler struct {
+	taxUsecase usecase.TaxUsecase
+}
+
+func NewAdminTaxRulesHandler(taxUsecase usecase.TaxUsecase) *AdminTaxRulesHandler {
+	return &AdminTaxRulesHandler{
+		taxUsecase: taxUsecase,
+	}
+}
+
+// ListTaxRules godoc
+// @Summary List all tax rules (Admin)
+// @Description Get a paginated list of tax rules
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param country query string false "Filter by country"
+// @Param state query string false "Filter by state"
+// @Param is_active query bool false "Filter by active flag"
+// @Success 200 {object} dto.TaxRuleListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/tax-rules [get]
+func (h *AdminTaxRulesHandler) ListTaxRules(c *gin.Context) {
+	var req dto.AdminTaxRuleListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Set defaults
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	filters := make(map[string]interface{})
+	if req.Country != "" {
+		filters["country"] = req.Country
+	}
+	if req.State != "" {
+		filters["state"] = req.State
+	}
+	if req.IsActive != nil {
+		filters["is_active"] = *req.IsActive
+	}
+
+	rules, total, err := h.taxUsecase.List(c.Request.Context(), req.Page, req.Limit, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to get tax rules",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.TaxRuleListResponse{
+		TaxRules: newTaxRuleResponses(rules),
+		Total:    total,
+		Page:     req.Page,
+		Limit:    req.Limit,
+	})
+}
+
+// GetTaxRule godoc
+// @Summary Get a tax rule (Admin)
+// @Description Get a single tax rule
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Tax rule Resource ID"
+// @Success 200 {object} dto.TaxRuleResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/tax-rules/{id} [get]
+func (h *AdminTaxRulesHandler) GetTaxRule(c *gin.Context) {
+	rule, err := h.taxUsecase.GetByResourceID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondTaxRuleError(c, "Failed to get tax rule", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newTaxRuleResponse(rule))
+}
+
+// CreateTaxRule godoc
+// @Summary Create a tax rule
+// @Description Create a tax rate for a country or state (Admin only). Inclusive rules mark catalog prices as already containing the tax.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateTaxRuleRequest true "Tax rule data"
+// @Success 201 {object} dto.TaxRuleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /admin/tax-rules [post]
+func (h *AdminTaxRulesHandler) CreateTaxRule(c *gin.Context) {
+	var req dto.CreateTaxRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.taxUsecase.Create(c.Request.Context(), req)
+	if err != nil {
+		respondTaxRuleError(c, "Failed to create tax rule", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newTaxRuleResponse(rule))
+}
+
+// UpdateTaxRule godoc
+// @Summary Update a tax rule
+// @Description Update an existing tax rule (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Tax rule Resource ID"
+// @Param request body dto.UpdateTaxRuleRequest true "Tax rule data"
+// @Success 200 {object} dto.TaxRuleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/tax-rules/{id} [put]
+func (h *AdminTaxRulesHandler) UpdateTaxRule(c *gin.Context) {
+	var req dto.UpdateTaxRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.taxUsecase.Update(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondTaxRuleError(c, "Failed to update tax rule", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newTaxRuleResponse(rule))
+}
+
+// DeleteTaxRule godoc
+// @Summary Delete a tax rule
+// @Description Delete a tax rule (Admin only). Orders keep the tax recorded at checkout.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Tax rule Resource ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/tax-rules/{id} [delete]
+func (h *AdminTaxRulesHandler) DeleteTaxRule(c *gin.Context) {
+	if err := h.taxUsecase.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		respondTaxRuleError(c, "Failed to delete tax rule", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Tax rule deleted successfully",
+	})
+}
+
+// respondTaxRuleError maps tax usecase errors to HTTP responses
+func respondTaxRuleError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrTaxRuleNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "Tax rule not found",
+			Message: "Tax rule with the given ID does not exist",
+		})
+		return
+	case errors.Is(err, usecase.ErrInvalidTaxRule):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
+
+// newTaxRuleResponse converts a tax rule model to its API representation
+func newTaxRuleResponse(rule *models.TaxRule) dto.TaxRuleResponse {
+	return dto.TaxRuleResponse{
+		ResourceID: rule.ResourceID,
+		Name:       rule.Name,
+		Country:    rule.Country,
+		State:      rule.State,
+		Rate:       rule.Rate,
+		Inclusive:  rule.Inclusive,
+		IsActive:   rule.IsActive,
+		CreatedAt:  rule.CreatedAt,
+		UpdatedAt:  rule.UpdatedAt,
+	}
+}
+
+func newTaxRuleResponses(rules []*models.TaxRule) []dto.TaxRuleResponse {
+	responses := make([]dto.TaxRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		responses = append(responses, newTaxRuleResponse(rule))
+	}
+	return responses
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
+	"electronics-store/internal/domain/models"
 	"electronics-store/internal/dto"
 	"electronics-store/internal/repository"
+	"electronics-store/internal/usecase"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,15 +15,18 @@ import (
 type AdminUsersHandler struct {
 	userRepo repository.UserRepository
 	orderRepo repository.OrderRepository
+	rbacUsecase usecase.RBACUsecase
 }
 
 func NewAdminUsersHandler(
 	userRepo repository.UserRepository,
 	orderRepo repository.OrderRepository,
+	rbacUsecase usecase.RBACUsecase,
 ) *AdminUsersHandler {
 	return &AdminUsersHandler{
-		userRepo:  userRepo,
-		orderRepo: orderRepo,
+		userRepo:    userRepo,
+		orderRepo:   orderRepo,
+		rbacUsecase: rbacUsecase,
 	}
 }
 
@@ -114,6 +120,11 @@ func (h *AdminUsersHandler) ListUsers(c *gin.Context) {
 			ordersCount++
 		}
 
+		roles := make([]*models.Role, 0, len(user.Roles))
+		for i := range user.Roles {
+			roles = append(roles, &user.Roles[i])
+		}
+
 		userResponses = append(userResponses, dto.AdminUserResponse{
 			ID:          user.ID,
 			ResourceID: user.ResourceID,
@@ -126,6 +137,7 @@ func (h *AdminUsersHandler) ListUsers(c *gin.Context) {
 			IsActive:    user.IsActive,
 			IsAdmin:     user.IsAdmin,
 			IsVerified:  user.IsVerified,
+			Roles:       roleNames(roles),
 			CreatedAt:   user.CreatedAt,
 			UpdatedAt:   user.UpdatedAt,
 			LastLoginAt: user.LastLoginAt,
@@ -144,7 +156,7 @@ func (h *AdminUsersHandler) ListUsers(c *gin.Context) {
 
 // UpdateUser godoc
 // @Summary Update a user
-// @Description Update user information (Admin only)
+// @Description Update user information. Admin access is granted through roles, see PUT /admin/users/{id}/roles.
 // @Tags admin
 // @Accept json
 // @Produce json
@@ -186,6 +198,8 @@ func (h *AdminUsersHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	before := usecase.AuditSnapshot(user)
+
 	// Update fields
 	if req.FirstName != nil {
 		user.FirstName = *req.FirstName
@@ -199,9 +213,6 @@ func (h *AdminUsersHandler) UpdateUser(c *gin.Context) {
 	if req.IsActive != nil {
 		user.IsActive = *req.IsActive
 	}
-	if req.IsAdmin != nil {
-		user.IsAdmin = *req.IsAdmin
-	}
 
 	// Update user
 	if err := h.userRepo.Update(ctx, user); err != nil {
@@ -222,6 +233,23 @@ func (h *AdminUsersHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	usecase.RecordAudit(ctx, usecase.AuditChange{
+		Action:     "user.update",
+		EntityType: "user",
+		EntityID:   resourceID,
+		Before:     before,
+		After:      usecase.AuditSnapshot(updatedUser),
+	})
+
+	roles, err := h.rbacUsecase.GetUserRoles(ctx, updatedUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to get user roles",
+			Message: err.Error(),
+		})
+		return
+	}
+
 	// Get user stats
 	userOrders, _ := h.orderRepo.List(ctx, updatedUser.ID, 1000, 0)
 	var totalSpent float64
@@ -245,6 +273,7 @@ func (h *AdminUsersHandler) UpdateUser(c *gin.Context) {
 		IsActive:    updatedUser.IsActive,
 		IsAdmin:     updatedUser.IsAdmin,
 		IsVerified:  updatedUser.IsVerified,
+		Roles:       roleNames(roles),
 		CreatedAt:   updatedUser.CreatedAt,
 		UpdatedAt:   updatedUser.UpdatedAt,
 		LastLoginAt: updatedUser.LastLoginAt,
@@ -286,6 +315,15 @@ func (h *AdminUsersHandler) GetUser(c *gin.Context) {
 		return
 	}
 
+	roles, err := h.rbacUsecase.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to get user roles",
+			Message: err.Error(),
+		})
+		return
+	}
+
 	// Get all orders for this user
 	orders, err := h.orderRepo.List(ctx, user.ID, 1000, 0)
 	if err != nil {
@@ -363,6 +401,7 @@ func (h *AdminUsersHandler) GetUser(c *gin.Context) {
 			IsActive:    user.IsActive,
 			IsAdmin:     user.IsAdmin,
 			IsVerified:  user.IsVerified,
+			Roles:       roleNames(roles),
 			CreatedAt:   user.CreatedAt,
 			UpdatedAt:   user.UpdatedAt,
 			LastLoginAt: user.LastLoginAt,
@@ -373,6 +412,131 @@ func (h *AdminUsersHandler) GetUser(c *gin.Context) {
 	})
 }
 
+// SetUserRoles godoc
+// @Summary Set a user's roles (Admin)
+// @Description Replace the staff roles of a user. Users with any role can sign in to the admin panel; an empty list removes their access. Only super admins can grant or revoke super_admin, and the last super admin cannot lose it.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User Resource ID"
+// @Param request body dto.SetUserRolesRequest true "Role names"
+// @Success 200 {object} dto.AdminUserResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/users/{id}/roles [put]
+func (h *AdminUsersHandler) SetUserRoles(c *gin.Context) {
+	actorID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req dto.SetUserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.userRepo.GetByResourceID(ctx, c.Param("id"))
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "User not found",
+			Message: "User with the given ID does not exist",
+		})
+		return
+	}
+
+	roles, err := h.rbacUsecase.SetUserRoles(ctx, actorID.(uint), user, req.Roles)
+	if err != nil {
+		respondRBACError(c, "Failed to set user roles", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AdminUserResponse{
+		ID:          user.ID,
+		ResourceID:  user.ResourceID,
+		Username:    user.Username,
+		Email:       user.Email,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Phone:       user.Phone,
+		Avatar:      user.Avatar,
+		IsActive:    user.IsActive,
+		IsAdmin:     len(roles) > 0,
+		IsVerified:  user.IsVerified,
+		Roles:       roleNames(roles),
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		LastLoginAt: user.LastLoginAt,
+	})
+}
+
+// ListRoles godoc
+// @Summary List staff roles (Admin)
+// @Description Get the roles that can be assigned to users and the permissions each grants
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {array} dto.RoleResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/roles [get]
+func (h *AdminUsersHandler) ListRoles(c *gin.Context) {
+	roles, err := h.rbacUsecase.ListRoles(c.Request.Context())
+	if err != nil {
+		respondRBACError(c, "Failed to get roles", err)
+		return
+	}
+
+	responses := make([]dto.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		permissions := make([]string, 0, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			permissions = append(permissions, permission.Name)
+		}
+		responses = append(responses, dto.RoleResponse{
+			ResourceID:  role.ResourceID,
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: permissions,
+		})
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// respondRBACError maps role usecase errors to HTTP responses
+func respondRBACError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrRoleNotFound):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrRoleAssignForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, usecase.ErrLastSuperAdmin):
+		status = http.StatusConflict
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
+
+func roleNames(roles []*models.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
 // Helper function
 func containsIgnoreCase(s, substr string) bool {
 	if len(s) < len(substr) {
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminVariantsHandler struct {
+	variantUsecase usecase.VariantUsecase
+}
+
+func NewAdminVariantsHandler(variantUsecase usecase.VariantUsecase) *AdminVariantsHandler {
+	return &AdminVariantsHandler{
+		variantUsecase: variantUsecase,
+	}
+}
+
+// ListOptions godoc
+// @Summary List product options (Admin)
+// @Description Get a product's options, such as Color or Storage, with their values
+// @Tags admin
+// @Produce json
+// @Param id path string true "Product Resource ID"
+// @Success 200 {array} dto.ProductOptionResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/products/{id}/options [get]
+func (h *AdminVariantsHandler) ListOptions(c *gin.Context) {
+	options, err := h.variantUsecase.ListOptions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondVariantError(c, "Failed to get product options", err)
+		return
+	}
+
+	responses := make([]dto.ProductOptionResponse, 0, len(options))
+	for _, option := range options {
+		responses = append(responses, newProductOptionResponse(option))
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// CreateOption godoc
+// @Summary Create a product option
+// @Description Add an option with its values to a product (Admin only). Options can only be added before the product has variants.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Product Resource ID"
+// @Param request body dto.CreateProductOptionRequest true "Option data"
+// @Success 201 {object} dto.ProductOptionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/products/{id}/options [post]
+func (h *AdminVariantsHandler) CreateOption(c *gin.Context) {
+	var req dto.CreateProductOptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	option, err := h.variantUsecase.CreateOption(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondVariantError(c, "Failed to create product option", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newProductOptionResponse(option))
+}
+
+// UpdateOption godoc
+// @Summary Update a product option
+// @Description Rename or reorder an option; values, when given, replace the existing ones. Values used by a variant can't be removed (Admin only).
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Product Resource ID"
+// @Param optionId path string true "Option Resource ID"
+// @Param request body dto.UpdateProductOptionRequest true "Option data"
+// @Success 200 {object} dto.ProductOptionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/products/{id}/options/{optionId} [put]
+func (h *AdminVariantsHandler) UpdateOption(c *gin.Context) {
+	var req dto.UpdateProductOptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	option, err := h.variantUsecase.UpdateOption(c.Request.Context(), c.Param("id"), c.Param("optionId"), req)
+	if err != nil {
+		respondVariantError(c, "Failed to update product option", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newProductOptionResponse(option))
+}
+
+// DeleteOption godoc
+// @Summary Delete a product option
+// @Description Delete an option and its values; none of them may be used by a variant (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Product Resource ID"
+// @Param optionId path string true "Option Resource ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/products/{id}/options/{optionId} [delete]
+func (h *AdminVariantsHandler) DeleteOption(c *gin.Context) {
+	if err := h.variantUsecase.DeleteOption(c.Request.Context(), c.Param("id"), c.Param("optionId")); err != nil {
+		respondVariantError(c, "Failed to delete product option", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Product option deleted successfully",
+	})
+}
+
+// ListVariants godoc
+// @Summary List product variants (Admin)
+// @Description Get all variants of a product, active or not
+// @Tags admin
+// @Produce json
+// @Param id path string true "Product Resource ID"
+// @Success 200 {array} dto.VariantResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/products/{id}/variants [get]
+func (h *AdminVariantsHandler) ListVariants(c *gin.Context) {
+	variants, err := h.variantUsecase.ListVariants(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondVariantError(c, "Failed to get variants", err)
+		return
+	}
+
+	responses := make([]dto.VariantResponse, 0, len(variants))
+	for _, variant := range variants {
+		responses = append(responses, newVariantResponse(&variant.Product, variant, true))
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetVariant godoc
+// @Summary Get a product variant (Admin)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Product Resource ID"
+// @Param variantId path string true "Variant Resource ID"
+// @Success 200 {object} dto.VariantResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/products/{id}/variants/{variantId} [get]
+func (h *AdminVariantsHandler) GetVariant(c *gin.Context) {
+	variant, err := h.variantUsecase.GetVariant(c.Request.Context(), c.Param("id"), c.Param("variantId"))
+	if err != nil {
+		respondVariantError(c, "Failed to get variant", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newVariantResponse(&variant.Product, variant, true))
+}
+
+// CreateVariant godoc
+// @Summary Create a product variant
+// @Description Add a variant to a product (Admin only). On a product with options, options must give one value of each, and no other variant may have the same values.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Product Resource ID"
+// @Param request body dto.CreateVariantRequest true "Variant data"
+// @Success 201 {object} dto.VariantResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/products/{id}/variants [post]
+func (h *AdminVariantsHandler) CreateVariant(c *gin.Context) {
+	var req dto.CreateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	variant, err := h.variantUsecase.CreateVariant(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondVariantError(c, "Failed to create variant", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newVariantResponse(&variant.Product, variant, true))
+}
+
+// UpdateVariant godoc
+// @Summary Update a product variant
+// @Description Update a variant; options and images, when given, replace the existing ones (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Product Resource ID"
+// @Param variantId path string true "Variant Resource ID"
+// @Param request body dto.UpdateVariantRequest true "Variant data"
+// @Success 200 {object} dto.VariantResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /admin/products/{id}/variants/{variantId} [put]
+func (h *AdminVariantsHandler) UpdateVariant(c *gin.Context) {
+	var req dto.UpdateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	variant, err := h.variantUsecase.UpdateVariant(c.Request.Context(), c.Param("id"), c.Param("variantId"), req)
+	if err != nil {
+		respondVariantError(c, "Failed to update variant", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newVariantResponse(&variant.Product, variant, true))
+}
+
+// DeleteVariant godoc
+// @Summary Delete a product variant
+// @Description Delete a variant and its images, removing it from carts; past orders keep the product only. Deactivate the variant instead to keep it on past orders (Admin only).
+// @Tags admin
+// @Produce json
+// @Param id path string true "Product Resource ID"
+// @Param variantId path string true "Variant Resource ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/products/{id}/variants/{variantId} [delete]
+func (h *AdminVariantsHandler) DeleteVariant(c *gin.Context) {
+	if err := h.variantUsecase.DeleteVariant(c.Request.Context(), c.Param("id"), c.Param("variantId")); err != nil {
+		respondVariantError(c, "Failed to delete variant", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Variant deleted successfully",
+	})
+}
+
+func respondVariantError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrProductNotFound),
+		errors.Is(err, usecase.ErrProductOptionNotFound),
+		errors.Is(err, usecase.ErrVariantNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidProductOption),
+		errors.Is(err, usecase.ErrInvalidVariantOptions):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrProductOptionInUse),
+		errors.Is(err, usecase.ErrProductHasVariants),
+		errors.Is(err, usecase.ErrDuplicateVariant),
+		errors.Is(err, usecase.ErrVariantSKUTaken):
+		status = http.StatusConflict
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
+
+// newVariantMatrix converts the options and variants of a product loaded
+// with them. The storefront only gets active variants; the cart accepts
+// their IDs as variant_id.
+func newVariantMatrix(product *models.Product, admin bool) ([]dto.ProductOptionResponse, []dto.VariantResponse) {
+	options := make([]dto.ProductOptionResponse, 0, len(product.Options))
+	for i := range product.Options {
+		options = append(options, newProductOptionResponse(&product.Options[i]))
+	}
+	variants := make([]dto.VariantResponse, 0, len(product.Variants))
+	for i := range product.Variants {
+		if admin || product.Variants[i].IsActive {
+			variants = append(variants, newVariantResponse(product, &product.Variants[i], admin))
+		}
+	}
+	return options, variants
+}
+
+func newProductOptionResponse(option *models.ProductOption) dto.ProductOptionResponse {
+	resp := dto.ProductOptionResponse{
+		ResourceID: option.ResourceID,
+		Name:       option.Name,
+		Position:   option.Position,
+		Values:     make([]string, 0, len(option.Values)),
+	}
+	for _, value := range option.Values {
+		resp.Values = append(resp.Values, value.Value)
+	}
+	return resp
+}
+
+// newVariantResponse converts a variant of product with its option values
+// and images loaded. Cost price is only shown to admins.
+func newVariantResponse(product *models.Product, variant *models.Variant, admin bool) dto.VariantResponse {
+	resp := dto.VariantResponse{
+		ID:            variant.ID,
+		ResourceID:    variant.ResourceID,
+		ProductID:     variant.ProductID,
+		Name:          variant.Name,
+		SKU:           variant.SKU,
+		Price:         variant.Price,
+		ComparePrice:  variant.ComparePrice,
+		StockQuantity: variant.StockQuantity,
+		InStock:       usecase.VariantOrderable(product, variant),
+		Weight:        variant.Weight,
+		IsActive:      variant.IsActive,
+		CreatedAt:     variant.CreatedAt,
+		UpdatedAt:     variant.UpdatedAt,
+	}
+	if admin {
+		resp.CostPrice = variant.CostPrice
+	}
+	if len(variant.OptionValues) > 0 {
+		resp.Options = make(map[string]string, len(variant.OptionValues))
+		for _, value := range variant.OptionValues {
+			if value.Option != nil {
+				resp.Options[value.Option.Name] = value.Value
+			}
+		}
+	}
+	for _, image := range variant.Images {
+		resp.Images = append(resp.Images, dto.VariantImageResponse{
+			ResourceID: image.ResourceID,
+			URL:        image.URL,
+			Alt:        image.Alt,
+			SortOrder:  image.SortOrder,
+		})
+	}
+	return resp
+}
@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"electronics-store/internal/dto"
 	"electronics-store/internal/services"
 	"electronics-store/internal/usecase"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -14,12 +20,14 @@ import (
 type AuthHandler struct {
 	authUsecase usecase.AuthUsecase
 	otpService  *services.OTPService
+	cartUsecase usecase.CartUsecase
 }
 
-func NewAuthHandler(authUsecase usecase.AuthUsecase, otpService *services.OTPService) *AuthHandler {
+func NewAuthHandler(authUsecase usecase.AuthUsecase, otpService *services.OTPService, cartUsecase usecase.CartUsecase) *AuthHandler {
 	return &AuthHandler{
 		authUsecase: authUsecase,
 		otpService:  otpService,
+		cartUsecase: cartUsecase,
 	}
 }
 
@@ -54,7 +62,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Register user
-	user, tokens, err := h.authUsecase.Register(c.Request.Context(), req)
+	user, tokens, err := h.authUsecase.Register(sessionContext(c), req)
 	if err != nil {
 		status := http.StatusInternalServerError
 		if err == usecase.ErrUserAlreadyExists {
@@ -67,16 +75,17 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	// Send OTP for email verification
-	_, err = h.otpService.SendOTP(user.Email, "email_verification", &user.ID)
-	if err != nil {
-		// Log error but don't fail registration
-		// In production, you might want to handle this differently
+	// Send OTP for email verification; registration succeeds without it and
+	// the client can ask for another code
+	otpChallengeID := ""
+	if otp, err := h.otpService.SendOTP(user.Email, "email_verification", &user.ID); err == nil {
+		otpChallengeID = otp.ResourceID
 	}
 
     // Set HTTP-only cookies (Secure=false for local dev; ensure HTTPS in production)
-    c.SetCookie("access_token", tokens.AccessToken, 15*60, "/", "", false, true)
-    c.SetCookie("refresh_token", tokens.RefreshToken, 7*24*60*60, "/", "", false, true)
+    c.SetCookie("access_token", tokens.AccessToken, tokens.ExpiresIn, "/", "", false, true)
+    c.SetCookie("refresh_token", tokens.RefreshToken, tokens.RefreshExpiresIn, "/", "", false, true)
+    h.mergeGuestCart(c, user.ID)
 
 	c.JSON(http.StatusCreated, dto.AuthResponse{
 		User: dto.UserResponse{
@@ -89,15 +98,16 @@ func (h *AuthHandler) Register(c *gin.Context) {
 			IsAdmin:    user.IsAdmin,
 			IsVerified: user.IsVerified,
 		},
-		AccessToken:  tokens.AccessToken,
-		RefreshToken: tokens.RefreshToken,
-		Message:      "Registration successful! Please check your email for verification code.",
+		AccessToken:    tokens.AccessToken,
+		RefreshToken:   tokens.RefreshToken,
+		Message:        "Registration successful! Please check your email for verification code.",
+		OTPChallengeID: otpChallengeID,
 	})
 }
 
 // Login godoc
 // @Summary Login user
-// @Description Login user with email and password
+// @Description Login user with email and password. If the account has two-factor authentication enabled the response is a dto.TwoFactorChallengeResponse instead; finish with /auth/2fa/login.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -105,6 +115,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Success 200 {object} dto.AuthResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
@@ -126,11 +137,18 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Login user
-	user, tokens, err := h.authUsecase.Login(c.Request.Context(), req)
+	user, tokens, err := h.authUsecase.Login(sessionContext(c), req)
 	if err != nil {
+		if respondTwoFactorChallenge(c, err) {
+			return
+		}
 		status := http.StatusInternalServerError
+		var locked *usecase.AccountLockedError
 		if err == usecase.ErrInvalidCredentials {
 			status = http.StatusUnauthorized
+		} else if errors.As(err, &locked) {
+			status = http.StatusTooManyRequests
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
 		}
 		c.JSON(status, dto.ErrorResponse{
 			Error:   "Login failed",
@@ -140,8 +158,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
     // Set HTTP-only cookies (Secure=false for local dev; ensure HTTPS in production)
-    c.SetCookie("access_token", tokens.AccessToken, 15*60, "/", "", false, true)
-    c.SetCookie("refresh_token", tokens.RefreshToken, 7*24*60*60, "/", "", false, true)
+    c.SetCookie("access_token", tokens.AccessToken, tokens.ExpiresIn, "/", "", false, true)
+    c.SetCookie("refresh_token", tokens.RefreshToken, tokens.RefreshExpiresIn, "/", "", false, true)
+    h.mergeGuestCart(c, user.ID)
 
 	c.JSON(http.StatusOK, dto.AuthResponse{
 		User: dto.UserResponse{
@@ -190,8 +209,11 @@ func (h *AuthHandler) GoogleAuth(c *gin.Context) {
 	}
 
 	// Google OAuth login
-	user, tokens, err := h.authUsecase.GoogleAuth(c.Request.Context(), req)
+	user, tokens, err := h.authUsecase.GoogleAuth(sessionContext(c), req)
 	if err != nil {
+		if respondTwoFactorChallenge(c, err) {
+			return
+		}
 		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
 			Error:   "Google authentication failed",
 			Message: err.Error(),
@@ -200,8 +222,9 @@ func (h *AuthHandler) GoogleAuth(c *gin.Context) {
 	}
 
 	// Set HTTP-only cookies (Secure=false for local dev; ensure HTTPS in production)
-	c.SetCookie("access_token", tokens.AccessToken, 15*60, "/", "", false, true)
-	c.SetCookie("refresh_token", tokens.RefreshToken, 7*24*60*60, "/", "", false, true)
+	c.SetCookie("access_token", tokens.AccessToken, tokens.ExpiresIn, "/", "", false, true)
+	c.SetCookie("refresh_token", tokens.RefreshToken, tokens.RefreshExpiresIn, "/", "", false, true)
+	h.mergeGuestCart(c, user.ID)
 
 	c.JSON(http.StatusOK, dto.AuthResponse{
 		User: dto.UserResponse{
@@ -254,8 +277,11 @@ func (h *AuthHandler) GoogleIDTokenAuth(c *gin.Context) {
 	fmt.Printf("Google OAuth - Received ID token: %s\n", req.IDToken[:50]+"...")
 
 	// Google ID Token authentication
-	user, tokens, err := h.authUsecase.GoogleIDTokenAuth(c.Request.Context(), req)
+	user, tokens, err := h.authUsecase.GoogleIDTokenAuth(sessionContext(c), req)
 	if err != nil {
+		if respondTwoFactorChallenge(c, err) {
+			return
+		}
 		fmt.Printf("Google OAuth Error - Authentication failed: %v\n", err)
 		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
 			Error:   "Google authentication failed",
@@ -267,8 +293,9 @@ func (h *AuthHandler) GoogleIDTokenAuth(c *gin.Context) {
 	fmt.Printf("Google OAuth Success - User: %s, Email: %s\n", user.Username, user.Email)
 
     // Set HTTP-only cookies (Secure=false for local dev; ensure HTTPS in production)
-    c.SetCookie("access_token", tokens.AccessToken, 15*60, "/", "", false, true)
-    c.SetCookie("refresh_token", tokens.RefreshToken, 7*24*60*60, "/", "", false, true)
+    c.SetCookie("access_token", tokens.AccessToken, tokens.ExpiresIn, "/", "", false, true)
+    c.SetCookie("refresh_token", tokens.RefreshToken, tokens.RefreshExpiresIn, "/", "", false, true)
+    h.mergeGuestCart(c, user.ID)
 
 	c.JSON(http.StatusOK, dto.AuthResponse{
 		User: dto.UserResponse{
@@ -309,8 +336,11 @@ func (h *AuthHandler) GoogleOAuthExchange(c *gin.Context) {
 
 	// Exchange code for tokens and authenticate
 	fmt.Printf("Google OAuth Exchange - Received code: %s\n", req.Code[:20]+"...")
-	user, tokens, err := h.authUsecase.ExchangeGoogleCode(c.Request.Context(), req.Code)
+	user, tokens, err := h.authUsecase.ExchangeGoogleCode(sessionContext(c), req.Code)
 	if err != nil {
+		if respondTwoFactorChallenge(c, err) {
+			return
+		}
 		fmt.Printf("Google OAuth Exchange Error: %v\n", err)
 		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
 			Error:   "Google OAuth exchange failed",
@@ -320,6 +350,7 @@ func (h *AuthHandler) GoogleOAuthExchange(c *gin.Context) {
 	}
 	
 	fmt.Printf("Google OAuth Exchange Success - User: %s\n", user.Email)
+	h.mergeGuestCart(c, user.ID)
 	
 	// Return full auth response
 	c.JSON(http.StatusOK, dto.AuthResponse{
@@ -338,6 +369,82 @@ func (h *AuthHandler) GoogleOAuthExchange(c *gin.Context) {
 	})
 }
 
+// CompleteTwoFactorLogin godoc
+// @Summary Finish a two-factor login
+// @Description Exchange the challenge token from a login plus a TOTP or recovery code for a session
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorLoginRequest true "Challenge token and code"
+// @Success 200 {object} dto.AuthResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Router /auth/2fa/login [post]
+func (h *AuthHandler) CompleteTwoFactorLogin(c *gin.Context) {
+	var req dto.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, tokens, err := h.authUsecase.CompleteTwoFactorLogin(sessionContext(c), req.ChallengeToken, req.Code)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, usecase.ErrInvalidToken), errors.Is(err, usecase.ErrInvalidTwoFactorCode), errors.Is(err, usecase.ErrTwoFactorNotEnabled):
+			status = http.StatusUnauthorized
+		case errors.Is(err, usecase.ErrTooManyTwoFactorAttempts):
+			status = http.StatusTooManyRequests
+		}
+		c.JSON(status, dto.ErrorResponse{
+			Error:   "Login failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Set HTTP-only cookies (Secure=false for local dev; ensure HTTPS in production)
+	c.SetCookie("access_token", tokens.AccessToken, tokens.ExpiresIn, "/", "", false, true)
+	c.SetCookie("refresh_token", tokens.RefreshToken, tokens.RefreshExpiresIn, "/", "", false, true)
+	h.mergeGuestCart(c, user.ID)
+
+	c.JSON(http.StatusOK, dto.AuthResponse{
+		User: dto.UserResponse{
+			ResourceID: user.ResourceID,
+			Username:   user.Username,
+			Email:      user.Email,
+			FirstName:  user.FirstName,
+			LastName:   user.LastName,
+			Avatar:     user.Avatar,
+			IsAdmin:    user.IsAdmin,
+			IsVerified: user.IsVerified,
+		},
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	})
+}
+
+// respondTwoFactorChallenge answers a login that still needs a second
+// factor with its challenge token. It returns false when err is not such a
+// challenge.
+func respondTwoFactorChallenge(c *gin.Context, err error) bool {
+	var challenge *usecase.TwoFactorChallengeError
+	if !errors.As(err, &challenge) {
+		return false
+	}
+	c.JSON(http.StatusOK, dto.TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge.ChallengeToken,
+		ExpiresIn:         challenge.ExpiresIn,
+		Message:           "Enter the code from your authenticator app or a recovery code",
+	})
+	return true
+}
+
 // RefreshToken godoc
 // @Summary Refresh access token
 // @Description Refresh access token using refresh token
@@ -351,18 +458,30 @@ func (h *AuthHandler) GoogleOAuthExchange(c *gin.Context) {
 // @Router /auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req dto.RefreshTokenRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request",
 			Message: err.Error(),
 		})
 		return
 	}
+	refreshToken := requestRefreshToken(c, req.RefreshToken)
+	if refreshToken == "" {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "Refresh token is required",
+		})
+		return
+	}
 
 	// Refresh token
-	tokens, err := h.authUsecase.RefreshToken(c.Request.Context(), req.RefreshToken)
+	tokens, err := h.authUsecase.RefreshToken(sessionContext(c), refreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidToken) || errors.Is(err, usecase.ErrRefreshTokenReused) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, dto.ErrorResponse{
 			Error:   "Token refresh failed",
 			Message: err.Error(),
 		})
@@ -370,8 +489,8 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	}
 
     // Set HTTP-only cookies (Secure=false for local dev; ensure HTTPS in production)
-    c.SetCookie("access_token", tokens.AccessToken, 15*60, "/", "", false, true)
-    c.SetCookie("refresh_token", tokens.RefreshToken, 7*24*60*60, "/", "", false, true)
+    c.SetCookie("access_token", tokens.AccessToken, tokens.ExpiresIn, "/", "", false, true)
+    c.SetCookie("refresh_token", tokens.RefreshToken, tokens.RefreshExpiresIn, "/", "", false, true)
 
 	c.JSON(http.StatusOK, dto.AuthResponse{
 		AccessToken:  tokens.AccessToken,
@@ -381,13 +500,28 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 
 // Logout godoc
 // @Summary Logout user
-// @Description Logout user and invalidate tokens
+// @Description Revoke the session of the given refresh token (or refresh_token cookie) and clear the auth cookies
 // @Tags auth
 // @Accept json
 // @Produce json
+// @Param request body dto.LogoutRequest false "Logout request"
 // @Success 200 {object} dto.SuccessResponse
+// @Failure 500 {object} dto.ErrorResponse
 // @Router /auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
+	var req dto.LogoutRequest
+	_ = c.ShouldBindJSON(&req)
+
+	if refreshToken := requestRefreshToken(c, req.RefreshToken); refreshToken != "" {
+		if err := h.authUsecase.Logout(c.Request.Context(), refreshToken); err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Failed to logout",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
     // Clear cookies
     c.SetCookie("access_token", "", -1, "/", "", false, true)
     c.SetCookie("refresh_token", "", -1, "/", "", false, true)
@@ -397,6 +531,43 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	})
 }
 
+// LogoutAll godoc
+// @Summary Logout from all devices
+// @Description Revoke every session of the current user. Access tokens already issued stay valid until they expire.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.authUsecase.LogoutAll(c.Request.Context(), userID.(uint)); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to logout",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.SetCookie("access_token", "", -1, "/", "", false, true)
+	c.SetCookie("refresh_token", "", -1, "/", "", false, true)
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Logged out of all sessions successfully",
+	})
+}
+
 // GetProfile godoc
 // @Summary Get user profile
 // @Description Get current user profile
@@ -550,6 +721,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 // @Success 200 {object} dto.OTPResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
 // @Router /auth/send-otp [post]
 func (h *AuthHandler) SendOTP(c *gin.Context) {
 	var req dto.SendOTPRequest
@@ -562,7 +734,7 @@ func (h *AuthHandler) SendOTP(c *gin.Context) {
 	}
 
 	// Send OTP
-	_, err := h.otpService.SendOTP(req.Email, req.Type, nil)
+	otp, err := h.otpService.SendOTP(req.Email, req.Type, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Failed to send OTP",
@@ -572,8 +744,9 @@ func (h *AuthHandler) SendOTP(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, dto.OTPResponse{
-		Message:   "OTP sent successfully",
-		ExpiresIn: 900, // 15 minutes
+		Message:     "OTP sent successfully",
+		ChallengeID: otp.ResourceID,
+		ExpiresIn:   900, // 15 minutes
 	})
 }
 
@@ -587,6 +760,7 @@ func (h *AuthHandler) SendOTP(c *gin.Context) {
 // @Success 200 {object} dto.VerifyOTPResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
 // @Router /auth/verify-otp [post]
 func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 	var req dto.VerifyOTPRequest
@@ -599,7 +773,7 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 	}
 
 	// Verify OTP
-	_, err := h.otpService.VerifyOTP(req.Email, req.OTPCode, req.Type)
+	_, err := h.otpService.VerifyOTP(req.ChallengeID, req.Email, req.OTPCode, req.Type)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
 			Error:   "Invalid OTP",
@@ -632,8 +806,11 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 		}
 
 		// Generate new tokens for verified user
-		tokens, err := h.authUsecase.GenerateTokens(user)
+		tokens, err := h.authUsecase.GenerateTokens(sessionContext(c), user)
 		if err != nil {
+			if respondTwoFactorChallenge(c, err) {
+				return
+			}
 			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 				Error:   "Failed to generate tokens",
 				Message: err.Error(),
@@ -642,8 +819,8 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 		}
 
         // Set HTTP-only cookies (Secure=false for local dev; ensure HTTPS in production)
-        c.SetCookie("access_token", tokens.AccessToken, 15*60, "/", "", false, true)
-        c.SetCookie("refresh_token", tokens.RefreshToken, 7*24*60*60, "/", "", false, true)
+        c.SetCookie("access_token", tokens.AccessToken, tokens.ExpiresIn, "/", "", false, true)
+        c.SetCookie("refresh_token", tokens.RefreshToken, tokens.RefreshExpiresIn, "/", "", false, true)
 
 		c.JSON(http.StatusOK, dto.AuthResponse{
 			User: dto.UserResponse{
@@ -687,6 +864,7 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 // @Success 200 {object} dto.OTPResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
 // @Router /auth/resend-otp [post]
 func (h *AuthHandler) ResendOTP(c *gin.Context) {
 	var req dto.ResendOTPRequest
@@ -699,7 +877,7 @@ func (h *AuthHandler) ResendOTP(c *gin.Context) {
 	}
 
 	// Resend OTP
-	_, err := h.otpService.ResendOTP(req.Email, req.Type, nil)
+	otp, err := h.otpService.ResendOTP(req.Email, req.Type, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Failed to resend OTP",
@@ -709,8 +887,9 @@ func (h *AuthHandler) ResendOTP(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, dto.OTPResponse{
-		Message:   "OTP resent successfully",
-		ExpiresIn: 900, // 15 minutes
+		Message:     "OTP resent successfully",
+		ChallengeID: otp.ResourceID,
+		ExpiresIn:   900, // 15 minutes
 	})
 }
 
@@ -723,6 +902,7 @@ func (h *AuthHandler) ResendOTP(c *gin.Context) {
 // @Param request body dto.SendOTPRequest true "Send OTP for password reset"
 // @Success 200 {object} dto.OTPResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
 // @Router /auth/forgot-password [post]
 func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	var req dto.SendOTPRequest
@@ -750,10 +930,18 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 		return
 	}
 	// send OTP
-	_, _ = h.otpService.SendOTP(req.Email, "password_reset", nil)
+	otp, err := h.otpService.SendOTP(req.Email, "password_reset", nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to send OTP",
+			Message: err.Error(),
+		})
+		return
+	}
 	c.JSON(http.StatusOK, dto.OTPResponse{
-		Message:   "Password reset OTP sent successfully",
-		ExpiresIn: 900,
+		Message:     "Password reset OTP sent successfully",
+		ChallengeID: otp.ResourceID,
+		ExpiresIn:   900,
 	})
 }
 
@@ -767,6 +955,7 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 // @Success 200 {object} dto.SuccessResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
 // @Router /auth/password-reset [post]
 func (h *AuthHandler) PasswordReset(c *gin.Context) {
 	var req dto.PasswordResetRequest
@@ -786,7 +975,7 @@ func (h *AuthHandler) PasswordReset(c *gin.Context) {
 		return
 	}
 	// Verify OTP
-	_, err := h.otpService.VerifyOTP(req.Email, req.OTPCode, "password_reset")
+	_, err := h.otpService.VerifyOTP(req.ChallengeID, req.Email, req.OTPCode, "password_reset")
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
 			Error:   "Invalid OTP",
@@ -820,7 +1009,48 @@ func (h *AuthHandler) PasswordReset(c *gin.Context) {
 		})
 		return
 	}
+	// Sessions started with the old password must not outlive it
+	if err := h.authUsecase.LogoutAll(c.Request.Context(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to revoke sessions",
+			Message: err.Error(),
+		})
+		return
+	}
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Message: "Password reset successfully. You can now log in with your new password.",
 	})
 }
+
+// mergeGuestCart moves the cart of a guest who just signed in into their
+// account's cart and drops the cart cookie. A failed merge doesn't fail the
+// sign-in; the guest cart is kept for the next one.
+func (h *AuthHandler) mergeGuestCart(c *gin.Context, userID uint) {
+	token, err := c.Cookie(cartTokenCookie)
+	if err != nil || token == "" {
+		return
+	}
+	if err := h.cartUsecase.MergeGuestCart(c.Request.Context(), userID, token); err != nil {
+		log.Printf("Failed to merge guest cart into user %d's cart: %v", userID, err)
+		return
+	}
+	clearCartCookie(c)
+}
+
+// sessionContext carries the client device into session creation
+func sessionContext(c *gin.Context) context.Context {
+	return usecase.WithSessionClient(c.Request.Context(), usecase.SessionClient{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	})
+}
+
+// requestRefreshToken returns the refresh token from the request body,
+// falling back to the refresh_token cookie
+func requestRefreshToken(c *gin.Context, bodyToken string) string {
+	if bodyToken != "" {
+		return bodyToken
+	}
+	cookieToken, _ := c.Cookie("refresh_token")
+	return cookieToken
+}
@@ -1,247 +1,346 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
-	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
-	"electronics-store/internal/domain/models"
 	"electronics-store/internal/dto"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
 )
 
+// cartTokenCookie holds the signed token of a guest's cart
+const cartTokenCookie = "cart_token"
+
 type CartHandler struct {
-	DB *gorm.DB
+	cartUsecase     usecase.CartUsecase
+	shippingUsecase usecase.ShippingUsecase
+	guestCartTTL    time.Duration
 }
 
-func NewCartHandler(db *gorm.DB) *CartHandler {
-	return &CartHandler{DB: db}
+// NewCartHandler creates the cart handler. The cart cookie of a guest lasts
+// guestCartTTL from their last use of the cart, like the cart itself.
+func NewCartHandler(cartUsecase usecase.CartUsecase, shippingUsecase usecase.ShippingUsecase, guestCartTTL time.Duration) *CartHandler {
+	return &CartHandler{cartUsecase: cartUsecase, shippingUsecase: shippingUsecase, guestCartTTL: guestCartTTL}
 }
 
 // GetCart godoc
-// @Summary Get user cart
-// @Description Get user's shopping cart
+// @Summary Get cart
+// @Description Get the shopping cart of the signed-in user, or of the guest whose cart_token cookie is sent, at current prices. Lines whose price changed since they were added, or that can no longer be ordered as they are, are flagged.
 // @Tags cart
 // @Accept json
 // @Produce json
-// @Success 200 {object} dto.SuccessResponse
-// @Failure 401 {object} dto.ErrorResponse
+// @Success 200 {object} dto.CartResponse
 // @Router /cart [get]
 func (h *CartHandler) GetCart(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	owner := cartOwner(c)
+
+	cart, err := h.cartUsecase.GetCart(c.Request.Context(), owner)
+	if err != nil {
+		respondCartError(c, "Failed to get cart", err)
 		return
 	}
-	var cart models.Cart
-	if err := h.DB.Where("user_id = ?", userID).FirstOrCreate(&cart, models.Cart{UserID: userID.(uint)}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cart"})
-		return
-	}
-	var items []models.CartItem
-	if err := h.DB.Where("cart_id = ?", cart.ID).Preload("Product.Images").Preload("Variant").Find(&items).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load cart items"})
-		return
-	}
-	var total float64
-	var respItems []map[string]interface{}
-	for _, item := range items {
-		price := item.Product.Price
-		total += price * float64(item.Quantity)
-		
-		// Get primary image
-		var imageURL string
-		if len(item.Product.Images) > 0 {
-			for _, img := range item.Product.Images {
-				if img.IsPrimary {
-					imageURL = img.URL
-					break
-				}
-			}
-			if imageURL == "" {
-				imageURL = item.Product.Images[0].URL
-			}
-		}
-		
-		respItem := map[string]interface{}{
-			"id":         item.ID,
-			"product_id": item.ProductID,
-			"variant_id": item.VariantID,
-			"quantity":   item.Quantity,
-			"price":      price,
-			"product": map[string]interface{}{
-				"id":          item.Product.ID,
-				"resource_id": item.Product.ResourceID,
-				"name":        item.Product.Name,
-				"image":       imageURL,
-				"price":       item.Product.Price,
-				"sku":         item.Product.SKU,
-			},
-		}
-		
-		if item.Variant != nil {
-			respItem["variant"] = map[string]interface{}{
-				"id":    item.Variant.ID,
-				"name":  item.Variant.Name,
-				"price": item.Variant.Price,
-			}
-		}
-		
-		respItems = append(respItems, respItem)
-	}
-	c.JSON(http.StatusOK, gin.H{
-		"items":      respItems,
-		"itemsCount": len(respItems),
-		"total":      total,
-	})
+	h.respondCart(c, owner, cart)
 }
 
 // AddToCart godoc
 // @Summary Add item to cart
-// @Description Add product to user's cart
+// @Description Add product to the cart. Products with options are added as one of their variants. A guest's first item creates their cart and sets the cart_token cookie.
 // @Tags cart
 // @Accept json
 // @Produce json
 // @Param request body dto.AddToCartRequest true "Add to cart request"
-// @Success 200 {object} dto.SuccessResponse
+// @Success 200 {object} dto.CartResponse
 // @Failure 400 {object} dto.ErrorResponse
-// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
 // @Router /cart/items [post]
 func (h *CartHandler) AddToCart(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	owner := cartOwner(c)
+	var req dto.AddToCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
 		return
 	}
-	var cart models.Cart
-	if err := h.DB.Where("user_id = ?", userID).FirstOrCreate(&cart, models.Cart{UserID: userID.(uint)}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cart"})
+	if req.ProductID == nil && req.ProductResourceID == nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: "product_id or product_resource_id required",
+		})
 		return
 	}
-	var req dto.AddToCartRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
-		return
-	}
-	
-	// Determine product ID (accept either numeric ID or resource_id)
-	var productID uint
-	if req.ProductID != nil {
-		productID = *req.ProductID
-	} else if req.ProductResourceID != nil {
-		// Look up product by resource_id
-		var product models.Product
-		if err := h.DB.Where("resource_id = ?", *req.ProductResourceID).First(&product).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Product not found"})
-			return
-		}
-		productID = product.ID
-	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "product_id or product_resource_id required"})
-		return
-	}
-	
-	var item models.CartItem
-	if err := h.DB.Where("cart_id = ? AND product_id = ? AND variant_id = ?", cart.ID, productID, req.VariantID).First(&item).Error; err == nil {
-		item.Quantity += req.Quantity
-		h.DB.Save(&item)
-	} else {
-		item = models.CartItem{
-			CartID:    cart.ID,
-			ProductID: productID,
-			VariantID: req.VariantID,
-			Quantity:  req.Quantity,
-		}
-		h.DB.Create(&item)
+
+	cart, err := h.cartUsecase.AddItem(c.Request.Context(), owner, req)
+	if err != nil {
+		respondCartError(c, "Failed to add item to cart", err)
+		return
 	}
-	h.GetCart(c)
+	h.respondCart(c, owner, cart)
 }
 
 // UpdateCartItem godoc
 // @Summary Update cart item
-// @Description Update quantity of cart item
+// @Description Update quantity of cart item. The item takes the current price.
 // @Tags cart
 // @Accept json
 // @Produce json
 // @Param id path string true "Item ID"
 // @Param request body dto.UpdateCartItemRequest true "Update cart item request"
-// @Success 200 {object} dto.SuccessResponse
+// @Success 200 {object} dto.CartResponse
 // @Failure 400 {object} dto.ErrorResponse
-// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
 // @Router /cart/items/{id} [put]
 func (h *CartHandler) UpdateCartItem(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	owner := cartOwner(c)
+	itemID, ok := cartItemID(c)
+	if !ok {
 		return
 	}
-	var cart models.Cart
-	if err := h.DB.Where("user_id = ?", userID).First(&cart).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cart"})
-		return
-	}
-	itemID, _ := strconv.Atoi(c.Param("id"))
 	var req dto.UpdateCartItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
 		return
 	}
-	var item models.CartItem
-	if err := h.DB.Where("id = ? AND cart_id = ?", itemID, cart.ID).First(&item).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+
+	cart, err := h.cartUsecase.UpdateItem(c.Request.Context(), owner, itemID, req.Quantity)
+	if err != nil {
+		respondCartError(c, "Failed to update cart item", err)
 		return
 	}
-	item.Quantity = req.Quantity
-	h.DB.Save(&item)
-	h.GetCart(c)
+	h.respondCart(c, owner, cart)
 }
 
 // RemoveFromCart godoc
 // @Summary Remove item from cart
-// @Description Remove product from user's cart
+// @Description Remove product from the cart
 // @Tags cart
 // @Accept json
 // @Produce json
 // @Param id path string true "Item ID"
-// @Success 200 {object} dto.SuccessResponse
-// @Failure 401 {object} dto.ErrorResponse
+// @Success 200 {object} dto.CartResponse
+// @Failure 404 {object} dto.ErrorResponse
 // @Router /cart/items/{id} [delete]
 func (h *CartHandler) RemoveFromCart(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	owner := cartOwner(c)
+	itemID, ok := cartItemID(c)
+	if !ok {
 		return
 	}
-	var cart models.Cart
-	if err := h.DB.Where("user_id = ?", userID).First(&cart).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cart"})
+
+	cart, err := h.cartUsecase.RemoveItem(c.Request.Context(), owner, itemID)
+	if err != nil {
+		respondCartError(c, "Failed to remove cart item", err)
 		return
 	}
-	itemID, _ := strconv.Atoi(c.Param("id"))
-	h.DB.Where("id = ? AND cart_id = ?", itemID, cart.ID).Delete(&models.CartItem{})
-	h.GetCart(c)
+	h.respondCart(c, owner, cart)
 }
 
 // ClearCart godoc
 // @Summary Clear cart
-// @Description Clear all items from user's cart
+// @Description Clear all items from the cart
 // @Tags cart
 // @Accept json
 // @Produce json
-// @Success 200 {object} dto.SuccessResponse
-// @Failure 401 {object} dto.ErrorResponse
+// @Success 200 {object} dto.CartResponse
 // @Router /cart [delete]
 func (h *CartHandler) ClearCart(c *gin.Context) {
+	owner := cartOwner(c)
+
+	cart, err := h.cartUsecase.Clear(c.Request.Context(), owner)
+	if err != nil {
+		respondCartError(c, "Failed to clear cart", err)
+		return
+	}
+	h.respondCart(c, owner, cart)
+}
+
+// ApplyDiscount godoc
+// @Summary Apply discount code
+// @Description Apply a discount code to the cart. The code is redeemed at checkout.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param request body dto.ApplyDiscountRequest true "Discount code"
+// @Success 200 {object} dto.CartResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /cart/discount [post]
+func (h *CartHandler) ApplyDiscount(c *gin.Context) {
+	owner := cartOwner(c)
+	var req dto.ApplyDiscountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	cart, err := h.cartUsecase.ApplyDiscount(c.Request.Context(), owner, req.Code)
+	if err != nil {
+		respondCartError(c, "Discount not applied", err)
+		return
+	}
+	h.respondCart(c, owner, cart)
+}
+
+// RemoveDiscount godoc
+// @Summary Remove discount code
+// @Description Remove the discount code from the cart
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.CartResponse
+// @Router /cart/discount [delete]
+func (h *CartHandler) RemoveDiscount(c *gin.Context) {
+	owner := cartOwner(c)
+
+	cart, err := h.cartUsecase.RemoveDiscount(c.Request.Context(), owner)
+	if err != nil {
+		respondCartError(c, "Failed to remove discount", err)
+		return
+	}
+	h.respondCart(c, owner, cart)
+}
+
+// ShippingOptions godoc
+// @Summary Quote shipping options
+// @Description Price the shipping methods available for the cart contents and destination address. Digital products are not shipped.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param address_id query string false "Address Resource ID (defaults to the user's default shipping address)"
+// @Success 200 {object} dto.ShippingOptionsResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /cart/shipping-options [get]
+func (h *CartHandler) ShippingOptions(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
-	var cart models.Cart
-	if err := h.DB.Where("user_id = ?", userID).First(&cart).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cart"})
+	var req dto.ShippingOptionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	quotes, err := h.shippingUsecase.QuoteCart(c.Request.Context(), userID.(uint), req.AddressID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, usecase.ErrAddressNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, usecase.ErrCartEmpty),
+			errors.Is(err, usecase.ErrProductUnavailable),
+			errors.Is(err, usecase.ErrInvalidQuantity):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, dto.ErrorResponse{
+			Error:   "Failed to quote shipping",
+			Message: err.Error(),
+		})
 		return
 	}
-	h.DB.Where("cart_id = ?", cart.ID).Delete(&models.CartItem{})
-	h.GetCart(c)
+
+	options := make([]dto.ShippingOptionResponse, 0, len(quotes.Options))
+	for _, quote := range quotes.Options {
+		options = append(options, dto.ShippingOptionResponse{
+			MethodID:      quote.Method.ResourceID,
+			Name:          quote.Method.Name,
+			Type:          quote.Method.Type,
+			Zone:          quote.Zone.Name,
+			Cost:          quote.Cost,
+			EstimatedDays: quote.Method.EstimatedDays,
+		})
+	}
+	c.JSON(http.StatusOK, dto.ShippingOptionsResponse{
+		RequiresShipping: quotes.RequiresShipping,
+		Weight:           quotes.Weight,
+		Options:          options,
+	})
+}
+
+// isDiscountRejection reports whether err means a discount code cannot be
+// redeemed, as opposed to an internal failure
+func isDiscountRejection(err error) bool {
+	return errors.Is(err, usecase.ErrDiscountInvalid) ||
+		errors.Is(err, usecase.ErrDiscountNotActive) ||
+		errors.Is(err, usecase.ErrDiscountExpired) ||
+		errors.Is(err, usecase.ErrDiscountUsageLimit) ||
+		errors.Is(err, usecase.ErrDiscountMinimumNotMet)
+}
+
+// cartOwner returns whose cart the request is for: the signed-in user's, or
+// the guest's whose cart cookie it carries
+func cartOwner(c *gin.Context) usecase.CartOwner {
+	if userID, exists := c.Get("user_id"); exists {
+		return usecase.CartOwner{UserID: userID.(uint)}
+	}
+	token, _ := c.Cookie(cartTokenCookie)
+	return usecase.CartOwner{GuestToken: token}
+}
+
+// respondCart sends the cart and keeps a guest's cart cookie in step with
+// it: refreshed while the cart is used, dropped once it has expired
+func (h *CartHandler) respondCart(c *gin.Context, owner usecase.CartOwner, cart *dto.CartResponse) {
+	if cart.GuestToken != "" {
+		// Secure=false for local dev like the auth cookies; ensure HTTPS in production
+		c.SetCookie(cartTokenCookie, cart.GuestToken, int(h.guestCartTTL.Seconds()), "/", "", false, true)
+	} else if owner.GuestToken != "" {
+		clearCartCookie(c)
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+func clearCartCookie(c *gin.Context) {
+	c.SetCookie(cartTokenCookie, "", -1, "/", "", false, true)
+}
+
+// cartItemID parses the item ID path parameter, responding with 400 when it
+// is not a number
+func cartItemID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid item ID",
+			Message: "Item ID must be a valid number",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func respondCartError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrCartItemNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrProductNotFound),
+		errors.Is(err, usecase.ErrProductUnavailable),
+		errors.Is(err, usecase.ErrInvalidQuantity),
+		errors.Is(err, usecase.ErrVariantNotFound),
+		errors.Is(err, usecase.ErrVariantRequired),
+		isDiscountRejection(err):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrInsufficientStock):
+		status = http.StatusConflict
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
 }
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationUsecase usecase.NotificationUsecase
+}
+
+func NewNotificationHandler(notificationUsecase usecase.NotificationUsecase) *NotificationHandler {
+	return &NotificationHandler{
+		notificationUsecase: notificationUsecase,
+	}
+}
+
+// List godoc
+// @Summary List notifications
+// @Description Get the current user's in-app notifications, newest first
+// @Tags notifications
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param unread query bool false "Only unread notifications"
+// @Success 200 {object} dto.NotificationListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /me/notifications [get]
+func (h *NotificationHandler) List(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req dto.NotificationListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// Set defaults
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	notifications, total, err := h.notificationUsecase.List(c.Request.Context(), userID.(uint), req.Unread, req.Page, req.Limit)
+	if err != nil {
+		respondNotificationError(c, "Failed to get notifications", err)
+		return
+	}
+	unread, err := h.notificationUsecase.UnreadCount(c.Request.Context(), userID.(uint))
+	if err != nil {
+		respondNotificationError(c, "Failed to get notifications", err)
+		return
+	}
+
+	responses := make([]dto.NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		responses = append(responses, newNotificationResponse(notification))
+	}
+
+	c.JSON(http.StatusOK, dto.NotificationListResponse{
+		Notifications: responses,
+		Total:         total,
+		Unread:        unread,
+		Page:          req.Page,
+		Limit:         req.Limit,
+	})
+}
+
+// UnreadCount godoc
+// @Summary Count unread notifications
+// @Description Get how many of the current user's notifications are unread, e.g. for a badge
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} dto.NotificationUnreadCountResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /me/notifications/unread-count [get]
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	unread, err := h.notificationUsecase.UnreadCount(c.Request.Context(), userID.(uint))
+	if err != nil {
+		respondNotificationError(c, "Failed to count notifications", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NotificationUnreadCountResponse{
+		Unread: unread,
+	})
+}
+
+// MarkRead godoc
+// @Summary Mark a notification read
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Notification Resource ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /me/notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.notificationUsecase.MarkRead(c.Request.Context(), userID.(uint), c.Param("id")); err != nil {
+		respondNotificationError(c, "Failed to mark notification read", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Notification marked read",
+	})
+}
+
+// MarkAllRead godoc
+// @Summary Mark all notifications read
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} dto.NotificationMarkAllReadResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /me/notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	marked, err := h.notificationUsecase.MarkAllRead(c.Request.Context(), userID.(uint))
+	if err != nil {
+		respondNotificationError(c, "Failed to mark notifications read", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NotificationMarkAllReadResponse{
+		Marked: marked,
+	})
+}
+
+// Delete godoc
+// @Summary Delete a notification
+// @Description Remove a notification from the current user's inbox
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Notification Resource ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /me/notifications/{id} [delete]
+func (h *NotificationHandler) Delete(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.notificationUsecase.Delete(c.Request.Context(), userID.(uint), c.Param("id")); err != nil {
+		respondNotificationError(c, "Failed to delete notification", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Notification deleted",
+	})
+}
+
+func respondNotificationError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, usecase.ErrNotificationNotFound) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
+
+func newNotificationResponse(notification *models.Notification) dto.NotificationResponse {
+	return dto.NotificationResponse{
+		ResourceID: notification.ResourceID,
+		Kind:       notification.Kind,
+		Title:      notification.Title,
+		Body:       notification.Body,
+		Link:       notification.Link,
+		Read:       notification.ReadAt != nil,
+		ReadAt:     notification.ReadAt,
+		CreatedAt:  notification.CreatedAt,
+	}
+}
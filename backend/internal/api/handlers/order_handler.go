@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
-	"electronics-store/internal/dto"
 	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
 	"electronics-store/internal/usecase"
 
 	"github.com/gin-gonic/gin"
@@ -66,22 +69,7 @@ func (h *OrderHandler) List(c *gin.Context) {
 	// Convert to response DTOs
 	var orderResponses []dto.OrderResponse
 	for _, order := range orders {
-		orderResponses = append(orderResponses, dto.OrderResponse{
-			ResourceID:     order.ResourceID,
-			OrderNumber:    order.OrderNumber,
-			UserID:         order.UserID,
-			Status:         order.Status,
-			PaymentStatus:  order.PaymentStatus,
-			Subtotal:       order.Subtotal,
-			TaxAmount:      order.TaxAmount,
-			ShippingCost:   order.ShippingCost,
-			DiscountAmount: order.DiscountAmount,
-			Total:          order.Total,
-			Currency:       order.Currency,
-			Notes:          order.Notes,
-			CreatedAt:      order.CreatedAt.Format("2006-01-02T15:04:05Z"),
-			UpdatedAt:      order.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-		})
+		orderResponses = append(orderResponses, newOrderResponse(order))
 	}
 
 	c.JSON(http.StatusOK, dto.OrderListResponse{
@@ -94,17 +82,26 @@ func (h *OrderHandler) List(c *gin.Context) {
 
 // GetByID godoc
 // @Summary Get order by ID
-// @Description Get a single order by its ID
+// @Description Get a single order of the current user by its ID
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param id path string true "Order Resource ID"
-// @Success 200 {object} dto.OrderResponse
+// @Success 200 {object} dto.OrderDetailResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Router /orders/{id} [get]
 func (h *OrderHandler) GetByID(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
 	resourceID := c.Param("id")
 	if resourceID == "" {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
@@ -114,49 +111,23 @@ func (h *OrderHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderUsecase.GetByResourceID(c.Request.Context(), resourceID)
+	order, err := h.orderUsecase.GetForUser(c.Request.Context(), userID.(uint), resourceID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "Failed to get order",
-			Message: err.Error(),
-		})
-		return
-	}
-
-	if order == nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error:   "Order not found",
-			Message: "Order with the given ID does not exist",
-		})
+		respondOrderError(c, "Failed to get order", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.OrderResponse{
-		ResourceID:     order.ResourceID,
-		OrderNumber:    order.OrderNumber,
-		UserID:         order.UserID,
-		Status:         order.Status,
-		PaymentStatus:  order.PaymentStatus,
-		Subtotal:       order.Subtotal,
-		TaxAmount:      order.TaxAmount,
-		ShippingCost:   order.ShippingCost,
-		DiscountAmount: order.DiscountAmount,
-		Total:          order.Total,
-		Currency:       order.Currency,
-		Notes:          order.Notes,
-		CreatedAt:      order.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:      order.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-	})
+	c.JSON(http.StatusOK, newOrderDetailResponse(order))
 }
 
 // Create godoc
 // @Summary Create order
-// @Description Create a new order
+// @Description Place an order for the items in the current user's cart. Prices and totals are computed server-side.
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param request body dto.CreateOrderRequest true "Create order request"
-// @Success 201 {object} dto.OrderResponse
+// @Success 201 {object} dto.OrderDetailResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Router /orders [post]
@@ -172,7 +143,7 @@ func (h *OrderHandler) Create(c *gin.Context) {
 	}
 
 	var req dto.CreateOrderRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request",
 			Message: err.Error(),
@@ -180,61 +151,65 @@ func (h *OrderHandler) Create(c *gin.Context) {
 		return
 	}
 
-	// Create order (simplified implementation)
-	order := &models.Order{
-		UserID:        userID.(uint),
-		Status:        "pending",
-		PaymentStatus: "pending",
-		Subtotal:      req.Subtotal,
-		TaxAmount:     req.TaxAmount,
-		ShippingCost:  req.ShippingCost,
-		DiscountAmount: req.DiscountAmount,
-		Total:         req.Total,
-		Currency:      req.Currency,
-		Notes:         req.Notes,
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+		return
 	}
 
-	err := h.orderUsecase.Create(c.Request.Context(), order)
+	order, err := h.orderUsecase.Checkout(c.Request.Context(), userID.(uint), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrCartEmpty) ||
+			errors.Is(err, usecase.ErrProductUnavailable) ||
+			errors.Is(err, usecase.ErrInvalidQuantity) ||
+			errors.Is(err, usecase.ErrShippingAddressRequired) ||
+			errors.Is(err, usecase.ErrShippingMethodRequired) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, usecase.ErrAddressNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, usecase.ErrInsufficientStock) ||
+			errors.Is(err, usecase.ErrShippingMethodUnavailable) ||
+			isDiscountRejection(err) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, dto.ErrorResponse{
 			Error:   "Failed to create order",
 			Message: err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusCreated, dto.OrderResponse{
-		ResourceID:     order.ResourceID,
-		OrderNumber:    order.OrderNumber,
-		UserID:         order.UserID,
-		Status:         order.Status,
-		PaymentStatus:  order.PaymentStatus,
-		Subtotal:       order.Subtotal,
-		TaxAmount:      order.TaxAmount,
-		ShippingCost:   order.ShippingCost,
-		DiscountAmount: order.DiscountAmount,
-		Total:          order.Total,
-		Currency:       order.Currency,
-		Notes:          order.Notes,
-		CreatedAt:      order.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:      order.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-	})
+	c.JSON(http.StatusCreated, newOrderDetailResponse(order))
 }
 
 // Update godoc
 // @Summary Update order
-// @Description Update an existing order
+// @Description Update notes or cancel the current user's order while it is pending
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param id path string true "Order Resource ID"
 // @Param request body dto.UpdateOrderRequest true "Update order request"
-// @Success 200 {object} dto.OrderResponse
+// @Success 200 {object} dto.OrderDetailResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
 // @Router /orders/{id} [put]
 func (h *OrderHandler) Update(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
 	resourceID := c.Param("id")
 	if resourceID == "" {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
@@ -244,23 +219,6 @@ func (h *OrderHandler) Update(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderUsecase.GetByResourceID(c.Request.Context(), resourceID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "Failed to get order",
-			Message: err.Error(),
-		})
-		return
-	}
-
-	if order == nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error:   "Order not found",
-			Message: "Order with the given ID does not exist",
-		})
-		return
-	}
-
 	var req dto.UpdateOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
@@ -270,93 +228,189 @@ func (h *OrderHandler) Update(c *gin.Context) {
 		return
 	}
 
-	// Update fields
-	if req.Status != nil {
-		order.Status = *req.Status
-	}
-	if req.PaymentStatus != nil {
-		order.PaymentStatus = *req.PaymentStatus
-	}
-	if req.Notes != nil {
-		order.Notes = *req.Notes
-	}
-
-	err = h.orderUsecase.Update(c.Request.Context(), order)
+	order, err := h.orderUsecase.UpdateForUser(c.Request.Context(), userID.(uint), resourceID, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "Failed to update order",
-			Message: err.Error(),
-		})
+		respondOrderError(c, "Failed to update order", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.OrderResponse{
-		ResourceID:     order.ResourceID,
-		OrderNumber:    order.OrderNumber,
-		UserID:         order.UserID,
-		Status:         order.Status,
-		PaymentStatus:  order.PaymentStatus,
-		Subtotal:       order.Subtotal,
-		TaxAmount:      order.TaxAmount,
-		ShippingCost:   order.ShippingCost,
-		DiscountAmount: order.DiscountAmount,
-		Total:          order.Total,
-		Currency:       order.Currency,
-		Notes:          order.Notes,
-		CreatedAt:      order.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:      order.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-	})
+	c.JSON(http.StatusOK, newOrderDetailResponse(order))
 }
 
-// Delete godoc
-// @Summary Delete order
-// @Description Delete an order
+// Cancel godoc
+// @Summary Cancel order
+// @Description Cancel the current user's order while it is still pending
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param id path string true "Order Resource ID"
-// @Success 200 {object} dto.SuccessResponse
-// @Failure 400 {object} dto.ErrorResponse
+// @Param request body dto.CancelOrderRequest false "Cancellation reason"
+// @Success 200 {object} dto.OrderDetailResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
-// @Router /orders/{id} [delete]
-func (h *OrderHandler) Delete(c *gin.Context) {
-	resourceID := c.Param("id")
-	if resourceID == "" {
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /orders/{id}/cancel [post]
+func (h *OrderHandler) Cancel(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req dto.CancelOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Invalid request",
-			Message: "Order ID is required",
+			Message: err.Error(),
 		})
 		return
 	}
 
-	order, err := h.orderUsecase.GetByResourceID(c.Request.Context(), resourceID)
+	order, err := h.orderUsecase.CancelForUser(c.Request.Context(), userID.(uint), c.Param("id"), req.Reason)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "Failed to get order",
-			Message: err.Error(),
-		})
+		respondOrderError(c, "Failed to cancel order", err)
 		return
 	}
 
-	if order == nil {
+	c.JSON(http.StatusOK, newOrderDetailResponse(order))
+}
+
+// respondOrderError maps order usecase errors to HTTP responses
+func respondOrderError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrOrderNotFound):
 		c.JSON(http.StatusNotFound, dto.ErrorResponse{
 			Error:   "Order not found",
 			Message: "Order with the given ID does not exist",
 		})
 		return
+	case errors.Is(err, usecase.ErrOrderActionNotAllowed):
+		status = http.StatusForbidden
+	case errors.Is(err, usecase.ErrOrderNotCancellable),
+		errors.Is(err, usecase.ErrOrderNotEditable),
+		errors.Is(err, usecase.ErrOrderHasPayment),
+		errors.Is(err, usecase.ErrInvalidStatusTransition):
+		status = http.StatusConflict
 	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
 
-	err = h.orderUsecase.Delete(c.Request.Context(), order.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "Failed to delete order",
-			Message: err.Error(),
+// newOrderResponse converts an order model to its API representation
+func newOrderResponse(order *models.Order) dto.OrderResponse {
+	resp := dto.OrderResponse{
+		ResourceID:      order.ResourceID,
+		OrderNumber:     order.OrderNumber,
+		UserID:          order.UserID,
+		Status:          order.Status,
+		PaymentStatus:   order.PaymentStatus,
+		Subtotal:        order.Subtotal,
+		TaxAmount:       order.TaxAmount,
+		ShippingCost:    order.ShippingCost,
+		ShippingMethod:  order.ShippingMethod,
+		ShippingAddress: newOrderAddressResponse(order.ShippingAddress),
+		BillingAddress:  newOrderAddressResponse(order.BillingAddress),
+		DiscountAmount:  order.DiscountAmount,
+		DiscountCode:    order.DiscountCode,
+		Total:           order.Total,
+		Currency:        order.Currency,
+		Notes:           order.Notes,
+		Carrier:         order.Carrier,
+		TrackingNumber:  order.TrackingNumber,
+		TrackingURL:     order.TrackingURL,
+		CreatedAt:       order.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:       order.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	for _, tax := range order.Taxes {
+		resp.Taxes = append(resp.Taxes, dto.OrderTaxResponse{
+			Name:          tax.Name,
+			Country:       tax.Country,
+			State:         tax.State,
+			Rate:          tax.Rate,
+			Inclusive:     tax.Inclusive,
+			TaxableAmount: tax.TaxableAmount,
+			Amount:        tax.Amount,
 		})
-		return
 	}
+	if order.ShippedAt != nil {
+		shippedAt := order.ShippedAt.Format(time.RFC3339)
+		resp.ShippedAt = &shippedAt
+	}
+	if order.DeliveredAt != nil {
+		deliveredAt := order.DeliveredAt.Format(time.RFC3339)
+		resp.DeliveredAt = &deliveredAt
+	}
+	return resp
+}
 
-	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Order deleted successfully",
-	})
-}
\ No newline at end of file
+// newOrderDetailResponse converts an order model including its line items
+// and status history
+func newOrderDetailResponse(order *models.Order) dto.OrderDetailResponse {
+	return dto.OrderDetailResponse{
+		OrderResponse: newOrderResponse(order),
+		OrderItems:    newOrderItemResponses(order.OrderItems),
+		StatusHistory: newOrderStatusHistoryResponses(order.StatusHistory),
+	}
+}
+
+// newOrderStatusHistoryResponses converts status history entries, oldest first
+func newOrderStatusHistoryResponses(history []models.OrderStatusHistory) []dto.OrderStatusHistoryResponse {
+	entries := make([]dto.OrderStatusHistoryResponse, 0, len(history))
+	for _, entry := range history {
+		entries = append(entries, dto.OrderStatusHistoryResponse{
+			ResourceID: entry.ResourceID,
+			FromStatus: entry.FromStatus,
+			ToStatus:   entry.ToStatus,
+			ActorID:    entry.ActorID,
+			ActorRole:  entry.ActorRole,
+			Note:       entry.Note,
+			CreatedAt:  entry.CreatedAt,
+		})
+	}
+	return entries
+}
+
+// newOrderItemResponses converts order line items to their API representation
+func newOrderItemResponses(orderItems []models.OrderItem) []dto.OrderItemResponse {
+	items := make([]dto.OrderItemResponse, 0, len(orderItems))
+	for _, item := range orderItems {
+		resp := dto.OrderItemResponse{
+			ResourceID: item.ResourceID,
+			Product: dto.ProductSummaryResponse{
+				ResourceID: item.Product.ResourceID,
+				Name:       item.Product.Name,
+				SKU:        item.Product.SKU,
+				Price:      item.Price,
+				Image:      item.Product.PrimaryImageURL(),
+			},
+			Quantity:  item.Quantity,
+			Price:     item.Price,
+			Total:     item.Total,
+			TaxAmount: item.TaxAmount,
+		}
+		if item.Variant != nil {
+			resp.Variant = &dto.VariantResponse{
+				ID:            item.Variant.ID,
+				ResourceID:    item.Variant.ResourceID,
+				ProductID:     item.Variant.ProductID,
+				Name:          item.Variant.Name,
+				SKU:           item.Variant.SKU,
+				Price:         item.Variant.Price,
+				ComparePrice:  item.Variant.ComparePrice,
+				StockQuantity: item.Variant.StockQuantity,
+				Weight:        item.Variant.Weight,
+				IsActive:      item.Variant.IsActive,
+				CreatedAt:     item.Variant.CreatedAt,
+				UpdatedAt:     item.Variant.UpdatedAt,
+			}
+		}
+		items = append(items, resp)
+	}
+	return items
+}
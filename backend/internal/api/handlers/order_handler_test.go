@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/repository"
+	"electronics-store/internal/services"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	customerID      uint = 1
+	otherCustomerID uint = 2
+)
+
+// memoryOrderRepository keeps orders in memory. It implements the part of
+// OrderRepository the customer order endpoints use; calling anything else
+// panics on the nil embedded interface.
+type memoryOrderRepository struct {
+	repository.OrderRepository
+	orders   map[uint]*models.Order
+	payments map[uint][]*models.Payment
+	history  []*models.OrderStatusHistory
+}
+
+func newMemoryOrderRepository(orders ...models.Order) *memoryOrderRepository {
+	r := &memoryOrderRepository{orders: map[uint]*models.Order{}, payments: map[uint][]*models.Payment{}}
+	for i := range orders {
+		r.orders[orders[i].ID] = &orders[i]
+	}
+	return r
+}
+
+func (r *memoryOrderRepository) GetByID(ctx context.Context, id uint) (*models.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, nil
+	}
+	loaded := *order
+	return &loaded, nil
+}
+
+func (r *memoryOrderRepository) GetByResourceID(ctx context.Context, resourceID string) (*models.Order, error) {
+	for _, order := range r.orders {
+		if order.ResourceID == resourceID {
+			return r.GetByID(ctx, order.ID)
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryOrderRepository) GetForUpdate(ctx context.Context, id uint) (*models.Order, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memoryOrderRepository) Update(ctx context.Context, order *models.Order) error {
+	updated := *order
+	r.orders[order.ID] = &updated
+	return nil
+}
+
+func (r *memoryOrderRepository) ListPayments(ctx context.Context, orderID uint) ([]*models.Payment, error) {
+	return r.payments[orderID], nil
+}
+
+func (r *memoryOrderRepository) CreateStatusHistory(ctx context.Context, entry *models.OrderStatusHistory) error {
+	r.history = append(r.history, entry)
+	return nil
+}
+
+func (r *memoryOrderRepository) Transaction(ctx context.Context, fn func(tx repository.OrderRepository) error) error {
+	return fn(r)
+}
+
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(ctx context.Context, out services.NotificationOutbox, n services.Notification) error {
+	return nil
+}
+
+func (nopNotifier) Flush() {}
+
+// newOrderTestRouter serves the customer order endpoints for customerID
+// over orders of customerID and otherCustomerID
+func newOrderTestRouter(t *testing.T) (*gin.Engine, *memoryOrderRepository) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	repo := newMemoryOrderRepository(
+		models.Order{ID: 1, ResourceID: "pending-order", UserID: customerID, Status: models.OrderStatusPending, PaymentStatus: models.OrderPaymentPending},
+		models.Order{ID: 2, ResourceID: "shipped-order", UserID: customerID, Status: models.OrderStatusShipped, PaymentStatus: models.OrderPaymentPaid},
+		models.Order{ID: 3, ResourceID: "other-order", UserID: otherCustomerID, Status: models.OrderStatusPending, PaymentStatus: models.OrderPaymentPending},
+		models.Order{ID: 4, ResourceID: "paid-order", UserID: customerID, Status: models.OrderStatusPending, PaymentStatus: models.OrderPaymentPaid},
+		models.Order{ID: 5, ResourceID: "paying-order", UserID: customerID, Status: models.OrderStatusPending, PaymentStatus: models.OrderPaymentPending},
+	)
+	repo.payments[4] = []*models.Payment{{OrderID: 4, Status: models.PaymentStatusCompleted}}
+	repo.payments[5] = []*models.Payment{{OrderID: 5, Status: models.PaymentStatusPending}}
+	orderUsecase := usecase.NewOrderUsecase(repo, nil, nil, nil, nil, nil, nopNotifier{})
+	handler := NewOrderHandler(orderUsecase)
+
+	router := gin.New()
+	orders := router.Group("/orders")
+	orders.Use(func(c *gin.Context) {
+		c.Set("user_id", customerID)
+		c.Next()
+	})
+	orders.GET("/:id", handler.GetByID)
+	orders.PUT("/:id", handler.Update)
+	orders.POST("/:id/cancel", handler.Cancel)
+	return router, repo
+}
+
+func serveOrderRequest(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestOrderHandlerCustomerAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"get own order", http.MethodGet, "/orders/pending-order", "", http.StatusOK},
+		{"get own shipped order", http.MethodGet, "/orders/shipped-order", "", http.StatusOK},
+		{"get other user's order", http.MethodGet, "/orders/other-order", "", http.StatusNotFound},
+		{"get unknown order", http.MethodGet, "/orders/missing-order", "", http.StatusNotFound},
+
+		{"update own pending order", http.MethodPut, "/orders/pending-order", `{"notes":"Leave at the door"}`, http.StatusOK},
+		{"update own shipped order", http.MethodPut, "/orders/shipped-order", `{"notes":"Leave at the door"}`, http.StatusConflict},
+		{"update other user's order", http.MethodPut, "/orders/other-order", `{"notes":"Leave at the door"}`, http.StatusNotFound},
+		{"cancel through update of own shipped order", http.MethodPut, "/orders/shipped-order", `{"status":"cancelled"}`, http.StatusConflict},
+
+		{"cancel own pending order", http.MethodPost, "/orders/pending-order/cancel", `{"reason":"Ordered by mistake"}`, http.StatusOK},
+		{"cancel own shipped order", http.MethodPost, "/orders/shipped-order/cancel", "", http.StatusConflict},
+		{"cancel own paid order", http.MethodPost, "/orders/paid-order/cancel", "", http.StatusConflict},
+		{"cancel own order with a payment in progress", http.MethodPost, "/orders/paying-order/cancel", "", http.StatusConflict},
+		{"cancel through update of own paid order", http.MethodPut, "/orders/paid-order", `{"status":"cancelled"}`, http.StatusConflict},
+		{"cancel other user's order", http.MethodPost, "/orders/other-order/cancel", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, repo := newOrderTestRouter(t)
+			before := map[uint]models.Order{}
+			for id, order := range repo.orders {
+				before[id] = *order
+			}
+
+			w := serveOrderRequest(router, tt.method, tt.path, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				return
+			}
+			for id, order := range repo.orders {
+				if order.Status != before[id].Status || order.Notes != before[id].Notes {
+					t.Errorf("order %d changed by a rejected request: %+v", id, order)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderHandlerUpdateNotes(t *testing.T) {
+	router, repo := newOrderTestRouter(t)
+
+	w := serveOrderRequest(router, http.MethodPut, "/orders/pending-order", `{"notes":"Leave at the door"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp dto.OrderDetailResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Notes != "Leave at the door" {
+		t.Errorf("response notes = %q, want %q", resp.Notes, "Leave at the door")
+	}
+	if repo.orders[1].Notes != "Leave at the door" {
+		t.Errorf("stored notes = %q, want %q", repo.orders[1].Notes, "Leave at the door")
+	}
+}
+
+func TestOrderHandlerCancel(t *testing.T) {
+	router, repo := newOrderTestRouter(t)
+
+	w := serveOrderRequest(router, http.MethodPost, "/orders/pending-order/cancel", `{"reason":"Ordered by mistake"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp dto.OrderDetailResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Status != models.OrderStatusCancelled {
+		t.Errorf("response status = %q, want %q", resp.Status, models.OrderStatusCancelled)
+	}
+	if len(repo.history) != 1 {
+		t.Fatalf("status history entries = %d, want 1", len(repo.history))
+	}
+	entry := repo.history[0]
+	if entry.ActorRole != usecase.OrderActorCustomer || entry.Note != "Cancelled by customer: Ordered by mistake" {
+		t.Errorf("status history = %+v, want a customer cancellation with the reason", entry)
+	}
+}
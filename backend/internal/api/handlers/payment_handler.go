@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/services"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header carrying the gateway signature of a webhook body
+const paymentSignatureHeader = "X-Payment-Signature"
+
+// maxWebhookBody caps how much of an unauthenticated webhook body is read
+const maxWebhookBody = 64 << 10
+
+type PaymentHandler struct {
+	paymentUsecase usecase.PaymentUsecase
+}
+
+func NewPaymentHandler(paymentUsecase usecase.PaymentUsecase) *PaymentHandler {
+	return &PaymentHandler{
+		paymentUsecase: paymentUsecase,
+	}
+}
+
+// Pay godoc
+// @Summary Pay for an order
+// @Description Charge the order total through the payment gateway
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Order Resource ID"
+// @Param request body dto.ProcessPaymentRequest true "Payment details"
+// @Success 200 {object} dto.PayOrderResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 402 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /orders/{id}/pay [post]
+func (h *PaymentHandler) Pay(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req dto.ProcessPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	order, payment, err := h.paymentUsecase.PayForUser(c.Request.Context(), userID.(uint), c.Param("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrPaymentsUnavailable):
+			c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{
+				Error:   "Payments unavailable",
+				Message: err.Error(),
+			})
+		case errors.Is(err, usecase.ErrOrderNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Order not found",
+				Message: "Order with the given ID does not exist",
+			})
+		case errors.Is(err, usecase.ErrPaymentDeclined):
+			c.JSON(http.StatusPaymentRequired, dto.ErrorResponse{
+				Error:   "Payment declined",
+				Message: err.Error(),
+			})
+		case errors.Is(err, usecase.ErrOrderAlreadyPaid), errors.Is(err, usecase.ErrOrderNotPayable), errors.Is(err, usecase.ErrPaymentInProgress):
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Payment not allowed",
+				Message: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Failed to process payment",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PayOrderResponse{
+		Order:   newOrderResponse(order),
+		Payment: newPaymentResponse(payment),
+	})
+}
+
+// Webhook godoc
+// @Summary Payment gateway webhook
+// @Description Receive signed payment notifications from the payment gateway
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param X-Payment-Signature header string true "Webhook signature"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 413 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /payments/webhook [post]
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, dto.ErrorResponse{
+				Error:   "Request too large",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err = h.paymentUsecase.HandleWebhook(c.Request.Context(), payload, c.GetHeader(paymentSignatureHeader))
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrPaymentsUnavailable):
+			c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{
+				Error:   "Payments unavailable",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrInvalidWebhookSignature):
+			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+				Error:   "Invalid signature",
+				Message: err.Error(),
+			})
+		case errors.Is(err, services.ErrInvalidWebhookPayload):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+		case errors.Is(err, usecase.ErrPaymentNotFound), errors.Is(err, usecase.ErrOrderNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Payment not found",
+				Message: err.Error(),
+			})
+		case errors.Is(err, usecase.ErrRefundInProgress):
+			// Answered with an error so the gateway sends it again later
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Refund in progress",
+				Message: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Failed to process webhook",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Webhook processed",
+	})
+}
+
+// newPaymentResponse converts a payment model to its API representation
+func newPaymentResponse(payment *models.Payment) dto.PaymentResponse {
+	return dto.PaymentResponse{
+		ResourceID:    payment.ResourceID,
+		Method:        payment.Method,
+		Amount:        payment.Amount,
+		Currency:      payment.Currency,
+		Status:        payment.Status,
+		TransactionID: payment.TransactionID,
+		ProcessedAt:   payment.ProcessedAt,
+		CreatedAt:     payment.CreatedAt,
+	}
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPaymentHandlerWithoutGateway(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMemoryOrderRepository(
+		models.Order{ID: 1, ResourceID: "pending-order", UserID: customerID, Status: models.OrderStatusPending, PaymentStatus: models.OrderPaymentPending, Total: 100},
+	)
+	handler := NewPaymentHandler(usecase.NewPaymentUsecase(repo, nil, nil, nopNotifier{}))
+
+	router := gin.New()
+	router.POST("/orders/:id/pay", func(c *gin.Context) {
+		c.Set("user_id", customerID)
+		c.Next()
+	}, handler.Pay)
+	router.POST("/payments/webhook", handler.Webhook)
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"pay", "/orders/pending-order/pay", `{"method":"stripe","payment_token":"tok_visa"}`},
+		{"webhook", "/payments/webhook", `{"id":"evt_1","type":"payment.captured","transaction_id":"mock_ch_1"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveOrderRequest(router, http.MethodPost, tt.path, tt.body)
+			if w.Code != http.StatusServiceUnavailable {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusServiceUnavailable, w.Body.String())
+			}
+		})
+	}
+	if repo.orders[1].PaymentStatus != models.OrderPaymentPending {
+		t.Errorf("payment status = %q, want it unchanged", repo.orders[1].PaymentStatus)
+	}
+}
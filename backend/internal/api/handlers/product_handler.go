@@ -297,6 +297,7 @@ func (h *ProductHandler) GetByID(c *gin.Context) {
 		CreatedAt:   product.Category.CreatedAt,
 		UpdatedAt:   product.Category.UpdatedAt,
 	}
+	options, variants := newVariantMatrix(product, false)
 
 	c.JSON(http.StatusOK, dto.ProductResponse{
 		ID:           product.ID,
@@ -319,6 +320,8 @@ func (h *ProductHandler) GetByID(c *gin.Context) {
 		CategoryID:   product.CategoryID,
 		Category:     category,
 		Images:       images,
+		Options:      options,
+		Variants:     variants,
 		CreatedAt:    product.CreatedAt,
 		UpdatedAt:    product.UpdatedAt,
 	})
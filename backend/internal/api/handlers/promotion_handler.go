@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"electronics-store/internal/dto"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionHandler struct {
+	promotionUsecase usecase.PromotionUsecase
+}
+
+func NewPromotionHandler(promotionUsecase usecase.PromotionUsecase) *PromotionHandler {
+	return &PromotionHandler{
+		promotionUsecase: promotionUsecase,
+	}
+}
+
+// List godoc
+// @Summary List active promotions
+// @Description Get the promotions that are active and within their schedule window
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param type query string false "Promotion type (banner, popup, sidebar)"
+// @Param position query string false "Position (top, bottom, left, right, center)"
+// @Success 200 {array} dto.PromotionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /promotions [get]
+func (h *PromotionHandler) List(c *gin.Context) {
+	var req dto.ActivePromotionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	promotions, err := h.promotionUsecase.ListActive(c.Request.Context(), req.Type, req.Position)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Failed to get promotions",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPromotionResponses(promotions))
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"electronics-store/internal/dto"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StockAlertHandler struct {
+	stockAlertUsecase usecase.StockAlertUsecase
+}
+
+func NewStockAlertHandler(stockAlertUsecase usecase.StockAlertUsecase) *StockAlertHandler {
+	return &StockAlertHandler{
+		stockAlertUsecase: stockAlertUsecase,
+	}
+}
+
+// Get godoc
+// @Summary Get back in stock alert
+// @Description Tell whether the current user will be emailed once the product is back in stock
+// @Tags products
+// @Produce json
+// @Param id path string true "Product Resource ID"
+// @Success 200 {object} dto.StockAlertResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /products/{id}/stock-alert [get]
+func (h *StockAlertHandler) Get(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	subscribed, err := h.stockAlertUsecase.IsSubscribed(c.Request.Context(), userID.(uint), c.Param("id"))
+	if err != nil {
+		respondStockAlertError(c, "Failed to get stock alert", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.StockAlertResponse{
+		ProductID:  c.Param("id"),
+		Subscribed: subscribed,
+	})
+}
+
+// Subscribe godoc
+// @Summary Create back in stock alert
+// @Description Email the current user once the out of stock product can be ordered again. The alert is removed after the email is sent.
+// @Tags products
+// @Produce json
+// @Param id path string true "Product Resource ID"
+// @Success 200 {object} dto.StockAlertResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /products/{id}/stock-alert [post]
+func (h *StockAlertHandler) Subscribe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.stockAlertUsecase.Subscribe(c.Request.Context(), userID.(uint), c.Param("id")); err != nil {
+		respondStockAlertError(c, "Failed to create stock alert", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.StockAlertResponse{
+		ProductID:  c.Param("id"),
+		Subscribed: true,
+	})
+}
+
+// Unsubscribe godoc
+// @Summary Delete back in stock alert
+// @Description Stop the back in stock email for the product
+// @Tags products
+// @Produce json
+// @Param id path string true "Product Resource ID"
+// @Success 200 {object} dto.StockAlertResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /products/{id}/stock-alert [delete]
+func (h *StockAlertHandler) Unsubscribe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.stockAlertUsecase.Unsubscribe(c.Request.Context(), userID.(uint), c.Param("id")); err != nil {
+		respondStockAlertError(c, "Failed to delete stock alert", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.StockAlertResponse{
+		ProductID:  c.Param("id"),
+		Subscribed: false,
+	})
+}
+
+func respondStockAlertError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrProductNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrProductInStock):
+		status = http.StatusConflict
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"electronics-store/internal/dto"
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	twoFactorUsecase usecase.TwoFactorUsecase
+	authUsecase      usecase.AuthUsecase
+}
+
+func NewTwoFactorHandler(twoFactorUsecase usecase.TwoFactorUsecase, authUsecase usecase.AuthUsecase) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorUsecase: twoFactorUsecase,
+		authUsecase:      authUsecase,
+	}
+}
+
+// Status godoc
+// @Summary Get two-factor authentication status
+// @Description Whether the current user has two-factor authentication enabled, whether it is required of them, and how many recovery codes are left
+// @Tags auth
+// @Produce json
+// @Success 200 {object} dto.TwoFactorStatusResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/2fa [get]
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	user, err := h.authUsecase.GetProfile(c.Request.Context(), userID.(uint))
+	if err != nil {
+		respondTwoFactorError(c, "Failed to get two-factor status", err)
+		return
+	}
+
+	enabled, recoveryCodesLeft, err := h.twoFactorUsecase.Status(c.Request.Context(), user.ID)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to get two-factor status", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.TwoFactorStatusResponse{
+		Enabled:           enabled,
+		Required:          user.IsAdmin,
+		RecoveryCodesLeft: recoveryCodesLeft,
+	})
+}
+
+// Enroll godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and otpauth URI for an authenticator app. Two-factor authentication is enabled once confirmed with a code.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} dto.TwoFactorEnrollResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /auth/2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	user, err := h.authUsecase.GetProfile(c.Request.Context(), userID.(uint))
+	if err != nil {
+		respondTwoFactorError(c, "Failed to start enrollment", err)
+		return
+	}
+
+	enrollment, err := h.twoFactorUsecase.Enroll(c.Request.Context(), user)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to start enrollment", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.TwoFactorEnrollResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+		Message:    "Add the account to your authenticator app, then confirm with a code from it",
+	})
+}
+
+// Confirm godoc
+// @Summary Confirm two-factor enrollment
+// @Description Enable two-factor authentication with a code from the authenticator app. The recovery codes are shown only once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} dto.TwoFactorRecoveryCodesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /auth/2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	recoveryCodes, err := h.twoFactorUsecase.Confirm(c.Request.Context(), userID.(uint), req.Code)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to enable two-factor authentication", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.TwoFactorRecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+		Message:       "Two-factor authentication enabled. Store these recovery codes safely; they won't be shown again.",
+	})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes; the old ones stop working
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} dto.TwoFactorRecoveryCodesResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Router /auth/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	recoveryCodes, err := h.twoFactorUsecase.RegenerateRecoveryCodes(c.Request.Context(), userID.(uint), req.Code)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to regenerate recovery codes", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.TwoFactorRecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+		Message:       "Recovery codes replaced. Store them safely; they won't be shown again.",
+	})
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off. Not allowed for admin accounts.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Router /auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+		})
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, err := h.authUsecase.GetProfile(c.Request.Context(), userID.(uint))
+	if err != nil {
+		respondTwoFactorError(c, "Failed to disable two-factor authentication", err)
+		return
+	}
+
+	if err := h.twoFactorUsecase.Disable(c.Request.Context(), user, req.Code); err != nil {
+		respondTwoFactorError(c, "Failed to disable two-factor authentication", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+func respondTwoFactorError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrInvalidTwoFactorCode):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrTwoFactorMandatory):
+		status = http.StatusForbidden
+	case errors.Is(err, usecase.ErrUserNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, usecase.ErrTwoFactorNotEnabled),
+		errors.Is(err, usecase.ErrTwoFactorNotEnrolled):
+		status = http.StatusConflict
+	}
+	c.JSON(status, dto.ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
@@ -31,6 +31,7 @@ func NewUploadHandler(uploadDir, baseURL string) *UploadHandler {
 	os.MkdirAll(filepath.Join(uploadDir, "product"), 0755)
 	os.MkdirAll(filepath.Join(uploadDir, "categories"), 0755)
 	os.MkdirAll(filepath.Join(uploadDir, "users"), 0755)
+	os.MkdirAll(filepath.Join(uploadDir, "promotions"), 0755)
 
 	return &UploadHandler{
 		uploadDir: uploadDir,
@@ -45,12 +46,39 @@ func NewUploadHandler(uploadDir, baseURL string) *UploadHandler {
 // @Accept multipart/form-data
 // @Produce json
 // @Param file formData file true "Image file"
-// @Param type formData string false "Upload type (product, category, user)" default(product)
+// @Param type formData string false "Upload type (product, category, user, promotions)" default(product)
 // @Success 200 {object} dto.UploadResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
 // @Router /admin/upload/image [post]
 func (h *UploadHandler) UploadImage(c *gin.Context) {
+	// Get upload type
+	uploadType := c.PostForm("type")
+	if uploadType == "" {
+		uploadType = "product"
+	}
+
+	h.saveImage(c, uploadType)
+}
+
+// UploadPromotionImage godoc
+// @Summary Upload a promotion image
+// @Description Upload a banner image for a promotion (Admin only)
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Image file"
+// @Success 200 {object} dto.UploadResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/promotions/image [post]
+func (h *UploadHandler) UploadPromotionImage(c *gin.Context) {
+	h.saveImage(c, "promotions")
+}
+
+// saveImage stores the image in the form's file field under the directory
+// of uploadType and responds with its URL
+func (h *UploadHandler) saveImage(c *gin.Context, uploadType string) {
 	// Get file from form
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -61,12 +89,6 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 		return
 	}
 
-	// Get upload type
-	uploadType := c.PostForm("type")
-	if uploadType == "" {
-		uploadType = "product"
-	}
-
 	// Validate file type
 	allowedExtensions := []string{".jpg", ".jpeg", ".png", ".gif", ".webp"}
 	ext := strings.ToLower(filepath.Ext(file.Filename))
@@ -132,7 +154,7 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 // @Accept multipart/form-data
 // @Produce json
 // @Param files formData file true "Image files" allowMultiple=true
-// @Param type formData string false "Upload type (product, category, user)" default(product)
+// @Param type formData string false "Upload type (product, category, user, promotions)" default(product)
 // @Success 200 {object} dto.MultipleUploadResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
@@ -261,7 +283,7 @@ func (h *UploadHandler) DeleteImage(c *gin.Context) {
 	id := c.Param("id")
 	
 	// Search for file in all upload directories
-	uploadTypes := []string{"products", "categories", "users"}
+	uploadTypes := []string{"products", "categories", "users", "promotions"}
 	
 	for _, uploadType := range uploadTypes {
 		uploadPath := filepath.Join(h.uploadDir, uploadType)
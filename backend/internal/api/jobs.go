@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"electronics-store/internal/scheduler"
+)
+
+// RegisterJobs adds the periodic maintenance jobs to the server's
+// scheduler. They are registered even when jobs are disabled so the admin
+// API can list them.
+func (s *Server) RegisterJobs() error {
+	cfg := s.config.Jobs
+
+	// Expired OTP codes are useless and only grow the table
+	if err := s.jobs.Register(scheduler.Job{
+		Name:     "otp-cleanup",
+		Schedule: cfg.OTPCleanupSchedule,
+		Timeout:  5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			return s.otpService.CleanupExpiredOTPs()
+		},
+	}); err != nil {
+		return err
+	}
+
+	// Delivered emails are kept a while for tracking, then dropped
+	if err := s.jobs.Register(scheduler.Job{
+		Name:     "email-outbox-cleanup",
+		Schedule: cfg.EmailOutboxCleanupSchedule,
+		Timeout:  5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := s.emailOutboxRepo.DeleteSentBefore(ctx, time.Now().Add(-s.config.Outbox.SentRetention))
+			return err
+		},
+	}); err != nil {
+		return err
+	}
+
+	// Customers waiting for a product hear about it once it is restocked,
+	// however the stock came back
+	if err := s.jobs.Register(scheduler.Job{
+		Name:     "back-in-stock",
+		Schedule: cfg.BackInStockSchedule,
+		Timeout:  5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			notified, err := s.stockAlertUsecase.NotifyRestocked(ctx)
+			if notified > 0 {
+				log.Printf("Notified %d customers of products back in stock", notified)
+			}
+			return err
+		},
+	}); err != nil {
+		return err
+	}
+
+	// Guest carts nobody came back to are dropped once they expire
+	if err := s.jobs.Register(scheduler.Job{
+		Name:     "guest-cart-cleanup",
+		Schedule: cfg.GuestCartCleanupSchedule,
+		Timeout:  5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			deleted, err := s.cartUsecase.DeleteExpiredGuestCarts(ctx)
+			if deleted > 0 {
+				log.Printf("Deleted %d expired guest carts", deleted)
+			}
+			return err
+		},
+	}); err != nil {
+		return err
+	}
+
+	// Unpaid orders hold reserved stock; give it back after a while
+	return s.jobs.Register(scheduler.Job{
+		Name:     "order-auto-cancel",
+		Schedule: cfg.OrderAutoCancelSchedule,
+		Timeout:  5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			cancelled, err := s.orderUsecase.CancelUnpaidOrders(ctx, cfg.PendingOrderTimeout)
+			if cancelled > 0 {
+				log.Printf("Cancelled %d orders not paid within %s", cancelled, cfg.PendingOrderTimeout)
+			}
+			return err
+		},
+	})
+}
@@ -3,14 +3,17 @@ package api
 import (
 	"context"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
 	"electronics-store/internal/api/handlers"
 	"electronics-store/internal/config"
 	"electronics-store/internal/database"
+	"electronics-store/internal/domain/models"
 	"electronics-store/internal/middleware"
 	"electronics-store/internal/repository"
+	"electronics-store/internal/scheduler"
 	"electronics-store/internal/services"
 	"electronics-store/internal/usecase"
 
@@ -18,12 +21,30 @@ import (
 )
 
 type Server struct {
-	config *config.Config
-	db     *database.Connection
-	router *gin.Engine
+	config      *config.Config
+	db          *database.Connection
+	jwtKeys     *services.JWTKeySet
+	rateLimiter *services.RateLimiter
+	rbac        usecase.RBACUsecase
+	router      *gin.Engine
+	httpServer  *http.Server
+
+	emailService   *services.EmailService
+	emailQueue     *services.EmailQueue
+	notifier       services.Notifier
+	paymentGateway services.PaymentGateway
+	cartTokens     *services.CartTokenSigner
+
+	// Background jobs and what they work with
+	jobs              *scheduler.Scheduler
+	otpService        *services.OTPService
+	orderUsecase      usecase.OrderUsecase
+	stockAlertUsecase usecase.StockAlertUsecase
+	cartUsecase       usecase.CartUsecase
+	emailOutboxRepo   repository.EmailOutboxRepository
 }
 
-func NewServer(cfg *config.Config, db *database.Connection) *Server {
+func NewServer(cfg *config.Config, db *database.Connection, jwtKeys *services.JWTKeySet, rateLimiter *services.RateLimiter, jobs *scheduler.Scheduler, emailService *services.EmailService, emailQueue *services.EmailQueue, notifier services.Notifier, paymentGateway services.PaymentGateway, otpService *services.OTPService, cartTokens *services.CartTokenSigner) *Server {
 	// Set Gin mode
 	if cfg.Server.Host == "localhost" {
 		gin.SetMode(gin.DebugMode)
@@ -32,8 +53,14 @@ func NewServer(cfg *config.Config, db *database.Connection) *Server {
 	}
 
 	router := gin.New()
+	// Gin trusts X-Forwarded-For from anyone by default, which would let
+	// clients pick their own IP and dodge the per-IP rate limits
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal("Invalid trusted proxies:", err)
+	}
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(middleware.RequestID())
 
 	// CORS middleware
 	router.Use(func(c *gin.Context) {
@@ -56,9 +83,24 @@ func NewServer(cfg *config.Config, db *database.Connection) *Server {
 	})
 
 	server := &Server{
-		config: cfg,
-		db:     db,
-		router: router,
+		config:      cfg,
+		db:          db,
+		jwtKeys:     jwtKeys,
+		rateLimiter: rateLimiter,
+		router:      router,
+		emailService:   emailService,
+		emailQueue:     emailQueue,
+		notifier:       notifier,
+		paymentGateway: paymentGateway,
+		jobs:           jobs,
+		otpService:     otpService,
+		cartTokens:     cartTokens,
+	}
+	server.httpServer = &http.Server{
+		Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
 	}
 
 	server.setupRoutes()
@@ -71,29 +113,63 @@ func (s *Server) setupRoutes() {
     productRepo := repository.NewProductRepository(s.db.DB)
     categoryRepo := repository.NewCategoryRepository(s.db.DB)
 	orderRepo := repository.NewOrderRepository(s.db.DB)
-	otpRepo := repository.NewOTPRepository(s.db.DB)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(s.db.DB)
 	reviewRepo := repository.NewReviewRepository(s.db.DB)
+	discountRepo := repository.NewDiscountRepository(s.db.DB)
+	promotionRepo := repository.NewPromotionRepository(s.db.DB)
+	taxRuleRepo := repository.NewTaxRuleRepository(s.db.DB)
+	addressRepo := repository.NewAddressRepository(s.db.DB)
+	shippingRepo := repository.NewShippingRepository(s.db.DB)
+	roleRepo := repository.NewRoleRepository(s.db.DB)
+	twoFactorRepo := repository.NewTwoFactorRepository(s.db.DB)
+	auditLogRepo := repository.NewAuditLogRepository(s.db.DB)
+	emailOutboxRepo := repository.NewEmailOutboxRepository(s.db.DB)
+	stockAlertRepo := repository.NewStockAlertRepository(s.db.DB)
+	notificationRepo := repository.NewNotificationRepository(s.db.DB)
+	variantRepo := repository.NewVariantRepository(s.db.DB)
+	cartRepo := repository.NewCartRepository(s.db.DB)
 
 	// Initialize services
-	emailService := services.NewEmailService(&s.config.Email)
-	otpService := services.NewOTPService(otpRepo, emailService)
 	googleOAuthService := services.NewGoogleOAuthService(s.config.OAuth.GoogleClientID)
 
 	// Initialize usecases
-	authUsecase := usecase.NewAuthUsecase(userRepo, s.config.JWT.AccessTokenSecret, s.config.JWT.RefreshTokenSecret, googleOAuthService, s.config.OAuth.GoogleClientSecret)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(twoFactorRepo)
+	authUsecase := usecase.NewAuthUsecase(userRepo, refreshTokenRepo, s.jwtKeys, s.config.JWT.AccessTokenTTL, s.config.JWT.RefreshTokenTTL, googleOAuthService, s.config.OAuth.GoogleClientSecret, s.rateLimiter, twoFactorUsecase)
     productUsecase := usecase.NewProductUsecase(productRepo)
     categoryUsecase := usecase.NewCategoryUsecase(categoryRepo, productUsecase)
-	orderUsecase := usecase.NewOrderUsecase(orderRepo)
+	taxUsecase := usecase.NewTaxUsecase(taxRuleRepo)
+	shippingUsecase := usecase.NewShippingUsecase(shippingRepo, orderRepo, addressRepo)
+	orderUsecase := usecase.NewOrderUsecase(orderRepo, discountRepo, addressRepo, taxUsecase, shippingUsecase, s.emailService, s.notifier)
+	discountUsecase := usecase.NewDiscountUsecase(discountRepo)
+	promotionUsecase := usecase.NewPromotionUsecase(promotionRepo)
+	paymentUsecase := usecase.NewPaymentUsecase(orderRepo, s.paymentGateway, s.emailService, s.notifier)
 	reviewUsecase := usecase.NewReviewUsecase(reviewRepo)
+	addressUsecase := usecase.NewAddressUsecase(addressRepo)
+	stockAlertUsecase := usecase.NewStockAlertUsecase(stockAlertRepo, productRepo, s.emailService, s.notifier)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo)
+	variantUsecase := usecase.NewVariantUsecase(variantRepo, productRepo)
+	cartUsecase := usecase.NewCartUsecase(cartRepo, productRepo, variantUsecase, discountUsecase, s.cartTokens, s.config.Cart.GuestCartTTL)
+	s.rbac = usecase.NewRBACUsecase(roleRepo)
+	auditUsecase := usecase.NewAuditUsecase(auditLogRepo)
+	s.orderUsecase = orderUsecase
+	s.stockAlertUsecase = stockAlertUsecase
+	s.cartUsecase = cartUsecase
+	s.emailOutboxRepo = emailOutboxRepo
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authUsecase, otpService)
+	authHandler := handlers.NewAuthHandler(authUsecase, s.otpService, cartUsecase)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorUsecase, authUsecase)
     productHandler := handlers.NewProductHandler(productUsecase)
     categoryHandler := handlers.NewCategoryHandler(categoryUsecase, productUsecase)
 	orderHandler := handlers.NewOrderHandler(orderUsecase)
-	cartHandler := handlers.NewCartHandler(s.db.DB)
+	paymentHandler := handlers.NewPaymentHandler(paymentUsecase)
+	cartHandler := handlers.NewCartHandler(cartUsecase, shippingUsecase, s.config.Cart.GuestCartTTL)
 	wishlistHandler := handlers.NewWishlistHandler(s.db.DB)
 	reviewHandler := handlers.NewReviewHandler(reviewUsecase, productRepo)
+	promotionHandler := handlers.NewPromotionHandler(promotionUsecase)
+	addressHandler := handlers.NewAddressHandler(addressUsecase)
+	stockAlertHandler := handlers.NewStockAlertHandler(stockAlertUsecase)
+	notificationHandler := handlers.NewNotificationHandler(notificationUsecase)
 	
 	// Initialize upload handler
 	uploadDir := "./uploads"
@@ -109,26 +185,70 @@ func (s *Server) setupRoutes() {
 		// Auth routes
 		auth := api.Group("/auth")
 		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
+			// Brute-force protection: every limit applies per account and,
+			// four times as generous since users may share an address, per
+			// client IP. The OTP routes share budgets so switching between
+			// them gains nothing; register sends a verification code, so it
+			// counts against the OTP-sending budget too. Login additionally
+			// locks accounts out after repeated failures (see
+			// AuthUsecase.Login).
+			limit := func(rule services.RateLimitRule) []gin.HandlerFunc {
+				perIP := rule
+				perIP.Name += ":ip"
+				perIP.Limit *= 4
+				perAccount := rule
+				perAccount.Name += ":account"
+				return []gin.HandlerFunc{
+					middleware.RateLimit(s.rateLimiter, perIP, middleware.ByClientIP),
+					middleware.RateLimit(s.rateLimiter, perAccount, middleware.ByEmail),
+				}
+			}
+			loginLimited := auth.Group("", limit(services.RateLimitRule{Name: "login", Limit: 10, Window: 15 * time.Minute})...)
+			otpSendLimited := auth.Group("", limit(services.RateLimitRule{Name: "otp-send", Limit: 3, Window: 15 * time.Minute})...)
+			otpVerifyLimited := auth.Group("", limit(services.RateLimitRule{Name: "otp-verify", Limit: 10, Window: 15 * time.Minute})...)
+
+			otpSendLimited.POST("/register", authHandler.Register)
+			loginLimited.POST("/login", authHandler.Login)
 			auth.POST("/google", authHandler.GoogleAuth)
 			auth.POST("/google/id-token", authHandler.GoogleIDTokenAuth)
 			auth.POST("/google/exchange", authHandler.GoogleOAuthExchange)
 			auth.POST("/refresh", authHandler.RefreshToken)
 			auth.POST("/logout", authHandler.Logout)
-			auth.GET("/profile", middleware.AuthMiddleware(s.config.JWT.AccessTokenSecret), authHandler.GetProfile)
-			auth.PUT("/profile", middleware.AuthMiddleware(s.config.JWT.AccessTokenSecret), authHandler.UpdateProfile)
+			auth.POST("/logout-all", middleware.AuthMiddleware(s.jwtKeys), authHandler.LogoutAll)
+			auth.GET("/profile", middleware.AuthMiddleware(s.jwtKeys), authHandler.GetProfile)
+			auth.PUT("/profile", middleware.AuthMiddleware(s.jwtKeys), authHandler.UpdateProfile)
+
+			// Two-factor authentication
+			loginLimited.POST("/2fa/login", authHandler.CompleteTwoFactorLogin)
+			auth.GET("/2fa", middleware.AuthMiddleware(s.jwtKeys), twoFactorHandler.Status)
+			auth.POST("/2fa/enroll", middleware.AuthMiddleware(s.jwtKeys), twoFactorHandler.Enroll)
+			auth.POST("/2fa/confirm", middleware.AuthMiddleware(s.jwtKeys), twoFactorHandler.Confirm)
+			// Changing settings takes a code too; it draws on the same
+			// per-account budget as completing a login, so a stolen access
+			// token can't be used to guess one
+			twoFactorCodeLimited := middleware.RateLimit(s.rateLimiter, usecase.TwoFactorCodeRule, middleware.ByUserID)
+			auth.POST("/2fa/recovery-codes", middleware.AuthMiddleware(s.jwtKeys), twoFactorCodeLimited, twoFactorHandler.RegenerateRecoveryCodes)
+			auth.POST("/2fa/disable", middleware.AuthMiddleware(s.jwtKeys), twoFactorCodeLimited, twoFactorHandler.Disable)
 			
 			// OTP routes
-			auth.POST("/send-otp", authHandler.SendOTP)
-			auth.POST("/verify-otp", authHandler.VerifyOTP)
-			auth.POST("/resend-otp", authHandler.ResendOTP)
-			auth.POST("/password-reset", authHandler.PasswordReset)
-			auth.POST("/forgot-password", authHandler.ForgotPassword)
+			otpSendLimited.POST("/send-otp", authHandler.SendOTP)
+			otpVerifyLimited.POST("/verify-otp", authHandler.VerifyOTP)
+			otpSendLimited.POST("/resend-otp", authHandler.ResendOTP)
+			otpVerifyLimited.POST("/password-reset", authHandler.PasswordReset)
+			otpSendLimited.POST("/forgot-password", authHandler.ForgotPassword)
 		}
 
-		// Me route (alternative to /auth/profile)
-		api.GET("/me", middleware.AuthMiddleware(s.config.JWT.AccessTokenSecret), authHandler.Me)
+		// Me routes: the profile (alternative to /auth/profile) and the
+		// in-app notification inbox
+		me := api.Group("/me", middleware.AuthMiddleware(s.jwtKeys))
+		{
+			me.GET("", authHandler.Me)
+			me.GET("/notifications", notificationHandler.List)
+			me.GET("/notifications/unread-count", notificationHandler.UnreadCount)
+			me.POST("/notifications/read-all", notificationHandler.MarkAllRead)
+			me.POST("/notifications/:id/read", notificationHandler.MarkRead)
+			me.DELETE("/notifications/:id", notificationHandler.Delete)
+		}
 
 		// Product routes
 		products := api.Group("/products")
@@ -140,6 +260,9 @@ func (s *Server) setupRoutes() {
 			products.GET("/:id", productHandler.GetByID)
 			products.GET("/:id/related", productHandler.GetRelatedProducts)
 			products.GET("/:id/reviews", reviewHandler.GetProductReviews)
+			products.GET("/:id/stock-alert", middleware.AuthMiddleware(s.jwtKeys), stockAlertHandler.Get)
+			products.POST("/:id/stock-alert", middleware.AuthMiddleware(s.jwtKeys), stockAlertHandler.Subscribe)
+			products.DELETE("/:id/stock-alert", middleware.AuthMiddleware(s.jwtKeys), stockAlertHandler.Unsubscribe)
 		}
 
         // Category routes
@@ -153,6 +276,9 @@ func (s *Server) setupRoutes() {
 		api.GET("/brands", productHandler.GetBrands)
 		api.GET("/search/suggest", productHandler.SuggestProducts)
 
+		// Promotions routes (public)
+		api.GET("/promotions", promotionHandler.List)
+
 		// Review routes
 		reviews := api.Group("/reviews")
 		{
@@ -160,7 +286,7 @@ func (s *Server) setupRoutes() {
 			// GET /products/:productId/reviews is handled above
 
 			// Protected routes (require authentication)
-			reviews.Use(middleware.AuthMiddleware(s.config.JWT.AccessTokenSecret))
+			reviews.Use(middleware.AuthMiddleware(s.jwtKeys))
 			reviews.POST("", reviewHandler.CreateReview)
 			reviews.GET("/my", reviewHandler.GetUserReviews)
 			reviews.PUT("/:id", reviewHandler.UpdateReview)
@@ -171,10 +297,17 @@ func (s *Server) setupRoutes() {
 			reviews.DELETE("/replies/:id", reviewHandler.DeleteReply)
 		}
 
-		// Admin routes (protected by admin middleware)
+		// Admin routes. Each group requires a permission granted through the
+		// user's roles; changes additionally require the group's write permission
+		// and are written to the audit log.
 		admin := api.Group("/admin")
-		admin.Use(middleware.AdminOnly(s.config.JWT.AccessTokenSecret))
+		// Two-factor authentication is mandatory for the admin API
+		admin.Use(middleware.AuthMiddleware(s.jwtKeys), middleware.AuditTrail(auditUsecase), middleware.RequireTwoFactor(twoFactorUsecase))
 		{
+			can := func(permission string) gin.HandlerFunc {
+				return middleware.RequirePermission(s.rbac, permission)
+			}
+
 			admin.GET("/health", func(c *gin.Context) {
 				c.JSON(http.StatusOK, gin.H{"status": "ok"})
 			})
@@ -182,14 +315,22 @@ func (s *Server) setupRoutes() {
 			// Initialize admin handlers
 			adminAnalyticsHandler := handlers.NewAdminAnalyticsHandler(s.db)
 			adminProductsHandler := handlers.NewAdminProductsHandler(productUsecase, productRepo, categoryRepo, s.db.DB)
-			adminOrdersHandler := handlers.NewAdminOrdersHandler(orderRepo)
-			adminUsersHandler := handlers.NewAdminUsersHandler(userRepo, orderRepo)
+			adminVariantsHandler := handlers.NewAdminVariantsHandler(variantUsecase)
+			adminOrdersHandler := handlers.NewAdminOrdersHandler(orderRepo, orderUsecase, paymentUsecase)
+			adminUsersHandler := handlers.NewAdminUsersHandler(userRepo, orderRepo, s.rbac)
 			adminCategoriesHandler := handlers.NewAdminCategoriesHandler(categoryRepo)
 			brandRepo := repository.NewBrandRepository(s.db.DB)
 			adminBrandsHandler := handlers.NewAdminBrandsHandler(brandRepo)
+			adminDiscountsHandler := handlers.NewAdminDiscountsHandler(discountUsecase)
+			adminPromotionsHandler := handlers.NewAdminPromotionsHandler(promotionUsecase)
+			adminTaxRulesHandler := handlers.NewAdminTaxRulesHandler(taxUsecase)
+			adminShippingHandler := handlers.NewAdminShippingHandler(shippingUsecase)
+			adminAuditLogsHandler := handlers.NewAdminAuditLogsHandler(auditUsecase, userRepo)
+			adminJobsHandler := handlers.NewAdminJobsHandler(s.jobs, s.config.Jobs.Enabled)
+			adminEmailsHandler := handlers.NewAdminEmailsHandler(emailOutboxRepo, s.emailQueue, s.emailService)
 
 			// Analytics routes
-			analytics := admin.Group("/analytics")
+			analytics := admin.Group("/analytics", can(models.PermissionAnalyticsRead))
 			{
 				analytics.GET("/dashboard", adminAnalyticsHandler.GetDashboard)
 				analytics.GET("/sales", adminAnalyticsHandler.GetSalesData)
@@ -197,50 +338,141 @@ func (s *Server) setupRoutes() {
 			}
 
 			// Products management routes
-			products := admin.Group("/products")
+			products := admin.Group("/products", can(models.PermissionCatalogRead))
 			{
 				products.GET("", adminProductsHandler.ListProducts)
 				products.GET("/:id", adminProductsHandler.GetProduct)
-				products.POST("", adminProductsHandler.CreateProduct)
-				products.PUT("/:id", adminProductsHandler.UpdateProduct)
-				products.DELETE("/:id", adminProductsHandler.DeleteProduct)
+				products.POST("", can(models.PermissionCatalogWrite), adminProductsHandler.CreateProduct)
+				products.PUT("/:id", can(models.PermissionCatalogWrite), adminProductsHandler.UpdateProduct)
+				products.DELETE("/:id", can(models.PermissionCatalogWrite), adminProductsHandler.DeleteProduct)
+
+				// Options such as Color or Storage, and variants made of their values
+				products.GET("/:id/options", adminVariantsHandler.ListOptions)
+				products.POST("/:id/options", can(models.PermissionCatalogWrite), adminVariantsHandler.CreateOption)
+				products.PUT("/:id/options/:optionId", can(models.PermissionCatalogWrite), adminVariantsHandler.UpdateOption)
+				products.DELETE("/:id/options/:optionId", can(models.PermissionCatalogWrite), adminVariantsHandler.DeleteOption)
+				products.GET("/:id/variants", adminVariantsHandler.ListVariants)
+				products.GET("/:id/variants/:variantId", adminVariantsHandler.GetVariant)
+				products.POST("/:id/variants", can(models.PermissionCatalogWrite), adminVariantsHandler.CreateVariant)
+				products.PUT("/:id/variants/:variantId", can(models.PermissionCatalogWrite), adminVariantsHandler.UpdateVariant)
+				products.DELETE("/:id/variants/:variantId", can(models.PermissionCatalogWrite), adminVariantsHandler.DeleteVariant)
 			}
 
 			// Orders management routes
-			orders := admin.Group("/orders")
+			orders := admin.Group("/orders", can(models.PermissionOrdersRead))
 			{
 				orders.GET("", adminOrdersHandler.ListOrders)
-				orders.PUT("/:id/status", adminOrdersHandler.UpdateOrderStatus)
+				orders.GET("/:id", adminOrdersHandler.GetOrder)
+				orders.PUT("/:id/status", can(models.PermissionOrdersWrite), adminOrdersHandler.UpdateOrderStatus)
+				orders.POST("/:id/refunds", can(models.PermissionRefundsWrite), adminOrdersHandler.CreateRefund)
 			}
 
 			// Users/Customers management routes
-			users := admin.Group("/users")
+			users := admin.Group("/users", can(models.PermissionUsersRead))
 			{
 				users.GET("", adminUsersHandler.ListUsers)
 				users.GET("/:id", adminUsersHandler.GetUser)
-				users.PUT("/:id", adminUsersHandler.UpdateUser)
+				users.PUT("/:id", can(models.PermissionUsersWrite), adminUsersHandler.UpdateUser)
+				users.PUT("/:id/roles", can(models.PermissionRolesWrite), adminUsersHandler.SetUserRoles)
+			}
+
+			// Staff roles
+			roles := admin.Group("/roles", can(models.PermissionUsersRead))
+			{
+				roles.GET("", adminUsersHandler.ListRoles)
 			}
 
 			// Categories management routes
-			categories := admin.Group("/categories")
+			categories := admin.Group("/categories", can(models.PermissionCatalogRead))
 			{
 				categories.GET("", adminCategoriesHandler.ListCategories)
-				categories.POST("", adminCategoriesHandler.CreateCategory)
-				categories.PUT("/:id", adminCategoriesHandler.UpdateCategory)
-				categories.DELETE("/:id", adminCategoriesHandler.DeleteCategory)
+				categories.POST("", can(models.PermissionCatalogWrite), adminCategoriesHandler.CreateCategory)
+				categories.PUT("/:id", can(models.PermissionCatalogWrite), adminCategoriesHandler.UpdateCategory)
+				categories.DELETE("/:id", can(models.PermissionCatalogWrite), adminCategoriesHandler.DeleteCategory)
 			}
 
 			// Brands management routes
-			brands := admin.Group("/brands")
+			brands := admin.Group("/brands", can(models.PermissionCatalogRead))
 			{
 				brands.GET("", adminBrandsHandler.ListBrands)
-				brands.POST("", adminBrandsHandler.CreateBrand)
-				brands.PUT("/:id", adminBrandsHandler.UpdateBrand)
-				brands.DELETE("/:id", adminBrandsHandler.DeleteBrand)
+				brands.POST("", can(models.PermissionCatalogWrite), adminBrandsHandler.CreateBrand)
+				brands.PUT("/:id", can(models.PermissionCatalogWrite), adminBrandsHandler.UpdateBrand)
+				brands.DELETE("/:id", can(models.PermissionCatalogWrite), adminBrandsHandler.DeleteBrand)
+			}
+
+			// Discounts management routes
+			discounts := admin.Group("/discounts", can(models.PermissionMarketingRead))
+			{
+				discounts.GET("", adminDiscountsHandler.ListDiscounts)
+				discounts.GET("/:id", adminDiscountsHandler.GetDiscount)
+				discounts.POST("", can(models.PermissionMarketingWrite), adminDiscountsHandler.CreateDiscount)
+				discounts.PUT("/:id", can(models.PermissionMarketingWrite), adminDiscountsHandler.UpdateDiscount)
+				discounts.DELETE("/:id", can(models.PermissionMarketingWrite), adminDiscountsHandler.DeleteDiscount)
+			}
+
+			// Promotions management routes
+			promotions := admin.Group("/promotions", can(models.PermissionMarketingRead))
+			{
+				promotions.GET("", adminPromotionsHandler.ListPromotions)
+				promotions.GET("/:id", adminPromotionsHandler.GetPromotion)
+				promotions.POST("", can(models.PermissionMarketingWrite), adminPromotionsHandler.CreatePromotion)
+				promotions.POST("/image", can(models.PermissionMarketingWrite), uploadHandler.UploadPromotionImage)
+				promotions.PUT("/:id", can(models.PermissionMarketingWrite), adminPromotionsHandler.UpdatePromotion)
+				promotions.DELETE("/:id", can(models.PermissionMarketingWrite), adminPromotionsHandler.DeletePromotion)
+			}
+
+			// Tax rules management routes
+			taxRules := admin.Group("/tax-rules", can(models.PermissionSettingsRead))
+			{
+				taxRules.GET("", adminTaxRulesHandler.ListTaxRules)
+				taxRules.GET("/:id", adminTaxRulesHandler.GetTaxRule)
+				taxRules.POST("", can(models.PermissionSettingsWrite), adminTaxRulesHandler.CreateTaxRule)
+				taxRules.PUT("/:id", can(models.PermissionSettingsWrite), adminTaxRulesHandler.UpdateTaxRule)
+				taxRules.DELETE("/:id", can(models.PermissionSettingsWrite), adminTaxRulesHandler.DeleteTaxRule)
+			}
+
+			// Shipping zones and methods management routes
+			shipping := admin.Group("/shipping", can(models.PermissionSettingsRead))
+			{
+				shipping.GET("/zones", adminShippingHandler.ListZones)
+				shipping.GET("/zones/:id", adminShippingHandler.GetZone)
+				shipping.POST("/zones", can(models.PermissionSettingsWrite), adminShippingHandler.CreateZone)
+				shipping.PUT("/zones/:id", can(models.PermissionSettingsWrite), adminShippingHandler.UpdateZone)
+				shipping.DELETE("/zones/:id", can(models.PermissionSettingsWrite), adminShippingHandler.DeleteZone)
+				shipping.POST("/zones/:id/methods", can(models.PermissionSettingsWrite), adminShippingHandler.CreateMethod)
+				shipping.PUT("/methods/:id", can(models.PermissionSettingsWrite), adminShippingHandler.UpdateMethod)
+				shipping.DELETE("/methods/:id", can(models.PermissionSettingsWrite), adminShippingHandler.DeleteMethod)
+			}
+
+			// Audit log
+			auditLogs := admin.Group("/audit-logs", can(models.PermissionAuditRead))
+			{
+				auditLogs.GET("", adminAuditLogsHandler.ListAuditLogs)
+			}
+
+			// Background jobs
+			jobs := admin.Group("/jobs", can(models.PermissionSettingsRead))
+			{
+				jobs.GET("", adminJobsHandler.ListJobs)
+			}
+
+			// Email outbox: delivery status and retrying dead emails
+			emails := admin.Group("/emails", can(models.PermissionSettingsRead))
+			{
+				emails.GET("", adminEmailsHandler.ListEmails)
+				emails.GET("/:id", adminEmailsHandler.GetEmail)
+				emails.POST("/:id/retry", can(models.PermissionSettingsWrite), adminEmailsHandler.RetryEmail)
+			}
+
+			// Email templates: what each email looks like, rendered with sample data
+			emailTemplates := admin.Group("/email-templates", can(models.PermissionSettingsRead))
+			{
+				emailTemplates.GET("", adminEmailsHandler.ListTemplates)
+				emailTemplates.GET("/:name/preview", adminEmailsHandler.PreviewTemplate)
 			}
 
 			// Upload routes
-			upload := admin.Group("/upload")
+			upload := admin.Group("/upload", can(models.PermissionCatalogWrite))
 			{
 				upload.POST("/image", uploadHandler.UploadImage)
 				upload.POST("/images", uploadHandler.UploadMultipleImages)
@@ -254,29 +486,48 @@ func (s *Server) setupRoutes() {
 
 		// Order routes (Protected)
 		orders := api.Group("/orders")
-		orders.Use(middleware.AuthMiddleware(s.config.JWT.AccessTokenSecret))
+		orders.Use(middleware.AuthMiddleware(s.jwtKeys))
 		{
 			orders.GET("", orderHandler.List)
 			orders.GET("/:id", orderHandler.GetByID)
 			orders.POST("", orderHandler.Create)
 			orders.PUT("/:id", orderHandler.Update)
-			orders.DELETE("/:id", orderHandler.Delete)
+			orders.POST("/:id/cancel", orderHandler.Cancel)
+			orders.POST("/:id/pay", paymentHandler.Pay)
 		}
 
-		// Cart routes (Protected)
+		// Payment gateway callbacks (authenticated by signature)
+		api.POST("/payments/webhook", paymentHandler.Webhook)
+
+		// Cart routes; shoppers who are not signed in get a guest cart
+		// identified by the cart_token cookie
 		cart := api.Group("/cart")
-		cart.Use(middleware.AuthMiddleware(s.config.JWT.AccessTokenSecret))
+		cart.Use(middleware.OptionalAuthMiddleware(s.jwtKeys))
 		{
 			cart.GET("", cartHandler.GetCart)
 			cart.POST("/items", cartHandler.AddToCart)
 			cart.PUT("/items/:id", cartHandler.UpdateCartItem)
 			cart.DELETE("/items/:id", cartHandler.RemoveFromCart)
 			cart.DELETE("", cartHandler.ClearCart)
+			cart.POST("/discount", cartHandler.ApplyDiscount)
+			cart.DELETE("/discount", cartHandler.RemoveDiscount)
+			cart.GET("/shipping-options", middleware.AuthMiddleware(s.jwtKeys), cartHandler.ShippingOptions)
+		}
+
+		// Address book routes (Protected)
+		addresses := api.Group("/addresses")
+		addresses.Use(middleware.AuthMiddleware(s.jwtKeys))
+		{
+			addresses.GET("", addressHandler.List)
+			addresses.POST("", addressHandler.Create)
+			addresses.GET("/:id", addressHandler.Get)
+			addresses.PUT("/:id", addressHandler.Update)
+			addresses.DELETE("/:id", addressHandler.Delete)
 		}
 
 		// Wishlist routes (Protected)
 		wishlist := api.Group("/wishlist")
-		wishlist.Use(middleware.AuthMiddleware(s.config.JWT.AccessTokenSecret))
+		wishlist.Use(middleware.AuthMiddleware(s.jwtKeys))
 		{
 			wishlist.GET("", wishlistHandler.GetWishlist)
 			wishlist.POST("", wishlistHandler.AddToWishlist)
@@ -292,6 +543,11 @@ func (s *Server) setupRoutes() {
 		})
 	}
 
+	// Public JWT verification keys for other services; HS256 keys are never listed
+	s.router.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, s.jwtKeys.PublicKeys())
+	})
+
 	// Root route
 	s.router.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -301,25 +557,40 @@ func (s *Server) setupRoutes() {
 	})
 }
 
-func (s *Server) Start() error {
-	// Auto migrate database
+// Migrate brings the database schema up to date and creates the built-in
+// roles and permissions admin routes are guarded by. It must finish before
+// the server, the background jobs or the email workers start, as they all
+// rely on the tables it creates.
+func (s *Server) Migrate(ctx context.Context) error {
 	if err := s.db.AutoMigrate(); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
-
-	// Create HTTP server
-	server := &http.Server{
-		Addr:         fmt.Sprintf("%s:%s", s.config.Server.Host, s.config.Server.Port),
-		Handler:      s.router,
-		ReadTimeout:  s.config.Server.ReadTimeout,
-		WriteTimeout: s.config.Server.WriteTimeout,
+	if err := s.rbac.SyncDefaultRoles(ctx); err != nil {
+		return fmt.Errorf("failed to sync roles: %w", err)
 	}
+	return nil
+}
 
-	// Start server
+// Start serves requests until the server is shut down. Call Migrate first.
+func (s *Server) Start() error {
 	fmt.Printf("Starting server on %s:%s\n", s.config.Server.Host, s.config.Server.Port)
-	return server.ListenAndServe()
+	return s.httpServer.ListenAndServe()
 }
 
+// Shutdown stops accepting requests and waits for in-flight requests,
+// running background jobs and emails being sent to finish before closing
+// the database. When ctx is done first, whatever is still running is cut
+// off.
 func (s *Server) Shutdown(ctx context.Context) error {
-	return s.db.Close()
+	httpErr := s.httpServer.Shutdown(ctx)
+	if err := s.jobs.Stop(ctx); err != nil {
+		log.Printf("Background jobs did not finish: %v", err)
+	}
+	if err := s.emailQueue.Stop(ctx); err != nil {
+		log.Printf("Email workers did not finish: %v", err)
+	}
+	if err := s.db.Close(); err != nil {
+		return err
+	}
+	return httpErr
 }
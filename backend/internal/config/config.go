@@ -3,19 +3,27 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	OAuth    OAuthConfig
-	S3       S3Config
-	Redis    RedisConfig
-	Email    EmailConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	OAuth     OAuthConfig
+	S3        S3Config
+	Redis     RedisConfig
+	Email     EmailConfig
+	Payment   PaymentConfig
+	RateLimit RateLimitConfig
+	OTP       OTPConfig
+	Cart      CartConfig
+	Jobs      JobsConfig
+	Outbox    OutboxConfig
+	Notify    NotifyConfig
 }
 
 type ServerConfig struct {
@@ -23,6 +31,16 @@ type ServerConfig struct {
 	Host         string
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+	// ShutdownTimeout is how long in-flight requests and jobs get to finish
+	// on SIGINT/SIGTERM
+	ShutdownTimeout time.Duration
+	// DevMode enables conveniences that are unsafe in production, such as
+	// printing OTP codes to stdout
+	DevMode bool
+	// TrustedProxies are the IPs or CIDRs of reverse proxies whose
+	// X-Forwarded-For header is believed when finding the client IP. With
+	// none the connection's peer address is the client IP.
+	TrustedProxies []string
 }
 
 type DatabaseConfig struct {
@@ -39,6 +57,9 @@ type JWTConfig struct {
 	RefreshTokenSecret string
 	AccessTokenTTL     time.Duration
 	RefreshTokenTTL    time.Duration
+	// KeySetFile is a JSON keyset with several signing keys identified by
+	// kid; when empty tokens are signed with AccessTokenSecret
+	KeySetFile string
 }
 
 type OAuthConfig struct {
@@ -71,6 +92,93 @@ type EmailConfig struct {
 	FromName     string
 	UseTLS       bool
 	UseSSL       bool
+	// Transport is how emails leave: "smtp", "file" to write them to
+	// MailboxDir as .eml files, or "memory" to keep them in the process
+	Transport  string
+	MailboxDir string
+	// TemplatesDir overrides the built-in email templates with the files
+	// of a directory laid out like internal/services/templates/email
+	TemplatesDir string
+	// StoreURL is the storefront that links in emails point to
+	StoreURL string
+	// AlertRecipients get staff alerts such as low stock warnings
+	AlertRecipients []string
+}
+
+type PaymentConfig struct {
+	// Provider selects the payment gateway; empty or "none" disables
+	// online payments. "mock" accepts any payment token and is only
+	// allowed in DevMode.
+	Provider string
+	// WebhookSecret verifies the signature of gateway webhooks; with a
+	// provider it must be set to a value other than the example placeholder
+	WebhookSecret string
+}
+
+type RateLimitConfig struct {
+	// Backend stores the counters: "memory" for a single instance, "redis"
+	// to share them between instances through Redis
+	Backend string
+	// An account is locked after LoginMaxFailures failed logins within
+	// LoginFailureWindow. Each further lockout within a day doubles its
+	// length, starting at LockoutBase and capped at LockoutMax.
+	LoginMaxFailures   int
+	LoginFailureWindow time.Duration
+	LockoutBase        time.Duration
+	LockoutMax         time.Duration
+}
+
+type OTPConfig struct {
+	// HashKey keys the HMAC codes are stored as, so reading the table
+	// doesn't reveal them
+	HashKey string
+	// MaxAttempts wrong guesses invalidate a code
+	MaxAttempts int
+}
+
+type CartConfig struct {
+	// TokenSecret signs the cookie that identifies a guest's cart
+	TokenSecret string
+	// GuestCartTTL is how long a guest cart is kept after its last use
+	GuestCartTTL time.Duration
+}
+
+type OutboxConfig struct {
+	// Workers deliver queued emails in each instance; 0 leaves delivery to
+	// other instances
+	Workers      int
+	PollInterval time.Duration
+	// A failed email is retried after RetryBase, doubling up to RetryMax,
+	// and dead-lettered after MaxAttempts attempts
+	MaxAttempts int
+	RetryBase   time.Duration
+	RetryMax    time.Duration
+	// SentRetention is how long delivered emails are kept for tracking
+	SentRetention time.Duration
+}
+
+type NotifyConfig struct {
+	// WebhookURL receives every notification that has a summary as a signed
+	// JSON POST; empty disables the webhook channel
+	WebhookURL     string
+	WebhookSecret  string
+	WebhookTimeout time.Duration
+}
+
+type JobsConfig struct {
+	// Enabled runs the background jobs in this instance. Replicas take a
+	// database lock per run, so enabling it everywhere is safe.
+	Enabled bool
+	// Schedules are cron expressions ("*/5 * * * *") or "@every 10m",
+	// "@hourly", "@daily"
+	OTPCleanupSchedule         string
+	OrderAutoCancelSchedule    string
+	EmailOutboxCleanupSchedule string
+	BackInStockSchedule        string
+	GuestCartCleanupSchedule   string
+	// PendingOrderTimeout is how long an unpaid order stays pending before
+	// it is cancelled and its stock released
+	PendingOrderTimeout time.Duration
 }
 
 func Load() (*Config, error) {
@@ -79,10 +187,13 @@ func Load() (*Config, error) {
 
 	cfg := &Config{
 		Server: ServerConfig{
-			Port:         getEnv("SERVER_PORT", "8081"),
-			Host:         getEnv("SERVER_HOST", "0.0.0.0"),
-			ReadTimeout:  getDurationEnv("SERVER_READ_TIMEOUT", 30*time.Second),
-			WriteTimeout: getDurationEnv("SERVER_WRITE_TIMEOUT", 30*time.Second),
+			Port:            getEnv("SERVER_PORT", "8081"),
+			Host:            getEnv("SERVER_HOST", "0.0.0.0"),
+			ReadTimeout:     getDurationEnv("SERVER_READ_TIMEOUT", 30*time.Second),
+			WriteTimeout:    getDurationEnv("SERVER_WRITE_TIMEOUT", 30*time.Second),
+			ShutdownTimeout: getDurationEnv("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+			DevMode:         getBoolEnv("DEV_MODE", false),
+			TrustedProxies:  getListEnv("TRUSTED_PROXIES", nil),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -97,6 +208,7 @@ func Load() (*Config, error) {
 			RefreshTokenSecret: getEnv("JWT_REFRESH_SECRET", "your-refresh-secret"),
 			AccessTokenTTL:     getDurationEnv("JWT_ACCESS_TTL", 15*time.Minute),
 			RefreshTokenTTL:    getDurationEnv("JWT_REFRESH_TTL", 7*24*time.Hour),
+			KeySetFile:         getEnv("JWT_KEYSET_FILE", ""),
 		},
 		OAuth: OAuthConfig{
 			GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
@@ -117,14 +229,60 @@ func Load() (*Config, error) {
 			DB:       getIntEnv("REDIS_DB", 0),
 		},
 		Email: EmailConfig{
-			SMTPHost:     getEnv("SMTP_HOST", "smtp.gmail.com"),
-			SMTPPort:     getIntEnv("SMTP_PORT", 587),
-			SMTPUsername: getEnv("SMTP_USERNAME", ""),
-			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
-			FromEmail:    getEnv("FROM_EMAIL", "noreply@electronicsstore.com"),
-			FromName:     getEnv("FROM_NAME", "Electronics Store"),
-			UseTLS:       getBoolEnv("SMTP_USE_TLS", true),
-			UseSSL:       getBoolEnv("SMTP_USE_SSL", false),
+			SMTPHost:        getEnv("SMTP_HOST", "smtp.gmail.com"),
+			SMTPPort:        getIntEnv("SMTP_PORT", 587),
+			SMTPUsername:    getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:    getEnv("SMTP_PASSWORD", ""),
+			FromEmail:       getEnv("FROM_EMAIL", "noreply@electronicsstore.com"),
+			FromName:        getEnv("FROM_NAME", "Electronics Store"),
+			UseTLS:          getBoolEnv("SMTP_USE_TLS", true),
+			UseSSL:          getBoolEnv("SMTP_USE_SSL", false),
+			Transport:       getEnv("EMAIL_TRANSPORT", "smtp"),
+			MailboxDir:      getEnv("EMAIL_MAILBOX_DIR", "mailbox"),
+			TemplatesDir:    getEnv("EMAIL_TEMPLATES_DIR", ""),
+			StoreURL:        getEnv("STORE_URL", "http://localhost:3000"),
+			AlertRecipients: getListEnv("EMAIL_ALERT_RECIPIENTS", nil),
+		},
+		Payment: PaymentConfig{
+			Provider:      getEnv("PAYMENT_PROVIDER", ""),
+			WebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+		},
+		RateLimit: RateLimitConfig{
+			Backend:            getEnv("RATE_LIMIT_BACKEND", "memory"),
+			LoginMaxFailures:   getIntEnv("LOGIN_MAX_FAILURES", 5),
+			LoginFailureWindow: getDurationEnv("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			LockoutBase:        getDurationEnv("LOGIN_LOCKOUT_BASE", time.Minute),
+			LockoutMax:         getDurationEnv("LOGIN_LOCKOUT_MAX", time.Hour),
+		},
+		OTP: OTPConfig{
+			HashKey:     getEnv("OTP_HASH_KEY", "change-me-otp-hash-key"),
+			MaxAttempts: getIntEnv("OTP_MAX_ATTEMPTS", 5),
+		},
+		Cart: CartConfig{
+			TokenSecret:  getEnv("CART_TOKEN_SECRET", "change-me-cart-token-secret"),
+			GuestCartTTL: getDurationEnv("GUEST_CART_TTL", 30*24*time.Hour),
+		},
+		Outbox: OutboxConfig{
+			Workers:       getIntEnv("EMAIL_WORKERS", 4),
+			PollInterval:  getDurationEnv("EMAIL_POLL_INTERVAL", 5*time.Second),
+			MaxAttempts:   getIntEnv("EMAIL_MAX_ATTEMPTS", 8),
+			RetryBase:     getDurationEnv("EMAIL_RETRY_BASE", 30*time.Second),
+			RetryMax:      getDurationEnv("EMAIL_RETRY_MAX", time.Hour),
+			SentRetention: getDurationEnv("EMAIL_SENT_RETENTION", 30*24*time.Hour),
+		},
+		Notify: NotifyConfig{
+			WebhookURL:     getEnv("NOTIFY_WEBHOOK_URL", ""),
+			WebhookSecret:  getEnv("NOTIFY_WEBHOOK_SECRET", ""),
+			WebhookTimeout: getDurationEnv("NOTIFY_WEBHOOK_TIMEOUT", 10*time.Second),
+		},
+		Jobs: JobsConfig{
+			Enabled:                    getBoolEnv("JOBS_ENABLED", true),
+			OTPCleanupSchedule:         getEnv("JOB_OTP_CLEANUP_SCHEDULE", "@hourly"),
+			OrderAutoCancelSchedule:    getEnv("JOB_ORDER_AUTO_CANCEL_SCHEDULE", "*/5 * * * *"),
+			EmailOutboxCleanupSchedule: getEnv("JOB_EMAIL_OUTBOX_CLEANUP_SCHEDULE", "@daily"),
+			BackInStockSchedule:        getEnv("JOB_BACK_IN_STOCK_SCHEDULE", "*/15 * * * *"),
+			GuestCartCleanupSchedule:   getEnv("JOB_GUEST_CART_CLEANUP_SCHEDULE", "@daily"),
+			PendingOrderTimeout:        getDurationEnv("PENDING_ORDER_TIMEOUT", 24*time.Hour),
 		},
 	}
 
@@ -164,3 +322,18 @@ func getBoolEnv(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// getListEnv reads a comma-separated list, skipping empty entries
+func getListEnv(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
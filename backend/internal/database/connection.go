@@ -99,22 +99,44 @@ func (c *Connection) AutoMigrate() error {
 
 	// Auto migrate all models in correct order
 	err := c.DB.AutoMigrate(
+		&models.Permission{},
+		&models.Role{},
 		&models.User{},
 		&models.OTPVerification{},
+		&models.RefreshToken{},
+		&models.TwoFactorAuth{},
+		&models.TwoFactorRecoveryCode{},
 		&models.Address{},
 		&models.Category{},
 		&models.Product{},
 		&models.Image{},
 		&models.Variant{},
+		&models.ProductOption{},
+		&models.ProductOptionValue{},
+		&models.VariantImage{},
 		&models.Review{},
 		&models.Order{},
 		&models.OrderItem{},
+		&models.OrderStatusHistory{},
 		&models.Payment{},
+		&models.Refund{},
+		&models.RefundItem{},
+		&models.OrderTax{},
+		&models.TaxRule{},
+		&models.ShippingZone{},
+		&models.ShippingZoneRegion{},
+		&models.ShippingMethod{},
+		&models.ShippingRateTier{},
 		&models.Cart{},
 		&models.CartItem{},
 		&models.Wishlist{},
 		&models.Discount{},
 		&models.Promotion{},
+		&models.AuditLog{},
+		&models.OutboxEmail{},
+		&models.StockAlert{},
+		&models.Notification{},
+		&models.JobRun{},
 	)
 
 	if err != nil {
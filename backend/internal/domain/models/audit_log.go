@@ -0,0 +1,28 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog records one change made through the admin API. Entries are only
+// ever inserted: the repository has no update or delete, and the table's
+// triggers reject both.
+type AuditLog struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	ActorID    *uint           `gorm:"index" json:"actor_id"`
+	Action     string          `gorm:"size:100;not null;index" json:"action"` // e.g. "order.status_update"
+	EntityType string          `gorm:"size:50;index:idx_audit_logs_entity" json:"entity_type"`
+	EntityID   string          `gorm:"size:100;index:idx_audit_logs_entity" json:"entity_id"`
+	Before     json.RawMessage `gorm:"type:json" json:"before"` // changed fields before the change
+	After      json.RawMessage `gorm:"type:json" json:"after"`  // changed fields after the change
+	Method     string          `gorm:"size:10" json:"method"`
+	Path       string          `gorm:"size:255" json:"path"` // route pattern, e.g. /api/v1/admin/orders/:id/status
+	StatusCode int             `json:"status_code"`
+	IPAddress  string          `gorm:"size:45" json:"ip_address"`
+	UserAgent  string          `gorm:"size:255" json:"user_agent"`
+	RequestID  string          `gorm:"size:64;index" json:"request_id"`
+	CreatedAt  time.Time       `gorm:"index" json:"created_at"`
+
+	Actor *User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+}
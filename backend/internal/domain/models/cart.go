@@ -7,11 +7,15 @@ import (
 	"gorm.io/gorm"
 )
 
+// Cart belongs to a user, or to a guest when UserID is nil. A guest's cart
+// is found by the signed token of its cookie and expires unless used.
 type Cart struct {
-	ID         uint      `gorm:"primaryKey"`
-	ResourceID string    `gorm:"unique"`
-	UserID     uint      `gorm:"index"`
-	CreatedAt  time.Time
+	ID           uint       `gorm:"primaryKey"`
+	ResourceID   string     `gorm:"unique"`
+	UserID       *uint      `gorm:"index"`
+	DiscountCode string     `gorm:"size:50"` // code applied with POST /cart/discount, redeemed at checkout
+	ExpiresAt    *time.Time `gorm:"index"`   // set for guest carts only
+	CreatedAt    time.Time
 	UpdatedAt  time.Time
 	Items      []CartItem `gorm:"foreignKey:CartID"`
 }
@@ -28,6 +32,7 @@ type CartItem struct {
 	ProductID  uint
 	VariantID  *uint
 	Quantity   int
+	Price      float64   `gorm:"type:decimal(10,2);default:0"` // unit price when added or last changed; 0 for older lines
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 	Product    Product   `gorm:"foreignkey:ProductID"`
@@ -51,6 +56,13 @@ func (Wishlist) TableName() string {
 	return "wishlist"
 }
 
+// Discount types
+const (
+	DiscountTypePercentage   = "percentage"
+	DiscountTypeFixedAmount  = "fixed_amount"
+	DiscountTypeFreeShipping = "free_shipping"
+)
+
 type Discount struct {
 	ID             uint       `gorm:"primaryKey" json:"id"`
 	ResourceID     string     `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
@@ -62,7 +74,7 @@ type Discount struct {
 	MaximumDiscount *float64  `gorm:"type:decimal(10,2)" json:"maximum_discount"`
 	UsageLimit     *int       `json:"usage_limit"`
 	UsedCount      int        `gorm:"default:0" json:"used_count"`
-	IsActive       bool       `gorm:"default:true" json:"is_active"`
+	IsActive       bool       `gorm:"not null" json:"is_active"`
 	StartsAt       *time.Time `json:"starts_at"`
 	ExpiresAt      *time.Time `json:"expires_at"`
 	CreatedAt      time.Time  `json:"created_at"`
@@ -75,9 +87,10 @@ type Promotion struct {
 	Title     string     `gorm:"size:200;not null" json:"title"`
 	Description string   `gorm:"type:text" json:"description"`
 	Image     string     `gorm:"size:500" json:"image"`
+	LinkURL   string     `gorm:"size:500" json:"link_url"`
 	Type      string     `gorm:"size:20;not null" json:"type"` // banner, popup, sidebar
 	Position  string     `gorm:"size:20;not null" json:"position"` // top, bottom, left, right, center
-	IsActive  bool       `gorm:"default:true" json:"is_active"`
+	IsActive  bool       `gorm:"not null" json:"is_active"`
 	StartsAt  *time.Time `json:"starts_at"`
 	ExpiresAt *time.Time `json:"expires_at"`
 	CreatedAt time.Time  `json:"created_at"`
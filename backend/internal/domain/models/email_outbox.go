@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Outbox email statuses
+const (
+	OutboxEmailPending = "pending" // waiting for its first or next attempt
+	OutboxEmailSending = "sending" // claimed by a worker until LockedUntil
+	OutboxEmailSent    = "sent"
+	OutboxEmailDead    = "dead" // out of attempts or expired; only retried by hand
+)
+
+// Outbox channels
+const (
+	OutboxChannelEmail   = "email"
+	OutboxChannelWebhook = "webhook"
+)
+
+// OutboxEmail is an email waiting to be delivered, written in the same
+// transaction as the change that caused it so it can't get lost. Workers
+// deliver it and record the outcome here. Webhook notifications are queued
+// the same way, with their JSON in Payload instead of a recipient and bodies.
+type OutboxEmail struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	ResourceID    string     `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
+	Channel       string     `gorm:"size:20;not null;default:email" json:"channel"`
+	Kind          string     `gorm:"size:50;not null;index" json:"kind"` // what the email is, e.g. "otp_password_reset"
+	ToEmail       string     `gorm:"size:255;not null" json:"to_email"`
+	ToName        string     `gorm:"size:255" json:"to_name"`
+	Subject       string     `gorm:"size:255;not null" json:"subject"`
+	TextBody      string     `gorm:"type:mediumtext" json:"-"` // bodies are cleared once sent, since they may hold codes
+	HTMLBody      string     `gorm:"type:mediumtext" json:"-"`
+	Payload       string     `gorm:"type:mediumtext" json:"-"`
+	Status        string     `gorm:"size:20;not null;default:pending;index:idx_email_outbox_due" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_email_outbox_due" json:"next_attempt_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	ExpiresAt     *time.Time `json:"expires_at"` // not worth sending after this, e.g. when its code has expired
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for OutboxEmail
+func (OutboxEmail) TableName() string {
+	return "email_outbox"
+}
+
+func (e *OutboxEmail) BeforeCreate(tx *gorm.DB) error {
+	if e.ResourceID == "" {
+		e.ResourceID = uuid.New().String()
+	}
+	if e.Channel == "" {
+		e.Channel = OutboxChannelEmail
+	}
+	if e.Status == "" {
+		e.Status = OutboxEmailPending
+	}
+	if e.NextAttemptAt.IsZero() {
+		e.NextAttemptAt = time.Now()
+	}
+	return nil
+}
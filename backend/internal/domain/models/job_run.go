@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// JobRun records the last scheduled run of a background job. Replicas share
+// it so a run that one of them finished isn't repeated by another whose
+// timer fired a little later.
+type JobRun struct {
+	Name      string    `gorm:"primaryKey;size:64" json:"name"`
+	LastSlot  time.Time `gorm:"not null" json:"last_slot"` // scheduled time of the last run
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for JobRun
+func (JobRun) TableName() string {
+	return "job_runs"
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Notification is an entry of a user's in-app inbox, written in the same
+// transaction as the change it is about
+type Notification struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	ResourceID string     `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
+	UserID     uint       `gorm:"not null;index:idx_notifications_user_created" json:"user_id"`
+	Kind       string     `gorm:"size:50;not null" json:"kind"` // what it is about, e.g. "order_shipped"
+	Title      string     `gorm:"size:255;not null" json:"title"`
+	Body       string     `gorm:"type:text" json:"body"`
+	Link       string     `gorm:"size:500" json:"link"` // storefront path to open, e.g. "/orders"
+	ReadAt     *time.Time `json:"read_at"`
+	CreatedAt  time.Time  `gorm:"index:idx_notifications_user_created" json:"created_at"`
+}
+
+// TableName specifies the table name for Notification
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.ResourceID == "" {
+		n.ResourceID = uuid.New().String()
+	}
+	return nil
+}
@@ -7,6 +7,44 @@ import (
 	"gorm.io/gorm"
 )
 
+// Order statuses
+const (
+	OrderStatusPending    = "pending"
+	OrderStatusConfirmed  = "confirmed"
+	OrderStatusProcessing = "processing"
+	OrderStatusShipped    = "shipped"
+	OrderStatusDelivered  = "delivered"
+	OrderStatusCancelled  = "cancelled"
+	OrderStatusRefunded   = "refunded"
+)
+
+// Order payment statuses
+const (
+	OrderPaymentPending           = "pending"
+	OrderPaymentPaid              = "paid"
+	OrderPaymentFailed            = "failed"
+	OrderPaymentPartiallyRefunded = "partially_refunded"
+	OrderPaymentRefunded          = "refunded"
+)
+
+// Payment statuses (payments.payment_status)
+const (
+	PaymentStatusPending    = "pending"
+	PaymentStatusProcessing = "processing"
+	PaymentStatusCompleted  = "completed"
+	PaymentStatusFailed     = "failed"
+	PaymentStatusCancelled  = "cancelled"
+	PaymentStatusRefunded   = "refunded"
+)
+
+// Refund statuses (refunds.status). A refund is pending while the gateway
+// is being asked to return the money.
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusCompleted = "completed"
+	RefundStatusFailed    = "failed"
+)
+
 type Order struct {
 	ID            uint      `gorm:"primaryKey" json:"id"`
 	ResourceID    string    `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
@@ -15,12 +53,21 @@ type Order struct {
 	Status        string    `gorm:"size:20;default:pending" json:"status"`
 	PaymentStatus string    `gorm:"size:20;default:pending" json:"payment_status"`
 	Subtotal      float64   `gorm:"type:decimal(10,2);not null;column:subtotal" json:"subtotal"`
-	TaxAmount     float64   `gorm:"type:decimal(10,2);default:0;column:tax_amount" json:"tax_amount"`
+	TaxAmount     float64   `gorm:"type:decimal(10,2);default:0;column:tax_amount" json:"tax_amount"` // all tax, including tax already contained in inclusive prices
 	ShippingCost  float64   `gorm:"type:decimal(10,2);default:0;column:shipping_amount" json:"shipping_cost"`
+	ShippingMethodID *uint  `gorm:"index" json:"shipping_method_id"`
+	ShippingMethod string   `gorm:"size:100" json:"shipping_method"` // method name at checkout
+	ShippingAddress *AddressSnapshot `gorm:"type:json;serializer:json" json:"shipping_address"`
+	BillingAddress  *AddressSnapshot `gorm:"type:json;serializer:json" json:"billing_address"`
 	DiscountAmount float64  `gorm:"type:decimal(10,2);default:0;column:discount_amount" json:"discount_amount"`
+	DiscountCode  string    `gorm:"size:50" json:"discount_code"`
 	Total         float64   `gorm:"type:decimal(10,2);not null;column:total_amount" json:"total"`
 	Currency      string    `gorm:"size:3;default:USD" json:"currency"`
 	Notes         string    `gorm:"type:text" json:"notes"`
+	InventoryReserved bool  `gorm:"default:false;column:inventory_reserved" json:"-"` // stock was decremented at checkout and not yet released
+	Carrier        string   `gorm:"size:50" json:"carrier"`
+	TrackingNumber string   `gorm:"size:100" json:"tracking_number"`
+	TrackingURL    string   `gorm:"size:500" json:"tracking_url"`
 	ShippedAt     *time.Time `json:"shipped_at"`
 	DeliveredAt   *time.Time `json:"delivered_at"`
 	CreatedAt     time.Time `json:"created_at"`
@@ -28,8 +75,11 @@ type Order struct {
 
 	// Relationships
 	User       User        `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	OrderItems []OrderItem `gorm:"foreignKey:OrderID" json:"order_items,omitempty"`
-	Payments   []Payment   `gorm:"foreignKey:OrderID" json:"payments,omitempty"`
+	OrderItems    []OrderItem          `gorm:"foreignKey:OrderID" json:"order_items,omitempty"`
+	Payments      []Payment            `gorm:"foreignKey:OrderID" json:"payments,omitempty"`
+	Refunds       []Refund             `gorm:"foreignKey:OrderID" json:"refunds,omitempty"`
+	Taxes         []OrderTax           `gorm:"foreignKey:OrderID" json:"taxes,omitempty"`
+	StatusHistory []OrderStatusHistory `gorm:"foreignKey:OrderID" json:"status_history,omitempty"`
 }
 
 type OrderItem struct {
@@ -39,8 +89,9 @@ type OrderItem struct {
 	ProductID  uint    `gorm:"not null" json:"product_id"`
 	VariantID  *uint   `gorm:"index" json:"variant_id"`
 	Quantity   int     `gorm:"not null" json:"quantity"`
-	Price      float64 `gorm:"type:decimal(10,2);not null" json:"price"`
-	Total      float64 `gorm:"type:decimal(10,2);not null" json:"total"`
+	Price      float64 `gorm:"type:decimal(10,2);not null;column:unit_price" json:"price"`
+	Total      float64 `gorm:"type:decimal(10,2);not null;column:total_price" json:"total"`
+	TaxAmount  float64 `gorm:"type:decimal(10,2);default:0;column:tax_amount" json:"tax_amount"`
 	CreatedAt  time.Time `json:"created_at"`
 
 	// Relationships
@@ -49,22 +100,41 @@ type OrderItem struct {
 	Variant *Variant `gorm:"foreignKey:VariantID" json:"variant,omitempty"`
 }
 
+// OrderStatusHistory records a single status transition of an order
+type OrderStatusHistory struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ResourceID string    `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
+	OrderID    uint      `gorm:"not null;index" json:"order_id"`
+	FromStatus string    `gorm:"size:20" json:"from_status"`
+	ToStatus   string    `gorm:"size:20;not null" json:"to_status"`
+	ActorID    *uint     `gorm:"index" json:"actor_id"`
+	ActorRole  string    `gorm:"size:20;not null" json:"actor_role"` // customer, admin, system
+	Note       string    `gorm:"type:text" json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for OrderStatusHistory
+func (OrderStatusHistory) TableName() string {
+	return "order_status_history"
+}
+
 type Payment struct {
 	ID              uint       `gorm:"primaryKey" json:"id"`
 	ResourceID      string     `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
-	OrderID         uint       `gorm:"not null" json:"order_id"`
+	OrderID         uint       `gorm:"not null;index" json:"order_id"`
 	Method          string     `gorm:"size:20;not null;column:payment_method" json:"method"`
 	Amount          float64    `gorm:"type:decimal(10,2);not null" json:"amount"`
 	Currency        string     `gorm:"size:3;default:USD" json:"currency"`
 	Status          string     `gorm:"column:payment_status" json:"status"`
-	TransactionID   string     `gorm:"size:255" json:"transaction_id"`
+	TransactionID   string     `gorm:"size:255;index" json:"transaction_id"`
 	GatewayResponse string     `gorm:"type:json;column:gateway_response" json:"gateway_response"`
 	ProcessedAt     *time.Time `gorm:"column:processed_at" json:"processed_at"`
 	CreatedAt       time.Time  `json:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at"`
 
 	// Relationships
-	Order Order `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+	Order   Order    `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+	Refunds []Refund `gorm:"foreignKey:PaymentID" json:"refunds,omitempty"`
 }
 
 // TableName specifies the table name for Payment
@@ -72,6 +142,37 @@ func (Payment) TableName() string {
 	return "payments"
 }
 
+// Refund is money returned against a single payment, optionally tied to
+// specific order lines
+type Refund struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	ResourceID      string    `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
+	OrderID         uint      `gorm:"not null;index" json:"order_id"`
+	PaymentID       uint      `gorm:"not null;index" json:"payment_id"`
+	Amount          float64   `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Currency        string    `gorm:"size:3;default:USD" json:"currency"`
+	Reason          string    `gorm:"type:text" json:"reason"`
+	Status          string    `gorm:"size:20;default:pending;index" json:"status"`
+	TransactionID   string    `gorm:"size:255" json:"transaction_id"`
+	GatewayResponse string    `gorm:"type:json" json:"gateway_response"`
+	Restocked       bool      `gorm:"default:false" json:"restocked"` // refunded lines were returned to stock
+	ActorID         *uint     `gorm:"index" json:"actor_id"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+
+	// Relationships
+	Items []RefundItem `gorm:"foreignKey:RefundID" json:"items,omitempty"`
+}
+
+type RefundItem struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	RefundID    uint      `gorm:"not null;index" json:"refund_id"`
+	OrderItemID uint      `gorm:"not null;index" json:"order_item_id"`
+	Quantity    int       `gorm:"not null" json:"quantity"`
+	Amount      float64   `gorm:"type:decimal(10,2);not null" json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
 func (o *Order) BeforeCreate(tx *gorm.DB) error {
 	if o.ResourceID == "" {
 		o.ResourceID = uuid.New().String()
@@ -89,6 +190,13 @@ func (oi *OrderItem) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+func (h *OrderStatusHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ResourceID == "" {
+		h.ResourceID = uuid.New().String()
+	}
+	return nil
+}
+
 func (p *Payment) BeforeCreate(tx *gorm.DB) error {
 	if p.ResourceID == "" {
 		p.ResourceID = uuid.New().String()
@@ -96,6 +204,13 @@ func (p *Payment) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+func (r *Refund) BeforeCreate(tx *gorm.DB) error {
+	if r.ResourceID == "" {
+		r.ResourceID = uuid.New().String()
+	}
+	return nil
+}
+
 func generateOrderNumber() string {
 	return "ORD" + time.Now().Format("20060102") + "-" + uuid.New().String()[:8]
 }
\ No newline at end of file
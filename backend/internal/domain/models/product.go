@@ -48,12 +48,13 @@ type Product struct {
 	CategoryID uint   `gorm:"-" json:"category_id"`
 
 	// Relationships
-	Category   Category   `gorm:"-" json:"category,omitempty"`
-	Categories []Category `gorm:"many2many:product_categories" json:"-"`
-	Images     []Image    `gorm:"foreignKey:ProductID" json:"images,omitempty"`
-	Variants   []Variant  `gorm:"foreignKey:ProductID" json:"variants,omitempty"`
-	Reviews    []Review   `gorm:"foreignKey:ProductID" json:"reviews,omitempty"`
-	BrandRef   *Brand     `gorm:"foreignKey:ID;references:BrandID" json:"brand_ref,omitempty"`
+	Category   Category        `gorm:"-" json:"category,omitempty"`
+	Categories []Category      `gorm:"many2many:product_categories" json:"-"`
+	Images     []Image         `gorm:"foreignKey:ProductID" json:"images,omitempty"`
+	Variants   []Variant       `gorm:"foreignKey:ProductID" json:"variants,omitempty"`
+	Options    []ProductOption `gorm:"foreignKey:ProductID" json:"options,omitempty"`
+	Reviews    []Review        `gorm:"foreignKey:ProductID" json:"reviews,omitempty"`
+	BrandRef   *Brand          `gorm:"foreignKey:ID;references:BrandID" json:"brand_ref,omitempty"`
 }
 
 type Brand struct {
@@ -79,6 +80,9 @@ type Image struct {
 	Product Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
 }
 
+// Variant is a sellable version of a product with its own SKU, price and
+// stock. On a product with options it is one combination of their values,
+// and its Name is those values, e.g. "Black / 256GB".
 type Variant struct {
 	ID            uint    `gorm:"primaryKey" json:"id"`
 	ResourceID    string  `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
@@ -90,12 +94,14 @@ type Variant struct {
 	CostPrice     float64 `gorm:"column:cost_price;type:decimal(10,2)" json:"cost_price"`
 	StockQuantity int     `gorm:"column:stock_quantity;default:0" json:"stock_quantity"`
 	Weight        float64 `gorm:"type:decimal(8,2)" json:"weight"`
-	IsActive      bool    `gorm:"column:is_active;default:true" json:"is_active"`
+	IsActive      bool    `gorm:"column:is_active;not null" json:"is_active"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 
 	// Relationships
-	Product Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Product      Product              `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	OptionValues []ProductOptionValue `gorm:"many2many:variant_option_values;joinForeignKey:VariantID;joinReferences:OptionValueID" json:"option_values,omitempty"`
+	Images       []VariantImage       `gorm:"foreignKey:VariantID" json:"images,omitempty"`
 }
 
 type Review struct {
@@ -177,6 +183,20 @@ func (p *Product) setComputedFields() {
 	}
 }
 
+// PrimaryImageURL returns the URL of the product's primary image, or of its
+// first image when none is marked primary. Images must be loaded.
+func (p *Product) PrimaryImageURL() string {
+	for _, image := range p.Images {
+		if image.IsPrimary {
+			return image.URL
+		}
+	}
+	if len(p.Images) > 0 {
+		return p.Images[0].URL
+	}
+	return ""
+}
+
 func (i *Image) BeforeCreate(tx *gorm.DB) error {
 	if i.ResourceID == "" {
 		i.ResourceID = uuid.New().String()
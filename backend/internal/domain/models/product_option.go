@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProductOption is a way a product comes in, such as Color or Storage.
+// Each variant of a product with options picks one value of every option.
+type ProductOption struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ResourceID string    `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
+	ProductID  uint      `gorm:"not null;uniqueIndex:idx_product_options_product_name" json:"product_id"`
+	Name       string    `gorm:"size:100;not null;uniqueIndex:idx_product_options_product_name" json:"name"`
+	Position   int       `gorm:"default:0" json:"position"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Relationships
+	Values []ProductOptionValue `gorm:"foreignKey:OptionID" json:"values,omitempty"`
+}
+
+// TableName specifies the table name for ProductOption
+func (ProductOption) TableName() string {
+	return "product_options"
+}
+
+// ProductOptionValue is one choice of an option, such as Black or 256GB
+type ProductOptionValue struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	ResourceID string `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
+	OptionID   uint   `gorm:"not null;uniqueIndex:idx_product_option_values_option_value" json:"option_id"`
+	Value      string `gorm:"size:100;not null;uniqueIndex:idx_product_option_values_option_value" json:"value"`
+	Position   int    `gorm:"default:0" json:"position"`
+
+	// Relationships
+	Option *ProductOption `gorm:"foreignKey:OptionID" json:"option,omitempty"`
+}
+
+// TableName specifies the table name for ProductOptionValue
+func (ProductOptionValue) TableName() string {
+	return "product_option_values"
+}
+
+// VariantImage is a picture of one variant, e.g. the product in one color
+type VariantImage struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ResourceID string    `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
+	VariantID  uint      `gorm:"not null;index" json:"variant_id"`
+	URL        string    `gorm:"size:500;not null" json:"url"`
+	Alt        string    `gorm:"column:alt_text;size:200" json:"alt"`
+	SortOrder  int       `gorm:"column:sort_order;default:0" json:"sort_order"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for VariantImage
+func (VariantImage) TableName() string {
+	return "variant_images"
+}
+
+func (o *ProductOption) BeforeCreate(tx *gorm.DB) error {
+	if o.ResourceID == "" {
+		o.ResourceID = uuid.New().String()
+	}
+	return nil
+}
+
+func (v *ProductOptionValue) BeforeCreate(tx *gorm.DB) error {
+	if v.ResourceID == "" {
+		v.ResourceID = uuid.New().String()
+	}
+	return nil
+}
+
+func (i *VariantImage) BeforeCreate(tx *gorm.DB) error {
+	if i.ResourceID == "" {
+		i.ResourceID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package models
+
+import "time"
+
+// Permissions guarding the admin API. Each names an area and whether it can
+// be read or changed.
+const (
+	PermissionAll = "*" // every permission, held by super admins
+
+	PermissionAnalyticsRead  = "analytics:read"
+	PermissionCatalogRead    = "catalog:read" // products, categories and brands
+	PermissionCatalogWrite   = "catalog:write"
+	PermissionOrdersRead     = "orders:read"
+	PermissionOrdersWrite    = "orders:write" // order status changes such as shipping
+	PermissionRefundsWrite   = "refunds:write"
+	PermissionUsersRead      = "users:read"
+	PermissionUsersWrite     = "users:write"
+	PermissionRolesWrite     = "roles:write"    // assigning roles to users
+	PermissionMarketingRead  = "marketing:read" // discounts and promotions
+	PermissionMarketingWrite = "marketing:write"
+	PermissionSettingsRead   = "settings:read" // tax rules, shipping, background jobs, the email outbox and email templates
+	PermissionSettingsWrite  = "settings:write"
+	PermissionAuditRead      = "audit:read" // the admin audit log
+)
+
+// Built-in roles
+const (
+	RoleSuperAdmin       = "super_admin"
+	RoleCatalogManager   = "catalog_manager"
+	RoleOrderFulfillment = "order_fulfillment"
+	RoleSupport          = "support"
+	RoleMarketing        = "marketing"
+)
+
+// Permission is a named capability granted to users through their roles
+type Permission struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"uniqueIndex;size:50;not null" json:"name"`
+	Description string    `gorm:"size:255" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Role is a named set of permissions. A user holding any role is staff and
+// can sign in to the admin panel; what they can do there depends on the
+// permissions of their roles.
+type Role struct {
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	ResourceID  string       `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
+	Name        string       `gorm:"uniqueIndex;size:50;not null" json:"name"`
+	Description string       `gorm:"size:255" json:"description"`
+	Permissions []Permission `gorm:"many2many:role_permissions" json:"permissions,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// DefaultPermissions lists every permission the admin API checks
+var DefaultPermissions = []Permission{
+	{Name: PermissionAll, Description: "Every permission"},
+	{Name: PermissionAnalyticsRead, Description: "View sales analytics"},
+	{Name: PermissionCatalogRead, Description: "View products, categories and brands"},
+	{Name: PermissionCatalogWrite, Description: "Manage products, categories and brands"},
+	{Name: PermissionOrdersRead, Description: "View orders"},
+	{Name: PermissionOrdersWrite, Description: "Update order status"},
+	{Name: PermissionRefundsWrite, Description: "Refund orders"},
+	{Name: PermissionUsersRead, Description: "View customers"},
+	{Name: PermissionUsersWrite, Description: "Update customers"},
+	{Name: PermissionRolesWrite, Description: "Assign staff roles"},
+	{Name: PermissionMarketingRead, Description: "View discounts and promotions"},
+	{Name: PermissionMarketingWrite, Description: "Manage discounts and promotions"},
+	{Name: PermissionSettingsRead, Description: "View tax rules, shipping, background jobs and queued emails"},
+	{Name: PermissionSettingsWrite, Description: "Manage tax rules and shipping, retry failed emails"},
+	{Name: PermissionAuditRead, Description: "View the admin audit log"},
+}
+
+// DefaultRoles are the built-in roles and the permissions each is granted.
+// They are created at startup; permissions granted to them later through the
+// database are kept.
+var DefaultRoles = []struct {
+	Name        string
+	Description string
+	Permissions []string
+}{
+	{RoleSuperAdmin, "Full access to the admin panel", []string{PermissionAll}},
+	{RoleCatalogManager, "Manages the product catalog", []string{
+		PermissionAnalyticsRead, PermissionCatalogRead, PermissionCatalogWrite,
+	}},
+	{RoleOrderFulfillment, "Warehouse staff who process and ship orders", []string{
+		PermissionOrdersRead, PermissionOrdersWrite,
+	}},
+	{RoleSupport, "Customer support", []string{
+		PermissionOrdersRead, PermissionUsersRead, PermissionCatalogRead,
+	}},
+	{RoleMarketing, "Runs discount codes and promotions", []string{
+		PermissionAnalyticsRead, PermissionCatalogRead, PermissionMarketingRead, PermissionMarketingWrite,
+	}},
+}
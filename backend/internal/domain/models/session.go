@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Reasons a refresh token stopped being usable
+const (
+	RefreshTokenRotated   = "rotated"    // exchanged for the next token of its session
+	RefreshTokenLogout    = "logout"     // the session was logged out
+	RefreshTokenLogoutAll = "logout_all" // every session of the user was logged out
+	RefreshTokenReused    = "reused"     // a rotated token of the session was presented again
+)
+
+// RefreshToken is one refresh token issued to a user. Tokens sharing a
+// FamilyID belong to the same login session on one device: each refresh
+// rotates the session to a new token, and presenting a rotated token again
+// revokes the whole family. Only a hash of the signed token is stored.
+type RefreshToken struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	UserID        uint       `gorm:"not null;index" json:"user_id"`
+	FamilyID      string     `gorm:"type:char(36);not null;index" json:"family_id"`
+	TokenID       string     `gorm:"type:char(36);not null;uniqueIndex" json:"token_id"` // jti claim
+	TokenHash     string     `gorm:"type:char(64);not null" json:"-"`                    // hex SHA-256 of the signed token
+	UserAgent     string     `gorm:"size:255" json:"user_agent"`
+	IPAddress     string     `gorm:"size:45" json:"ip_address"`
+	ExpiresAt     time.Time  `gorm:"not null;index" json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	RevokedReason string     `gorm:"size:20" json:"revoked_reason"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
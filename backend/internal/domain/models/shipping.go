@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Shipping method types
+const (
+	ShippingMethodFlatRate    = "flat_rate"    // one price per order
+	ShippingMethodWeightBased = "weight_based" // price from the tier matching the shipment weight
+	ShippingMethodFreeOver    = "free_over"    // flat price, free once the subtotal reaches a threshold
+)
+
+// ShippingRegionAnyCountry matches every destination; zones using it are only
+// chosen when no zone names the destination country
+const ShippingRegionAnyCountry = "*"
+
+// ShippingZone groups the destinations that share the same shipping methods
+type ShippingZone struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ResourceID string    `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
+	Name       string    `gorm:"size:100;not null" json:"name"`
+	IsActive   bool      `gorm:"not null" json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Relationships
+	Regions []ShippingZoneRegion `gorm:"foreignKey:ZoneID" json:"regions,omitempty"`
+	Methods []ShippingMethod     `gorm:"foreignKey:ZoneID" json:"methods,omitempty"`
+}
+
+// ShippingZoneRegion is a country, or one state of a country, covered by a zone
+type ShippingZoneRegion struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	ZoneID  uint   `gorm:"not null;index" json:"zone_id"`
+	Country string `gorm:"size:100;not null;index" json:"country"`
+	State   string `gorm:"size:100" json:"state"` // empty for the whole country
+}
+
+type ShippingMethod struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ResourceID    string    `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
+	ZoneID        uint      `gorm:"not null;index" json:"zone_id"`
+	Name          string    `gorm:"size:100;not null" json:"name"`
+	Type          string    `gorm:"size:20;not null" json:"type"` // flat_rate, weight_based, free_over
+	Rate          float64   `gorm:"type:decimal(10,2);default:0" json:"rate"`
+	FreeThreshold *float64  `gorm:"type:decimal(10,2)" json:"free_threshold"` // free_over only
+	EstimatedDays string    `gorm:"size:50" json:"estimated_days"`
+	SortOrder     int       `gorm:"default:0" json:"sort_order"`
+	IsActive      bool      `gorm:"not null" json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Relationships
+	Tiers []ShippingRateTier `gorm:"foreignKey:MethodID" json:"tiers,omitempty"`
+}
+
+// ShippingRateTier prices shipments of a weight-based method whose billable
+// weight (kg) is at least MinWeight and below MaxWeight
+type ShippingRateTier struct {
+	ID        uint     `gorm:"primaryKey" json:"id"`
+	MethodID  uint     `gorm:"not null;index" json:"method_id"`
+	MinWeight float64  `gorm:"type:decimal(8,2);not null;default:0" json:"min_weight"`
+	MaxWeight *float64 `gorm:"type:decimal(8,2)" json:"max_weight"` // nil for no upper bound
+	Rate      float64  `gorm:"type:decimal(10,2);not null" json:"rate"`
+}
+
+func (z *ShippingZone) BeforeCreate(tx *gorm.DB) error {
+	if z.ResourceID == "" {
+		z.ResourceID = uuid.New().String()
+	}
+	return nil
+}
+
+func (m *ShippingMethod) BeforeCreate(tx *gorm.DB) error {
+	if m.ResourceID == "" {
+		m.ResourceID = uuid.New().String()
+	}
+	return nil
+}
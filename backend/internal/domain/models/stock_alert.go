@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StockAlert asks for an email once an out of stock product can be ordered
+// again. It is removed when that email is queued.
+type StockAlert struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ResourceID string    `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_stock_alerts_user_product" json:"user_id"`
+	ProductID  uint      `gorm:"not null;uniqueIndex:idx_stock_alerts_user_product;index" json:"product_id"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// Relationships
+	User    User    `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Product Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+// TableName specifies the table name for StockAlert
+func (StockAlert) TableName() string {
+	return "stock_alerts"
+}
+
+func (a *StockAlert) BeforeCreate(tx *gorm.DB) error {
+	if a.ResourceID == "" {
+		a.ResourceID = uuid.New().String()
+	}
+	return nil
+}
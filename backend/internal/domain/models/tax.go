@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaxRule is a tax rate charged on taxable products shipped to a region. A
+// rule without a state applies to the whole country; every active rule that
+// matches the destination is applied.
+type TaxRule struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ResourceID string    `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
+	Name       string    `gorm:"size:100;not null" json:"name"`
+	Country    string    `gorm:"size:100;not null;index:idx_tax_rules_region" json:"country"`
+	State      string    `gorm:"size:100;index:idx_tax_rules_region" json:"state"` // empty for country-wide rules
+	Rate       float64   `gorm:"type:decimal(7,4);not null" json:"rate"`           // percentage, e.g. 8.25
+	Inclusive  bool      `gorm:"default:false" json:"inclusive"`                   // catalog prices already include this tax
+	IsActive   bool      `gorm:"not null" json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// OrderTax records the tax a single rule contributed to an order at checkout.
+// Rule details are copied so later rule changes don't rewrite past orders.
+type OrderTax struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	OrderID       uint      `gorm:"not null;index" json:"order_id"`
+	TaxRuleID     *uint     `gorm:"index" json:"tax_rule_id"`
+	Name          string    `gorm:"size:100;not null" json:"name"`
+	Country       string    `gorm:"size:100;not null" json:"country"`
+	State         string    `gorm:"size:100" json:"state"`
+	Rate          float64   `gorm:"type:decimal(7,4);not null" json:"rate"`
+	Inclusive     bool      `gorm:"default:false" json:"inclusive"`
+	TaxableAmount float64   `gorm:"type:decimal(10,2);not null" json:"taxable_amount"`
+	Amount        float64   `gorm:"type:decimal(10,2);not null" json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (t *TaxRule) BeforeCreate(tx *gorm.DB) error {
+	if t.ResourceID == "" {
+		t.ResourceID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// TwoFactorAuth is a user's TOTP enrollment. It stays pending, and is not
+// asked for at login, until the user confirms it with a code from their
+// authenticator app.
+type TwoFactorAuth struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"not null;uniqueIndex" json:"user_id"`
+	Secret       string     `gorm:"size:64;not null" json:"-"` // base32 TOTP secret
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	LastUsedStep int64      `gorm:"not null;default:0" json:"-"` // time step of the last accepted code, so no code works twice
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Enabled reports whether the enrollment has been confirmed
+func (t *TwoFactorAuth) Enabled() bool {
+	return t != nil && t.ConfirmedAt != nil
+}
+
+// TwoFactorRecoveryCode is a one-time code that stands in for a TOTP code
+// when the user has lost their authenticator
+type TwoFactorRecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index:idx_recovery_codes_user_hash" json:"user_id"`
+	CodeHash  string     `gorm:"type:char(64);not null;index:idx_recovery_codes_user_hash" json:"-"` // hex SHA-256 of the normalized code
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
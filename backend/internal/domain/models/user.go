@@ -30,35 +30,84 @@ type User struct {
 	Addresses []Address `gorm:"foreignKey:UserID" json:"addresses,omitempty"`
 	Orders    []Order   `gorm:"foreignKey:UserID" json:"orders,omitempty"`
 	Reviews   []Review  `gorm:"foreignKey:UserID" json:"reviews,omitempty"`
+	Roles     []Role    `gorm:"many2many:user_roles" json:"roles,omitempty"`
 }
 
+// Address types
+const (
+	AddressTypeShipping = "shipping"
+	AddressTypeBilling  = "billing"
+)
+
+// Address is an entry in a user's address book. Each user has at most one
+// default address per type.
 type Address struct {
-	ID         uint   `gorm:"primaryKey" json:"id"`
-	ResourceID string `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
-	UserID     uint   `gorm:"not null" json:"user_id"`
-	Type       string `gorm:"size:20;not null" json:"type"` // home, work, other
-	Street     string `gorm:"size:200;not null" json:"street"`
-	City       string `gorm:"size:50;not null" json:"city"`
-	State      string `gorm:"size:50;not null" json:"state"`
-	Country    string `gorm:"size:50;not null" json:"country"`
-	PostalCode string `gorm:"size:20;not null" json:"postal_code"`
-	IsDefault  bool   `gorm:"default:false" json:"is_default"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ResourceID   string    `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
+	UserID       uint      `gorm:"not null;index" json:"user_id"`
+	Type         string    `gorm:"size:20;not null;default:shipping" json:"type"` // shipping, billing
+	FirstName    string    `gorm:"size:50;not null" json:"first_name"`
+	LastName     string    `gorm:"size:50;not null" json:"last_name"`
+	Company      string    `gorm:"size:100" json:"company"`
+	AddressLine1 string    `gorm:"column:address_line_1;size:255;not null" json:"address_line_1"`
+	AddressLine2 string    `gorm:"column:address_line_2;size:255" json:"address_line_2"`
+	City         string    `gorm:"size:100;not null" json:"city"`
+	State        string    `gorm:"size:100;not null" json:"state"`
+	PostalCode   string    `gorm:"size:20;not null" json:"postal_code"`
+	Country      string    `gorm:"size:100;not null" json:"country"`
+	Phone        string    `gorm:"size:20" json:"phone"`
+	IsDefault    bool      `gorm:"default:false" json:"is_default"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 
 	// Relationships
 	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
+// AddressSnapshot is a copy of an address stored on an order, so editing or
+// deleting the address book entry later leaves the order unchanged
+type AddressSnapshot struct {
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Company      string `json:"company,omitempty"`
+	AddressLine1 string `json:"address_line_1"`
+	AddressLine2 string `json:"address_line_2,omitempty"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	PostalCode   string `json:"postal_code"`
+	Country      string `json:"country"`
+	Phone        string `json:"phone,omitempty"`
+}
+
+// Snapshot copies the address for storing on an order
+func (a *Address) Snapshot() *AddressSnapshot {
+	if a == nil {
+		return nil
+	}
+	return &AddressSnapshot{
+		FirstName:    a.FirstName,
+		LastName:     a.LastName,
+		Company:      a.Company,
+		AddressLine1: a.AddressLine1,
+		AddressLine2: a.AddressLine2,
+		City:         a.City,
+		State:        a.State,
+		PostalCode:   a.PostalCode,
+		Country:      a.Country,
+		Phone:        a.Phone,
+	}
+}
+
 type OTPVerification struct {
 	ID         uint      `gorm:"primaryKey" json:"id"`
 	ResourceID string    `gorm:"uniqueIndex;type:char(36);not null" json:"resource_id"`
 	UserID     *uint     `gorm:"index" json:"user_id"`
 	Email      string    `gorm:"size:100;not null;index" json:"email"`
 	Phone      string    `gorm:"size:20;index" json:"phone"`
-	OTPCode    string    `gorm:"size:10;not null;index" json:"otp_code"`
+	CodeHash   string    `gorm:"type:char(64);not null" json:"-"` // keyed HMAC of the code, see OTPService
 	OTPType    string    `gorm:"type:enum('email_verification','phone_verification','password_reset','login');not null" json:"otp_type"`
 	IsUsed     bool      `gorm:"default:false" json:"is_used"`
+	Attempts   int       `gorm:"not null;default:0" json:"attempts"` // wrong guesses so far
 	ExpiresAt  time.Time `gorm:"not null;index" json:"expires_at"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
@@ -0,0 +1,71 @@
+package dto
+
+import "time"
+
+// Address DTOs
+
+// CreateAddressRequest adds an address to the user's address book. The first
+// address of a type becomes the default for that type.
+type CreateAddressRequest struct {
+	Type         string `json:"type" binding:"omitempty,oneof=shipping billing"`
+	FirstName    string `json:"first_name" binding:"required,max=50"`
+	LastName     string `json:"last_name" binding:"required,max=50"`
+	Company      string `json:"company" binding:"omitempty,max=100"`
+	AddressLine1 string `json:"address_line_1" binding:"required,max=255"`
+	AddressLine2 string `json:"address_line_2" binding:"omitempty,max=255"`
+	City         string `json:"city" binding:"required,max=100"`
+	State        string `json:"state" binding:"required,max=100"`
+	PostalCode   string `json:"postal_code" binding:"required,max=20"`
+	Country      string `json:"country" binding:"required,max=100"`
+	Phone        string `json:"phone" binding:"omitempty,max=20"`
+	IsDefault    bool   `json:"is_default"`
+}
+
+// UpdateAddressRequest changes the given fields of an address. Setting
+// is_default to true makes it the only default of its type.
+type UpdateAddressRequest struct {
+	Type         *string `json:"type" binding:"omitempty,oneof=shipping billing"`
+	FirstName    *string `json:"first_name" binding:"omitempty,min=1,max=50"`
+	LastName     *string `json:"last_name" binding:"omitempty,min=1,max=50"`
+	Company      *string `json:"company" binding:"omitempty,max=100"`
+	AddressLine1 *string `json:"address_line_1" binding:"omitempty,min=1,max=255"`
+	AddressLine2 *string `json:"address_line_2" binding:"omitempty,max=255"`
+	City         *string `json:"city" binding:"omitempty,min=1,max=100"`
+	State        *string `json:"state" binding:"omitempty,min=1,max=100"`
+	PostalCode   *string `json:"postal_code" binding:"omitempty,min=1,max=20"`
+	Country      *string `json:"country" binding:"omitempty,min=1,max=100"`
+	Phone        *string `json:"phone" binding:"omitempty,max=20"`
+	IsDefault    *bool   `json:"is_default"`
+}
+
+type AddressResponse struct {
+	ResourceID   string    `json:"resource_id"`
+	Type         string    `json:"type"`
+	FirstName    string    `json:"first_name"`
+	LastName     string    `json:"last_name"`
+	Company      string    `json:"company,omitempty"`
+	AddressLine1 string    `json:"address_line_1"`
+	AddressLine2 string    `json:"address_line_2,omitempty"`
+	City         string    `json:"city"`
+	State        string    `json:"state"`
+	PostalCode   string    `json:"postal_code"`
+	Country      string    `json:"country"`
+	Phone        string    `json:"phone,omitempty"`
+	IsDefault    bool      `json:"is_default"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// OrderAddressResponse is the copy of an address taken when the order was placed
+type OrderAddressResponse struct {
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Company      string `json:"company,omitempty"`
+	AddressLine1 string `json:"address_line_1"`
+	AddressLine2 string `json:"address_line_2,omitempty"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	PostalCode   string `json:"postal_code"`
+	Country      string `json:"country"`
+	Phone        string `json:"phone,omitempty"`
+}
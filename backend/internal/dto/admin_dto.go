@@ -1,6 +1,9 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // ============================================
 // ADMIN ANALYTICS DTOs
@@ -87,8 +90,8 @@ type AdminProductListResponse struct {
 type AdminOrderListRequest struct {
 	Page          int    `form:"page" binding:"omitempty,min=1"`
 	Limit         int    `form:"limit" binding:"omitempty,min=1,max=100"`
-	Status        string `form:"status" binding:"omitempty,oneof=pending processing shipped delivered cancelled refunded"`
-	PaymentStatus string `form:"payment_status" binding:"omitempty,oneof=pending paid failed refunded"`
+	Status        string `form:"status" binding:"omitempty,oneof=pending confirmed processing shipped delivered cancelled refunded"`
+	PaymentStatus string `form:"payment_status" binding:"omitempty,oneof=pending paid failed partially_refunded refunded"`
 	UserID        uint   `form:"user_id"`
 	Search        string `form:"search"`
 	SortBy        string `form:"sort_by" binding:"omitempty,oneof=created_at total_amount status"`
@@ -104,14 +107,224 @@ type AdminOrderListResponse struct {
 
 type AdminOrderDetailResponse struct {
 	OrderResponse
-	Customer UserSummary        `json:"customer"`
-	Items    []OrderItemResponse `json:"items"`
-	Payments []PaymentResponse  `json:"payments,omitempty"`
+	Customer      UserSummary                  `json:"customer"`
+	Items         []OrderItemResponse          `json:"items"`
+	Payments      []PaymentResponse            `json:"payments,omitempty"`
+	Refunds       []RefundResponse             `json:"refunds,omitempty"`
+	StatusHistory []OrderStatusHistoryResponse `json:"status_history,omitempty"`
 }
 
 type UpdateOrderStatusRequest struct {
-	Status string `json:"status" binding:"required,oneof=pending processing shipped delivered cancelled refunded"`
-	Notes  string `json:"notes"`
+	Status string `json:"status" binding:"required,oneof=pending confirmed processing shipped delivered cancelled refunded"`
+	Notes  string `json:"notes"` // Recorded on the status history entry
+
+	// Shipment details, recorded when the status is shipped and included in
+	// the shipping email. The tracking URL is derived for UPS, FedEx, USPS
+	// and DHL when not given.
+	Carrier        string `json:"carrier" binding:"omitempty,max=50"`
+	TrackingNumber string `json:"tracking_number" binding:"omitempty,max=100"`
+	TrackingURL    string `json:"tracking_url" binding:"omitempty,url,max=500"`
+}
+
+// CreateRefundRequest refunds part or all of a payment. When items are given
+// the amount defaults to their line value; an explicit amount overrides it.
+type CreateRefundRequest struct {
+	PaymentID string              `json:"payment_id" binding:"required"`
+	Amount    *float64            `json:"amount" binding:"omitempty,gt=0"`
+	Items     []RefundItemRequest `json:"items" binding:"omitempty,dive"`
+	Reason    string              `json:"reason" binding:"omitempty,max=500"`
+	Restock   bool                `json:"restock"`
+}
+
+type RefundItemRequest struct {
+	OrderItemID string `json:"order_item_id" binding:"required"`
+	Quantity    int    `json:"quantity" binding:"required,min=1"`
+}
+
+type RefundResponse struct {
+	ResourceID    string               `json:"resource_id"`
+	PaymentID     string               `json:"payment_id"`
+	Amount        float64              `json:"amount"`
+	Currency      string               `json:"currency"`
+	Reason        string               `json:"reason"`
+	Status        string               `json:"status"`
+	TransactionID string               `json:"transaction_id"`
+	Restocked     bool                 `json:"restocked"`
+	Items         []RefundItemResponse `json:"items"`
+	CreatedAt     time.Time            `json:"created_at"`
+}
+
+type RefundItemResponse struct {
+	OrderItemID string  `json:"order_item_id"`
+	Quantity    int     `json:"quantity"`
+	Amount      float64 `json:"amount"`
+}
+
+type CreateRefundResponse struct {
+	Refund RefundResponse           `json:"refund"`
+	Order  AdminOrderDetailResponse `json:"order"`
+}
+
+// ============================================
+// ADMIN DISCOUNTS DTOs
+// ============================================
+
+type AdminDiscountListRequest struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// ============================================
+// ADMIN PROMOTIONS DTOs
+// ============================================
+
+type AdminPromotionListRequest struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Type     string `form:"type" binding:"omitempty,oneof=banner popup sidebar"`
+	Position string `form:"position" binding:"omitempty,oneof=top bottom left right center"`
+	IsActive *bool  `form:"is_active"`
+}
+
+// ============================================
+// ADMIN TAX RULES DTOs
+// ============================================
+
+type AdminTaxRuleListRequest struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Country  string `form:"country" binding:"omitempty,max=100"`
+	State    string `form:"state" binding:"omitempty,max=100"`
+	IsActive *bool  `form:"is_active"`
+}
+
+// CreateTaxRuleRequest defines a tax rate for a country, or for one state of
+// a country when state is given. Rate is a percentage.
+type CreateTaxRuleRequest struct {
+	Name      string  `json:"name" binding:"required,max=100"`
+	Country   string  `json:"country" binding:"required,max=100"`
+	State     string  `json:"state" binding:"omitempty,max=100"`
+	Rate      float64 `json:"rate" binding:"min=0,max=100"`
+	Inclusive bool    `json:"inclusive"`
+	IsActive  *bool   `json:"is_active"`
+}
+
+type UpdateTaxRuleRequest struct {
+	Name      *string  `json:"name" binding:"omitempty,max=100"`
+	Country   *string  `json:"country" binding:"omitempty,min=1,max=100"`
+	State     *string  `json:"state" binding:"omitempty,max=100"`
+	Rate      *float64 `json:"rate" binding:"omitempty,min=0,max=100"`
+	Inclusive *bool    `json:"inclusive"`
+	IsActive  *bool    `json:"is_active"`
+}
+
+type TaxRuleResponse struct {
+	ResourceID string    `json:"resource_id"`
+	Name       string    `json:"name"`
+	Country    string    `json:"country"`
+	State      string    `json:"state"`
+	Rate       float64   `json:"rate"`
+	Inclusive  bool      `json:"inclusive"`
+	IsActive   bool      `json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type TaxRuleListResponse struct {
+	TaxRules []TaxRuleResponse `json:"tax_rules"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	Limit    int               `json:"limit"`
+}
+
+// ============================================
+// ADMIN SHIPPING DTOs
+// ============================================
+
+// ShippingRegionRequest is a country, or one state of a country, covered by a
+// zone. Country "*" matches any destination not covered by another zone.
+type ShippingRegionRequest struct {
+	Country string `json:"country" binding:"required,max=100"`
+	State   string `json:"state" binding:"omitempty,max=100"`
+}
+
+type CreateShippingZoneRequest struct {
+	Name     string                  `json:"name" binding:"required,max=100"`
+	Regions  []ShippingRegionRequest `json:"regions" binding:"required,min=1,dive"`
+	IsActive *bool                   `json:"is_active"`
+}
+
+// UpdateShippingZoneRequest replaces the zone's regions when regions is given
+type UpdateShippingZoneRequest struct {
+	Name     *string                 `json:"name" binding:"omitempty,min=1,max=100"`
+	Regions  []ShippingRegionRequest `json:"regions" binding:"omitempty,min=1,dive"`
+	IsActive *bool                   `json:"is_active"`
+}
+
+// ShippingRateTierRequest prices weight-based shipments from min_weight (kg,
+// inclusive) up to max_weight (exclusive, omitted for no upper bound)
+type ShippingRateTierRequest struct {
+	MinWeight float64  `json:"min_weight" binding:"min=0"`
+	MaxWeight *float64 `json:"max_weight" binding:"omitempty,gt=0"`
+	Rate      float64  `json:"rate" binding:"min=0"`
+}
+
+type CreateShippingMethodRequest struct {
+	Name          string                    `json:"name" binding:"required,max=100"`
+	Type          string                    `json:"type" binding:"required,oneof=flat_rate weight_based free_over"`
+	Rate          float64                   `json:"rate" binding:"min=0"`
+	FreeThreshold *float64                  `json:"free_threshold" binding:"omitempty,min=0"`
+	Tiers         []ShippingRateTierRequest `json:"tiers" binding:"omitempty,dive"`
+	EstimatedDays string                    `json:"estimated_days" binding:"omitempty,max=50"`
+	SortOrder     int                       `json:"sort_order"`
+	IsActive      *bool                     `json:"is_active"`
+}
+
+// UpdateShippingMethodRequest replaces the method's rate tiers when tiers is given
+type UpdateShippingMethodRequest struct {
+	Name          *string                   `json:"name" binding:"omitempty,min=1,max=100"`
+	Type          *string                   `json:"type" binding:"omitempty,oneof=flat_rate weight_based free_over"`
+	Rate          *float64                  `json:"rate" binding:"omitempty,min=0"`
+	FreeThreshold *float64                  `json:"free_threshold" binding:"omitempty,min=0"`
+	Tiers         []ShippingRateTierRequest `json:"tiers" binding:"omitempty,dive"`
+	EstimatedDays *string                   `json:"estimated_days" binding:"omitempty,max=50"`
+	SortOrder     *int                      `json:"sort_order"`
+	IsActive      *bool                     `json:"is_active"`
+}
+
+type ShippingZoneResponse struct {
+	ResourceID string                   `json:"resource_id"`
+	Name       string                   `json:"name"`
+	IsActive   bool                     `json:"is_active"`
+	Regions    []ShippingRegionResponse `json:"regions"`
+	Methods    []ShippingMethodResponse `json:"methods"`
+	CreatedAt  time.Time                `json:"created_at"`
+	UpdatedAt  time.Time                `json:"updated_at"`
+}
+
+type ShippingRegionResponse struct {
+	Country string `json:"country"`
+	State   string `json:"state,omitempty"`
+}
+
+type ShippingMethodResponse struct {
+	ResourceID    string                     `json:"resource_id"`
+	Name          string                     `json:"name"`
+	Type          string                     `json:"type"`
+	Rate          float64                    `json:"rate"`
+	FreeThreshold *float64                   `json:"free_threshold,omitempty"`
+	Tiers         []ShippingRateTierResponse `json:"tiers,omitempty"`
+	EstimatedDays string                     `json:"estimated_days"`
+	SortOrder     int                        `json:"sort_order"`
+	IsActive      bool                       `json:"is_active"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	UpdatedAt     time.Time                  `json:"updated_at"`
+}
+
+type ShippingRateTierResponse struct {
+	MinWeight float64  `json:"min_weight"`
+	MaxWeight *float64 `json:"max_weight"`
+	Rate      float64  `json:"rate"`
 }
 
 // ============================================
@@ -145,8 +358,9 @@ type AdminUserResponse struct {
 	Phone      string    `json:"phone"`
 	Avatar     string    `json:"avatar"`
 	IsActive   bool      `json:"is_active"`
-	IsAdmin    bool      `json:"is_admin"`
+	IsAdmin    bool      `json:"is_admin"` // holds at least one role
 	IsVerified bool      `json:"is_verified"`
+	Roles      []string  `json:"roles"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 	LastLoginAt *time.Time `json:"last_login_at"`
@@ -159,7 +373,19 @@ type UpdateUserRequest struct {
 	LastName  *string `json:"last_name" binding:"omitempty,min=1,max=50"`
 	Phone     *string `json:"phone" binding:"omitempty,max=20"`
 	IsActive  *bool   `json:"is_active"`
-	IsAdmin   *bool   `json:"is_admin"`
+}
+
+// SetUserRolesRequest replaces the roles of a user. An empty list removes
+// their admin access.
+type SetUserRolesRequest struct {
+	Roles []string `json:"roles" binding:"required,dive,min=1,max=50"`
+}
+
+type RoleResponse struct {
+	ResourceID  string   `json:"resource_id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
 }
 
 type AdminUserDetailResponse struct {
@@ -184,5 +410,122 @@ type AdminCategoryListResponse struct {
 	Limit      int                `json:"limit"`
 }
 
+// ============================================
+// ADMIN AUDIT LOG DTOs
+// ============================================
+
+// AdminAuditLogListRequest filters the audit log. From and To are RFC 3339
+// timestamps; To is exclusive.
+type AdminAuditLogListRequest struct {
+	Page       int        `form:"page" binding:"omitempty,min=1"`
+	Limit      int        `form:"limit" binding:"omitempty,min=1,max=100"`
+	Actor      string     `form:"actor"` // user resource ID
+	Action     string     `form:"action" binding:"omitempty,max=100"`
+	EntityType string     `form:"entity_type" binding:"omitempty,max=50"`
+	EntityID   string     `form:"entity_id" binding:"omitempty,max=100"`
+	RequestID  string     `form:"request_id" binding:"omitempty,max=64"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type AuditLogResponse struct {
+	ID         uint            `json:"id"`
+	Actor      *UserSummary    `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before" swaggertype:"object"`
+	After      json.RawMessage `json:"after" swaggertype:"object"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	StatusCode int             `json:"status_code"`
+	IPAddress  string          `json:"ip_address"`
+	UserAgent  string          `json:"user_agent"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type AuditLogListResponse struct {
+	AuditLogs []AuditLogResponse `json:"audit_logs"`
+	Total     int64              `json:"total"`
+	Page      int                `json:"page"`
+	Limit     int                `json:"limit"`
+}
+
+// JobStatusResponse reports a background job as seen by the instance that
+// served the request; counters start at zero when the instance starts
+type JobStatusResponse struct {
+	Name           string     `json:"name"`
+	Schedule       string     `json:"schedule"`
+	Running        bool       `json:"running"`
+	Runs           int64      `json:"runs"`
+	Failures       int64      `json:"failures"`
+	Skipped        int64      `json:"skipped"` // due runs another instance took
+	LastRunAt      *time.Time `json:"last_run_at"`
+	LastSuccessAt  *time.Time `json:"last_success_at"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextRunAt      *time.Time `json:"next_run_at"`
+}
+
+type JobListResponse struct {
+	Enabled bool                `json:"enabled"`
+	Jobs    []JobStatusResponse `json:"jobs"`
+}
+
+type AdminEmailListRequest struct {
+	Page   int    `form:"page" binding:"omitempty,min=1"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Status  string `form:"status" binding:"omitempty,oneof=pending sending sent dead"`
+	Channel string `form:"channel" binding:"omitempty,oneof=email webhook"`
+}
+
+// OutboxEmailResponse is the delivery status of a queued email
+type OutboxEmailResponse struct {
+	ResourceID    string     `json:"resource_id"`
+	Channel       string     `json:"channel"`
+	Kind          string     `json:"kind"`
+	ToEmail       string     `json:"to_email,omitempty"` // empty for webhook calls
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"` // only while pending
+	LastError     string     `json:"last_error,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type OutboxEmailListResponse struct {
+	Emails []OutboxEmailResponse `json:"emails"`
+	Total  int64                 `json:"total"`
+	Page   int                   `json:"page"`
+	Limit  int                   `json:"limit"`
+}
+
+// EmailTemplateResponse describes an email of the template registry
+type EmailTemplateResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type EmailTemplateListResponse struct {
+	Templates []EmailTemplateResponse `json:"templates"`
+}
+
+// EmailPreviewRequest picks how a preview is returned: json has the subject
+// and both bodies, html and text return the body alone
+type EmailPreviewRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=json html text"`
+}
+
+// EmailPreviewResponse is an email template rendered with sample data
+type EmailPreviewResponse struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
 // Note: SuccessResponse and ErrorResponse are defined in auth_dto.go
 
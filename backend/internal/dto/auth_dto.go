@@ -95,8 +95,16 @@ func (g *GoogleOAuthExchangeRequest) Validate() error {
 	return nil
 }
 
+// RefreshTokenRequest may omit the token when it is sent as the
+// refresh_token cookie
 type RefreshTokenRequest struct {
-	RefreshToken string `json:"refresh_token" binding:"required"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutRequest names the session to end; without a token the
+// refresh_token cookie is used
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 type UpdateProfileRequest struct {
@@ -125,6 +133,9 @@ type AuthResponse struct {
 	AccessToken  string       `json:"access_token"`
 	RefreshToken string       `json:"refresh_token"`
 	Message      string       `json:"message,omitempty"`
+	// OTPChallengeID identifies the email verification code sent on
+	// registration
+	OTPChallengeID string `json:"otp_challenge_id,omitempty"`
 }
 
 type UserResponse struct {
@@ -139,9 +150,10 @@ type UserResponse struct {
 }
 
 type TokenResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	ExpiresIn    int    `json:"expires_in"`
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int    `json:"expires_in"`         // access token lifetime in seconds
+	RefreshExpiresIn int    `json:"refresh_expires_in"` // refresh token lifetime in seconds
 }
 
 // Common response structures
@@ -193,9 +205,10 @@ type SendOTPRequest struct {
 }
 
 type VerifyOTPRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	OTPCode  string `json:"otp_code" binding:"required,len=6"`
-	Type     string `json:"type" binding:"required,oneof=email_verification password_reset login"`
+	ChallengeID string `json:"challenge_id" binding:"required"` // from the response that sent the code
+	Email       string `json:"email" binding:"required,email"`
+	OTPCode     string `json:"otp_code" binding:"required,len=6"`
+	Type        string `json:"type" binding:"required,oneof=email_verification password_reset login"`
 }
 
 type ResendOTPRequest struct {
@@ -204,6 +217,7 @@ type ResendOTPRequest struct {
 }
 
 type PasswordResetRequest struct {
+	ChallengeID     string `json:"challenge_id" binding:"required"` // from /auth/forgot-password
 	Email           string `json:"email" binding:"required,email"`
 	OTPCode         string `json:"otp_code" binding:"required,len=6"`
 	NewPassword     string `json:"new_password" binding:"required,min=8"`
@@ -221,8 +235,9 @@ func (p *PasswordResetRequest) Validate() error {
 }
 
 type OTPResponse struct {
-	Message   string `json:"message"`
-	ExpiresIn int    `json:"expires_in"` // in seconds
+	Message     string `json:"message"`
+	ChallengeID string `json:"challenge_id"` // send back with the code to verify it
+	ExpiresIn   int    `json:"expires_in"`   // in seconds
 }
 
 type VerifyOTPResponse struct {
@@ -231,3 +246,39 @@ type VerifyOTPResponse struct {
 	AccessToken  string `json:"access_token,omitempty"`
 	RefreshToken string `json:"refresh_token,omitempty"`
 }
+
+// TwoFactorChallengeResponse answers a login whose account has two-factor
+// authentication enabled; no session is started until the challenge token
+// is sent to /auth/2fa/login with a code
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"` // in seconds
+	Message           string `json:"message"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"` // TOTP code or recovery code
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"` // TOTP code or recovery code
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled           bool  `json:"enabled"`
+	Required          bool  `json:"required"` // admins must enable it to use the admin API
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	Message    string `json:"message"`
+}
+
+type TwoFactorRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Message       string   `json:"message"`
+}
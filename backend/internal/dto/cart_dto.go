@@ -5,24 +5,78 @@ import (
 )
 
 // Cart DTOs
+
+// CartResponse is a cart priced at current prices. Lines are checked
+// against the catalog on every read, so HasChanges and CanCheckout reflect
+// the products as they are now.
 type CartResponse struct {
-	ResourceID string         `json:"resource_id"`
-	UserID     *uint          `json:"user_id"`
-	SessionID  string         `json:"session_id"`
-	Items      []CartItemResponse `json:"items,omitempty"`
-	TotalItems int            `json:"total_items"`
-	Subtotal   float64        `json:"subtotal"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
+	ResourceID     string                `json:"resource_id"`
+	Items          []CartItemResponse    `json:"items"`
+	ItemsCount     int                   `json:"itemsCount"`  // number of lines
+	TotalItems     int                   `json:"total_items"` // sum of line quantities
+	Subtotal       float64               `json:"subtotal"`
+	Discount       *CartDiscountResponse `json:"discount"`
+	DiscountAmount float64               `json:"discount_amount"`
+	Total          float64               `json:"total"`
+	HasChanges     bool                  `json:"has_changes"`  // a line changed price or can no longer be ordered as is
+	CanCheckout    bool                  `json:"can_checkout"` // the cart has lines and none of them has an issue
+	// GuestToken is the signed token of a guest's cart, which the handler
+	// sets as the cart cookie; it is empty for a user's cart
+	GuestToken string `json:"-"`
 }
 
+// Cart line issues
+const (
+	CartIssueUnavailable       = "unavailable" // the product or variant is no longer sold
+	CartIssueOutOfStock        = "out_of_stock"
+	CartIssueInsufficientStock = "insufficient_stock" // fewer left than the line's quantity
+)
+
 type CartItemResponse struct {
-    ID        uint   `json:"id"`
-    ProductID uint   `json:"product_id"`
-    VariantID *uint  `json:"variant_id,omitempty"`
-    Quantity  int    `json:"quantity"`
-    Price     int    `json:"price"`
-    // Product and Variant short info option here...
+	ID                uint                 `json:"id"`
+	ProductID         uint                 `json:"product_id"`
+	VariantID         *uint                `json:"variant_id,omitempty"`
+	Quantity          int                  `json:"quantity"`
+	Price             float64              `json:"price"` // current unit price
+	Total             float64              `json:"total"`
+	PriceChanged      bool                 `json:"price_changed"`
+	PreviousPrice     *float64             `json:"previous_price,omitempty"`     // unit price when added, set when it changed since
+	Available         bool                 `json:"available"`                    // the line can be ordered as it is
+	Issue             string               `json:"issue,omitempty"`              // why it can't, when Available is false
+	AvailableQuantity *int                 `json:"available_quantity,omitempty"` // stock left, set for stock issues
+	Product           CartProductResponse  `json:"product"`
+	Variant           *CartVariantResponse `json:"variant,omitempty"`
+}
+
+type CartProductResponse struct {
+	ID           uint    `json:"id"`
+	ResourceID   string  `json:"resource_id"`
+	Name         string  `json:"name"`
+	Image        string  `json:"image"`
+	Price        float64 `json:"price"`
+	ComparePrice float64 `json:"compare_price"`
+	SKU          string  `json:"sku"`
+}
+
+type CartVariantResponse struct {
+	ID         uint              `json:"id"`
+	ResourceID string            `json:"resource_id"`
+	Name       string            `json:"name"`
+	SKU        string            `json:"sku"`
+	Price      float64           `json:"price"`
+	Options    map[string]string `json:"options,omitempty"`
+	Image      string            `json:"image,omitempty"`
+}
+
+// CartDiscountResponse is the code applied to a cart. It is quoted again on
+// every read; Message says why it no longer applies when Valid is false.
+type CartDiscountResponse struct {
+	Code    string  `json:"code"`
+	Valid   bool    `json:"valid"`
+	Name    string  `json:"name,omitempty"`
+	Type    string  `json:"type,omitempty"`
+	Message string  `json:"message,omitempty"`
+	Amount  float64 `json:"amount,omitempty"`
 }
 
 type AddToCartRequest struct {
@@ -77,29 +131,71 @@ type DiscountResponse struct {
 }
 
 type CreateDiscountRequest struct {
-	Name            string     `json:"name" validate:"required,min=1,max=100"`
-	Code            string     `json:"code" validate:"max=50"`
-	Type            string     `json:"type" validate:"required,oneof=percentage fixed_amount free_shipping"`
-	Value           float64    `json:"value" validate:"required,min=0"`
-	MinimumAmount   float64    `json:"minimum_amount" validate:"min=0"`
-	MaximumDiscount *float64   `json:"maximum_discount" validate:"omitempty,min=0"`
-	UsageLimit      *int       `json:"usage_limit" validate:"omitempty,min=1"`
+	Name            string     `json:"name" binding:"required,min=1,max=100"`
+	Code            string     `json:"code" binding:"required,max=50"`
+	Type            string     `json:"type" binding:"required,oneof=percentage fixed_amount free_shipping"`
+	Value           float64    `json:"value" binding:"min=0"`
+	MinimumAmount   float64    `json:"minimum_amount" binding:"min=0"`
+	MaximumDiscount *float64   `json:"maximum_discount" binding:"omitempty,min=0"`
+	UsageLimit      *int       `json:"usage_limit" binding:"omitempty,min=1"`
 	IsActive        bool       `json:"is_active"`
 	StartsAt        *time.Time `json:"starts_at"`
 	ExpiresAt       *time.Time `json:"expires_at"`
 }
 
+// UpdateDiscountRequest changes the fields that are given. The cap, usage
+// limit and date window are removed with their Clear flag, as leaving them
+// out keeps them unchanged.
 type UpdateDiscountRequest struct {
-	Name            *string    `json:"name" validate:"omitempty,min=1,max=100"`
-	Code            *string    `json:"code" validate:"omitempty,max=50"`
-	Type            *string    `json:"type" validate:"omitempty,oneof=percentage fixed_amount free_shipping"`
-	Value           *float64   `json:"value" validate:"omitempty,min=0"`
-	MinimumAmount   *float64   `json:"minimum_amount" validate:"omitempty,min=0"`
-	MaximumDiscount *float64   `json:"maximum_discount" validate:"omitempty,min=0"`
-	UsageLimit      *int       `json:"usage_limit" validate:"omitempty,min=1"`
+	Name            *string    `json:"name" binding:"omitempty,min=1,max=100"`
+	Code            *string    `json:"code" binding:"omitempty,max=50"`
+	Type            *string    `json:"type" binding:"omitempty,oneof=percentage fixed_amount free_shipping"`
+	Value           *float64   `json:"value" binding:"omitempty,min=0"`
+	MinimumAmount   *float64   `json:"minimum_amount" binding:"omitempty,min=0"`
+	MaximumDiscount *float64   `json:"maximum_discount" binding:"omitempty,min=0"`
+	UsageLimit      *int       `json:"usage_limit" binding:"omitempty,min=1"`
 	IsActive        *bool      `json:"is_active"`
 	StartsAt        *time.Time `json:"starts_at"`
 	ExpiresAt       *time.Time `json:"expires_at"`
+
+	ClearMaximumDiscount bool `json:"clear_maximum_discount"`
+	ClearUsageLimit      bool `json:"clear_usage_limit"`
+	ClearStartsAt        bool `json:"clear_starts_at"`
+	ClearExpiresAt       bool `json:"clear_expires_at"`
+}
+
+type DiscountListResponse struct {
+	Discounts []DiscountResponse `json:"discounts"`
+	Total     int64              `json:"total"`
+	Page      int                `json:"page"`
+	Limit     int                `json:"limit"`
+}
+
+type ApplyDiscountRequest struct {
+	Code string `json:"code" binding:"required,max=50"`
+}
+
+// Shipping option DTOs
+
+// ShippingOptionsRequest selects the destination to quote; the user's default
+// address is used when address_id is omitted
+type ShippingOptionsRequest struct {
+	AddressID string `form:"address_id" binding:"omitempty,max=36"`
+}
+
+type ShippingOptionsResponse struct {
+	RequiresShipping bool                     `json:"requires_shipping"`
+	Weight           float64                  `json:"weight"` // billable weight in kg
+	Options          []ShippingOptionResponse `json:"options"`
+}
+
+type ShippingOptionResponse struct {
+	MethodID      string  `json:"method_id"`
+	Name          string  `json:"name"`
+	Type          string  `json:"type"`
+	Zone          string  `json:"zone"`
+	Cost          float64 `json:"cost"`
+	EstimatedDays string  `json:"estimated_days"`
 }
 
 // Promotion DTOs
@@ -108,6 +204,7 @@ type PromotionResponse struct {
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	Image       string     `json:"image"`
+	LinkURL     string     `json:"link_url"`
 	Type        string     `json:"type"`
 	Position    string     `json:"position"`
 	IsActive    bool       `json:"is_active"`
@@ -118,23 +215,38 @@ type PromotionResponse struct {
 }
 
 type CreatePromotionRequest struct {
-	Title       string     `json:"title" validate:"required,min=1,max=200"`
+	Title       string     `json:"title" binding:"required,min=1,max=200"`
 	Description string     `json:"description"`
-	Image       string     `json:"image" validate:"max=500"`
-	Type        string     `json:"type" validate:"required,oneof=banner popup sidebar"`
-	Position    string     `json:"position" validate:"required,oneof=top bottom left right center"`
+	Image       string     `json:"image" binding:"max=500"`
+	LinkURL     string     `json:"link_url" binding:"max=500"`
+	Type        string     `json:"type" binding:"required,oneof=banner popup sidebar"`
+	Position    string     `json:"position" binding:"required,oneof=top bottom left right center"`
 	IsActive    bool       `json:"is_active"`
 	StartsAt    *time.Time `json:"starts_at"`
 	ExpiresAt   *time.Time `json:"expires_at"`
 }
 
 type UpdatePromotionRequest struct {
-	Title       *string    `json:"title" validate:"omitempty,min=1,max=200"`
+	Title       *string    `json:"title" binding:"omitempty,min=1,max=200"`
 	Description *string    `json:"description"`
-	Image       *string    `json:"image" validate:"omitempty,max=500"`
-	Type        *string    `json:"type" validate:"omitempty,oneof=banner popup sidebar"`
-	Position    *string    `json:"position" validate:"omitempty,oneof=top bottom left right center"`
+	Image       *string    `json:"image" binding:"omitempty,max=500"`
+	LinkURL     *string    `json:"link_url" binding:"omitempty,max=500"`
+	Type        *string    `json:"type" binding:"omitempty,oneof=banner popup sidebar"`
+	Position    *string    `json:"position" binding:"omitempty,oneof=top bottom left right center"`
 	IsActive    *bool      `json:"is_active"`
 	StartsAt    *time.Time `json:"starts_at"`
 	ExpiresAt   *time.Time `json:"expires_at"`
 }
+
+type PromotionListResponse struct {
+	Promotions []PromotionResponse `json:"promotions"`
+	Total      int64               `json:"total"`
+	Page       int                 `json:"page"`
+	Limit      int                 `json:"limit"`
+}
+
+// ActivePromotionsRequest filters the public promotions listing
+type ActivePromotionsRequest struct {
+	Type     string `form:"type" binding:"omitempty,oneof=banner popup sidebar"`
+	Position string `form:"position" binding:"omitempty,oneof=top bottom left right center"`
+}
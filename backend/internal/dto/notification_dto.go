@@ -0,0 +1,40 @@
+package dto
+
+import "time"
+
+// Notification DTOs
+
+type NotificationListRequest struct {
+	Page   int  `form:"page" binding:"omitempty,min=1"`
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Unread bool `form:"unread"`
+}
+
+type NotificationResponse struct {
+	ResourceID string     `json:"resource_id"`
+	Kind       string     `json:"kind"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body,omitempty"`
+	Link       string     `json:"link,omitempty"`
+	Read       bool       `json:"read"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NotificationListResponse is a page of the inbox. Unread counts all unread
+// notifications, whatever the page and filter.
+type NotificationListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	Total         int64                  `json:"total"`
+	Unread        int64                  `json:"unread"`
+	Page          int                    `json:"page"`
+	Limit         int                    `json:"limit"`
+}
+
+type NotificationUnreadCountResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+type NotificationMarkAllReadResponse struct {
+	Marked int64 `json:"marked"`
+}
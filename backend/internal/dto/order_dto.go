@@ -7,22 +7,21 @@ import (
 
 // Order DTOs
 
+// CreateOrderRequest places an order for the current contents of the user's cart.
+// Line prices, shipping, tax and totals are always computed on the server.
+// Shipping and tax are based on the shipping address, which defaults to the
+// user's default shipping address. The billing address defaults to the
+// user's default billing address, then to the shipping address. Both are
+// copied onto the order. A shipping method from GET /cart/shipping-options
+// is required unless nothing in the cart needs shipping.
 type CreateOrderRequest struct {
-	Subtotal       float64 `json:"subtotal" binding:"required,min=0"`
-	TaxAmount      float64 `json:"tax_amount" binding:"min=0"`
-	ShippingCost   float64 `json:"shipping_cost" binding:"min=0"`
-	DiscountAmount float64 `json:"discount_amount" binding:"min=0"`
-	Total          float64 `json:"total" binding:"required,min=0"`
-	Currency       string  `json:"currency" binding:"required,len=3"`
-	Notes          string  `json:"notes" binding:"omitempty,max=500"`
+	ShippingAddressID string `json:"shipping_address_id" binding:"omitempty,max=36"`
+	BillingAddressID  string `json:"billing_address_id" binding:"omitempty,max=36"`
+	ShippingMethodID  string `json:"shipping_method_id" binding:"omitempty,max=36"`
+	Notes             string `json:"notes" binding:"omitempty,max=500"`
 }
 
 func (c *CreateOrderRequest) Validate() error {
-	// Validate currency
-	if len(c.Currency) != 3 {
-		return errors.New("currency must be 3 characters")
-	}
-
 	// Validate notes length
 	if len(c.Notes) > 500 {
 		return errors.New("notes must be less than 500 characters")
@@ -31,17 +30,23 @@ func (c *CreateOrderRequest) Validate() error {
 	return nil
 }
 
+// UpdateOrderRequest lists the changes a customer may request on their own order.
+// The only status a customer can set is "cancelled"; payment and fulfilment
+// statuses are managed through the admin endpoints.
 type UpdateOrderRequest struct {
-	Status        *string `json:"status" binding:"omitempty,oneof=pending confirmed shipped delivered cancelled"`
-	PaymentStatus *string `json:"payment_status" binding:"omitempty,oneof=pending paid failed refunded"`
-	Notes         *string `json:"notes" binding:"omitempty,max=500"`
+	Status *string `json:"status" binding:"omitempty,oneof=cancelled"`
+	Notes  *string `json:"notes" binding:"omitempty,max=500"`
+}
+
+type CancelOrderRequest struct {
+	Reason string `json:"reason" binding:"omitempty,max=500"`
 }
 
 type ProcessPaymentRequest struct {
-	Method         string `json:"method" binding:"required,oneof=stripe paypal bank_transfer"`
-	PaymentToken   string `json:"payment_token" binding:"required"`
-	ReturnURL      string `json:"return_url" binding:"omitempty,url"`
-	CancelURL      string `json:"cancel_url" binding:"omitempty,url"`
+	Method       string `json:"method" binding:"required,oneof=stripe paypal bank_transfer"`
+	PaymentToken string `json:"payment_token" binding:"required"`
+	ReturnURL    string `json:"return_url" binding:"omitempty,url"`
+	CancelURL    string `json:"cancel_url" binding:"omitempty,url"`
 }
 
 func (p *ProcessPaymentRequest) Validate() error {
@@ -64,36 +69,68 @@ func (p *ProcessPaymentRequest) Validate() error {
 }
 
 type OrderResponse struct {
-	ResourceID     string    `json:"resource_id"`
-	OrderNumber    string    `json:"order_number"`
-	UserID         uint      `json:"user_id"`
-	Status         string    `json:"status"`
-	PaymentStatus  string    `json:"payment_status"`
-	Subtotal       float64   `json:"subtotal"`
-	TaxAmount      float64   `json:"tax_amount"`
-	ShippingCost   float64   `json:"shipping_cost"`
-	DiscountAmount float64   `json:"discount_amount"`
-	Total          float64   `json:"total"`
-	Currency       string    `json:"currency"`
-	Notes          string    `json:"notes"`
-	ShippedAt      *string   `json:"shipped_at"`
-	DeliveredAt    *string   `json:"delivered_at"`
-	CreatedAt      string    `json:"created_at"`
-	UpdatedAt      string    `json:"updated_at"`
+	ResourceID      string                `json:"resource_id"`
+	OrderNumber     string                `json:"order_number"`
+	UserID          uint                  `json:"user_id"`
+	Status          string                `json:"status"`
+	PaymentStatus   string                `json:"payment_status"`
+	Subtotal        float64               `json:"subtotal"`
+	TaxAmount       float64               `json:"tax_amount"`
+	ShippingCost    float64               `json:"shipping_cost"`
+	ShippingMethod  string                `json:"shipping_method,omitempty"`
+	ShippingAddress *OrderAddressResponse `json:"shipping_address,omitempty"`
+	BillingAddress  *OrderAddressResponse `json:"billing_address,omitempty"`
+	DiscountAmount  float64               `json:"discount_amount"`
+	DiscountCode    string                `json:"discount_code,omitempty"`
+	Total           float64               `json:"total"`
+	Currency        string                `json:"currency"`
+	Notes           string                `json:"notes"`
+	Taxes           []OrderTaxResponse    `json:"taxes,omitempty"`
+	Carrier         string                `json:"carrier,omitempty"`
+	TrackingNumber  string                `json:"tracking_number,omitempty"`
+	TrackingURL     string                `json:"tracking_url,omitempty"`
+	ShippedAt       *string               `json:"shipped_at"`
+	DeliveredAt     *string               `json:"delivered_at"`
+	CreatedAt       string                `json:"created_at"`
+	UpdatedAt       string                `json:"updated_at"`
 }
 
 type OrderDetailResponse struct {
 	OrderResponse
-	OrderItems []OrderItemResponse `json:"order_items"`
+	OrderItems    []OrderItemResponse          `json:"order_items"`
+	StatusHistory []OrderStatusHistoryResponse `json:"status_history"`
+}
+
+type OrderStatusHistoryResponse struct {
+	ResourceID string    `json:"resource_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ActorID    *uint     `json:"actor_id"`
+	ActorRole  string    `json:"actor_role"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type OrderItemResponse struct {
-	ResourceID string                    `json:"resource_id"`
-	Product    ProductSummaryResponse    `json:"product"`
-	Variant    *VariantResponse          `json:"variant,omitempty"`
-	Quantity   int                       `json:"quantity"`
-	Price      float64                   `json:"price"`
-	Total      float64                   `json:"total"`
+	ResourceID string                 `json:"resource_id"`
+	Product    ProductSummaryResponse `json:"product"`
+	Variant    *VariantResponse       `json:"variant,omitempty"`
+	Quantity   int                    `json:"quantity"`
+	Price      float64                `json:"price"`
+	Total      float64                `json:"total"`
+	TaxAmount  float64                `json:"tax_amount"`
+}
+
+// OrderTaxResponse is the tax one rule added to an order. Inclusive taxes are
+// already contained in the line prices and are not added to the total.
+type OrderTaxResponse struct {
+	Name          string  `json:"name"`
+	Country       string  `json:"country"`
+	State         string  `json:"state,omitempty"`
+	Rate          float64 `json:"rate"`
+	Inclusive     bool    `json:"inclusive"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	Amount        float64 `json:"amount"`
 }
 
 type ProductSummaryResponse struct {
@@ -104,7 +141,6 @@ type ProductSummaryResponse struct {
 	Image      string  `json:"image,omitempty"` // Primary product image URL
 }
 
-
 type OrderListResponse struct {
 	Orders []OrderResponse `json:"orders"`
 	Total  int64           `json:"total"`
@@ -123,6 +159,10 @@ type PaymentResponse struct {
 	CreatedAt     time.Time  `json:"created_at"`
 }
 
+type PayOrderResponse struct {
+	Order   OrderResponse   `json:"order"`
+	Payment PaymentResponse `json:"payment"`
+}
 
 // Pagination DTOs
 
@@ -143,4 +183,3 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
-
@@ -26,6 +26,7 @@ type ProductResponse struct {
 	CategoryID   uint      `json:"category_id"`
 	Category     CategoryResponse `json:"category,omitempty"`
 	Images       []ImageResponse  `json:"images,omitempty"`
+	Options      []ProductOptionResponse `json:"options,omitempty"`
 	Variants     []VariantResponse `json:"variants,omitempty"`
 	Reviews      []ReviewResponse  `json:"reviews,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
@@ -222,31 +223,97 @@ type MultipleUploadResponse struct {
 }
 
 // Variant DTOs
+
+// VariantResponse is a variant with the value it has for each of the
+// product's options, keyed by option name
 type VariantResponse struct {
-	ResourceID    string    `json:"resource_id"`
-	ProductID     uint      `json:"product_id"`
-	Name          string    `json:"name"`
-	SKU           string    `json:"sku"`
-	Price         float64   `json:"price"`
-	ComparePrice  float64   `json:"compare_price"`
-	CostPrice     float64   `json:"cost_price"`
-	StockQuantity int       `json:"stock_quantity"`
-	Weight        float64   `json:"weight"`
-	IsActive      bool      `json:"is_active"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            uint                   `json:"id"`
+	ResourceID    string                 `json:"resource_id"`
+	ProductID     uint                   `json:"product_id"`
+	Name          string                 `json:"name"`
+	SKU           string                 `json:"sku"`
+	Price         float64                `json:"price"`
+	ComparePrice  float64                `json:"compare_price"`
+	CostPrice     float64                `json:"cost_price"`
+	StockQuantity int                    `json:"stock_quantity"`
+	InStock       bool                   `json:"in_stock"`
+	Weight        float64                `json:"weight"`
+	IsActive      bool                   `json:"is_active"`
+	Options       map[string]string      `json:"options,omitempty"`
+	Images        []VariantImageResponse `json:"images,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+type VariantImageResponse struct {
+	ResourceID string `json:"resource_id"`
+	URL        string `json:"url"`
+	Alt        string `json:"alt"`
+	SortOrder  int    `json:"sort_order"`
 }
 
+// CreateVariantRequest adds a variant to a product. On a product with
+// options, Options must give a value of every option, e.g.
+// {"Color": "Black", "Storage": "256GB"}, and Name defaults to those values.
+// A price of 0 sells the variant at the product's price.
 type CreateVariantRequest struct {
-	ProductID     uint    `json:"product_id" validate:"required"`
-	Name          string  `json:"name" validate:"required,min=1,max=255"`
-	SKU           string  `json:"sku" validate:"max=100"`
-	Price         float64 `json:"price" validate:"min=0"`
-	ComparePrice  float64 `json:"compare_price" validate:"min=0"`
-	CostPrice     float64 `json:"cost_price" validate:"min=0"`
-	StockQuantity int     `json:"stock_quantity" validate:"min=0"`
-	Weight        float64 `json:"weight" validate:"min=0"`
-	IsActive      bool    `json:"is_active"`
+	Options       map[string]string     `json:"options"`
+	Name          string                `json:"name" binding:"max=255"`
+	SKU           string                `json:"sku" binding:"required,max=100"`
+	Price         float64               `json:"price" binding:"min=0"`
+	ComparePrice  float64               `json:"compare_price" binding:"min=0"`
+	CostPrice     float64               `json:"cost_price" binding:"min=0"`
+	StockQuantity int                   `json:"stock_quantity" binding:"min=0"`
+	Weight        float64               `json:"weight" binding:"min=0"`
+	IsActive      *bool                 `json:"is_active"`
+	Images        []VariantImageRequest `json:"images" binding:"omitempty,dive"`
+}
+
+// UpdateVariantRequest changes the given fields of a variant. Options and
+// images, when given, replace the existing ones.
+type UpdateVariantRequest struct {
+	Options       map[string]string     `json:"options"`
+	Name          *string               `json:"name" binding:"omitempty,max=255"`
+	SKU           *string               `json:"sku" binding:"omitempty,min=1,max=100"`
+	Price         *float64              `json:"price" binding:"omitempty,min=0"`
+	ComparePrice  *float64              `json:"compare_price" binding:"omitempty,min=0"`
+	CostPrice     *float64              `json:"cost_price" binding:"omitempty,min=0"`
+	StockQuantity *int                  `json:"stock_quantity" binding:"omitempty,min=0"`
+	Weight        *float64              `json:"weight" binding:"omitempty,min=0"`
+	IsActive      *bool                 `json:"is_active"`
+	Images        []VariantImageRequest `json:"images" binding:"omitempty,dive"`
+}
+
+type VariantImageRequest struct {
+	URL       string `json:"url" binding:"required,max=500"`
+	Alt       string `json:"alt" binding:"max=200"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// Product option DTOs
+
+type ProductOptionResponse struct {
+	ResourceID string   `json:"resource_id"`
+	Name       string   `json:"name"`
+	Position   int      `json:"position"`
+	Values     []string `json:"values"`
+}
+
+// CreateProductOptionRequest adds an option such as Color with its values,
+// in display order
+type CreateProductOptionRequest struct {
+	Name     string   `json:"name" binding:"required,min=1,max=100"`
+	Position int      `json:"position"`
+	Values   []string `json:"values" binding:"required,min=1,dive,min=1,max=100"`
+}
+
+// UpdateProductOptionRequest renames or reorders an option. Values, when
+// given, replace the existing ones; a value still used by a variant can't
+// be removed.
+type UpdateProductOptionRequest struct {
+	Name     *string  `json:"name" binding:"omitempty,min=1,max=100"`
+	Position *int     `json:"position"`
+	Values   []string `json:"values" binding:"omitempty,min=1,dive,min=1,max=100"`
 }
 
 // Review DTOs
@@ -332,3 +399,10 @@ type CategoryPageResponse struct {
     Products   []ProductResponse `json:"products"`
     Pagination Pagination        `json:"pagination"`
 }
+
+// StockAlertResponse tells whether the user asked to be emailed once a
+// product is back in stock
+type StockAlertResponse struct {
+	ProductID  string `json:"product_id"`
+	Subscribed bool   `json:"subscribed"`
+}
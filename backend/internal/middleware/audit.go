@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"electronics-store/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditEntityTypes names the entity each admin route group changes
+var auditEntityTypes = map[string]string{
+	"products":   "product",
+	"orders":     "order",
+	"users":      "user",
+	"categories": "category",
+	"brands":     "brand",
+	"discounts":  "discount",
+	"promotions": "promotion",
+	"tax-rules":  "tax_rule",
+	"shipping":   "shipping",
+	"upload":     "image",
+}
+
+// AuditTrail writes an audit log entry for every request that may change
+// data. Handlers and usecases describe their changes with
+// usecase.RecordAudit; a request that records none, including one that
+// failed, is logged as a single entry naming the route and the entity in
+// its :id parameter. It must run after AuthMiddleware.
+func AuditTrail(auditUsecase usecase.AuditUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		req := &usecase.AuditRequest{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: c.GetString("request_id"),
+			Method:    c.Request.Method,
+			Path:      c.FullPath(),
+		}
+		if userID, ok := c.Get("user_id"); ok {
+			id := userID.(uint)
+			req.ActorID = &id
+		}
+		c.Request = c.Request.WithContext(usecase.WithAuditRequest(c.Request.Context(), req))
+
+		c.Next()
+
+		changes := req.Changes()
+		if len(changes) == 0 {
+			changes = []usecase.AuditChange{routeAuditChange(c)}
+		}
+		// The entry is written even if the client has gone away
+		ctx := context.WithoutCancel(c.Request.Context())
+		if err := auditUsecase.Record(ctx, req, c.Writer.Status(), changes); err != nil {
+			log.Printf("audit: failed to record %s %s: %v", req.Method, req.Path, err)
+		}
+	}
+}
+
+// routeAuditChange describes a request from its route alone, e.g.
+// DELETE /api/v1/admin/brands/:id becomes brand.delete of the :id entity
+func routeAuditChange(c *gin.Context) usecase.AuditChange {
+	entityType := ""
+	if _, rest, found := strings.Cut(c.FullPath(), "/admin/"); found {
+		group, _, _ := strings.Cut(rest, "/")
+		entityType = auditEntityTypes[group]
+		if entityType == "" {
+			entityType = group
+		}
+	}
+
+	verb := "update"
+	switch c.Request.Method {
+	case http.MethodPost:
+		verb = "create"
+	case http.MethodDelete:
+		verb = "delete"
+	}
+
+	return usecase.AuditChange{
+		Action:     entityType + "." + verb,
+		EntityType: entityType,
+		EntityID:   c.Param("id"),
+	}
+}
@@ -1,16 +1,19 @@
 package middleware
 
 import (
+    "context"
     "net/http"
     "strings"
 
     "github.com/gin-gonic/gin"
     "github.com/golang-jwt/jwt/v5"
     "electronics-store/internal/dto"
+    "electronics-store/internal/services"
 )
 
-// AuthMiddleware validates JWT token and sets user_id in context
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// AuthMiddleware validates the access token against the JWT keyset and sets
+// user_id in context
+func AuthMiddleware(jwtKeys *services.JWTKeySet) gin.HandlerFunc {
 	return func(c *gin.Context) {
         // Prefer Authorization header; fallback to access_token cookie
         authHeader := c.GetHeader("Authorization")
@@ -40,14 +43,9 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
             }
         }
 
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(jwtSecret), nil
-		})
+		// Parse and validate token; the key and algorithm come from its kid
+		claims := jwt.MapClaims{}
+		token, err := jwtKeys.Parse(tokenString, claims)
 
 		if err != nil || !token.Valid {
 			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
@@ -58,12 +56,11 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-        if !ok {
+		// Refresh tokens are signed by the same keys but never grant access
+		if tokenType, _ := claims["type"].(string); tokenType != "access" {
 			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
 				Error:   "Unauthorized",
-				Message: "Invalid token claims",
+				Message: "Invalid token type",
 			})
 			c.Abort()
 			return
@@ -85,22 +82,129 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
         if isAdmin, ok := claims["is_admin"].(bool); ok {
             c.Set("is_admin", isAdmin)
         }
+		c.Set("two_factor", hasAuthMethod(claims, services.AuthMethodMFA))
 		c.Next()
 	}
 }
 
-// AdminOnly ensures the user is authenticated and has admin privileges
-func AdminOnly(jwtSecret string) gin.HandlerFunc {
-    base := AuthMiddleware(jwtSecret)
-    return func(c *gin.Context) {
-        base(c)
-        if c.IsAborted() {
-            return
-        }
-        if val, exists := c.Get("is_admin"); !exists || val != true {
-            c.JSON(http.StatusForbidden, dto.ErrorResponse{ Error: "Forbidden", Message: "Admin access required" })
-            c.Abort()
-            return
-        }
-    }
+// hasAuthMethod reports whether the token's amr claim lists method
+func hasAuthMethod(claims jwt.MapClaims, method string) bool {
+	amr, _ := claims["amr"].([]interface{})
+	for _, m := range amr {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// OptionalAuthMiddleware authenticates requests that carry an access token
+// like AuthMiddleware and lets requests without one through anonymously. A
+// token that is present but invalid is still rejected, so clients refresh
+// it instead of silently losing the user.
+func OptionalAuthMiddleware(jwtKeys *services.JWTKeySet) gin.HandlerFunc {
+	authenticate := AuthMiddleware(jwtKeys)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if _, err := c.Cookie("access_token"); err != nil {
+				c.Next()
+				return
+			}
+		}
+		authenticate(c)
+	}
+}
+
+// PermissionChecker reports whether a user holds a permission
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID uint, permission string) (bool, error)
+}
+
+// RequirePermission lets the request through only when the authenticated
+// user holds permission through one of their roles. It must run after
+// AuthMiddleware.
+func RequirePermission(checker PermissionChecker, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "User not authenticated",
+			})
+			c.Abort()
+			return
+		}
+
+		allowed, err := checker.HasPermission(c.Request.Context(), userID.(uint), permission)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Failed to check permissions",
+				Message: err.Error(),
+			})
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, dto.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Permission " + permission + " required",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// TwoFactorChecker reports whether a user has two-factor authentication
+// enabled
+type TwoFactorChecker interface {
+	IsEnabled(ctx context.Context, userID uint) (bool, error)
+}
+
+// RequireTwoFactor lets the request through only when the authenticated user
+// has two-factor authentication enabled and the session completed it. It
+// guards the admin API, where 2FA is mandatory, and must run after
+// AuthMiddleware.
+func RequireTwoFactor(checker TwoFactorChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "User not authenticated",
+			})
+			c.Abort()
+			return
+		}
+
+		enabled, err := checker.IsEnabled(c.Request.Context(), userID.(uint))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Failed to check two-factor authentication",
+				Message: err.Error(),
+			})
+			c.Abort()
+			return
+		}
+		if !enabled {
+			c.JSON(http.StatusForbidden, dto.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Two-factor authentication must be enabled to use the admin API",
+			})
+			c.Abort()
+			return
+		}
+		// Tokens issued before enrollment, or refreshed from such a session,
+		// never passed the second factor
+		if !c.GetBool("two_factor") {
+			c.JSON(http.StatusForbidden, dto.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "Sign in again with two-factor authentication to use the admin API",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
 }
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"electronics-store/internal/config"
+	"electronics-store/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := services.NewRateLimiter(services.NewMemoryRateLimitStore(), config.RateLimitConfig{})
+	rule := services.RateLimitRule{Name: "test-login", Limit: 2, Window: time.Minute}
+
+	router := gin.New()
+	router.POST("/login", RateLimit(limiter, rule, ByEmail), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	login := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name          string
+		body          string
+		wantStatus    int
+		wantRemaining string
+	}{
+		{"first attempt", `{"email":"ada@example.com"}`, http.StatusOK, "1"},
+		{"same account in other case", `{"email":" ADA@example.com"}`, http.StatusOK, "0"},
+		{"over the limit", `{"email":"ada@example.com"}`, http.StatusTooManyRequests, "0"},
+		{"other account", `{"email":"grace@example.com"}`, http.StatusOK, "1"},
+		{"no account to count by", `{}`, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		w := login(tt.body)
+		if w.Code != tt.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != tt.wantRemaining {
+			t.Errorf("%s: X-RateLimit-Remaining = %q, want %q", tt.name, got, tt.wantRemaining)
+		}
+		if tt.wantStatus == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "60" {
+			t.Errorf("%s: Retry-After = %q, want 60", tt.name, w.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestRateLimitByClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(trustedProxies []string) *gin.Engine {
+		limiter := services.NewRateLimiter(services.NewMemoryRateLimitStore(), config.RateLimitConfig{})
+		rule := services.RateLimitRule{Name: "test-otp", Limit: 2, Window: time.Minute}
+		router := gin.New()
+		if err := router.SetTrustedProxies(trustedProxies); err != nil {
+			t.Fatalf("SetTrustedProxies: %v", err)
+		}
+		router.POST("/send-otp", RateLimit(limiter, rule, ByClientIP), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return router
+	}
+	send := func(router *gin.Engine, remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/send-otp", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("spoofed header from a client", func(t *testing.T) {
+		router := newRouter(nil)
+		want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests}
+		for i, wantStatus := range want {
+			// A new forwarded address on every request must not reset the count
+			forwardedFor := fmt.Sprintf("198.51.100.%d", i+1)
+			if got := send(router, "203.0.113.7:41000", forwardedFor); got != wantStatus {
+				t.Fatalf("request %d: status = %d, want %d", i+1, got, wantStatus)
+			}
+		}
+	})
+
+	t.Run("header from a trusted proxy", func(t *testing.T) {
+		router := newRouter([]string{"10.0.0.0/8"})
+		for i := 0; i < 2; i++ {
+			if got := send(router, "10.0.0.2:41000", "198.51.100.1"); got != http.StatusOK {
+				t.Fatalf("request %d: status = %d, want %d", i+1, got, http.StatusOK)
+			}
+		}
+		if got := send(router, "10.0.0.2:41000", "198.51.100.1"); got != http.StatusTooManyRequests {
+			t.Errorf("client over the limit: status = %d, want %d", got, http.StatusTooManyRequests)
+		}
+		// Other clients behind the same proxy have budgets of their own
+		if got := send(router, "10.0.0.2:41000", "198.51.100.2"); got != http.StatusOK {
+			t.Errorf("other client: status = %d, want %d", got, http.StatusOK)
+		}
+	})
+}
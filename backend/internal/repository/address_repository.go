@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"electronics-store/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AddressRepository interface {
+	// ListByUser returns the user's addresses, defaults first
+	ListByUser(ctx context.Context, userID uint) ([]*models.Address, error)
+	// GetForUser returns an address only if it belongs to the given user
+	GetForUser(ctx context.Context, userID uint, resourceID string) (*models.Address, error)
+	// GetDefault returns the user's default address of the given type
+	GetDefault(ctx context.Context, userID uint, addressType string) (*models.Address, error)
+	// GetLatest returns the user's most recently updated address of the given type
+	GetLatest(ctx context.Context, userID uint, addressType string) (*models.Address, error)
+	Create(ctx context.Context, address *models.Address) error
+	Update(ctx context.Context, address *models.Address) error
+	Delete(ctx context.Context, id uint) error
+	// ClearDefault unsets the default flag on the user's addresses of the
+	// given type, except the address with id exceptID
+	ClearDefault(ctx context.Context, userID uint, addressType string, exceptID uint) error
+	// LockUser locks the user's row until the transaction ends so concurrent
+	// address book changes cannot leave two defaults of a type behind
+	LockUser(ctx context.Context, userID uint) error
+	// Transaction runs fn with a repository bound to a single database transaction
+	Transaction(ctx context.Context, fn func(tx AddressRepository) error) error
+}
+
+type addressRepository struct {
+	db *gorm.DB
+}
+
+func NewAddressRepository(db *gorm.DB) AddressRepository {
+	return &addressRepository{db: db}
+}
+
+func (r *addressRepository) ListByUser(ctx context.Context, userID uint) ([]*models.Address, error) {
+	var addresses []*models.Address
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("is_default desc, updated_at desc, id desc").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+func (r *addressRepository) GetForUser(ctx context.Context, userID uint, resourceID string) (*models.Address, error) {
+	var address models.Address
+	err := r.db.WithContext(ctx).
+		Where("resource_id = ? AND user_id = ?", resourceID, userID).
+		First(&address).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *addressRepository) GetDefault(ctx context.Context, userID uint, addressType string) (*models.Address, error) {
+	var address models.Address
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND type = ? AND is_default = ?", userID, addressType, true).
+		Order("updated_at desc").
+		First(&address).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *addressRepository) GetLatest(ctx context.Context, userID uint, addressType string) (*models.Address, error) {
+	var address models.Address
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND type = ?", userID, addressType).
+		Order("updated_at desc, id desc").
+		First(&address).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *addressRepository) Create(ctx context.Context, address *models.Address) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(address).Error
+}
+
+func (r *addressRepository) Update(ctx context.Context, address *models.Address) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(address).Error
+}
+
+func (r *addressRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Address{}, id).Error
+}
+
+func (r *addressRepository) ClearDefault(ctx context.Context, userID uint, addressType string, exceptID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Address{}).
+		Where("user_id = ? AND type = ? AND id <> ? AND is_default = ?", userID, addressType, exceptID, true).
+		Update("is_default", false).Error
+}
+
+func (r *addressRepository) LockUser(ctx context.Context, userID uint) error {
+	var ids []uint
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", userID).
+		Pluck("id", &ids).Error
+}
+
+func (r *addressRepository) Transaction(ctx context.Context, fn func(tx AddressRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&addressRepository{db: tx})
+	})
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"electronics-store/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// AuditLogRepository stores the admin audit log. It is append-only: entries
+// can be created and read but never changed.
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *models.AuditLog) error
+	List(ctx context.Context, limit, offset int, filters map[string]interface{}) ([]*models.AuditLog, error)
+	Count(ctx context.Context, filters map[string]interface{}) (int64, error)
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	return r.db.WithContext(ctx).Omit("Actor").Create(entry).Error
+}
+
+func (r *auditLogRepository) List(ctx context.Context, limit, offset int, filters map[string]interface{}) ([]*models.AuditLog, error) {
+	var entries []*models.AuditLog
+	err := applyAuditLogFilters(r.db.WithContext(ctx), filters).
+		Preload("Actor", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Order("created_at desc, id desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *auditLogRepository) Count(ctx context.Context, filters map[string]interface{}) (int64, error) {
+	var count int64
+	err := applyAuditLogFilters(r.db.WithContext(ctx).Model(&models.AuditLog{}), filters).Count(&count).Error
+	return count, err
+}
+
+func applyAuditLogFilters(query *gorm.DB, filters map[string]interface{}) *gorm.DB {
+	if actorID, ok := filters["actor_id"]; ok {
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if action, ok := filters["action"]; ok {
+		query = query.Where("action = ?", action)
+	}
+	if entityType, ok := filters["entity_type"]; ok {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	if entityID, ok := filters["entity_id"]; ok {
+		query = query.Where("entity_id = ?", entityID)
+	}
+	if requestID, ok := filters["request_id"]; ok {
+		query = query.Where("request_id = ?", requestID)
+	}
+	if from, ok := filters["from"]; ok {
+		query = query.Where("created_at >= ?", from)
+	}
+	if to, ok := filters["to"]; ok {
+		query = query.Where("created_at < ?", to)
+	}
+	return query
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"electronics-store/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CartRepository stores users' and guests' carts and their lines
+type CartRepository interface {
+	// GetOrCreate returns the user's cart, creating an empty one on first use
+	GetOrCreate(ctx context.Context, userID uint) (*models.Cart, error)
+	// GetGuest returns the guest cart with the given resource ID unless it
+	// has expired
+	GetGuest(ctx context.Context, resourceID string) (*models.Cart, error)
+	// GetGuestForUpdate is GetGuest that locks the cart row until the
+	// transaction ends
+	GetGuestForUpdate(ctx context.Context, resourceID string) (*models.Cart, error)
+	// CreateGuest creates an empty guest cart expiring at expiresAt
+	CreateGuest(ctx context.Context, expiresAt time.Time) (*models.Cart, error)
+	// ExtendGuest moves a guest cart's expiry to expiresAt
+	ExtendGuest(ctx context.Context, cartID uint, expiresAt time.Time) error
+	// Delete removes a cart and its lines
+	Delete(ctx context.Context, cartID uint) error
+	// DeleteExpiredGuests removes the guest carts that expired before the
+	// given time, with their lines, and returns how many it removed
+	DeleteExpiredGuests(ctx context.Context, before time.Time) (int64, error)
+	// ListItems returns the cart's lines oldest first, with the product,
+	// variant, option values and images a cart shows
+	ListItems(ctx context.Context, cartID uint) ([]models.CartItem, error)
+	// GetItem returns a line only if it belongs to the given cart
+	GetItem(ctx context.Context, cartID, itemID uint) (*models.CartItem, error)
+	// FindItem returns the cart's line of the product and variant, if any
+	FindItem(ctx context.Context, cartID, productID uint, variantID *uint) (*models.CartItem, error)
+	CreateItem(ctx context.Context, item *models.CartItem) error
+	UpdateItem(ctx context.Context, item *models.CartItem) error
+	DeleteItem(ctx context.Context, id uint) error
+	ClearItems(ctx context.Context, cartID uint) error
+	SetDiscountCode(ctx context.Context, cartID uint, code string) error
+	// Transaction runs fn with a repository bound to a single database transaction
+	Transaction(ctx context.Context, fn func(tx CartRepository) error) error
+}
+
+type cartRepository struct {
+	db *gorm.DB
+}
+
+func NewCartRepository(db *gorm.DB) CartRepository {
+	return &cartRepository{db: db}
+}
+
+func (r *cartRepository) GetOrCreate(ctx context.Context, userID uint) (*models.Cart, error) {
+	var cart models.Cart
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		FirstOrCreate(&cart, models.Cart{UserID: &userID}).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (r *cartRepository) GetGuest(ctx context.Context, resourceID string) (*models.Cart, error) {
+	return r.getGuest(r.db.WithContext(ctx), resourceID)
+}
+
+func (r *cartRepository) GetGuestForUpdate(ctx context.Context, resourceID string) (*models.Cart, error) {
+	return r.getGuest(r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}), resourceID)
+}
+
+func (r *cartRepository) getGuest(db *gorm.DB, resourceID string) (*models.Cart, error) {
+	var cart models.Cart
+	err := db.
+		Where("resource_id = ? AND user_id IS NULL AND expires_at > ?", resourceID, time.Now()).
+		First(&cart).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (r *cartRepository) CreateGuest(ctx context.Context, expiresAt time.Time) (*models.Cart, error) {
+	cart := models.Cart{ExpiresAt: &expiresAt}
+	if err := r.db.WithContext(ctx).Create(&cart).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (r *cartRepository) ExtendGuest(ctx context.Context, cartID uint, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.Cart{}).
+		Where("id = ? AND user_id IS NULL", cartID).
+		Update("expires_at", expiresAt).Error
+}
+
+func (r *cartRepository) Delete(ctx context.Context, cartID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Cart{}, cartID).Error
+	})
+}
+
+func (r *cartRepository) DeleteExpiredGuests(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Model(&models.Cart{}).
+			Select("id").
+			Where("user_id IS NULL AND expires_at < ?", before)
+		if err := tx.Where("cart_id IN (?)", expired).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("user_id IS NULL AND expires_at < ?", before).Delete(&models.Cart{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// preloadItem loads what a cart line response needs
+func (r *cartRepository) preloadItem(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Product.Images").
+		Preload("Variant.OptionValues.Option").
+		Preload("Variant.Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order, id")
+		})
+}
+
+func (r *cartRepository) ListItems(ctx context.Context, cartID uint) ([]models.CartItem, error) {
+	var items []models.CartItem
+	err := r.preloadItem(r.db.WithContext(ctx)).
+		Where("cart_id = ?", cartID).
+		Order("id").
+		Find(&items).Error
+	return items, err
+}
+
+func (r *cartRepository) GetItem(ctx context.Context, cartID, itemID uint) (*models.CartItem, error) {
+	var item models.CartItem
+	err := r.preloadItem(r.db.WithContext(ctx)).
+		Where("id = ? AND cart_id = ?", itemID, cartID).
+		First(&item).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *cartRepository) FindItem(ctx context.Context, cartID, productID uint, variantID *uint) (*models.CartItem, error) {
+	query := r.db.WithContext(ctx).Where("cart_id = ? AND product_id = ?", cartID, productID)
+	if variantID != nil {
+		query = query.Where("variant_id = ?", *variantID)
+	} else {
+		query = query.Where("variant_id IS NULL")
+	}
+
+	var item models.CartItem
+	if err := query.First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *cartRepository) CreateItem(ctx context.Context, item *models.CartItem) error {
+	return r.db.WithContext(ctx).Omit("Product", "Variant").Create(item).Error
+}
+
+func (r *cartRepository) UpdateItem(ctx context.Context, item *models.CartItem) error {
+	return r.db.WithContext(ctx).Model(item).Updates(map[string]interface{}{
+		"quantity": item.Quantity,
+		"price":    item.Price,
+	}).Error
+}
+
+func (r *cartRepository) DeleteItem(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.CartItem{}, id).Error
+}
+
+func (r *cartRepository) ClearItems(ctx context.Context, cartID uint) error {
+	return r.db.WithContext(ctx).Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error
+}
+
+func (r *cartRepository) SetDiscountCode(ctx context.Context, cartID uint, code string) error {
+	return r.db.WithContext(ctx).Model(&models.Cart{}).
+		Where("id = ?", cartID).
+		Update("discount_code", code).Error
+}
+
+func (r *cartRepository) Transaction(ctx context.Context, fn func(tx CartRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&cartRepository{db: tx})
+	})
+}
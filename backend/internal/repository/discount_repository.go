@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"electronics-store/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type DiscountRepository interface {
+	List(ctx context.Context, limit, offset int) ([]*models.Discount, error)
+	Count(ctx context.Context) (int64, error)
+	GetByResourceID(ctx context.Context, resourceID string) (*models.Discount, error)
+	GetByCode(ctx context.Context, code string) (*models.Discount, error)
+	Create(ctx context.Context, discount *models.Discount) error
+	Update(ctx context.Context, discount *models.Discount) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type discountRepository struct {
+	db *gorm.DB
+}
+
+func NewDiscountRepository(db *gorm.DB) DiscountRepository {
+	return &discountRepository{db: db}
+}
+
+func (r *discountRepository) List(ctx context.Context, limit, offset int) ([]*models.Discount, error) {
+	var discounts []*models.Discount
+	err := r.db.WithContext(ctx).
+		Order("created_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&discounts).Error
+	return discounts, err
+}
+
+func (r *discountRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Discount{}).Count(&count).Error
+	return count, err
+}
+
+func (r *discountRepository) GetByResourceID(ctx context.Context, resourceID string) (*models.Discount, error) {
+	var discount models.Discount
+	err := r.db.WithContext(ctx).Where("resource_id = ?", resourceID).First(&discount).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &discount, nil
+}
+
+func (r *discountRepository) GetByCode(ctx context.Context, code string) (*models.Discount, error) {
+	var discount models.Discount
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&discount).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &discount, nil
+}
+
+func (r *discountRepository) Create(ctx context.Context, discount *models.Discount) error {
+	return r.db.WithContext(ctx).Create(discount).Error
+}
+
+func (r *discountRepository) Update(ctx context.Context, discount *models.Discount) error {
+	return r.db.WithContext(ctx).Save(discount).Error
+}
+
+func (r *discountRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Discount{}, id).Error
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"electronics-store/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+type EmailOutboxRepository interface {
+	Create(ctx context.Context, email *models.OutboxEmail) error
+	GetByResourceID(ctx context.Context, resourceID string) (*models.OutboxEmail, error)
+	List(ctx context.Context, status, channel string, limit, offset int) ([]*models.OutboxEmail, int64, error)
+	// ListDueIDs returns up to limit IDs of emails ready for an attempt:
+	// pending ones whose next attempt is due and claimed ones whose worker
+	// let the lease run out
+	ListDueIDs(ctx context.Context, now time.Time, limit int) ([]uint, error)
+	// Claim takes a due email for an attempt until leaseUntil and counts the
+	// attempt. It returns nil if the email isn't due or another worker
+	// claimed it first.
+	Claim(ctx context.Context, id uint, now, leaseUntil time.Time) (*models.OutboxEmail, error)
+	// MarkSent records delivery and clears the bodies and payload
+	MarkSent(ctx context.Context, id uint) error
+	// MarkFailed records a failed attempt. The email is retried at
+	// nextAttemptAt, or dead-lettered when that is nil.
+	MarkFailed(ctx context.Context, id uint, lastError string, nextAttemptAt *time.Time) error
+	// Requeue makes a dead email pending again with fresh attempts,
+	// returning false if it isn't dead. An expired email dies again on its
+	// next attempt.
+	Requeue(ctx context.Context, id uint) (bool, error)
+	// DeleteSentBefore removes emails delivered before the given time
+	DeleteSentBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type emailOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailOutboxRepository(db *gorm.DB) EmailOutboxRepository {
+	return &emailOutboxRepository{db: db}
+}
+
+func (r *emailOutboxRepository) Create(ctx context.Context, email *models.OutboxEmail) error {
+	return r.db.WithContext(ctx).Create(email).Error
+}
+
+func (r *emailOutboxRepository) GetByResourceID(ctx context.Context, resourceID string) (*models.OutboxEmail, error) {
+	var email models.OutboxEmail
+	err := r.db.WithContext(ctx).Where("resource_id = ?", resourceID).First(&email).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &email, nil
+}
+
+func (r *emailOutboxRepository) List(ctx context.Context, status, channel string, limit, offset int) ([]*models.OutboxEmail, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.OutboxEmail{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if channel != "" {
+		query = query.Where("channel = ?", channel)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var emails []*models.OutboxEmail
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&emails).Error
+	return emails, total, err
+}
+
+// dueCondition matches emails a worker may claim at now
+const dueCondition = "((status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?))"
+
+func (r *emailOutboxRepository) ListDueIDs(ctx context.Context, now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&models.OutboxEmail{}).
+		Where(dueCondition, models.OutboxEmailPending, now, models.OutboxEmailSending, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *emailOutboxRepository) Claim(ctx context.Context, id uint, now, leaseUntil time.Time) (*models.OutboxEmail, error) {
+	// The conditional update is atomic, so of several workers or replicas
+	// only one claims the email
+	result := r.db.WithContext(ctx).Model(&models.OutboxEmail{}).
+		Where("id = ?", id).
+		Where(dueCondition, models.OutboxEmailPending, now, models.OutboxEmailSending, now).
+		Updates(map[string]interface{}{
+			"status":       models.OutboxEmailSending,
+			"locked_until": leaseUntil,
+			"attempts":     gorm.Expr("attempts + 1"),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	var email models.OutboxEmail
+	if err := r.db.WithContext(ctx).First(&email, id).Error; err != nil {
+		return nil, err
+	}
+	return &email, nil
+}
+
+func (r *emailOutboxRepository) MarkSent(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&models.OutboxEmail{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.OutboxEmailSent,
+			"sent_at":      time.Now(),
+			"locked_until": nil,
+			"last_error":   "",
+			"text_body":    "",
+			"html_body":    "",
+			"payload":      "",
+		}).Error
+}
+
+func (r *emailOutboxRepository) MarkFailed(ctx context.Context, id uint, lastError string, nextAttemptAt *time.Time) error {
+	updates := map[string]interface{}{
+		"status":       models.OutboxEmailDead,
+		"locked_until": nil,
+		"last_error":   lastError,
+	}
+	if nextAttemptAt != nil {
+		updates["status"] = models.OutboxEmailPending
+		updates["next_attempt_at"] = *nextAttemptAt
+	}
+	return r.db.WithContext(ctx).Model(&models.OutboxEmail{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *emailOutboxRepository) Requeue(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.OutboxEmail{}).
+		Where("id = ? AND status = ?", id, models.OutboxEmailDead).
+		Updates(map[string]interface{}{
+			"status":          models.OutboxEmailPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *emailOutboxRepository) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND sent_at < ?", models.OutboxEmailSent, before).
+		Delete(&models.OutboxEmail{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"electronics-store/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRunRepository remembers the scheduled time each background job last ran
+// for. It is the scheduler's RunLog.
+type JobRunRepository interface {
+	// LastSlot returns the scheduled time of the job's last run, or the zero
+	// time if it never ran
+	LastSlot(ctx context.Context, name string) (time.Time, error)
+	// RecordSlot records a run of the job for the given scheduled time
+	RecordSlot(ctx context.Context, name string, slot time.Time) error
+}
+
+type jobRunRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRunRepository(db *gorm.DB) JobRunRepository {
+	return &jobRunRepository{db: db}
+}
+
+func (r *jobRunRepository) LastSlot(ctx context.Context, name string) (time.Time, error) {
+	var run models.JobRun
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&run).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return run.LastSlot, nil
+}
+
+func (r *jobRunRepository) RecordSlot(ctx context.Context, name string, slot time.Time) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_slot", "updated_at"}),
+	}).Create(&models.JobRun{Name: name, LastSlot: slot}).Error
+}
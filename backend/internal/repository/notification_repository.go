@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"electronics-store/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository interface {
+	// ListByUser returns a page of a user's notifications, newest first,
+	// with the total count for the same filter
+	ListByUser(ctx context.Context, userID uint, unreadOnly bool, limit, offset int) ([]*models.Notification, int64, error)
+	CountUnread(ctx context.Context, userID uint) (int64, error)
+	// MarkRead marks one of a user's notifications read, returning false if
+	// the user has no such notification. Reading it again is a no-op.
+	MarkRead(ctx context.Context, userID uint, resourceID string) (bool, error)
+	// MarkAllRead marks every unread notification of a user read
+	MarkAllRead(ctx context.Context, userID uint) (int64, error)
+	// Delete removes one of a user's notifications, returning false if the
+	// user has no such notification
+	Delete(ctx context.Context, userID uint, resourceID string) (bool, error)
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) ListByUser(ctx context.Context, userID uint, unreadOnly bool, limit, offset int) ([]*models.Notification, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []*models.Notification
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, userID uint, resourceID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND resource_id = ?", userID, resourceID).
+		Count(&count).Error
+	if err != nil || count == 0 {
+		return false, err
+	}
+
+	err = r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND resource_id = ? AND read_at IS NULL", userID, resourceID).
+		Update("read_at", time.Now()).Error
+	return true, err
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+func (r *notificationRepository) Delete(ctx context.Context, userID uint, resourceID string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND resource_id = ?", userID, resourceID).
+		Delete(&models.Notification{})
+	return result.RowsAffected > 0, result.Error
+}
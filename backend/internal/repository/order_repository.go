@@ -3,9 +3,11 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"electronics-store/internal/domain/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepository interface {
@@ -17,6 +19,40 @@ type OrderRepository interface {
 	Delete(ctx context.Context, id uint) error
 	List(ctx context.Context, userID uint, limit, offset int) ([]*models.Order, error)
 	Count(ctx context.Context, userID uint) (int64, error)
+	// ListUnpaidPendingIDs returns up to limit IDs, in order and greater than
+	// afterID, of orders placed before createdBefore that are still pending
+	// and unpaid
+	ListUnpaidPendingIDs(ctx context.Context, createdBefore time.Time, afterID uint, limit int) ([]uint, error)
+
+	// Checkout support
+	Transaction(ctx context.Context, fn func(tx OrderRepository) error) error
+	GetCartForCheckout(ctx context.Context, userID uint) (*models.Cart, error)
+	ClearCart(ctx context.Context, cartID uint) error
+	GetForUpdate(ctx context.Context, id uint) (*models.Order, error)
+	CreateStatusHistory(ctx context.Context, entry *models.OrderStatusHistory) error
+
+	// Payments
+	CreatePayment(ctx context.Context, payment *models.Payment) error
+	UpdatePayment(ctx context.Context, payment *models.Payment) error
+	GetPaymentByTransactionID(ctx context.Context, transactionID string) (*models.Payment, error)
+	ListPayments(ctx context.Context, orderID uint) ([]*models.Payment, error)
+	CreateRefund(ctx context.Context, refund *models.Refund) error
+	UpdateRefund(ctx context.Context, refund *models.Refund) error
+	RefundedQuantities(ctx context.Context, orderID uint, restockedOnly bool) (map[uint]int, error)
+
+	// Discounts
+	IncrementDiscountUsage(ctx context.Context, discountID uint) (bool, error)
+	ReleaseDiscountUsage(ctx context.Context, code string) error
+
+	// Inventory
+	ReserveStock(ctx context.Context, productID uint, variantID *uint, quantity int, allowBackorder bool) (bool, error)
+	ReleaseStock(ctx context.Context, productID uint, variantID *uint, quantity int) error
+	// StockLevel returns the stock of a product, or of the variant when one is given
+	StockLevel(ctx context.Context, productID uint, variantID *uint) (int, error)
+
+	// Outbox
+	EnqueueEmail(ctx context.Context, email *models.OutboxEmail) error
+	CreateNotification(ctx context.Context, notification *models.Notification) error
 }
 
 type orderRepository struct {
@@ -28,7 +64,29 @@ func NewOrderRepository(db *gorm.DB) OrderRepository {
 }
 
 func (r *orderRepository) Create(ctx context.Context, order *models.Order) error {
-	return r.db.WithContext(ctx).Create(order).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Associations are written explicitly so preloaded products/variants
+		// on the line items are never upserted along with the order
+		if err := tx.Omit(clause.Associations).Create(order).Error; err != nil {
+			return err
+		}
+		if len(order.OrderItems) == 0 {
+			return nil
+		}
+		for i := range order.OrderItems {
+			order.OrderItems[i].OrderID = order.ID
+		}
+		if err := tx.Omit(clause.Associations).Create(&order.OrderItems).Error; err != nil {
+			return err
+		}
+		if len(order.Taxes) == 0 {
+			return nil
+		}
+		for i := range order.Taxes {
+			order.Taxes[i].OrderID = order.ID
+		}
+		return tx.Create(&order.Taxes).Error
+	})
 }
 
 func (r *orderRepository) GetByID(ctx context.Context, id uint) (*models.Order, error) {
@@ -37,11 +95,20 @@ func (r *orderRepository) GetByID(ctx context.Context, id uint) (*models.Order,
 		Preload("User").
 		Preload("OrderItems").
 		Preload("OrderItems.Product").
+		Preload("OrderItems.Product.Images").
 		Preload("OrderItems.Variant").
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at asc, id asc")
+		}).
 		Preload("Payments", func(db *gorm.DB) *gorm.DB {
 			// Explicitly select all payment fields including payment_status
 			return db.Select("id", "resource_id", "order_id", "payment_method", "amount", "currency", "payment_status", "transaction_id", "gateway_response", "processed_at", "created_at", "updated_at")
 		}).
+		Preload("Refunds", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at asc, id asc")
+		}).
+		Preload("Refunds.Items").
+		Preload("Taxes").
 		First(&order, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -58,11 +125,20 @@ func (r *orderRepository) GetByResourceID(ctx context.Context, resourceID string
 		Preload("User").
 		Preload("OrderItems").
 		Preload("OrderItems.Product").
+		Preload("OrderItems.Product.Images").
 		Preload("OrderItems.Variant").
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at asc, id asc")
+		}).
 		Preload("Payments", func(db *gorm.DB) *gorm.DB {
 			// Explicitly select all payment fields including payment_status
 			return db.Select("id", "resource_id", "order_id", "payment_method", "amount", "currency", "payment_status", "transaction_id", "gateway_response", "processed_at", "created_at", "updated_at")
 		}).
+		Preload("Refunds", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at asc, id asc")
+		}).
+		Preload("Refunds.Items").
+		Preload("Taxes").
 		Where("resource_id = ?", resourceID).
 		First(&order).Error
 	if err != nil {
@@ -80,11 +156,20 @@ func (r *orderRepository) GetByOrderNumber(ctx context.Context, orderNumber stri
 		Preload("User").
 		Preload("OrderItems").
 		Preload("OrderItems.Product").
+		Preload("OrderItems.Product.Images").
 		Preload("OrderItems.Variant").
+		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at asc, id asc")
+		}).
 		Preload("Payments", func(db *gorm.DB) *gorm.DB {
 			// Explicitly select all payment fields including payment_status
 			return db.Select("id", "resource_id", "order_id", "payment_method", "amount", "currency", "payment_status", "transaction_id", "gateway_response", "processed_at", "created_at", "updated_at")
 		}).
+		Preload("Refunds", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at asc, id asc")
+		}).
+		Preload("Refunds.Items").
+		Preload("Taxes").
 		Where("order_number = ?", orderNumber).
 		First(&order).Error
 	if err != nil {
@@ -97,7 +182,7 @@ func (r *orderRepository) GetByOrderNumber(ctx context.Context, orderNumber stri
 }
 
 func (r *orderRepository) Update(ctx context.Context, order *models.Order) error {
-	return r.db.WithContext(ctx).Save(order).Error
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(order).Error
 }
 
 func (r *orderRepository) Delete(ctx context.Context, id uint) error {
@@ -110,11 +195,13 @@ func (r *orderRepository) List(ctx context.Context, userID uint, limit, offset i
 		Preload("User").
 		Preload("OrderItems").
 		Preload("OrderItems.Product").
+		Preload("OrderItems.Product.Images").
 		Preload("OrderItems.Variant").
 		Preload("Payments", func(db *gorm.DB) *gorm.DB {
 			// Explicitly select all payment fields including payment_status
 			return db.Select("id", "resource_id", "order_id", "payment_method", "amount", "currency", "payment_status", "transaction_id", "gateway_response", "processed_at", "created_at", "updated_at")
-		})
+		}).
+		Preload("Taxes")
 
 	if userID > 0 {
 		query = query.Where("user_id = ?", userID)
@@ -137,4 +224,254 @@ func (r *orderRepository) Count(ctx context.Context, userID uint) (int64, error)
 
 	err := query.Count(&count).Error
 	return count, err
-}
\ No newline at end of file
+}
+
+func (r *orderRepository) ListUnpaidPendingIDs(ctx context.Context, createdBefore time.Time, afterID uint, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&models.Order{}).
+		Where("status = ? AND payment_status IN ?", models.OrderStatusPending, []string{models.OrderPaymentPending, models.OrderPaymentFailed}).
+		Where("created_at < ? AND id > ?", createdBefore, afterID).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Transaction runs fn with a repository bound to a single database transaction
+func (r *orderRepository) Transaction(ctx context.Context, fn func(tx OrderRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&orderRepository{db: tx})
+	})
+}
+
+// GetCartForCheckout loads the user's cart with its items, locking the cart row
+// so concurrent checkouts of the same cart are serialized
+func (r *orderRepository) GetCartForCheckout(ctx context.Context, userID uint) (*models.Cart, error) {
+	var cart models.Cart
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).
+		First(&cart).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	err = r.db.WithContext(ctx).
+		Preload("Product").
+		Preload("Variant").
+		Where("cart_id = ?", cart.ID).
+		Order("id asc").
+		Find(&cart.Items).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// ClearCart removes all items of a checked out cart and the discount code
+// that was redeemed with it
+func (r *orderRepository) ClearCart(ctx context.Context, cartID uint) error {
+	if err := r.db.WithContext(ctx).Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error; err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Model(&models.Cart{}).Where("id = ?", cartID).Update("discount_code", "").Error
+}
+
+// GetForUpdate loads an order with its line items and locks the order row
+// for the rest of the surrounding transaction
+func (r *orderRepository) GetForUpdate(ctx context.Context, id uint) (*models.Order, error) {
+	var order models.Order
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("OrderItems").
+		Preload("OrderItems.Product").
+		First(&order, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ReserveStock decrements stock for a product, or for the variant when one is
+// given, with a single conditional update. It reports false without changing
+// anything when there is not enough stock and backorders are not allowed.
+func (r *orderRepository) ReserveStock(ctx context.Context, productID uint, variantID *uint, quantity int, allowBackorder bool) (bool, error) {
+	var query *gorm.DB
+	if variantID != nil {
+		query = r.db.WithContext(ctx).
+			Model(&models.Variant{}).
+			Where("id = ? AND product_id = ?", *variantID, productID)
+	} else {
+		query = r.db.WithContext(ctx).
+			Model(&models.Product{}).
+			Where("id = ?", productID)
+	}
+	if !allowBackorder {
+		query = query.Where("stock_quantity >= ?", quantity)
+	}
+
+	result := query.UpdateColumn("stock_quantity", gorm.Expr("stock_quantity - ?", quantity))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseDiscountUsage gives back one redemption of a discount, e.g. when the
+// order that used it is cancelled. The count never goes below zero.
+func (r *orderRepository) ReleaseDiscountUsage(ctx context.Context, code string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Discount{}).
+		Where("code = ? AND used_count > 0", code).
+		UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error
+}
+
+// ReleaseStock returns previously reserved stock to a product or variant
+func (r *orderRepository) ReleaseStock(ctx context.Context, productID uint, variantID *uint, quantity int) error {
+	if variantID != nil {
+		return r.db.WithContext(ctx).
+			Model(&models.Variant{}).
+			Where("id = ? AND product_id = ?", *variantID, productID).
+			UpdateColumn("stock_quantity", gorm.Expr("stock_quantity + ?", quantity)).Error
+	}
+	return r.db.WithContext(ctx).
+		Model(&models.Product{}).
+		Where("id = ?", productID).
+		UpdateColumn("stock_quantity", gorm.Expr("stock_quantity + ?", quantity)).Error
+}
+
+func (r *orderRepository) StockLevel(ctx context.Context, productID uint, variantID *uint) (int, error) {
+	var stock int
+	var err error
+	if variantID != nil {
+		err = r.db.WithContext(ctx).
+			Model(&models.Variant{}).
+			Where("id = ? AND product_id = ?", *variantID, productID).
+			Select("stock_quantity").
+			Scan(&stock).Error
+	} else {
+		err = r.db.WithContext(ctx).
+			Model(&models.Product{}).
+			Where("id = ?", productID).
+			Select("stock_quantity").
+			Scan(&stock).Error
+	}
+	return stock, err
+}
+
+// EnqueueEmail writes an email to the outbox, so it is only sent if the
+// surrounding transaction commits
+func (r *orderRepository) EnqueueEmail(ctx context.Context, email *models.OutboxEmail) error {
+	return r.db.WithContext(ctx).Create(email).Error
+}
+
+// CreateNotification adds an entry to a user's in-app inbox, which appears
+// only if the surrounding transaction commits
+func (r *orderRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	return r.db.WithContext(ctx).Create(notification).Error
+}
+
+func (r *orderRepository) CreateStatusHistory(ctx context.Context, entry *models.OrderStatusHistory) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *orderRepository) CreatePayment(ctx context.Context, payment *models.Payment) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(payment).Error
+}
+
+func (r *orderRepository) UpdatePayment(ctx context.Context, payment *models.Payment) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(payment).Error
+}
+
+// GetPaymentByTransactionID returns the most recent payment recorded for a
+// gateway transaction
+func (r *orderRepository) GetPaymentByTransactionID(ctx context.Context, transactionID string) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.WithContext(ctx).
+		Where("transaction_id = ?", transactionID).
+		Order("id desc").
+		First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// ListPayments returns the payments of an order with the refunds issued against each
+func (r *orderRepository) ListPayments(ctx context.Context, orderID uint) ([]*models.Payment, error) {
+	var payments []*models.Payment
+	err := r.db.WithContext(ctx).
+		Preload("Refunds").
+		Where("order_id = ?", orderID).
+		Order("id asc").
+		Find(&payments).Error
+	return payments, err
+}
+
+func (r *orderRepository) CreateRefund(ctx context.Context, refund *models.Refund) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(refund).Error; err != nil {
+			return err
+		}
+		if len(refund.Items) == 0 {
+			return nil
+		}
+		for i := range refund.Items {
+			refund.Items[i].RefundID = refund.ID
+		}
+		return tx.Create(&refund.Items).Error
+	})
+}
+
+func (r *orderRepository) UpdateRefund(ctx context.Context, refund *models.Refund) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(refund).Error
+}
+
+// RefundedQuantities sums the refunded quantity of each order line, keyed by
+// order item ID, counting pending refunds but not failed ones. With
+// restockedOnly it only counts lines that went back to stock.
+func (r *orderRepository) RefundedQuantities(ctx context.Context, orderID uint, restockedOnly bool) (map[uint]int, error) {
+	var rows []struct {
+		OrderItemID uint
+		Quantity    int
+	}
+	query := r.db.WithContext(ctx).
+		Table("refund_items").
+		Select("refund_items.order_item_id, SUM(refund_items.quantity) AS quantity").
+		Joins("JOIN refunds ON refunds.id = refund_items.refund_id").
+		Where("refunds.order_id = ? AND refunds.status <> ?", orderID, models.RefundStatusFailed)
+	if restockedOnly {
+		query = query.Where("refunds.restocked = ?", true)
+	}
+	if err := query.Group("refund_items.order_item_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	quantities := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		quantities[row.OrderItemID] = row.Quantity
+	}
+	return quantities, nil
+}
+
+// IncrementDiscountUsage counts one redemption of a discount with a single
+// conditional update. It reports false when the usage limit is already reached.
+func (r *orderRepository) IncrementDiscountUsage(ctx context.Context, discountID uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Discount{}).
+		Where("id = ? AND (usage_limit IS NULL OR used_count < usage_limit)", discountID).
+		UpdateColumn("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
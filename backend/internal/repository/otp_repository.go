@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"electronics-store/internal/domain/models"
 	"time"
 
@@ -9,11 +10,22 @@ import (
 
 type OTPRepository interface {
 	Create(otp *models.OTPVerification) error
-	FindByEmailAndCode(email, otpCode, otpType string) (*models.OTPVerification, error)
 	FindByResourceID(resourceID string) (*models.OTPVerification, error)
+	// RecordFailedAttempt counts a wrong guess at the OTP and marks it used
+	// once maxAttempts is reached. It returns false if the OTP was already
+	// used, including by a concurrent guess that exhausted it.
+	RecordFailedAttempt(id uint, maxAttempts int) (bool, error)
+	// MarkUsed spends the OTP, returning false if it was already used
+	MarkUsed(id uint) (bool, error)
 	Update(otp *models.OTPVerification) error
 	InvalidateByEmailAndType(email, otpType string) error
 	DeleteExpired() error
+
+	// Outbox
+	EnqueueEmail(ctx context.Context, email *models.OutboxEmail) error
+	CreateNotification(ctx context.Context, notification *models.Notification) error
+	// Transaction runs fn with a repository bound to a single database transaction
+	Transaction(fn func(tx OTPRepository) error) error
 }
 
 type otpRepository struct {
@@ -28,23 +40,37 @@ func (r *otpRepository) Create(otp *models.OTPVerification) error {
 	return r.db.Create(otp).Error
 }
 
-func (r *otpRepository) FindByEmailAndCode(email, otpCode, otpType string) (*models.OTPVerification, error) {
+func (r *otpRepository) FindByResourceID(resourceID string) (*models.OTPVerification, error) {
 	var otp models.OTPVerification
-	err := r.db.Where("email = ? AND otp_code = ? AND otp_type = ? AND is_used = ?", 
-		email, otpCode, otpType, false).First(&otp).Error
+	err := r.db.Where("resource_id = ?", resourceID).First(&otp).Error
 	if err != nil {
 		return nil, err
 	}
 	return &otp, nil
 }
 
-func (r *otpRepository) FindByResourceID(resourceID string) (*models.OTPVerification, error) {
-	var otp models.OTPVerification
-	err := r.db.Where("resource_id = ?", resourceID).First(&otp).Error
-	if err != nil {
-		return nil, err
+func (r *otpRepository) RecordFailedAttempt(id uint, maxAttempts int) (bool, error) {
+	result := r.db.Model(&models.OTPVerification{}).
+		Where("id = ? AND is_used = ?", id, false).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return false, result.Error
 	}
-	return &otp, nil
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	err := r.db.Model(&models.OTPVerification{}).
+		Where("id = ? AND attempts >= ?", id, maxAttempts).
+		Update("is_used", true).Error
+	return true, err
+}
+
+func (r *otpRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&models.OTPVerification{}).
+		Where("id = ? AND is_used = ?", id, false).
+		Update("is_used", true)
+	return result.RowsAffected > 0, result.Error
 }
 
 func (r *otpRepository) Update(otp *models.OTPVerification) error {
@@ -60,3 +86,19 @@ func (r *otpRepository) InvalidateByEmailAndType(email, otpType string) error {
 func (r *otpRepository) DeleteExpired() error {
 	return r.db.Where("expires_at < ?", time.Now()).Delete(&models.OTPVerification{}).Error
 }
+
+func (r *otpRepository) EnqueueEmail(ctx context.Context, email *models.OutboxEmail) error {
+	return r.db.WithContext(ctx).Create(email).Error
+}
+
+// CreateNotification adds an entry to a user's in-app inbox, which appears
+// only if the surrounding transaction commits
+func (r *otpRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	return r.db.WithContext(ctx).Create(notification).Error
+}
+
+func (r *otpRepository) Transaction(fn func(tx OTPRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&otpRepository{db: tx})
+	})
+}
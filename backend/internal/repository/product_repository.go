@@ -48,6 +48,16 @@ func (r *productRepository) GetByID(ctx context.Context, id uint) (*models.Produ
 		Preload("Categories").
 		Preload("Images").
 		Preload("Variants").
+		Preload("Variants.OptionValues.Option").
+		Preload("Variants.Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order, id")
+		}).
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		}).
+		Preload("Options.Values", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		}).
 		Preload("Reviews").
 		First(&product, id).Error
 	if err != nil {
@@ -71,6 +81,16 @@ func (r *productRepository) GetByResourceID(ctx context.Context, resourceID stri
 		Preload("Categories").
 		Preload("Images").
 		Preload("Variants").
+		Preload("Variants.OptionValues.Option").
+		Preload("Variants.Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order, id")
+		}).
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		}).
+		Preload("Options.Values", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		}).
 		Preload("Reviews").
 		Where("resource_id = ?", resourceID).
 		First(&product).Error
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"electronics-store/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type PromotionRepository interface {
+	List(ctx context.Context, limit, offset int, filters map[string]interface{}) ([]*models.Promotion, error)
+	Count(ctx context.Context, filters map[string]interface{}) (int64, error)
+	// ListActive returns active promotions whose schedule window contains now
+	ListActive(ctx context.Context, promotionType, position string, now time.Time) ([]*models.Promotion, error)
+	GetByResourceID(ctx context.Context, resourceID string) (*models.Promotion, error)
+	Create(ctx context.Context, promotion *models.Promotion) error
+	Update(ctx context.Context, promotion *models.Promotion) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type promotionRepository struct {
+	db *gorm.DB
+}
+
+func NewPromotionRepository(db *gorm.DB) PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+func (r *promotionRepository) List(ctx context.Context, limit, offset int, filters map[string]interface{}) ([]*models.Promotion, error) {
+	var promotions []*models.Promotion
+	err := applyPromotionFilters(r.db.WithContext(ctx), filters).
+		Order("created_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&promotions).Error
+	return promotions, err
+}
+
+func (r *promotionRepository) Count(ctx context.Context, filters map[string]interface{}) (int64, error) {
+	var count int64
+	err := applyPromotionFilters(r.db.WithContext(ctx).Model(&models.Promotion{}), filters).Count(&count).Error
+	return count, err
+}
+
+func (r *promotionRepository) ListActive(ctx context.Context, promotionType, position string, now time.Time) ([]*models.Promotion, error) {
+	var promotions []*models.Promotion
+	query := r.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("expires_at IS NULL OR expires_at > ?", now)
+	if promotionType != "" {
+		query = query.Where("type = ?", promotionType)
+	}
+	if position != "" {
+		query = query.Where("position = ?", position)
+	}
+	err := query.Order("starts_at desc").Order("created_at desc").Find(&promotions).Error
+	return promotions, err
+}
+
+func (r *promotionRepository) GetByResourceID(ctx context.Context, resourceID string) (*models.Promotion, error) {
+	var promotion models.Promotion
+	err := r.db.WithContext(ctx).Where("resource_id = ?", resourceID).First(&promotion).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *promotionRepository) Create(ctx context.Context, promotion *models.Promotion) error {
+	return r.db.WithContext(ctx).Create(promotion).Error
+}
+
+func (r *promotionRepository) Update(ctx context.Context, promotion *models.Promotion) error {
+	return r.db.WithContext(ctx).Save(promotion).Error
+}
+
+func (r *promotionRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Promotion{}, id).Error
+}
+
+func applyPromotionFilters(query *gorm.DB, filters map[string]interface{}) *gorm.DB {
+	if promotionType, ok := filters["type"]; ok {
+		query = query.Where("type = ?", promotionType)
+	}
+	if position, ok := filters["position"]; ok {
+		query = query.Where("position = ?", position)
+	}
+	if isActive, ok := filters["is_active"]; ok {
+		query = query.Where("is_active = ?", isActive)
+	}
+	return query
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"electronics-store/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	// GetByTokenIDForUpdate returns the token with the given jti, locked until
+	// the surrounding transaction ends
+	GetByTokenIDForUpdate(ctx context.Context, tokenID string) (*models.RefreshToken, error)
+	GetByTokenID(ctx context.Context, tokenID string) (*models.RefreshToken, error)
+	Revoke(ctx context.Context, id uint, reason string) error
+	// RevokeFamily revokes every unrevoked token of a session
+	RevokeFamily(ctx context.Context, familyID, reason string) error
+	// RevokeAllForUser revokes every unrevoked token of the user
+	RevokeAllForUser(ctx context.Context, userID uint, reason string) error
+	// Transaction runs fn with a repository bound to a single database transaction
+	Transaction(ctx context.Context, fn func(tx RefreshTokenRepository) error) error
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *refreshTokenRepository) GetByTokenIDForUpdate(ctx context.Context, tokenID string) (*models.RefreshToken, error) {
+	return r.getByTokenID(r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}), tokenID)
+}
+
+func (r *refreshTokenRepository) GetByTokenID(ctx context.Context, tokenID string) (*models.RefreshToken, error) {
+	return r.getByTokenID(r.db.WithContext(ctx), tokenID)
+}
+
+func (r *refreshTokenRepository) getByTokenID(db *gorm.DB, tokenID string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := db.Where("token_id = ?", tokenID).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) Revoke(ctx context.Context, id uint, reason string) error {
+	return r.revoke(r.db.WithContext(ctx).Where("id = ?", id), reason)
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID, reason string) error {
+	return r.revoke(r.db.WithContext(ctx).Where("family_id = ?", familyID), reason)
+}
+
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uint, reason string) error {
+	return r.revoke(r.db.WithContext(ctx).Where("user_id = ?", userID), reason)
+}
+
+func (r *refreshTokenRepository) revoke(db *gorm.DB, reason string) error {
+	return db.Model(&models.RefreshToken{}).
+		Where("revoked_at IS NULL").
+		Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"revoked_reason": reason,
+		}).Error
+}
+
+func (r *refreshTokenRepository) Transaction(ctx context.Context, fn func(tx RefreshTokenRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&refreshTokenRepository{db: tx})
+	})
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"electronics-store/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleRepository interface {
+	List(ctx context.Context) ([]*models.Role, error)
+	GetByName(ctx context.Context, name string) (*models.Role, error)
+	// GetByNames returns the roles with the given names; unknown names are skipped
+	GetByNames(ctx context.Context, names []string) ([]*models.Role, error)
+	Create(ctx context.Context, role *models.Role) error
+	// EnsurePermissions creates the permissions that do not exist yet and
+	// returns all of them by name
+	EnsurePermissions(ctx context.Context, permissions []models.Permission) (map[string]models.Permission, error)
+	// GrantPermissions adds permissions to a role, keeping those it already has
+	GrantPermissions(ctx context.Context, roleID uint, permissions []models.Permission) error
+	// HasPermission reports whether any role of the user grants the permission
+	// or the wildcard permission
+	HasPermission(ctx context.Context, userID uint, permission string) (bool, error)
+	GetUserRoles(ctx context.Context, userID uint) ([]*models.Role, error)
+	// SetUserRoles replaces the roles of a user and marks them as staff when
+	// they hold any role
+	SetUserRoles(ctx context.Context, userID uint, roles []*models.Role) error
+	// CountUsersWithRole counts the users holding the named role, locking
+	// their assignments until the surrounding transaction ends
+	CountUsersWithRole(ctx context.Context, name string) (int64, error)
+	// Transaction runs fn with a repository bound to a single database transaction
+	Transaction(ctx context.Context, fn func(tx RoleRepository) error) error
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) List(ctx context.Context) ([]*models.Role, error) {
+	var roles []*models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Order("name ASC").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) GetByNames(ctx context.Context, names []string) ([]*models.Role, error) {
+	var roles []*models.Role
+	if len(names) == 0 {
+		return roles, nil
+	}
+	err := r.db.WithContext(ctx).Where("name IN ?", names).Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Omit("Permissions").Create(role).Error
+}
+
+func (r *roleRepository) EnsurePermissions(ctx context.Context, permissions []models.Permission) (map[string]models.Permission, error) {
+	db := r.db.WithContext(ctx)
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+
+	if len(permissions) > 0 {
+		missing := make([]models.Permission, len(permissions))
+		copy(missing, permissions)
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&missing).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var existing []models.Permission
+	if err := db.Where("name IN ?", names).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	byName := make(map[string]models.Permission, len(existing))
+	for _, permission := range existing {
+		byName[permission.Name] = permission
+	}
+	return byName, nil
+}
+
+func (r *roleRepository) GrantPermissions(ctx context.Context, roleID uint, permissions []models.Permission) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&models.Role{ID: roleID}).Association("Permissions").Append(permissions)
+}
+
+func (r *roleRepository) HasPermission(ctx context.Context, userID uint, permission string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("user_roles").
+		Joins("JOIN role_permissions ON role_permissions.role_id = user_roles.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("user_roles.user_id = ? AND permissions.name IN ?", userID, []string{permission, models.PermissionAll}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *roleRepository) GetUserRoles(ctx context.Context, userID uint) ([]*models.Role, error) {
+	var roles []*models.Role
+	err := r.db.WithContext(ctx).
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.name ASC").
+		Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) SetUserRoles(ctx context.Context, userID uint, roles []*models.Role) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Exec("DELETE FROM user_roles WHERE user_id = ?", userID).Error; err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if err := db.Exec("INSERT INTO user_roles (user_id, role_id) VALUES (?, ?)", userID, role.ID).Error; err != nil {
+			return err
+		}
+	}
+	return db.Model(&models.User{}).Where("id = ?", userID).Update("is_admin", len(roles) > 0).Error
+}
+
+func (r *roleRepository) CountUsersWithRole(ctx context.Context, name string) (int64, error) {
+	var userIDs []uint
+	err := r.db.WithContext(ctx).
+		Table("user_roles").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Joins("JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL").
+		Where("roles.name = ?", name).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Pluck("user_roles.user_id", &userIDs).Error
+	return int64(len(userIDs)), err
+}
+
+func (r *roleRepository) Transaction(ctx context.Context, fn func(tx RoleRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&roleRepository{db: tx})
+	})
+}
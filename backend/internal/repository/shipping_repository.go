@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"electronics-store/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ShippingRepository interface {
+	// Zones are returned with their regions, methods and rate tiers
+	ListZones(ctx context.Context) ([]*models.ShippingZone, error)
+	GetZoneByResourceID(ctx context.Context, resourceID string) (*models.ShippingZone, error)
+	CreateZone(ctx context.Context, zone *models.ShippingZone) error
+	// UpdateZone saves the zone and, when replaceRegions is set, replaces its regions
+	UpdateZone(ctx context.Context, zone *models.ShippingZone, replaceRegions bool) error
+	DeleteZone(ctx context.Context, id uint) error
+
+	GetMethodByResourceID(ctx context.Context, resourceID string) (*models.ShippingMethod, error)
+	CreateMethod(ctx context.Context, method *models.ShippingMethod) error
+	// UpdateMethod saves the method and, when replaceTiers is set, replaces its rate tiers
+	UpdateMethod(ctx context.Context, method *models.ShippingMethod, replaceTiers bool) error
+	DeleteMethod(ctx context.Context, id uint) error
+}
+
+type shippingRepository struct {
+	db *gorm.DB
+}
+
+func NewShippingRepository(db *gorm.DB) ShippingRepository {
+	return &shippingRepository{db: db}
+}
+
+func (r *shippingRepository) ListZones(ctx context.Context) ([]*models.ShippingZone, error) {
+	var zones []*models.ShippingZone
+	err := r.preloadZone(r.db.WithContext(ctx)).
+		Order("name asc").
+		Find(&zones).Error
+	return zones, err
+}
+
+func (r *shippingRepository) GetZoneByResourceID(ctx context.Context, resourceID string) (*models.ShippingZone, error) {
+	var zone models.ShippingZone
+	err := r.preloadZone(r.db.WithContext(ctx)).
+		Where("resource_id = ?", resourceID).
+		First(&zone).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &zone, nil
+}
+
+func (r *shippingRepository) CreateZone(ctx context.Context, zone *models.ShippingZone) error {
+	return r.db.WithContext(ctx).Omit("Methods").Create(zone).Error
+}
+
+func (r *shippingRepository) UpdateZone(ctx context.Context, zone *models.ShippingZone, replaceRegions bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(zone).Error; err != nil {
+			return err
+		}
+		if !replaceRegions {
+			return nil
+		}
+		if err := tx.Where("zone_id = ?", zone.ID).Delete(&models.ShippingZoneRegion{}).Error; err != nil {
+			return err
+		}
+		if len(zone.Regions) == 0 {
+			return nil
+		}
+		for i := range zone.Regions {
+			zone.Regions[i].ID = 0
+			zone.Regions[i].ZoneID = zone.ID
+		}
+		return tx.Create(&zone.Regions).Error
+	})
+}
+
+func (r *shippingRepository) DeleteZone(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		methodIDs := tx.Model(&models.ShippingMethod{}).Select("id").Where("zone_id = ?", id)
+		if err := tx.Where("method_id IN (?)", methodIDs).Delete(&models.ShippingRateTier{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("zone_id = ?", id).Delete(&models.ShippingMethod{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("zone_id = ?", id).Delete(&models.ShippingZoneRegion{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ShippingZone{}, id).Error
+	})
+}
+
+func (r *shippingRepository) GetMethodByResourceID(ctx context.Context, resourceID string) (*models.ShippingMethod, error) {
+	var method models.ShippingMethod
+	err := r.db.WithContext(ctx).
+		Preload("Tiers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("min_weight asc")
+		}).
+		Where("resource_id = ?", resourceID).
+		First(&method).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &method, nil
+}
+
+func (r *shippingRepository) CreateMethod(ctx context.Context, method *models.ShippingMethod) error {
+	return r.db.WithContext(ctx).Create(method).Error
+}
+
+func (r *shippingRepository) UpdateMethod(ctx context.Context, method *models.ShippingMethod, replaceTiers bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(method).Error; err != nil {
+			return err
+		}
+		if !replaceTiers {
+			return nil
+		}
+		if err := tx.Where("method_id = ?", method.ID).Delete(&models.ShippingRateTier{}).Error; err != nil {
+			return err
+		}
+		if len(method.Tiers) == 0 {
+			return nil
+		}
+		for i := range method.Tiers {
+			method.Tiers[i].ID = 0
+			method.Tiers[i].MethodID = method.ID
+		}
+		return tx.Create(&method.Tiers).Error
+	})
+}
+
+func (r *shippingRepository) DeleteMethod(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("method_id = ?", id).Delete(&models.ShippingRateTier{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ShippingMethod{}, id).Error
+	})
+}
+
+func (r *shippingRepository) preloadZone(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Regions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("country asc, state asc")
+		}).
+		Preload("Methods", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order asc, id asc")
+		}).
+		Preload("Methods.Tiers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("min_weight asc")
+		})
+}
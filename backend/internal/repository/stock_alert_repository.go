@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+
+	"electronics-store/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockAlertRepository interface {
+	// Create subscribes a user to a product; subscribing twice is a no-op
+	Create(ctx context.Context, alert *models.StockAlert) error
+	Exists(ctx context.Context, userID, productID uint) (bool, error)
+	Delete(ctx context.Context, userID, productID uint) error
+	// ListOrderable returns up to limit alerts with an ID greater than
+	// afterID whose product can be ordered again, with the user and the
+	// product's images loaded
+	ListOrderable(ctx context.Context, afterID uint, limit int) ([]*models.StockAlert, error)
+	DeleteByID(ctx context.Context, id uint) (bool, error)
+
+	// Outbox
+	EnqueueEmail(ctx context.Context, email *models.OutboxEmail) error
+	CreateNotification(ctx context.Context, notification *models.Notification) error
+	// Transaction runs fn with a repository bound to a single database transaction
+	Transaction(ctx context.Context, fn func(tx StockAlertRepository) error) error
+}
+
+type stockAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewStockAlertRepository(db *gorm.DB) StockAlertRepository {
+	return &stockAlertRepository{db: db}
+}
+
+func (r *stockAlertRepository) Create(ctx context.Context, alert *models.StockAlert) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(alert).Error
+}
+
+func (r *stockAlertRepository) Exists(ctx context.Context, userID, productID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.StockAlert{}).
+		Where("user_id = ? AND product_id = ?", userID, productID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *stockAlertRepository) Delete(ctx context.Context, userID, productID uint) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND product_id = ?", userID, productID).
+		Delete(&models.StockAlert{}).Error
+}
+
+func (r *stockAlertRepository) ListOrderable(ctx context.Context, afterID uint, limit int) ([]*models.StockAlert, error) {
+	var alerts []*models.StockAlert
+	err := r.db.WithContext(ctx).
+		Joins("JOIN products ON products.id = stock_alerts.product_id").
+		Where("products.is_active = ? AND products.deleted_at IS NULL", true).
+		Where("products.stock_quantity > 0 OR products.track_quantity = ? OR products.allow_backorder = ?", false, true).
+		Where("stock_alerts.id > ?", afterID).
+		Preload("User").
+		Preload("Product").
+		Preload("Product.Images").
+		Order("stock_alerts.id").
+		Limit(limit).
+		Find(&alerts).Error
+	return alerts, err
+}
+
+// DeleteByID removes an alert, reporting false if it was already gone
+func (r *stockAlertRepository) DeleteByID(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&models.StockAlert{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// EnqueueEmail writes an email to the outbox, so it is only sent if the
+// surrounding transaction commits
+func (r *stockAlertRepository) EnqueueEmail(ctx context.Context, email *models.OutboxEmail) error {
+	return r.db.WithContext(ctx).Create(email).Error
+}
+
+// CreateNotification adds an entry to a user's in-app inbox, which appears
+// only if the surrounding transaction commits
+func (r *stockAlertRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	return r.db.WithContext(ctx).Create(notification).Error
+}
+
+func (r *stockAlertRepository) Transaction(ctx context.Context, fn func(tx StockAlertRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&stockAlertRepository{db: tx})
+	})
+}
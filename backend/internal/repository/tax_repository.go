@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"electronics-store/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type TaxRuleRepository interface {
+	List(ctx context.Context, limit, offset int, filters map[string]interface{}) ([]*models.TaxRule, error)
+	Count(ctx context.Context, filters map[string]interface{}) (int64, error)
+	// ListForRegion returns the active rules for a country, both country-wide
+	// and those for the given state
+	ListForRegion(ctx context.Context, country, state string) ([]*models.TaxRule, error)
+	GetByResourceID(ctx context.Context, resourceID string) (*models.TaxRule, error)
+	Create(ctx context.Context, rule *models.TaxRule) error
+	Update(ctx context.Context, rule *models.TaxRule) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type taxRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxRuleRepository(db *gorm.DB) TaxRuleRepository {
+	return &taxRuleRepository{db: db}
+}
+
+func (r *taxRuleRepository) List(ctx context.Context, limit, offset int, filters map[string]interface{}) ([]*models.TaxRule, error) {
+	var rules []*models.TaxRule
+	err := applyTaxRuleFilters(r.db.WithContext(ctx), filters).
+		Order("country asc, state asc, name asc").
+		Limit(limit).
+		Offset(offset).
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *taxRuleRepository) Count(ctx context.Context, filters map[string]interface{}) (int64, error) {
+	var count int64
+	err := applyTaxRuleFilters(r.db.WithContext(ctx).Model(&models.TaxRule{}), filters).Count(&count).Error
+	return count, err
+}
+
+func (r *taxRuleRepository) ListForRegion(ctx context.Context, country, state string) ([]*models.TaxRule, error) {
+	var rules []*models.TaxRule
+	err := r.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Where("country = ?", country).
+		Where("state = '' OR state IS NULL OR state = ?", state).
+		Order("id asc").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *taxRuleRepository) GetByResourceID(ctx context.Context, resourceID string) (*models.TaxRule, error) {
+	var rule models.TaxRule
+	err := r.db.WithContext(ctx).Where("resource_id = ?", resourceID).First(&rule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *taxRuleRepository) Create(ctx context.Context, rule *models.TaxRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+func (r *taxRuleRepository) Update(ctx context.Context, rule *models.TaxRule) error {
+	return r.db.WithContext(ctx).Save(rule).Error
+}
+
+func (r *taxRuleRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.TaxRule{}, id).Error
+}
+
+func applyTaxRuleFilters(query *gorm.DB, filters map[string]interface{}) *gorm.DB {
+	if country, ok := filters["country"]; ok {
+		query = query.Where("country = ?", country)
+	}
+	if state, ok := filters["state"]; ok {
+		query = query.Where("state = ?", state)
+	}
+	if isActive, ok := filters["is_active"]; ok {
+		query = query.Where("is_active = ?", isActive)
+	}
+	return query
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"electronics-store/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository interface {
+	GetByUserID(ctx context.Context, userID uint) (*models.TwoFactorAuth, error)
+	// Save creates or replaces the user's enrollment
+	Save(ctx context.Context, twoFactor *models.TwoFactorAuth) error
+	// DeleteByUserID removes the user's enrollment and recovery codes
+	DeleteByUserID(ctx context.Context, userID uint) error
+	// AdvanceLastUsedStep records step as the last accepted time step. It
+	// returns false if a code of that step or a later one was already
+	// accepted.
+	AdvanceLastUsedStep(ctx context.Context, id uint, step int64) (bool, error)
+	// ReplaceRecoveryCodes deletes the user's recovery codes and stores new
+	// ones by hash
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error
+	// UseRecoveryCode spends an unused recovery code of the user, returning
+	// false if there is none with that hash
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID uint) (int64, error)
+	// Transaction runs fn with a repository bound to a single database transaction
+	Transaction(ctx context.Context, fn func(tx TwoFactorRepository) error) error
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) GetByUserID(ctx context.Context, userID uint) (*models.TwoFactorAuth, error) {
+	var twoFactor models.TwoFactorAuth
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&twoFactor).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepository) Save(ctx context.Context, twoFactor *models.TwoFactorAuth) error {
+	return r.db.WithContext(ctx).Save(twoFactor).Error
+}
+
+func (r *twoFactorRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.TwoFactorAuth{}).Error
+	})
+}
+
+func (r *twoFactorRepository) AdvanceLastUsedStep(ctx context.Context, id uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.TwoFactorAuth{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		Update("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.TwoFactorRecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = models.TwoFactorRecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *twoFactorRepository) CountUnusedRecoveryCodes(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *twoFactorRepository) Transaction(ctx context.Context, fn func(tx TwoFactorRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&twoFactorRepository{db: tx})
+	})
+}
@@ -103,7 +103,7 @@ func (r *userRepository) Delete(ctx context.Context, id uint) error {
 
 func (r *userRepository) List(ctx context.Context, limit, offset int) ([]*models.User, error) {
 	var users []*models.User
-	err := r.db.WithContext(ctx).Limit(limit).Offset(offset).Find(&users).Error
+	err := r.db.WithContext(ctx).Preload("Roles").Limit(limit).Offset(offset).Find(&users).Error
 	return users, err
 }
 
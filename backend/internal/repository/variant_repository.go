@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"electronics-store/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VariantRepository stores product options and the variants made of their
+// values
+type VariantRepository interface {
+	// ListOptions returns a product's options with their values, in position order
+	ListOptions(ctx context.Context, productID uint) ([]*models.ProductOption, error)
+	GetOption(ctx context.Context, productID uint, resourceID string) (*models.ProductOption, error)
+	CreateOption(ctx context.Context, option *models.ProductOption) error
+	// UpdateOption saves the option and its values, deleting the values
+	// whose IDs are in removedValueIDs
+	UpdateOption(ctx context.Context, option *models.ProductOption, removedValueIDs []uint) error
+	DeleteOption(ctx context.Context, id uint) error
+	// OptionValuesInUse reports whether a variant is made of any of the values
+	OptionValuesInUse(ctx context.Context, valueIDs []uint) (bool, error)
+
+	// ListVariants returns a product's variants with their product, option
+	// values (and each value's option) and images loaded
+	ListVariants(ctx context.Context, productID uint) ([]*models.Variant, error)
+	GetVariant(ctx context.Context, productID uint, resourceID string) (*models.Variant, error)
+	GetVariantByID(ctx context.Context, id uint) (*models.Variant, error)
+	GetVariantBySKU(ctx context.Context, sku string) (*models.Variant, error)
+	CountVariants(ctx context.Context, productID uint) (int64, error)
+	CreateVariant(ctx context.Context, variant *models.Variant) error
+	// UpdateVariant saves the variant and, when set, replaces its option
+	// values or its images
+	UpdateVariant(ctx context.Context, variant *models.Variant, replaceOptions, replaceImages bool) error
+	// DeleteVariant removes a variant and its images. Cart lines of it are
+	// removed and order lines keep the product only.
+	DeleteVariant(ctx context.Context, id uint) error
+}
+
+type variantRepository struct {
+	db *gorm.DB
+}
+
+func NewVariantRepository(db *gorm.DB) VariantRepository {
+	return &variantRepository{db: db}
+}
+
+func (r *variantRepository) ListOptions(ctx context.Context, productID uint) ([]*models.ProductOption, error) {
+	var options []*models.ProductOption
+	err := r.db.WithContext(ctx).
+		Preload("Values", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		}).
+		Where("product_id = ?", productID).
+		Order("position, id").
+		Find(&options).Error
+	return options, err
+}
+
+func (r *variantRepository) GetOption(ctx context.Context, productID uint, resourceID string) (*models.ProductOption, error) {
+	var option models.ProductOption
+	err := r.db.WithContext(ctx).
+		Preload("Values", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		}).
+		Where("product_id = ? AND resource_id = ?", productID, resourceID).
+		First(&option).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &option, nil
+}
+
+func (r *variantRepository) CreateOption(ctx context.Context, option *models.ProductOption) error {
+	return r.db.WithContext(ctx).Create(option).Error
+}
+
+func (r *variantRepository) UpdateOption(ctx context.Context, option *models.ProductOption, removedValueIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(option).Error; err != nil {
+			return err
+		}
+		if len(removedValueIDs) > 0 {
+			if err := tx.Delete(&models.ProductOptionValue{}, removedValueIDs).Error; err != nil {
+				return err
+			}
+		}
+		for i := range option.Values {
+			option.Values[i].OptionID = option.ID
+			if err := tx.Omit(clause.Associations).Save(&option.Values[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *variantRepository) DeleteOption(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("option_id = ?", id).Delete(&models.ProductOptionValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ProductOption{}, id).Error
+	})
+}
+
+func (r *variantRepository) OptionValuesInUse(ctx context.Context, valueIDs []uint) (bool, error) {
+	if len(valueIDs) == 0 {
+		return false, nil
+	}
+	var count int64
+	err := r.db.WithContext(ctx).Model(&variantOptionValue{}).
+		Where("option_value_id IN ?", valueIDs).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// preloadVariant loads what a variant response needs
+func (r *variantRepository) preloadVariant(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Product").
+		Preload("OptionValues.Option").
+		Preload("Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order, id")
+		})
+}
+
+func (r *variantRepository) ListVariants(ctx context.Context, productID uint) ([]*models.Variant, error) {
+	var variants []*models.Variant
+	err := r.preloadVariant(r.db.WithContext(ctx)).
+		Where("product_id = ?", productID).
+		Order("id").
+		Find(&variants).Error
+	return variants, err
+}
+
+func (r *variantRepository) GetVariant(ctx context.Context, productID uint, resourceID string) (*models.Variant, error) {
+	var variant models.Variant
+	err := r.preloadVariant(r.db.WithContext(ctx)).
+		Where("product_id = ? AND resource_id = ?", productID, resourceID).
+		First(&variant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+func (r *variantRepository) GetVariantByID(ctx context.Context, id uint) (*models.Variant, error) {
+	var variant models.Variant
+	err := r.db.WithContext(ctx).First(&variant, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+func (r *variantRepository) GetVariantBySKU(ctx context.Context, sku string) (*models.Variant, error) {
+	var variant models.Variant
+	err := r.db.WithContext(ctx).Where("sku = ?", sku).First(&variant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+func (r *variantRepository) CountVariants(ctx context.Context, productID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Variant{}).
+		Where("product_id = ?", productID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *variantRepository) CreateVariant(ctx context.Context, variant *models.Variant) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Product", "OptionValues").Create(variant).Error; err != nil {
+			return err
+		}
+		return linkOptionValues(tx, variant)
+	})
+}
+
+func (r *variantRepository) UpdateVariant(ctx context.Context, variant *models.Variant, replaceOptions, replaceImages bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(variant).Error; err != nil {
+			return err
+		}
+		if replaceOptions {
+			if err := tx.Where("variant_id = ?", variant.ID).Delete(&variantOptionValue{}).Error; err != nil {
+				return err
+			}
+			if err := linkOptionValues(tx, variant); err != nil {
+				return err
+			}
+		}
+		if !replaceImages {
+			return nil
+		}
+		if err := tx.Where("variant_id = ?", variant.ID).Delete(&models.VariantImage{}).Error; err != nil {
+			return err
+		}
+		if len(variant.Images) == 0 {
+			return nil
+		}
+		for i := range variant.Images {
+			variant.Images[i].ID = 0
+			variant.Images[i].ResourceID = ""
+			variant.Images[i].VariantID = variant.ID
+		}
+		return tx.Create(&variant.Images).Error
+	})
+}
+
+func (r *variantRepository) DeleteVariant(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("variant_id = ?", id).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.OrderItem{}).Where("variant_id = ?", id).Update("variant_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("variant_id = ?", id).Delete(&models.VariantImage{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("variant_id = ?", id).Delete(&variantOptionValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Variant{}, id).Error
+	})
+}
+
+// variantOptionValue links a variant to one of the option values it is made of
+type variantOptionValue struct {
+	VariantID     uint
+	OptionValueID uint
+}
+
+func (variantOptionValue) TableName() string {
+	return "variant_option_values"
+}
+
+// linkOptionValues records the option values of a saved variant. The values
+// exist already, so only the links are written.
+func linkOptionValues(tx *gorm.DB, variant *models.Variant) error {
+	if len(variant.OptionValues) == 0 {
+		return nil
+	}
+	links := make([]variantOptionValue, 0, len(variant.OptionValues))
+	for _, value := range variant.OptionValues {
+		links = append(links, variantOptionValue{VariantID: variant.ID, OptionValueID: value.ID})
+	}
+	return tx.Create(&links).Error
+}
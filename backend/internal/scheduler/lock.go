@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// Locker hands out named locks shared by every replica, so a job run
+// happens on one of them only
+type Locker interface {
+	// TryLock takes the lock without waiting. It returns false when another
+	// process holds it; otherwise the caller must call unlock when done.
+	TryLock(ctx context.Context, name string) (unlock func(), ok bool, err error)
+}
+
+// lockPrefix namespaces the job locks; MySQL lock names are server-wide
+const lockPrefix = "electronics-store:job:"
+
+type mysqlLocker struct {
+	db *sql.DB
+}
+
+// NewMySQLLocker uses MySQL advisory locks (GET_LOCK). A lock belongs to the
+// connection that took it, so each lock holds on to a pooled connection
+// until it is released, and is dropped by the server if that connection
+// dies.
+func NewMySQLLocker(db *gorm.DB) (Locker, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return &mysqlLocker{db: sqlDB}, nil
+}
+
+func (l *mysqlLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	key := lockPrefix + name
+	if len(key) > 64 {
+		return nil, false, fmt.Errorf("lock name %q is too long", name)
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// GET_LOCK returns 1 when taken, 0 when held elsewhere and NULL on error
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", key).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		// The job's context may be done by now; releasing must still happen
+		if _, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", key); err != nil {
+			log.Printf("Failed to release job lock %s: %v", name, err)
+			// Don't hand a connection that may still hold the lock back to
+			// the pool; discarding it makes the server drop the lock
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+	return unlock, true, nil
+}
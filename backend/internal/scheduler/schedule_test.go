@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func bits(values ...int) uint64 {
+	var b uint64
+	for _, v := range values {
+		b |= 1 << uint(v)
+	}
+	return b
+}
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		field   string
+		want    uint64
+		wantErr bool
+	}{
+		{field: "*", want: 1<<60 - 1},
+		{field: "7", want: bits(7)},
+		{field: "0", want: bits(0)},
+		{field: "59", want: bits(59)},
+		{field: "1-3", want: bits(1, 2, 3)},
+		{field: "1,3,5", want: bits(1, 3, 5)},
+		{field: "*/15", want: bits(0, 15, 30, 45)},
+		{field: "10-20/5", want: bits(10, 15, 20)},
+		{field: "5/20", want: bits(5, 25, 45)},
+		{field: "1-2,40-59/10", want: bits(1, 2, 40, 50)},
+
+		{field: "", wantErr: true},
+		{field: "60", wantErr: true},
+		{field: "-1", wantErr: true},
+		{field: "5-1", wantErr: true},
+		{field: "1-", wantErr: true},
+		{field: "*/0", wantErr: true},
+		{field: "*/x", wantErr: true},
+		{field: "mon", wantErr: true},
+		{field: "1,,2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			got, err := parseCronField(tt.field, 0, 59)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseCronField(%q) = %b, want an error", tt.field, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCronField(%q): %v", tt.field, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseCronField(%q) = %b, want %b", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"61 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"@every",
+		"@every soon",
+		"@every 500ms",
+		"@yearly",
+	}
+	for _, spec := range specs {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	at := func(year int, month time.Month, day, hour, minute, second int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"every quarter hour", "*/15 * * * *", at(2026, 10, 16, 10, 7, 0), at(2026, 10, 16, 10, 15, 0)},
+		{"every quarter hour into the next hour", "*/15 * * * *", at(2026, 10, 16, 10, 45, 30), at(2026, 10, 16, 11, 0, 0)},
+		{"strictly after a matching time", "30 10 * * *", at(2026, 10, 16, 10, 30, 0), at(2026, 10, 17, 10, 30, 0)},
+		{"working hours on a Friday evening", "0 9-17 * * 1-5", at(2026, 10, 16, 17, 30, 0), at(2026, 10, 19, 9, 0, 0)},
+		{"hour list", "0 6,18 * * *", at(2026, 10, 16, 7, 0, 0), at(2026, 10, 16, 18, 0, 0)},
+		{"hourly", "@hourly", at(2026, 10, 16, 10, 0, 0), at(2026, 10, 16, 11, 0, 0)},
+		{"daily across the year end", "@daily", at(2026, 12, 31, 23, 59, 0), at(2027, 1, 1, 0, 0, 0)},
+		{"weekly on Sunday", "@weekly", at(2026, 10, 16, 12, 0, 0), at(2026, 10, 18, 0, 0, 0)},
+		{"monthly", "@monthly", at(2026, 10, 16, 12, 0, 0), at(2026, 11, 1, 0, 0, 0)},
+		{"7 is Sunday", "0 0 * * 7", at(2026, 10, 16, 12, 0, 0), at(2026, 10, 18, 0, 0, 0)},
+		{"leap day", "0 0 29 2 *", at(2026, 3, 1, 0, 0, 0), at(2028, 2, 29, 0, 0, 0)},
+		{"day of month or weekday, weekday first", "0 0 1 * 0", at(2026, 10, 16, 12, 0, 0), at(2026, 10, 18, 0, 0, 0)},
+		{"day of month or weekday, day of month first", "0 0 1 * 1", at(2026, 10, 27, 12, 0, 0), at(2026, 11, 1, 0, 0, 0)},
+		{"day of month with any weekday", "0 0 31 * *", at(2026, 11, 5, 0, 0, 0), at(2026, 12, 31, 0, 0, 0)},
+		{"every ten minutes", "@every 10m", at(2026, 10, 16, 10, 7, 30), at(2026, 10, 16, 10, 10, 0)},
+		{"every ten minutes on the boundary", "@every 10m", at(2026, 10, 16, 10, 10, 0), at(2026, 10, 16, 10, 20, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q): %v", tt.spec, err)
+			}
+			if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+// Package scheduler runs periodic maintenance jobs inside the server
+// process. Every replica runs the scheduler; a database lock per job and a
+// shared record of the last run make sure a due run happens on one replica
+// only.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSchedulerStarted = errors.New("scheduler already started")
+	ErrDuplicateJob     = errors.New("job already registered")
+)
+
+// Job is a unit of periodic work. Runs are never concurrent, and each
+// scheduled time is run by one replica only.
+type Job struct {
+	Name string
+	// Schedule is a cron expression or shorthand, see ParseSchedule
+	Schedule string
+	// Timeout bounds a single run; zero means no limit
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// JobStats are a job's metrics in this process since it started
+type JobStats struct {
+	Name          string
+	Schedule      string
+	Running       bool
+	Runs          int64 // runs executed by this process
+	Failures      int64
+	Skipped       int64 // due runs another replica held the lock for or already ran
+	LastRunAt     *time.Time
+	LastSuccessAt *time.Time
+	LastDuration  time.Duration
+	LastError     string
+	NextRunAt     *time.Time
+}
+
+type job struct {
+	Job
+	schedule Schedule
+
+	mu    sync.Mutex
+	stats JobStats
+}
+
+// RunLog remembers, across replicas, the scheduled time each job last ran
+// for
+type RunLog interface {
+	// LastSlot returns the scheduled time of the job's last run, or the zero
+	// time if it never ran
+	LastSlot(ctx context.Context, name string) (time.Time, error)
+	RecordSlot(ctx context.Context, name string, slot time.Time) error
+}
+
+// Scheduler runs registered jobs on their schedules until stopped
+type Scheduler struct {
+	locker Locker
+	runLog RunLog
+
+	mu      sync.Mutex
+	jobs    []*job
+	started bool
+	stop    context.CancelFunc // ends the scheduling loops
+
+	// runCtx is what runs execute under. It is independent of the context
+	// the scheduler was started with so a shutdown lets running jobs finish,
+	// and is only cancelled by abort when Stop runs out of time.
+	runCtx context.Context
+	abort  context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a scheduler that takes a lock from locker for every run and
+// skips runs runLog says already happened. Nil ones run jobs without
+// locking or checking, which is only safe with a single instance.
+func New(locker Locker, runLog RunLog) *Scheduler {
+	runCtx, abort := context.WithCancel(context.Background())
+	return &Scheduler{
+		locker: locker,
+		runLog: runLog,
+		runCtx: runCtx,
+		abort:  abort,
+	}
+}
+
+// Register adds a job. Jobs must be registered before Start.
+func (s *Scheduler) Register(j Job) error {
+	if j.Name == "" || j.Run == nil {
+		return errors.New("job needs a name and a run function")
+	}
+	schedule, err := ParseSchedule(j.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", j.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return ErrSchedulerStarted
+	}
+	for _, existing := range s.jobs {
+		if existing.Name == j.Name {
+			return fmt.Errorf("%w: %s", ErrDuplicateJob, j.Name)
+		}
+	}
+	s.jobs = append(s.jobs, &job{
+		Job:      j,
+		schedule: schedule,
+		stats:    JobStats{Name: j.Name, Schedule: j.Schedule},
+	})
+	return nil
+}
+
+// Start schedules the registered jobs. It returns immediately; jobs stop
+// being scheduled when ctx is done or Stop is called.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, s.stop = context.WithCancel(ctx)
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Stop stops scheduling runs and waits for the running ones to finish. If
+// ctx is done first their contexts are cancelled and ctx's error returned.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stop != nil {
+		s.stop()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.abort()
+		return ctx.Err()
+	}
+}
+
+// Stats returns the metrics of every job in registration order
+func (s *Scheduler) Stats() []JobStats {
+	s.mu.Lock()
+	jobs := s.jobs
+	s.mu.Unlock()
+
+	stats := make([]JobStats, len(jobs))
+	for i, j := range jobs {
+		j.mu.Lock()
+		stats[i] = j.stats
+		j.mu.Unlock()
+	}
+	return stats
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	next := j.schedule.Next(time.Now())
+	for {
+		if next.IsZero() {
+			log.Printf("Job %s has no upcoming runs", j.Name)
+			return
+		}
+		j.mu.Lock()
+		j.stats.NextRunAt = &next
+		j.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.run(j, next)
+		next = j.schedule.Next(time.Now())
+	}
+}
+
+// run executes the run of j scheduled for slot if this replica gets the
+// job's lock and no replica ran that slot yet
+func (s *Scheduler) run(j *job, slot time.Time) {
+	ctx := s.runCtx
+	if s.locker != nil {
+		unlock, ok, err := s.locker.TryLock(ctx, j.Name)
+		if err != nil {
+			j.fail("failed to take lock", err)
+			return
+		}
+		if !ok {
+			j.skip()
+			return
+		}
+		defer unlock()
+	}
+	if s.runLog != nil {
+		// The lock only keeps runs from overlapping; a replica whose timer
+		// fired a little later would take it right after this one let go
+		last, err := s.runLog.LastSlot(ctx, j.Name)
+		if err != nil {
+			j.fail("failed to read last run", err)
+			return
+		}
+		if !slot.After(last) {
+			j.skip()
+			return
+		}
+	}
+
+	if j.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	j.mu.Lock()
+	j.stats.Running = true
+	j.mu.Unlock()
+
+	err := runSafely(ctx, j.Name, j.Run)
+	duration := time.Since(start)
+	if err != nil {
+		log.Printf("Job %s failed after %s: %v", j.Name, duration, err)
+	}
+	j.record(start, duration, err)
+
+	// A failed run is recorded too; the next slot retries it. The job's
+	// context may be done by now, recording must still happen.
+	if s.runLog != nil {
+		if err := s.runLog.RecordSlot(context.Background(), j.Name, slot); err != nil {
+			log.Printf("Job %s: failed to record run: %v", j.Name, err)
+		}
+	}
+}
+
+// fail counts a run that could not start because of err
+func (j *job) fail(what string, err error) {
+	log.Printf("Job %s: %s: %v", j.Name, what, err)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.stats.Failures++
+	j.stats.LastError = what + ": " + err.Error()
+}
+
+// skip counts a due run left to another replica
+func (j *job) skip() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.stats.Skipped++
+}
+
+// record updates the metrics after a run attempt
+func (j *job) record(start time.Time, duration time.Duration, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.stats.Running = false
+	j.stats.Runs++
+	j.stats.LastRunAt = &start
+	j.stats.LastDuration = duration
+	if err != nil {
+		j.stats.Failures++
+		j.stats.LastError = err.Error()
+		return
+	}
+	j.stats.LastError = ""
+	j.stats.LastSuccessAt = &start
+}
+
+// runSafely turns a panicking job into a failed run instead of a crashed
+// server
+func runSafely(ctx context.Context, name string, run func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Job %s panicked: %v\n%s", name, r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run(ctx)
+}
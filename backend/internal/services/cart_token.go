@@ -0,0 +1,58 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// placeholderCartTokenSecret is the secret that shipped as the default and
+// example; anyone could sign a token for any guest cart with it
+const placeholderCartTokenSecret = "change-me-cart-token-secret"
+
+// CartTokenSigner signs the token a guest's cart cookie holds: the cart's
+// resource ID and an HMAC-SHA256 of it, so a client can't point its cookie
+// at another cart
+type CartTokenSigner struct {
+	key []byte
+}
+
+// NewCartTokenSigner signs tokens with secret. A missing or placeholder
+// secret is only accepted in devMode.
+func NewCartTokenSigner(secret string, devMode bool) (*CartTokenSigner, error) {
+	if !devMode {
+		if secret == "" {
+			return nil, errors.New("CART_TOKEN_SECRET must be set")
+		}
+		if secret == placeholderCartTokenSecret {
+			return nil, errors.New("CART_TOKEN_SECRET is still the example value")
+		}
+	}
+	return &CartTokenSigner{key: []byte(secret)}, nil
+}
+
+// Sign returns the token for the cart with the given resource ID
+func (s *CartTokenSigner) Sign(cartResourceID string) string {
+	return cartResourceID + "." + s.signature(cartResourceID)
+}
+
+// Verify returns the cart resource ID of a token made by Sign, or false
+// when the token is malformed or its signature doesn't match
+func (s *CartTokenSigner) Verify(token string) (string, bool) {
+	cartResourceID, signature, found := strings.Cut(token, ".")
+	if !found || cartResourceID == "" {
+		return "", false
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.signature(cartResourceID))) {
+		return "", false
+	}
+	return cartResourceID, true
+}
+
+func (s *CartTokenSigner) signature(cartResourceID string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte("cart:" + cartResourceID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
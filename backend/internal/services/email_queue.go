@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"electronics-store/internal/config"
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/repository"
+)
+
+var (
+	ErrOutboxEmailNotFound = errors.New("email not found")
+	ErrOutboxEmailNotDead  = errors.New("only dead emails can be retried")
+)
+
+// emailLease is how long a worker owns a claimed email. It is well above
+// smtpTimeout and the webhook timeout, so another worker only takes over
+// from one that died.
+const emailLease = 5 * time.Minute
+
+// NewOutboxEmail turns a rendered email into an outbox row, to be written
+// in the same transaction as the change that caused it. kind names the
+// email for tracking; expiresAt, if set, is when it is no longer worth
+// sending.
+func NewOutboxEmail(kind string, data EmailData, expiresAt *time.Time) *models.OutboxEmail {
+	return &models.OutboxEmail{
+		Kind:      kind,
+		ToEmail:   data.ToEmail,
+		ToName:    data.ToName,
+		Subject:   data.Subject,
+		TextBody:  data.TextBody,
+		HTMLBody:  data.HTMLBody,
+		ExpiresAt: expiresAt,
+	}
+}
+
+// EmailQueue delivers the emails in the outbox table with a pool of
+// workers, handing each entry to the OutboxChannel of its channel. Failed
+// attempts are retried with exponential backoff until cfg.MaxAttempts,
+// after which the email is dead-lettered. Every instance can run workers;
+// claiming an email is atomic, so each is sent once.
+type EmailQueue struct {
+	outboxRepo repository.EmailOutboxRepository
+	channels   map[string]OutboxChannel
+	cfg        config.OutboxConfig
+
+	wake chan struct{}
+	mu   sync.Mutex
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+func NewEmailQueue(outboxRepo repository.EmailOutboxRepository, channels map[string]OutboxChannel, cfg config.OutboxConfig) *EmailQueue {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	return &EmailQueue{
+		outboxRepo: outboxRepo,
+		channels:   channels,
+		cfg:        cfg,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// Enqueue writes an email that isn't tied to any other change to the
+// outbox and wakes the workers
+func (q *EmailQueue) Enqueue(ctx context.Context, email *models.OutboxEmail) error {
+	if err := q.outboxRepo.Create(ctx, email); err != nil {
+		return err
+	}
+	q.Notify()
+	return nil
+}
+
+// Notify wakes the workers to look for new emails now rather than at the
+// next poll. Call it once the transaction that wrote them has committed.
+func (q *EmailQueue) Notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Retry puts a dead email back in the queue
+func (q *EmailQueue) Retry(ctx context.Context, resourceID string) error {
+	email, err := q.outboxRepo.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+	if email == nil {
+		return ErrOutboxEmailNotFound
+	}
+	requeued, err := q.outboxRepo.Requeue(ctx, email.ID)
+	if err != nil {
+		return err
+	}
+	if !requeued {
+		return ErrOutboxEmailNotDead
+	}
+	q.Notify()
+	return nil
+}
+
+// Start runs the workers until ctx is done or Stop is called. With no
+// workers configured it does nothing.
+func (q *EmailQueue) Start(ctx context.Context) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.stop != nil || q.cfg.Workers <= 0 {
+		return
+	}
+
+	ctx, q.stop = context.WithCancel(ctx)
+	ids := make(chan uint)
+	for i := 0; i < q.cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.work(ids)
+	}
+	q.wg.Add(1)
+	go q.dispatch(ctx, ids)
+}
+
+// Stop stops picking up emails and waits for the ones being sent. Emails
+// still being sent when ctx is done are retried once their lease runs out.
+func (q *EmailQueue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if q.stop != nil {
+		q.stop()
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dispatch hands due emails to the workers on every poll or wake-up
+func (q *EmailQueue) dispatch(ctx context.Context, ids chan<- uint) {
+	defer q.wg.Done()
+	defer close(ids)
+
+	ticker := time.NewTicker(q.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		due, err := q.outboxRepo.ListDueIDs(ctx, time.Now(), q.cfg.Workers*10)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to list queued emails: %v", err)
+		}
+		for _, id := range due {
+			select {
+			case ids <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+func (q *EmailQueue) work(ids <-chan uint) {
+	defer q.wg.Done()
+
+	// Deliveries aren't cut off by Stop; an interrupted one would be sent
+	// again by whoever takes over the lease
+	ctx := context.Background()
+	for id := range ids {
+		if err := q.deliver(ctx, id); err != nil {
+			log.Printf("Failed to process queued email %d: %v", id, err)
+		}
+	}
+}
+
+// deliver claims an email, sends it and records the outcome
+func (q *EmailQueue) deliver(ctx context.Context, id uint) error {
+	now := time.Now()
+	email, err := q.outboxRepo.Claim(ctx, id, now, now.Add(emailLease))
+	if err != nil || email == nil {
+		return err
+	}
+
+	if email.ExpiresAt != nil && now.After(*email.ExpiresAt) {
+		log.Printf("Dead-lettered %s email %s: expired before it could be sent", email.Kind, email.ResourceID)
+		return q.outboxRepo.MarkFailed(ctx, email.ID, "expired before it could be sent", nil)
+	}
+
+	channel := q.channels[email.Channel]
+	if channel == nil {
+		log.Printf("Dead-lettered %s email %s: no %s channel configured", email.Kind, email.ResourceID, email.Channel)
+		return q.outboxRepo.MarkFailed(ctx, email.ID, "no "+email.Channel+" channel configured", nil)
+	}
+
+	err = channel.Deliver(email)
+	if err == nil {
+		return q.outboxRepo.MarkSent(ctx, email.ID)
+	}
+
+	if email.Attempts >= q.cfg.MaxAttempts {
+		log.Printf("Dead-lettered %s email %s after %d attempts: %v", email.Kind, email.ResourceID, email.Attempts, err)
+		return q.outboxRepo.MarkFailed(ctx, email.ID, err.Error(), nil)
+	}
+	next := time.Now().Add(q.backoff(email.Attempts))
+	return q.outboxRepo.MarkFailed(ctx, email.ID, err.Error(), &next)
+}
+
+// backoff is the wait after the given number of failed attempts
+func (q *EmailQueue) backoff(attempts int) time.Duration {
+	delay := q.cfg.RetryBase
+	for i := 1; i < attempts && delay < q.cfg.RetryMax; i++ {
+		delay *= 2
+	}
+	if q.cfg.RetryMax > 0 && delay > q.cfg.RetryMax {
+		delay = q.cfg.RetryMax
+	}
+	return delay
+}
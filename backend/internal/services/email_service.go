@@ -1,148 +1,195 @@
 package services
 
 import (
-	"bytes"
 	"fmt"
-	"html/template"
-	"net/smtp"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"electronics-store/internal/config"
+	"electronics-store/internal/domain/models"
 )
 
+// EmailService renders the emails of the template registry. Sending them
+// is up to a Mailer.
 type EmailService struct {
-	config *config.EmailConfig
+	config    *config.EmailConfig
+	templates *EmailTemplates
 }
 
 type EmailData struct {
 	ToEmail   string
 	ToName    string
 	Subject   string
-	Body      string
+	TextBody  string
 	HTMLBody  string
 	OTPCode   string
 	UserName  string
 	ExpiresIn int // in minutes
 }
 
-type EmailTemplate struct {
-	Title       string
-	Greeting    string
-	Message     string
-	OTPCode     string
-	ExpiresIn   int
-	Footer      string
-	CompanyName string
-	SupportURL  string
-}
+// NewEmailService loads the email templates from cfg.TemplatesDir, or the
+// built-in ones when it is empty, and renders every preview so a broken
+// template fails at startup rather than when the first order ships
+func NewEmailService(cfg *config.EmailConfig) (*EmailService, error) {
+	s := &EmailService{config: cfg}
 
-func NewEmailService(cfg *config.EmailConfig) *EmailService {
-	return &EmailService{
-		config: cfg,
+	fsys := BuiltinEmailTemplates()
+	if cfg.TemplatesDir != "" {
+		fsys = os.DirFS(cfg.TemplatesDir)
 	}
-}
-
-// SendOTPEmail sends OTP verification email
-func (s *EmailService) SendOTPEmail(email, name, otpCode, otpType string) error {
-	subject := s.getOTPSubject(otpType)
-	htmlBody, err := s.generateOTPEmailHTML(name, otpCode, otpType)
+	templates, err := LoadEmailTemplates(fsys, s.templateFuncs())
 	if err != nil {
-		return fmt.Errorf("failed to generate email template: %w", err)
+		return nil, fmt.Errorf("failed to load email templates: %w", err)
 	}
+	s.templates = templates
 
-	emailData := EmailData{
-		ToEmail:  email,
-		ToName:   name,
-		Subject:  subject,
-		HTMLBody: htmlBody,
-		OTPCode:  otpCode,
-		UserName: name,
-		ExpiresIn: 15, // 15 minutes
+	for _, info := range EmailTemplateList() {
+		if _, err := s.Preview(info.Name); err != nil {
+			return nil, err
+		}
 	}
-
-	return s.sendEmail(emailData)
+	return s, nil
 }
 
-// SendWelcomeEmail sends welcome email after successful registration
-func (s *EmailService) SendWelcomeEmail(email, name string) error {
-	subject := "Welcome to Electronics Store! 🎉"
-	htmlBody, err := s.generateWelcomeEmailHTML(name)
+// OTPEmail renders the OTP verification email
+func (s *EmailService) OTPEmail(email, name, otpCode, otpType string) (EmailData, error) {
+	data, err := s.render(EmailTemplateOTP, email, name, otpEmailData{
+		Name:      name,
+		Title:     s.getOTPSubject(otpType),
+		Message:   s.getOTPMessage(otpType),
+		Code:      otpCode,
+		ExpiresIn: 15, // 15 minutes
+	})
 	if err != nil {
-		return fmt.Errorf("failed to generate welcome email: %w", err)
+		return EmailData{}, err
 	}
-
-	emailData := EmailData{
-		ToEmail:  email,
-		ToName:   name,
-		Subject:  subject,
-		HTMLBody: htmlBody,
-		UserName: name,
-	}
-
-	return s.sendEmail(emailData)
+	data.OTPCode = otpCode
+	data.UserName = name
+	data.ExpiresIn = 15
+	return data, nil
 }
 
-// SendPasswordResetEmail sends password reset email
-func (s *EmailService) SendPasswordResetEmail(email, name, otpCode string) error {
-	subject := "Password Reset Request - Electronics Store"
-	htmlBody, err := s.generatePasswordResetEmailHTML(name, otpCode)
+// WelcomeEmail renders the welcome email sent after registration
+func (s *EmailService) WelcomeEmail(email, name string) (EmailData, error) {
+	data, err := s.render(EmailTemplateWelcome, email, name, welcomeEmailData{Name: name})
 	if err != nil {
-		return fmt.Errorf("failed to generate password reset email: %w", err)
+		return EmailData{}, err
 	}
+	data.UserName = name
+	return data, nil
+}
 
-	emailData := EmailData{
-		ToEmail:  email,
-		ToName:   name,
-		Subject:  subject,
-		HTMLBody: htmlBody,
-		OTPCode:  otpCode,
-		UserName: name,
-		ExpiresIn: 15,
-	}
+// PasswordResetEmail renders the password reset email
+func (s *EmailService) PasswordResetEmail(email, name, otpCode string) (EmailData, error) {
+	return s.OTPEmail(email, name, otpCode, "password_reset")
+}
 
-	return s.sendEmail(emailData)
+// OrderConfirmationEmail renders the confirmation of a placed order. The
+// order needs its user and line items loaded.
+func (s *EmailService) OrderConfirmationEmail(order *models.Order) (EmailData, error) {
+	return s.renderOrderEmail(EmailTemplateOrderConfirmation, order, nil)
 }
 
-// sendEmail sends email using SMTP
-func (s *EmailService) sendEmail(data EmailData) error {
-	// Create message
-	message := s.createMessage(data)
+// OrderShippedEmail renders the shipping notice of an order, including
+// its tracking details when it has them
+func (s *EmailService) OrderShippedEmail(order *models.Order) (EmailData, error) {
+	return s.renderOrderEmail(EmailTemplateOrderShipped, order, nil)
+}
 
-	// Setup authentication
-	auth := smtp.PlainAuth("", s.config.SMTPUsername, s.config.SMTPPassword, s.config.SMTPHost)
+// OrderDeliveredEmail renders the delivery notice of an order
+func (s *EmailService) OrderDeliveredEmail(order *models.Order) (EmailData, error) {
+	return s.renderOrderEmail(EmailTemplateOrderDelivered, order, nil)
+}
 
-	// Send email
-	addr := fmt.Sprintf("%s:%d", s.config.SMTPHost, s.config.SMTPPort)
-	
-	if s.config.UseSSL {
-		return s.sendEmailSSL(addr, auth, data, message)
-	}
-	
-	return smtp.SendMail(addr, auth, s.config.FromEmail, []string{data.ToEmail}, message)
+// RefundIssuedEmail renders the notice of a refund issued for an order
+func (s *EmailService) RefundIssuedEmail(order *models.Order, refund *models.Refund) (EmailData, error) {
+	return s.renderOrderEmail(EmailTemplateRefundIssued, order, refund)
 }
 
-// sendEmailSSL sends email using SSL (for ports like 465)
-func (s *EmailService) sendEmailSSL(addr string, auth smtp.Auth, data EmailData, message []byte) error {
-	// This would require a more complex implementation with TLS
-	// For now, we'll use the standard SMTP with TLS
-	return smtp.SendMail(addr, auth, s.config.FromEmail, []string{data.ToEmail}, message)
+// LowStockItem is a product or variant whose stock fell to or below its
+// low stock threshold
+type LowStockItem struct {
+	Name      string
+	Variant   string
+	SKU       string
+	Stock     int
+	Threshold int
 }
 
-// createMessage creates the email message
-func (s *EmailService) createMessage(data EmailData) []byte {
-	headers := make(map[string]string)
-	headers["From"] = fmt.Sprintf("%s <%s>", s.config.FromName, s.config.FromEmail)
-	headers["To"] = fmt.Sprintf("%s <%s>", data.ToName, data.ToEmail)
-	headers["Subject"] = data.Subject
-	headers["MIME-Version"] = "1.0"
-	headers["Content-Type"] = "text/html; charset=UTF-8"
+// LowStockAlertEmails renders a low stock alert for each configured alert
+// recipient; there are none when no recipients are configured
+func (s *EmailService) LowStockAlertEmails(items []LowStockItem) ([]EmailData, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	emails := make([]EmailData, 0, len(s.config.AlertRecipients))
+	for _, recipient := range s.config.AlertRecipients {
+		data, err := s.renderLowStockAlert(recipient, items)
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, data)
+	}
+	return emails, nil
+}
 
-	message := ""
-	for k, v := range headers {
-		message += fmt.Sprintf("%s: %s\r\n", k, v)
+// BackInStockEmail tells a customer that a product they asked to be
+// alerted about can be ordered again. The product needs its images loaded.
+func (s *EmailService) BackInStockEmail(user *models.User, product *models.Product) (EmailData, error) {
+	name := recipientName(user)
+	view := productEmailView{
+		Name:     product.Name,
+		URL:      s.storeURL() + "/products/" + product.ResourceID,
+		Price:    product.Price,
+		Currency: "USD",
+	}
+	if image := primaryImage(product.Images); image != nil {
+		view.ImageURL = image.URL
 	}
-	message += "\r\n" + data.HTMLBody
+	return s.render(EmailTemplateBackInStock, user.Email, name, backInStockEmailData{
+		Name:    name,
+		Product: view,
+	})
+}
 
-	return []byte(message)
+// Preview renders an email of the registry with sample data
+func (s *EmailService) Preview(name string) (EmailData, error) {
+	order := sampleOrder()
+	switch name {
+	case EmailTemplateOTP:
+		return s.OTPEmail(order.User.Email, order.User.FirstName, "123456", "email_verification")
+	case EmailTemplateWelcome:
+		return s.WelcomeEmail(order.User.Email, order.User.FirstName)
+	case EmailTemplateOrderConfirmation:
+		return s.OrderConfirmationEmail(order)
+	case EmailTemplateOrderShipped:
+		return s.OrderShippedEmail(order)
+	case EmailTemplateOrderDelivered:
+		return s.OrderDeliveredEmail(order)
+	case EmailTemplateRefundIssued:
+		return s.RefundIssuedEmail(order, &models.Refund{
+			Amount:   order.OrderItems[1].Total,
+			Currency: order.Currency,
+			Reason:   "Returned unopened",
+			Items: []models.RefundItem{
+				{OrderItemID: order.OrderItems[1].ID, Quantity: order.OrderItems[1].Quantity, Amount: order.OrderItems[1].Total},
+			},
+		})
+	case EmailTemplateLowStockAlert:
+		items := []LowStockItem{
+			{Name: order.OrderItems[0].Product.Name, Variant: order.OrderItems[0].Variant.Name, SKU: order.OrderItems[0].Variant.SKU, Stock: 2, Threshold: 5},
+			{Name: order.OrderItems[1].Product.Name, SKU: order.OrderItems[1].Product.SKU, Stock: 0, Threshold: 10},
+		}
+		return s.renderLowStockAlert("staff@example.com", items)
+	case EmailTemplateBackInStock:
+		return s.BackInStockEmail(&order.User, &order.OrderItems[0].Product)
+	default:
+		return EmailData{}, fmt.Errorf("%w: %s", ErrEmailTemplateNotFound, name)
+	}
 }
 
 // getOTPSubject returns appropriate subject based on OTP type
@@ -159,160 +206,320 @@ func (s *EmailService) getOTPSubject(otpType string) string {
 	}
 }
 
-// generateOTPEmailHTML generates HTML email template for OTP
-func (s *EmailService) generateOTPEmailHTML(name, otpCode, otpType string) (string, error) {
-	templateData := EmailTemplate{
-		Title:       s.getOTPSubject(otpType),
-		Greeting:    fmt.Sprintf("Hello %s,", name),
-		Message:     s.getOTPMessage(otpType),
-		OTPCode:     otpCode,
-		ExpiresIn:   15,
-		Footer:      "If you didn't request this code, please ignore this email.",
-		CompanyName: "Electronics Store",
-		SupportURL:  "https://electronicsstore.com/support",
+// getOTPMessage returns appropriate message based on OTP type
+func (s *EmailService) getOTPMessage(otpType string) string {
+	switch otpType {
+	case "email_verification":
+		return "Thank you for registering with Electronics Store! Please verify your email address using the code below to complete your registration."
+	case "password_reset":
+		return "We received a request to reset your password. Use the code below to reset your password:"
+	case "login":
+		return "Use the code below to complete your login to Electronics Store:"
+	default:
+		return "Please use the code below to complete your verification:"
 	}
+}
 
-	tmpl := `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>{{.Title}}</title>
-    <style>
-        body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; margin: 0; padding: 0; background-color: #f4f4f4; }
-        .container { max-width: 600px; margin: 0 auto; background-color: #ffffff; }
-        .header { background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 30px; text-align: center; }
-        .content { padding: 40px 30px; }
-        .otp-code { background-color: #f8f9fa; border: 2px dashed #dee2e6; border-radius: 8px; padding: 20px; text-align: center; margin: 30px 0; }
-        .otp-number { font-size: 32px; font-weight: bold; color: #495057; letter-spacing: 8px; font-family: 'Courier New', monospace; }
-        .message { color: #6c757d; line-height: 1.6; margin: 20px 0; }
-        .footer { background-color: #f8f9fa; padding: 20px; text-align: center; color: #6c757d; font-size: 14px; }
-        .button { display: inline-block; background-color: #007bff; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; margin: 20px 0; }
-        .warning { background-color: #fff3cd; border: 1px solid #ffeaa7; color: #856404; padding: 15px; border-radius: 5px; margin: 20px 0; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>{{.CompanyName}}</h1>
-            <h2>{{.Title}}</h2>
-        </div>
-        <div class="content">
-            <p>{{.Greeting}}</p>
-            <div class="message">{{.Message}}</div>
-            
-            <div class="otp-code">
-                <p style="margin: 0 0 10px 0; color: #6c757d;">Your verification code is:</p>
-                <div class="otp-number">{{.OTPCode}}</div>
-                <p style="margin: 10px 0 0 0; color: #6c757d; font-size: 14px;">This code expires in {{.ExpiresIn}} minutes</p>
-            </div>
-            
-            <div class="warning">
-                <strong>Security Notice:</strong> {{.Footer}}
-            </div>
-            
-            <p>If you have any questions, please contact our support team at <a href="{{.SupportURL}}">{{.SupportURL}}</a></p>
-        </div>
-        <div class="footer">
-            <p>&copy; 2024 {{.CompanyName}}. All rights reserved.</p>
-            <p>This is an automated message, please do not reply to this email.</p>
-        </div>
-    </div>
-</body>
-</html>`
-
-	tmplParsed, err := template.New("otp_email").Parse(tmpl)
+// render renders an email of the registry for one recipient
+func (s *EmailService) render(name, toEmail, toName string, data any) (EmailData, error) {
+	email, err := s.templates.Render(name, data)
 	if err != nil {
-		return "", err
+		return EmailData{}, fmt.Errorf("failed to render %s email: %w", name, err)
 	}
+	return EmailData{
+		ToEmail:  toEmail,
+		ToName:   toName,
+		Subject:  email.Subject,
+		TextBody: email.Text,
+		HTMLBody: email.HTML,
+	}, nil
+}
 
-	var buf bytes.Buffer
-	if err := tmplParsed.Execute(&buf, templateData); err != nil {
-		return "", err
+// renderOrderEmail renders an email about an order to its customer
+func (s *EmailService) renderOrderEmail(name string, order *models.Order, refund *models.Refund) (EmailData, error) {
+	customer := recipientName(&order.User)
+	data := orderEmailData{
+		Name:  customer,
+		Order: s.newOrderEmailView(order),
+	}
+	if refund != nil {
+		data.Refund = newRefundEmailView(order, refund)
 	}
+	return s.render(name, order.User.Email, customer, data)
+}
 
-	return buf.String(), nil
+// renderLowStockAlert renders a low stock alert for one staff member
+func (s *EmailService) renderLowStockAlert(recipient string, items []LowStockItem) (EmailData, error) {
+	return s.render(EmailTemplateLowStockAlert, recipient, "", lowStockEmailData{
+		Items:    items,
+		AdminURL: s.storeURL() + "/admin/products",
+	})
 }
 
-// generateWelcomeEmailHTML generates welcome email template
-func (s *EmailService) generateWelcomeEmailHTML(name string) (string, error) {
-	templateData := EmailTemplate{
-		Title:       "Welcome to Electronics Store! 🎉",
-		Greeting:    fmt.Sprintf("Welcome %s!", name),
-		Message:     "Thank you for joining Electronics Store! We're excited to have you on board. You can now explore our amazing collection of electronics and enjoy a seamless shopping experience.",
-		CompanyName: "Electronics Store",
-		SupportURL:  "https://electronicsstore.com/support",
+// templateFuncs are the functions available to every email template
+func (s *EmailService) templateFuncs() map[string]any {
+	return map[string]any{
+		"storeName":  func() string { return s.config.FromName },
+		"storeURL":   s.storeURL,
+		"supportURL": func() string { return s.storeURL() + "/support" },
+		"year":       func() int { return time.Now().Year() },
+		"money":      formatMoney,
+		"date":       func(t time.Time) string { return t.Format("January 2, 2006") },
 	}
+}
 
-	tmpl := `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>{{.Title}}</title>
-    <style>
-        body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; margin: 0; padding: 0; background-color: #f4f4f4; }
-        .container { max-width: 600px; margin: 0 auto; background-color: #ffffff; }
-        .header { background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 30px; text-align: center; }
-        .content { padding: 40px 30px; }
-        .message { color: #6c757d; line-height: 1.6; margin: 20px 0; }
-        .footer { background-color: #f8f9fa; padding: 20px; text-align: center; color: #6c757d; font-size: 14px; }
-        .button { display: inline-block; background-color: #007bff; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; margin: 20px 0; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>{{.CompanyName}}</h1>
-            <h2>{{.Title}}</h2>
-        </div>
-        <div class="content">
-            <p>{{.Greeting}}</p>
-            <div class="message">{{.Message}}</div>
-            
-            <div style="text-align: center; margin: 30px 0;">
-                <a href="https://electronicsstore.com/products" class="button">Start Shopping</a>
-            </div>
-            
-            <p>If you have any questions, please contact our support team at <a href="{{.SupportURL}}">{{.SupportURL}}</a></p>
-        </div>
-        <div class="footer">
-            <p>&copy; 2024 {{.CompanyName}}. All rights reserved.</p>
-            <p>This is an automated message, please do not reply to this email.</p>
-        </div>
-    </div>
-</body>
-</html>`
-
-	tmplParsed, err := template.New("welcome_email").Parse(tmpl)
-	if err != nil {
-		return "", err
+func (s *EmailService) storeURL() string {
+	return strings.TrimRight(s.config.StoreURL, "/")
+}
+
+// Data the email templates are rendered with
+
+type otpEmailData struct {
+	Name      string
+	Title     string
+	Message   string
+	Code      string
+	ExpiresIn int
+}
+
+type welcomeEmailData struct {
+	Name string
+}
+
+type orderEmailData struct {
+	Name   string
+	Order  orderEmailView
+	Refund *refundEmailView
+}
+
+type orderEmailView struct {
+	Number          string
+	URL             string
+	PlacedAt        time.Time
+	Currency        string
+	Items           []emailLineView
+	Subtotal        float64
+	Discount        float64
+	DiscountCode    string
+	ShippingMethod  string
+	Shipping        float64
+	Tax             float64
+	Total           float64
+	ShippingAddress *models.AddressSnapshot
+	Carrier         string
+	TrackingNumber  string
+	TrackingURL     string
+	DeliveredAt     *time.Time
+}
+
+type refundEmailView struct {
+	Amount   float64
+	Currency string
+	Reason   string
+	Items    []emailLineView
+}
+
+type emailLineView struct {
+	Name     string
+	Variant  string
+	Quantity int
+	Price    float64
+	Total    float64
+}
+
+type lowStockEmailData struct {
+	Items    []LowStockItem
+	AdminURL string
+}
+
+type backInStockEmailData struct {
+	Name    string
+	Product productEmailView
+}
+
+type productEmailView struct {
+	Name     string
+	URL      string
+	Price    float64
+	Currency string
+	ImageURL string
+}
+
+func (s *EmailService) newOrderEmailView(order *models.Order) orderEmailView {
+	view := orderEmailView{
+		Number:          order.OrderNumber,
+		URL:             s.storeURL() + "/orders",
+		PlacedAt:        order.CreatedAt,
+		Currency:        order.Currency,
+		Subtotal:        order.Subtotal,
+		Discount:        order.DiscountAmount,
+		DiscountCode:    order.DiscountCode,
+		ShippingMethod:  order.ShippingMethod,
+		Shipping:        order.ShippingCost,
+		Tax:             order.TaxAmount,
+		Total:           order.Total,
+		ShippingAddress: order.ShippingAddress,
+		Carrier:         order.Carrier,
+		TrackingNumber:  order.TrackingNumber,
+		TrackingURL:     order.TrackingURL,
+		DeliveredAt:     order.DeliveredAt,
+	}
+	for _, item := range order.OrderItems {
+		view.Items = append(view.Items, newEmailLineView(item, item.Quantity, item.Total))
 	}
+	return view
+}
 
-	var buf bytes.Buffer
-	if err := tmplParsed.Execute(&buf, templateData); err != nil {
-		return "", err
+func newRefundEmailView(order *models.Order, refund *models.Refund) *refundEmailView {
+	view := &refundEmailView{
+		Amount:   refund.Amount,
+		Currency: refund.Currency,
+		Reason:   refund.Reason,
+	}
+	for _, refunded := range refund.Items {
+		for _, item := range order.OrderItems {
+			if item.ID == refunded.OrderItemID {
+				view.Items = append(view.Items, newEmailLineView(item, refunded.Quantity, refunded.Amount))
+				break
+			}
+		}
 	}
+	return view
+}
 
-	return buf.String(), nil
+func newEmailLineView(item models.OrderItem, quantity int, total float64) emailLineView {
+	line := emailLineView{
+		Name:     item.Product.Name,
+		Quantity: quantity,
+		Price:    item.Price,
+		Total:    total,
+	}
+	if item.Variant != nil {
+		line.Variant = item.Variant.Name
+	}
+	return line
 }
 
-// generatePasswordResetEmailHTML generates password reset email template
-func (s *EmailService) generatePasswordResetEmailHTML(name, otpCode string) (string, error) {
-	// Reuse the OTP template for password reset
-	return s.generateOTPEmailHTML(name, otpCode, "password_reset")
+// recipientName is how a customer is greeted
+func recipientName(user *models.User) string {
+	switch {
+	case user.FirstName != "":
+		return user.FirstName
+	case user.Username != "":
+		return user.Username
+	default:
+		return strings.Split(user.Email, "@")[0]
+	}
 }
 
-// getOTPMessage returns appropriate message based on OTP type
-func (s *EmailService) getOTPMessage(otpType string) string {
-	switch otpType {
-	case "email_verification":
-		return "Thank you for registering with Electronics Store! Please verify your email address using the code below to complete your registration."
-	case "password_reset":
-		return "We received a request to reset your password. Use the code below to reset your password:"
-	case "login":
-		return "Use the code below to complete your login to Electronics Store:"
+// primaryImage returns the image flagged primary, else the first by sort order
+func primaryImage(images []models.Image) *models.Image {
+	if len(images) == 0 {
+		return nil
+	}
+	sorted := make([]models.Image, len(images))
+	copy(sorted, images)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].IsPrimary != sorted[j].IsPrimary {
+			return sorted[i].IsPrimary
+		}
+		return sorted[i].SortOrder < sorted[j].SortOrder
+	})
+	return &sorted[0]
+}
+
+// formatMoney formats an amount for display, e.g. $1,299.00
+func formatMoney(amount float64, currency string) string {
+	cents := int64(math.Round(math.Abs(amount) * 100))
+	digits := strconv.FormatInt(cents/100, 10)
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+	formatted := fmt.Sprintf("%s.%02d", digits, cents%100)
+	if amount < 0 {
+		formatted = "-" + formatted
+	}
+
+	switch currency {
+	case "", "USD":
+		return "$" + formatted
+	case "EUR":
+		return "€" + formatted
+	case "GBP":
+		return "£" + formatted
 	default:
-		return "Please use the code below to complete your verification:"
+		return formatted + " " + currency
+	}
+}
+
+// sampleOrder is the order email previews are rendered with
+func sampleOrder() *models.Order {
+	placed := time.Date(2024, time.March, 14, 10, 30, 0, 0, time.UTC)
+	shipped := placed.Add(26 * time.Hour)
+	delivered := placed.Add(74 * time.Hour)
+	return &models.Order{
+		ID:             1,
+		ResourceID:     "00000000-0000-0000-0000-000000000001",
+		OrderNumber:    "ORD-20240314-0001",
+		Status:         models.OrderStatusShipped,
+		PaymentStatus:  models.OrderPaymentPaid,
+		Subtotal:       1398.00,
+		TaxAmount:      111.84,
+		ShippingCost:   9.99,
+		ShippingMethod: "Standard Shipping",
+		DiscountAmount: 100.00,
+		DiscountCode:   "SPRING100",
+		Total:          1419.83,
+		Currency:       "USD",
+		Carrier:        "UPS",
+		TrackingNumber: "1Z999AA10123456784",
+		TrackingURL:    "https://www.ups.com/track?tracknum=1Z999AA10123456784",
+		ShippedAt:      &shipped,
+		DeliveredAt:    &delivered,
+		CreatedAt:      placed,
+		ShippingAddress: &models.AddressSnapshot{
+			FirstName:    "Jane",
+			LastName:     "Doe",
+			AddressLine1: "123 Main Street",
+			AddressLine2: "Apt 4B",
+			City:         "Springfield",
+			State:        "IL",
+			PostalCode:   "62701",
+			Country:      "US",
+		},
+		User: models.User{
+			ResourceID: "00000000-0000-0000-0000-000000000002",
+			Username:   "janedoe",
+			Email:      "jane.doe@example.com",
+			FirstName:  "Jane",
+			LastName:   "Doe",
+		},
+		OrderItems: []models.OrderItem{
+			{
+				ID:       1,
+				Quantity: 1,
+				Price:    1199.00,
+				Total:    1199.00,
+				Product: models.Product{
+					ResourceID: "00000000-0000-0000-0000-000000000003",
+					Name:       "UltraBook Pro 14",
+					SKU:        "UBP-14",
+					Price:      1199.00,
+				},
+				Variant: &models.Variant{Name: "16GB / 512GB, Space Gray", SKU: "UBP-14-16-512-GRY"},
+			},
+			{
+				ID:       2,
+				Quantity: 1,
+				Price:    199.00,
+				Total:    199.00,
+				Product: models.Product{
+					ResourceID: "00000000-0000-0000-0000-000000000004",
+					Name:       "NoiseAway Wireless Headphones",
+					SKU:        "NAW-HP1",
+					Price:      199.00,
+				},
+			},
+		},
 	}
 }
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates/email
+var builtinEmailTemplates embed.FS
+
+var ErrEmailTemplateNotFound = errors.New("email template not found")
+
+// Email templates
+const (
+	EmailTemplateOTP               = "otp"
+	EmailTemplateWelcome           = "welcome"
+	EmailTemplateOrderConfirmation = "order_confirmation"
+	EmailTemplateOrderShipped      = "order_shipped"
+	EmailTemplateOrderDelivered    = "order_delivered"
+	EmailTemplateRefundIssued      = "refund_issued"
+	EmailTemplateLowStockAlert     = "low_stock_alert"
+	EmailTemplateBackInStock       = "back_in_stock"
+)
+
+// EmailTemplateInfo describes an email of the template registry
+type EmailTemplateInfo struct {
+	Name        string
+	Description string
+}
+
+// emailTemplateInfos lists every email of the registry
+var emailTemplateInfos = []EmailTemplateInfo{
+	{EmailTemplateOTP, "Verification, login and password reset codes"},
+	{EmailTemplateWelcome, "Sent after registration"},
+	{EmailTemplateOrderConfirmation, "Sent when an order is placed"},
+	{EmailTemplateOrderShipped, "Sent when an order ships, with tracking details"},
+	{EmailTemplateOrderDelivered, "Sent when an order is delivered"},
+	{EmailTemplateRefundIssued, "Sent when a refund is issued for an order"},
+	{EmailTemplateLowStockAlert, "Sent to staff when an order takes items below their low stock threshold"},
+	{EmailTemplateBackInStock, "Sent to customers who asked to be told when a product is available again"},
+}
+
+// EmailTemplateList returns the emails of the template registry
+func EmailTemplateList() []EmailTemplateInfo {
+	list := make([]EmailTemplateInfo, len(emailTemplateInfos))
+	copy(list, emailTemplateInfos)
+	return list
+}
+
+// RenderedEmail is an email template rendered for one recipient
+type RenderedEmail struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// EmailTemplates renders the emails of the registry. Each email has a
+// <name>.txt file defining its "subject" and plain text "content" and a
+// <name>.html file defining its "title" and HTML "content"; they are
+// rendered inside the "layout" of layout.txt and layout.html.
+type EmailTemplates struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+// BuiltinEmailTemplates returns the templates compiled into the binary
+func BuiltinEmailTemplates() fs.FS {
+	fsys, err := fs.Sub(builtinEmailTemplates, "templates/email")
+	if err != nil {
+		panic(err)
+	}
+	return fsys
+}
+
+// LoadEmailTemplates parses the templates of every email in the registry
+// from fsys. funcs are available to all of them.
+func LoadEmailTemplates(fsys fs.FS, funcs map[string]any) (*EmailTemplates, error) {
+	t := &EmailTemplates{
+		text: make(map[string]*texttemplate.Template, len(emailTemplateInfos)),
+		html: make(map[string]*htmltemplate.Template, len(emailTemplateInfos)),
+	}
+	for _, info := range emailTemplateInfos {
+		text, err := texttemplate.New(info.Name).Funcs(funcs).ParseFS(fsys, "layout.txt", info.Name+".txt")
+		if err != nil {
+			return nil, fmt.Errorf("email template %s: %w", info.Name, err)
+		}
+		html, err := htmltemplate.New(info.Name).Funcs(funcs).ParseFS(fsys, "layout.html", info.Name+".html")
+		if err != nil {
+			return nil, fmt.Errorf("email template %s: %w", info.Name, err)
+		}
+		for _, name := range []string{"subject", "content", "layout"} {
+			if text.Lookup(name) == nil {
+				return nil, fmt.Errorf("email template %s: %s.txt does not define %q", info.Name, info.Name, name)
+			}
+		}
+		for _, name := range []string{"title", "content", "layout"} {
+			if html.Lookup(name) == nil {
+				return nil, fmt.Errorf("email template %s: %s.html does not define %q", info.Name, info.Name, name)
+			}
+		}
+		t.text[info.Name] = text
+		t.html[info.Name] = html
+	}
+	return t, nil
+}
+
+// Render renders the subject and both bodies of an email
+func (t *EmailTemplates) Render(name string, data any) (RenderedEmail, error) {
+	text, html := t.text[name], t.html[name]
+	if text == nil || html == nil {
+		return RenderedEmail{}, fmt.Errorf("%w: %s", ErrEmailTemplateNotFound, name)
+	}
+
+	var subject, textBody, htmlBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return RenderedEmail{}, err
+	}
+	if err := text.ExecuteTemplate(&textBody, "layout", data); err != nil {
+		return RenderedEmail{}, err
+	}
+	if err := html.ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return RenderedEmail{}, err
+	}
+
+	return RenderedEmail{
+		// A subject is a single header line
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(textBody.String()) + "\n",
+		HTML:    htmlBody.String(),
+	}, nil
+}
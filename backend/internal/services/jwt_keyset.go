@@ -0,0 +1,293 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"electronics-store/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms a JWT key can use
+const (
+	JWTAlgHS256 = "HS256"
+	JWTAlgRS256 = "RS256"
+	JWTAlgEdDSA = "EdDSA"
+)
+
+// AuthMethodMFA is listed in the amr claim (RFC 8176) of the tokens of a
+// session that completed two-factor authentication
+const AuthMethodMFA = "mfa"
+
+// defaultJWTKeyID names the key built from JWT_ACCESS_SECRET when no keyset
+// file is configured
+const defaultJWTKeyID = "default"
+
+var (
+	ErrJWTUnknownKey        = errors.New("token signed with an unknown key")
+	ErrJWTAlgorithmMismatch = errors.New("token algorithm does not match its key")
+)
+
+// JWTKeySet signs tokens with one active key and verifies tokens signed by
+// any key it holds. Tokens carry the ID of their signing key in the kid
+// header, so a new signing key can be introduced while tokens signed by the
+// previous one stay valid until they expire. Tokens without a kid, issued
+// before key IDs were used, are checked against the legacy keys.
+type JWTKeySet struct {
+	keys    map[string]*jwtKey
+	signing *jwtKey
+	legacy  []*jwtKey
+}
+
+type jwtKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{} // nil for keys that only verify
+	verifyKey interface{}
+}
+
+// jwtKeySetFile is the JSON document JWT_KEYSET_FILE points at, e.g.
+//
+//	{
+//	  "signing_kid": "2026-10",
+//	  "legacy_kids": ["default"],
+//	  "keys": [
+//	    {"kid": "2026-10", "alg": "EdDSA", "private_key_file": "/etc/store/jwt/2026-10.pem"},
+//	    {"kid": "2026-04", "alg": "RS256", "public_key_file": "/etc/store/jwt/2026-04.pub.pem"},
+//	    {"kid": "default", "alg": "HS256", "secret": "..."}
+//	  ]
+//	}
+//
+// Keys with only a public key verify tokens but cannot sign.
+type jwtKeySetFile struct {
+	SigningKID string           `json:"signing_kid"`
+	LegacyKIDs []string         `json:"legacy_kids"`
+	Keys       []jwtKeySetEntry `json:"keys"`
+}
+
+type jwtKeySetEntry struct {
+	KID            string `json:"kid"`
+	Alg            string `json:"alg"`
+	Secret         string `json:"secret"`
+	PrivateKeyFile string `json:"private_key_file"`
+	PublicKeyFile  string `json:"public_key_file"`
+}
+
+// LoadJWTKeySet builds the keyset from cfg.KeySetFile. Without a keyset file
+// tokens are signed with HS256 and the access token secret.
+func LoadJWTKeySet(cfg config.JWTConfig) (*JWTKeySet, error) {
+	if cfg.KeySetFile == "" {
+		file := jwtKeySetFile{
+			SigningKID: defaultJWTKeyID,
+			LegacyKIDs: []string{defaultJWTKeyID},
+			Keys: []jwtKeySetEntry{
+				{KID: defaultJWTKeyID, Alg: JWTAlgHS256, Secret: cfg.AccessTokenSecret},
+			},
+		}
+		return newJWTKeySet(file)
+	}
+
+	data, err := os.ReadFile(cfg.KeySetFile)
+	if err != nil {
+		return nil, fmt.Errorf("read JWT keyset: %w", err)
+	}
+	var file jwtKeySetFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse JWT keyset: %w", err)
+	}
+	return newJWTKeySet(file)
+}
+
+func newJWTKeySet(file jwtKeySetFile) (*JWTKeySet, error) {
+	ks := &JWTKeySet{keys: make(map[string]*jwtKey, len(file.Keys))}
+	for _, entry := range file.Keys {
+		if entry.KID == "" {
+			return nil, errors.New("JWT key without kid")
+		}
+		if _, exists := ks.keys[entry.KID]; exists {
+			return nil, fmt.Errorf("duplicate JWT key %q", entry.KID)
+		}
+		key, err := loadJWTKey(entry)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", entry.KID, err)
+		}
+		ks.keys[entry.KID] = key
+	}
+
+	ks.signing = ks.keys[file.SigningKID]
+	if ks.signing == nil {
+		return nil, fmt.Errorf("JWT signing key %q not found", file.SigningKID)
+	}
+	if ks.signing.signKey == nil {
+		return nil, fmt.Errorf("JWT signing key %q has no private key or secret", file.SigningKID)
+	}
+	for _, kid := range file.LegacyKIDs {
+		key := ks.keys[kid]
+		if key == nil {
+			return nil, fmt.Errorf("JWT legacy key %q not found", kid)
+		}
+		ks.legacy = append(ks.legacy, key)
+	}
+	return ks, nil
+}
+
+func loadJWTKey(entry jwtKeySetEntry) (*jwtKey, error) {
+	key := &jwtKey{id: entry.KID}
+	switch entry.Alg {
+	case JWTAlgHS256:
+		if entry.Secret == "" {
+			return nil, errors.New("HS256 keys need a secret")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.signKey = []byte(entry.Secret)
+		key.verifyKey = key.signKey
+		return key, nil
+	case JWTAlgRS256:
+		key.method = jwt.SigningMethodRS256
+	case JWTAlgEdDSA:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", entry.Alg)
+	}
+
+	switch {
+	case entry.PrivateKeyFile != "":
+		data, err := os.ReadFile(entry.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		var private crypto.Signer
+		if entry.Alg == JWTAlgRS256 {
+			private, err = jwt.ParseRSAPrivateKeyFromPEM(data)
+		} else {
+			var edKey crypto.PrivateKey
+			edKey, err = jwt.ParseEdPrivateKeyFromPEM(data)
+			if err == nil {
+				private = edKey.(crypto.Signer)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		key.signKey = private
+		key.verifyKey = private.Public()
+	case entry.PublicKeyFile != "":
+		data, err := os.ReadFile(entry.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Alg == JWTAlgRS256 {
+			key.verifyKey, err = jwt.ParseRSAPublicKeyFromPEM(data)
+		} else {
+			key.verifyKey, err = jwt.ParseEdPublicKeyFromPEM(data)
+		}
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s keys need a private_key_file or public_key_file", entry.Alg)
+	}
+	return key, nil
+}
+
+// Sign signs claims with the active signing key and records its kid
+func (ks *JWTKeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.method, claims)
+	token.Header["kid"] = ks.signing.id
+	return token.SignedString(ks.signing.signKey)
+}
+
+// Parse verifies a token against the key named by its kid header and
+// decodes it into claims
+func (ks *JWTKeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, ks.keyFunc)
+}
+
+func (ks *JWTKeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		set := jwt.VerificationKeySet{}
+		for _, key := range ks.legacy {
+			if key.method.Alg() == token.Method.Alg() {
+				set.Keys = append(set.Keys, key.verifyKey)
+			}
+		}
+		if len(set.Keys) == 0 {
+			return nil, ErrJWTUnknownKey
+		}
+		return set, nil
+	}
+
+	key := ks.keys[kid]
+	if key == nil {
+		return nil, ErrJWTUnknownKey
+	}
+	// The algorithm is pinned by the key, never taken from the token
+	if key.method.Alg() != token.Method.Alg() {
+		return nil, ErrJWTAlgorithmMismatch
+	}
+	return key.verifyKey, nil
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // OKP curve
+	X         string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKS is a JSON Web Key Set document
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicKeys returns the asymmetric keys of the set so other services can
+// verify tokens without holding a signing secret. HS256 keys are never
+// published.
+func (ks *JWTKeySet) PublicKeys() JWKS {
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jwks := JWKS{Keys: []JWK{}}
+	for _, id := range ids {
+		key := ks.keys[id]
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     key.id,
+				Use:       "sig",
+				Algorithm: key.method.Alg(),
+				N:         base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     key.id,
+				Use:       "sig",
+				Algorithm: key.method.Alg(),
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	return jwks
+}
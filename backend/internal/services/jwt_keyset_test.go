@@ -0,0 +1,196 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"electronics-store/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "42",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+// writeEd25519Keys writes a new Ed25519 key pair as PEM files to dir
+func writeEd25519Keys(t *testing.T, dir, name string) (privateFile, publicFile string) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("marshal private key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+
+	privateFile = filepath.Join(dir, name+".pem")
+	publicFile = filepath.Join(dir, name+".pub.pem")
+	if err := os.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600); err != nil {
+		t.Fatalf("write private key: %v", err)
+	}
+	if err := os.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600); err != nil {
+		t.Fatalf("write public key: %v", err)
+	}
+	return privateFile, publicFile
+}
+
+// loadTestKeySet loads a keyset file with the given contents
+func loadTestKeySet(t *testing.T, file jwtKeySetFile) *JWTKeySet {
+	t.Helper()
+	data, err := json.Marshal(file)
+	if err != nil {
+		t.Fatalf("marshal keyset: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "keyset.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write keyset: %v", err)
+	}
+	ks, err := LoadJWTKeySet(config.JWTConfig{KeySetFile: path})
+	if err != nil {
+		t.Fatalf("LoadJWTKeySet: %v", err)
+	}
+	return ks
+}
+
+func TestJWTKeySetRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldPrivate, oldPublic := writeEd25519Keys(t, dir, "2026-04")
+	newPrivate, _ := writeEd25519Keys(t, dir, "2026-10")
+
+	// Before the rotation tokens are signed with 2026-04
+	before := loadTestKeySet(t, jwtKeySetFile{
+		SigningKID: "2026-04",
+		Keys: []jwtKeySetEntry{
+			{KID: "2026-04", Alg: JWTAlgEdDSA, PrivateKeyFile: oldPrivate},
+		},
+	})
+	// After it 2026-10 signs and 2026-04 is kept, public key only, to
+	// verify the tokens it signed until they expire
+	after := loadTestKeySet(t, jwtKeySetFile{
+		SigningKID: "2026-10",
+		Keys: []jwtKeySetEntry{
+			{KID: "2026-10", Alg: JWTAlgEdDSA, PrivateKeyFile: newPrivate},
+			{KID: "2026-04", Alg: JWTAlgEdDSA, PublicKeyFile: oldPublic},
+		},
+	})
+
+	t.Run("signs with the active kid", func(t *testing.T) {
+		signed, err := after.Sign(testClaims())
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		var claims jwt.RegisteredClaims
+		token, err := after.Parse(signed, &claims)
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if kid := token.Header["kid"]; kid != "2026-10" {
+			t.Errorf("kid = %v, want 2026-10", kid)
+		}
+		if token.Method.Alg() != JWTAlgEdDSA {
+			t.Errorf("alg = %s, want %s", token.Method.Alg(), JWTAlgEdDSA)
+		}
+		if claims.Subject != "42" {
+			t.Errorf("subject = %q, want 42", claims.Subject)
+		}
+	})
+
+	t.Run("verifies tokens of a retired kid", func(t *testing.T) {
+		signed, err := before.Sign(testClaims())
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		token, err := after.Parse(signed, &jwt.RegisteredClaims{})
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if kid := token.Header["kid"]; kid != "2026-04" {
+			t.Errorf("kid = %v, want 2026-04", kid)
+		}
+	})
+
+	t.Run("rejects tokens of an unknown kid", func(t *testing.T) {
+		signed, err := after.Sign(testClaims())
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		if _, err := before.Parse(signed, &jwt.RegisteredClaims{}); !errors.Is(err, ErrJWTUnknownKey) {
+			t.Errorf("Parse error = %v, want %v", err, ErrJWTUnknownKey)
+		}
+	})
+
+	t.Run("rejects a known kid with another algorithm", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+		token.Header["kid"] = "2026-10"
+		signed, err := token.SignedString([]byte("guessed-secret"))
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		if _, err := after.Parse(signed, &jwt.RegisteredClaims{}); !errors.Is(err, ErrJWTAlgorithmMismatch) {
+			t.Errorf("Parse error = %v, want %v", err, ErrJWTAlgorithmMismatch)
+		}
+	})
+
+	t.Run("rejects a known kid signed by another key", func(t *testing.T) {
+		forger := loadTestKeySet(t, jwtKeySetFile{
+			SigningKID: "2026-10",
+			Keys: []jwtKeySetEntry{
+				{KID: "2026-10", Alg: JWTAlgEdDSA, PrivateKeyFile: oldPrivate},
+			},
+		})
+		signed, err := forger.Sign(testClaims())
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		if _, err := after.Parse(signed, &jwt.RegisteredClaims{}); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			t.Errorf("Parse error = %v, want %v", err, jwt.ErrTokenSignatureInvalid)
+		}
+	})
+}
+
+func TestJWTKeySetLegacyTokens(t *testing.T) {
+	ks, err := LoadJWTKeySet(config.JWTConfig{AccessTokenSecret: "access-secret", RefreshTokenSecret: "refresh-secret"})
+	if err != nil {
+		t.Fatalf("LoadJWTKeySet: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr bool
+	}{
+		{"signed with the access secret", "access-secret", false},
+		{"signed with the refresh secret", "refresh-secret", true},
+		{"signed with another secret", "other-secret", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Tokens issued before key IDs were used have no kid header
+			signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims()).SignedString([]byte(tt.secret))
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			_, err = ks.Parse(signed, &jwt.RegisteredClaims{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
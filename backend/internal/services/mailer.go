@@ -0,0 +1,269 @@
+package services
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"electronics-store/internal/config"
+
+	"github.com/google/uuid"
+)
+
+// Email transports
+const (
+	EmailTransportSMTP   = "smtp"
+	EmailTransportFile   = "file"
+	EmailTransportMemory = "memory"
+)
+
+// smtpTimeout bounds one delivery, from connecting to QUIT
+const smtpTimeout = 30 * time.Second
+
+// Mailer delivers a rendered email right away. Request handlers don't use
+// it; they queue emails in the outbox and EmailQueue workers send them.
+type Mailer interface {
+	Send(data EmailData) error
+}
+
+// NewMailer creates the mailer of cfg.Transport: SMTP, a directory of .eml
+// files, or an in-memory mailbox
+func NewMailer(cfg *config.EmailConfig) (Mailer, error) {
+	switch cfg.Transport {
+	case "", EmailTransportSMTP:
+		return NewSMTPMailer(cfg), nil
+	case EmailTransportFile:
+		return NewFileMailbox(cfg.MailboxDir, fromAddress(cfg))
+	case EmailTransportMemory:
+		return NewMemoryMailbox(), nil
+	default:
+		return nil, fmt.Errorf("unknown email transport %q", cfg.Transport)
+	}
+}
+
+// SMTPMailer sends emails through the configured SMTP server
+type SMTPMailer struct {
+	config *config.EmailConfig
+}
+
+func NewSMTPMailer(cfg *config.EmailConfig) *SMTPMailer {
+	return &SMTPMailer{config: cfg}
+}
+
+func (m *SMTPMailer) Send(data EmailData) error {
+	// Create message
+	message := buildMessage(fromAddress(m.config), data)
+
+	// Setup authentication
+	auth := smtp.PlainAuth("", m.config.SMTPUsername, m.config.SMTPPassword, m.config.SMTPHost)
+
+	// Send email
+	addr := fmt.Sprintf("%s:%d", m.config.SMTPHost, m.config.SMTPPort)
+
+	if m.config.UseSSL {
+		return m.sendEmailSSL(addr, auth, data, message)
+	}
+
+	return m.sendMail(addr, auth, data.ToEmail, message)
+}
+
+// sendEmailSSL sends email using SSL (for ports like 465)
+func (m *SMTPMailer) sendEmailSSL(addr string, auth smtp.Auth, data EmailData, message []byte) error {
+	// This would require a more complex implementation with TLS
+	// For now, we'll use the standard SMTP with TLS
+	return m.sendMail(addr, auth, data.ToEmail, message)
+}
+
+// sendMail is smtp.SendMail with a deadline on the whole exchange, so an
+// unresponsive server can't hold a queue worker forever
+func (m *SMTPMailer) sendMail(addr string, auth smtp.Auth, to string, message []byte) error {
+	conn, err := net.DialTimeout("tcp", addr, smtpTimeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.config.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.config.SMTPHost}); err != nil {
+			return err
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.config.FromEmail); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// FileMailbox writes every email to a directory as an .eml file instead of
+// sending it, for development and tests. The files open in any mail client.
+type FileMailbox struct {
+	dir  string
+	from mail.Address
+}
+
+// NewFileMailbox creates dir if needed and writes emails from from into it
+func NewFileMailbox(dir string, from mail.Address) (*FileMailbox, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("file mailbox needs a directory")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mailbox directory: %w", err)
+	}
+	return &FileMailbox{dir: dir, from: from}, nil
+}
+
+// Send writes the email to <dir>/<time>-<id>.eml, so files sort in the
+// order they were sent
+func (m *FileMailbox) Send(data EmailData) error {
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), uuid.New().String())
+	return os.WriteFile(filepath.Join(m.dir, name), buildMessage(m.from, data), 0o644)
+}
+
+// Dir is the directory emails are written to
+func (m *FileMailbox) Dir() string {
+	return m.dir
+}
+
+// MemoryMailbox keeps every email it is sent in memory, for tests to
+// assert against
+type MemoryMailbox struct {
+	mu       sync.Mutex
+	messages []EmailData
+}
+
+func NewMemoryMailbox() *MemoryMailbox {
+	return &MemoryMailbox{}
+}
+
+func (m *MemoryMailbox) Send(data EmailData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, data)
+	return nil
+}
+
+// Messages returns the emails sent so far, oldest first
+func (m *MemoryMailbox) Messages() []EmailData {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	messages := make([]EmailData, len(m.messages))
+	copy(messages, m.messages)
+	return messages
+}
+
+// To returns the emails sent to an address, oldest first
+func (m *MemoryMailbox) To(email string) []EmailData {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var messages []EmailData
+	for _, message := range m.messages {
+		if message.ToEmail == email {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// Last returns the email sent most recently, if any
+func (m *MemoryMailbox) Last() (EmailData, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.messages) == 0 {
+		return EmailData{}, false
+	}
+	return m.messages[len(m.messages)-1], true
+}
+
+// Reset empties the mailbox
+func (m *MemoryMailbox) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
+
+func fromAddress(cfg *config.EmailConfig) mail.Address {
+	return mail.Address{Name: cfg.FromName, Address: cfg.FromEmail}
+}
+
+// buildMessage creates the email message. With a plain text body it is a
+// multipart/alternative message, the HTML part last as the preferred one.
+func buildMessage(from mail.Address, data EmailData) []byte {
+	var message bytes.Buffer
+	to := mail.Address{Name: data.ToName, Address: data.ToEmail}
+	fmt.Fprintf(&message, "From: %s\r\n", from.String())
+	fmt.Fprintf(&message, "To: %s\r\n", to.String())
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", data.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+
+	if data.TextBody == "" {
+		message.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+		message.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		writeQuotedPrintable(&message, data.HTMLBody)
+		return message.Bytes()
+	}
+
+	parts := multipart.NewWriter(&message)
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", parts.Boundary())
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", data.TextBody},
+		{"text/html; charset=UTF-8", data.HTMLBody},
+	} {
+		// Writes to a bytes.Buffer don't fail
+		w, _ := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		writeQuotedPrintable(w, part.body)
+	}
+	parts.Close()
+	return message.Bytes()
+}
+
+// writeQuotedPrintable writes body quoted-printable encoded, which keeps
+// every line within the SMTP line length limit
+func writeQuotedPrintable(w io.Writer, body string) {
+	qp := quotedprintable.NewWriter(w)
+	qp.Write([]byte(body))
+	qp.Close()
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"electronics-store/internal/config"
+	"electronics-store/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// Notification channels
+const (
+	NotificationChannelEmail   = models.OutboxChannelEmail
+	NotificationChannelWebhook = models.OutboxChannelWebhook
+	NotificationChannelInApp   = "in_app"
+)
+
+// Webhook notification headers
+const (
+	WebhookIDHeader        = "X-Notification-ID"
+	WebhookSignatureHeader = "X-Notification-Signature"
+)
+
+// Notification is something a customer or staff should hear about, ready
+// for every channel it goes out on. Its emails go to their recipients. If
+// it has a Title it also goes to the inbox of UserID, when set, and to the
+// webhook, when configured; one-time codes have none, so they are only
+// ever emailed.
+type Notification struct {
+	Kind      string // what it is about, e.g. "order_shipped"
+	UserID    *uint
+	Emails    []EmailData
+	Title     string
+	Body      string
+	Link      string     // storefront path to open, e.g. "/orders"
+	ExpiresAt *time.Time // when its emails are no longer worth sending
+}
+
+// NotificationOutbox is where notifications are recorded. Repositories
+// implement it, so notifications are written in the transaction of the
+// change they are about and only go out if it commits.
+type NotificationOutbox interface {
+	EnqueueEmail(ctx context.Context, email *models.OutboxEmail) error
+	CreateNotification(ctx context.Context, notification *models.Notification) error
+}
+
+// Notifier tells customers and staff about changes. Services depend on it
+// rather than on a way of sending, so they can be tested with a fake.
+type Notifier interface {
+	// Notify records n on each of its channels through out
+	Notify(ctx context.Context, out NotificationOutbox, n Notification) error
+	// Flush starts delivering what was recorded. Call it once the
+	// transaction Notify wrote to has committed.
+	Flush()
+}
+
+// NotificationService is the Notifier of the server. Emails and webhook
+// calls are queued in the outbox and delivered by EmailQueue workers;
+// in-app notifications are written straight to the inbox.
+type NotificationService struct {
+	queue    *EmailQueue
+	webhook  bool
+	storeURL string
+}
+
+func NewNotificationService(queue *EmailQueue, cfg config.NotifyConfig, storeURL string) *NotificationService {
+	return &NotificationService{
+		queue:    queue,
+		webhook:  cfg.WebhookURL != "",
+		storeURL: strings.TrimRight(storeURL, "/"),
+	}
+}
+
+func (s *NotificationService) Notify(ctx context.Context, out NotificationOutbox, n Notification) error {
+	for _, email := range n.Emails {
+		if err := out.EnqueueEmail(ctx, NewOutboxEmail(n.Kind, email, n.ExpiresAt)); err != nil {
+			return err
+		}
+	}
+	if n.Title == "" {
+		return nil
+	}
+
+	if n.UserID != nil {
+		err := out.CreateNotification(ctx, &models.Notification{
+			UserID: *n.UserID,
+			Kind:   n.Kind,
+			Title:  n.Title,
+			Body:   n.Body,
+			Link:   n.Link,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if s.webhook {
+		entry, err := s.newWebhookEntry(n)
+		if err != nil {
+			return err
+		}
+		return out.EnqueueEmail(ctx, entry)
+	}
+	return nil
+}
+
+func (s *NotificationService) Flush() {
+	s.queue.Notify()
+}
+
+// WebhookPayload is the JSON body of a webhook call. ID stays the same
+// across retries, so receivers can drop duplicates.
+type WebhookPayload struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// newWebhookEntry turns a notification into the outbox entry of its
+// webhook call
+func (s *NotificationService) newWebhookEntry(n Notification) (*models.OutboxEmail, error) {
+	payload := WebhookPayload{
+		ID:        uuid.New().String(),
+		Kind:      n.Kind,
+		Title:     n.Title,
+		Body:      n.Body,
+		CreatedAt: time.Now().UTC(),
+	}
+	if n.Link != "" {
+		payload.URL = s.storeURL + n.Link
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OutboxEmail{
+		ResourceID: payload.ID,
+		Channel:    models.OutboxChannelWebhook,
+		Kind:       n.Kind,
+		Subject:    n.Title,
+		Payload:    string(body),
+		ExpiresAt:  n.ExpiresAt,
+	}, nil
+}
+
+// OutboxChannel delivers the outbox entries of one channel
+type OutboxChannel interface {
+	Deliver(entry *models.OutboxEmail) error
+}
+
+// EmailChannel delivers outbox emails through a Mailer
+type EmailChannel struct {
+	mailer Mailer
+}
+
+func NewEmailChannel(mailer Mailer) *EmailChannel {
+	return &EmailChannel{mailer: mailer}
+}
+
+func (c *EmailChannel) Deliver(entry *models.OutboxEmail) error {
+	return c.mailer.Send(EmailData{
+		ToEmail:  entry.ToEmail,
+		ToName:   entry.ToName,
+		Subject:  entry.Subject,
+		TextBody: entry.TextBody,
+		HTMLBody: entry.HTMLBody,
+	})
+}
+
+// WebhookChannel POSTs webhook calls to the configured URL. The body is
+// signed with an HMAC-SHA256 under the webhook secret, hex encoded in the
+// X-Notification-Signature header.
+type WebhookChannel struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func NewWebhookChannel(cfg config.NotifyConfig) *WebhookChannel {
+	timeout := cfg.WebhookTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &WebhookChannel{
+		url:    cfg.WebhookURL,
+		secret: []byte(cfg.WebhookSecret),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *WebhookChannel) Deliver(entry *models.OutboxEmail) error {
+	req, err := http.NewRequest(http.MethodPost, c.url, strings.NewReader(entry.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, entry.ResourceID)
+	req.Header.Set(WebhookSignatureHeader, c.Sign([]byte(entry.Payload)))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature sent with a webhook body
+func (c *WebhookChannel) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// OTPNotification emails a one-time code. Codes have no title, so they
+// never reach an inbox or the webhook.
+func OTPNotification(otpType string, email EmailData, expiresAt time.Time) Notification {
+	return Notification{
+		Kind:      "otp_" + otpType,
+		Emails:    []EmailData{email},
+		ExpiresAt: &expiresAt, // a code is useless once expired, so is its email
+	}
+}
+
+// OrderNotification tells a customer about their order. kind is the email
+// template email was rendered from.
+func OrderNotification(kind string, order *models.Order, email EmailData) Notification {
+	userID := order.UserID
+	n := Notification{
+		Kind:   kind,
+		UserID: &userID,
+		Emails: []EmailData{email},
+		Link:   "/orders",
+	}
+	switch kind {
+	case EmailTemplateOrderConfirmation:
+		n.Title = fmt.Sprintf("Order %s confirmed", order.OrderNumber)
+		n.Body = fmt.Sprintf("We received your order of %s and will let you know when it ships.", formatMoney(order.Total, order.Currency))
+	case EmailTemplateOrderShipped:
+		n.Title = fmt.Sprintf("Order %s has shipped", order.OrderNumber)
+		n.Body = "Your order is on its way."
+		if order.TrackingNumber != "" {
+			n.Body = fmt.Sprintf("Your order is on its way. Tracking number: %s.", strings.TrimSpace(order.Carrier+" "+order.TrackingNumber))
+		}
+	case EmailTemplateOrderDelivered:
+		n.Title = fmt.Sprintf("Order %s was delivered", order.OrderNumber)
+		n.Body = "We hope you enjoy your purchase."
+	}
+	return n
+}
+
+// RefundNotification tells a customer about a refund issued for their order
+func RefundNotification(order *models.Order, refund *models.Refund, email EmailData) Notification {
+	userID := order.UserID
+	return Notification{
+		Kind:   EmailTemplateRefundIssued,
+		UserID: &userID,
+		Emails: []EmailData{email},
+		Title:  fmt.Sprintf("Refund issued for order %s", order.OrderNumber),
+		Body:   fmt.Sprintf("%s is on its way back to your original payment method.", formatMoney(refund.Amount, refund.Currency)),
+		Link:   "/orders",
+	}
+}
+
+// LowStockNotification alerts staff that items ran low. It has no user, so
+// it goes to the alert recipients' emails and the webhook.
+func LowStockNotification(items []LowStockItem, emails []EmailData) Notification {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		name := item.Name
+		if item.Variant != "" {
+			name += " (" + item.Variant + ")"
+		}
+		lines = append(lines, fmt.Sprintf("%s [%s]: %d left", name, item.SKU, item.Stock))
+	}
+	title := "Low stock: 1 item"
+	if len(items) != 1 {
+		title = fmt.Sprintf("Low stock: %d items", len(items))
+	}
+	return Notification{
+		Kind:   EmailTemplateLowStockAlert,
+		Emails: emails,
+		Title:  title,
+		Body:   strings.Join(lines, "\n"),
+		Link:   "/admin/products",
+	}
+}
+
+// BackInStockNotification tells a customer that a product they asked about
+// can be ordered again
+func BackInStockNotification(userID uint, product *models.Product, email EmailData) Notification {
+	return Notification{
+		Kind:   EmailTemplateBackInStock,
+		UserID: &userID,
+		Emails: []EmailData{email},
+		Title:  fmt.Sprintf("%s is back in stock", product.Name),
+		Body:   "Order it now before it sells out again.",
+		Link:   "/products/" + product.ResourceID,
+	}
+}
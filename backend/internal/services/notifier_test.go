@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"electronics-store/internal/config"
+	"electronics-store/internal/domain/models"
+)
+
+// memoryOutbox is an outbox table kept in memory, standing in for both the
+// repository notifications are written through and the one EmailQueue
+// workers deliver from
+type memoryOutbox struct {
+	mu            sync.Mutex
+	emails        []*models.OutboxEmail
+	notifications []*models.Notification
+}
+
+func (o *memoryOutbox) EnqueueEmail(ctx context.Context, email *models.OutboxEmail) error {
+	return o.Create(ctx, email)
+}
+
+func (o *memoryOutbox) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.notifications = append(o.notifications, notification)
+	return nil
+}
+
+func (o *memoryOutbox) Create(ctx context.Context, email *models.OutboxEmail) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	email.ID = uint(len(o.emails) + 1)
+	if email.Channel == "" {
+		email.Channel = models.OutboxChannelEmail
+	}
+	email.Status = models.OutboxEmailPending
+	o.emails = append(o.emails, email)
+	return nil
+}
+
+func (o *memoryOutbox) GetByResourceID(ctx context.Context, resourceID string) (*models.OutboxEmail, error) {
+	return nil, nil
+}
+
+func (o *memoryOutbox) List(ctx context.Context, status, channel string, limit, offset int) ([]*models.OutboxEmail, int64, error) {
+	return nil, 0, nil
+}
+
+func (o *memoryOutbox) ListDueIDs(ctx context.Context, now time.Time, limit int) ([]uint, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var ids []uint
+	for _, email := range o.emails {
+		if email.Status == models.OutboxEmailPending && len(ids) < limit {
+			ids = append(ids, email.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (o *memoryOutbox) Claim(ctx context.Context, id uint, now, leaseUntil time.Time) (*models.OutboxEmail, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	email := o.emails[id-1]
+	if email.Status != models.OutboxEmailPending {
+		return nil, nil
+	}
+	email.Status = models.OutboxEmailSending
+	email.Attempts++
+	claimed := *email
+	return &claimed, nil
+}
+
+func (o *memoryOutbox) MarkSent(ctx context.Context, id uint) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.emails[id-1].Status = models.OutboxEmailSent
+	return nil
+}
+
+func (o *memoryOutbox) MarkFailed(ctx context.Context, id uint, lastError string, nextAttemptAt *time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.emails[id-1].Status = models.OutboxEmailDead
+	if nextAttemptAt != nil {
+		o.emails[id-1].Status = models.OutboxEmailPending
+	}
+	return nil
+}
+
+func (o *memoryOutbox) Requeue(ctx context.Context, id uint) (bool, error) {
+	return false, nil
+}
+
+func (o *memoryOutbox) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// newTestNotifier returns a notifier whose emails are delivered by a
+// running EmailQueue into the returned mailbox
+func newTestNotifier(t *testing.T, outbox *memoryOutbox) (*NotificationService, *MemoryMailbox) {
+	t.Helper()
+	mailbox := NewMemoryMailbox()
+	queue := NewEmailQueue(outbox, map[string]OutboxChannel{
+		NotificationChannelEmail: NewEmailChannel(mailbox),
+	}, config.OutboxConfig{Workers: 1, PollInterval: time.Hour, MaxAttempts: 1})
+	queue.Start(context.Background())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		queue.Stop(ctx)
+	})
+	return NewNotificationService(queue, config.NotifyConfig{}, "https://store.example.com"), mailbox
+}
+
+func newTestEmailService(t *testing.T) *EmailService {
+	t.Helper()
+	emails, err := NewEmailService(&config.EmailConfig{
+		FromName:        "Electronics Store",
+		StoreURL:        "https://store.example.com",
+		AlertRecipients: []string{"staff@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("NewEmailService: %v", err)
+	}
+	return emails
+}
+
+// waitForMail waits until the mailbox holds n emails
+func waitForMail(t *testing.T, mailbox *MemoryMailbox, n int) []EmailData {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		messages := mailbox.Messages()
+		if len(messages) >= n {
+			return messages
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d emails, want %d", len(messages), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func assertContains(t *testing.T, what, s string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(s, w) {
+			t.Errorf("%s does not contain %q:\n%s", what, w, s)
+		}
+	}
+}
+
+func TestNotifierDeliversOrderEmails(t *testing.T) {
+	emails := newTestEmailService(t)
+	outbox := &memoryOutbox{}
+	notifier, mailbox := newTestNotifier(t, outbox)
+
+	order := sampleOrder()
+	confirmation, err := emails.OrderConfirmationEmail(order)
+	if err != nil {
+		t.Fatalf("OrderConfirmationEmail: %v", err)
+	}
+	shipped, err := emails.OrderShippedEmail(order)
+	if err != nil {
+		t.Fatalf("OrderShippedEmail: %v", err)
+	}
+
+	userID := uint(7)
+	ctx := context.Background()
+	for _, n := range []Notification{
+		{Kind: "order_placed", UserID: &userID, Emails: []EmailData{confirmation}, Title: "Order placed", Link: "/orders"},
+		{Kind: "order_shipped", UserID: &userID, Emails: []EmailData{shipped}, Title: "Order shipped", Link: "/orders"},
+	} {
+		if err := notifier.Notify(ctx, outbox, n); err != nil {
+			t.Fatalf("Notify %s: %v", n.Kind, err)
+		}
+	}
+	notifier.Flush()
+
+	// A single worker sends them in the order they were queued
+	messages := waitForMail(t, mailbox, 2)
+	if len(mailbox.To("jane.doe@example.com")) != 2 {
+		t.Errorf("emails to the customer = %d, want 2", len(mailbox.To("jane.doe@example.com")))
+	}
+
+	placed := messages[0]
+	if placed.ToName != "Jane" {
+		t.Errorf("confirmation ToName = %q, want %q", placed.ToName, "Jane")
+	}
+	assertContains(t, "confirmation subject", placed.Subject, order.OrderNumber)
+	assertContains(t, "confirmation text", placed.TextBody, "UltraBook Pro 14", "NoiseAway Wireless Headphones", "1,419.83")
+	assertContains(t, "confirmation HTML", placed.HTMLBody, "UltraBook Pro 14", "https://store.example.com")
+
+	sent := messages[1]
+	assertContains(t, "shipping text", sent.TextBody, "UPS", "1Z999AA10123456784")
+	assertContains(t, "shipping HTML", sent.HTMLBody, "https://www.ups.com/track?tracknum=1Z999AA10123456784")
+
+	if len(outbox.notifications) != 2 {
+		t.Errorf("inbox notifications = %d, want 2", len(outbox.notifications))
+	}
+}
+
+func TestNotifierDeliversStockEmails(t *testing.T) {
+	emails := newTestEmailService(t)
+	outbox := &memoryOutbox{}
+	notifier, mailbox := newTestNotifier(t, outbox)
+
+	order := sampleOrder()
+	product := &order.OrderItems[1].Product
+	backInStock, err := emails.BackInStockEmail(&order.User, product)
+	if err != nil {
+		t.Fatalf("BackInStockEmail: %v", err)
+	}
+	lowStock, err := emails.LowStockAlertEmails([]LowStockItem{
+		{Name: product.Name, SKU: product.SKU, Stock: 2, Threshold: 5},
+	})
+	if err != nil {
+		t.Fatalf("LowStockAlertEmails: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := notifier.Notify(ctx, outbox, Notification{Kind: "back_in_stock", Emails: []EmailData{backInStock}}); err != nil {
+		t.Fatalf("Notify back_in_stock: %v", err)
+	}
+	if err := notifier.Notify(ctx, outbox, Notification{Kind: "low_stock", Emails: lowStock}); err != nil {
+		t.Fatalf("Notify low_stock: %v", err)
+	}
+	notifier.Flush()
+
+	waitForMail(t, mailbox, 2)
+
+	customer := mailbox.To("jane.doe@example.com")
+	if len(customer) != 1 {
+		t.Fatalf("emails to the customer = %d, want 1", len(customer))
+	}
+	assertContains(t, "back in stock subject", customer[0].Subject, product.Name)
+	assertContains(t, "back in stock HTML", customer[0].HTMLBody, "https://store.example.com/products/"+product.ResourceID)
+
+	staff := mailbox.To("staff@example.com")
+	if len(staff) != 1 {
+		t.Fatalf("emails to staff = %d, want 1", len(staff))
+	}
+	assertContains(t, "low stock text", staff[0].TextBody, product.Name, product.SKU)
+
+	if len(outbox.notifications) != 0 {
+		t.Errorf("inbox notifications = %d, want 0 for untitled notifications", len(outbox.notifications))
+	}
+}
@@ -1,27 +1,75 @@
 package services
 
 import (
+	"context"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
 	"time"
 
+	"electronics-store/internal/config"
 	"electronics-store/internal/domain/models"
 	"electronics-store/internal/repository"
+
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrInvalidOTP          = errors.New("invalid OTP code")
+	ErrOTPExpired          = errors.New("OTP code has expired")
+	ErrOTPAttemptsExceeded = errors.New("too many wrong OTP codes; request a new one")
+	ErrOTPDeliveryDisabled = errors.New("OTP codes can't be delivered: email is not configured")
+)
+
+// placeholderOTPHashKey is the hash key that shipped as the default and
+// example; codes hashed under it can be brute-forced by anyone who reads
+// the table
+const placeholderOTPHashKey = "change-me-otp-hash-key"
+
+// OTPService sends and verifies one-time codes. A code is stored only as an
+// HMAC keyed with cfg.HashKey and bound to its record's resource ID, which
+// is handed to the client as the challenge ID the code is verified against.
 type OTPService struct {
 	otpRepo      repository.OTPRepository
 	emailService *EmailService
+	notifier     Notifier
+	hashKey      []byte
+	maxAttempts  int
+	devMode      bool
 }
 
-func NewOTPService(otpRepo repository.OTPRepository, emailService *EmailService) *OTPService {
+// NewOTPService creates the OTP service. Codes are emailed through
+// notifier and invalidated after cfg.MaxAttempts wrong guesses. Only in
+// devMode are codes printed to stdout and a missing or placeholder hash
+// key accepted.
+func NewOTPService(otpRepo repository.OTPRepository, emailService *EmailService, notifier Notifier, cfg config.OTPConfig, devMode bool) (*OTPService, error) {
+	if !devMode {
+		if cfg.HashKey == "" {
+			return nil, errors.New("OTP_HASH_KEY must be set")
+		}
+		if cfg.HashKey == placeholderOTPHashKey {
+			return nil, errors.New("OTP_HASH_KEY is still the example value")
+		}
+	}
+
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
 	return &OTPService{
 		otpRepo:      otpRepo,
 		emailService: emailService,
-	}
+		notifier:     notifier,
+		hashKey:      []byte(cfg.HashKey),
+		maxAttempts:  maxAttempts,
+		devMode:      devMode,
+	}, nil
 }
 
 // GenerateOTP generates a 6-digit OTP code
@@ -39,8 +87,17 @@ func (s *OTPService) GenerateOTP() (string, error) {
 	return fmt.Sprintf("%06d", otp.Int64()), nil
 }
 
-// SendOTP creates and stores an OTP verification record
+// SendOTP creates and stores an OTP verification record and queues the
+// email with the code in the same transaction, so a code is never issued
+// without its email. The record's ResourceID is the challenge ID the code
+// must be verified with. Earlier codes for the same email and type stop
+// working, so only one code at a time can be guessed at.
 func (s *OTPService) SendOTP(email, otpType string, userID *uint) (*models.OTPVerification, error) {
+	canEmail := s.emailService != nil && s.notifier != nil
+	if !canEmail && !s.devMode {
+		return nil, ErrOTPDeliveryDisabled
+	}
+
 	// Generate OTP code
 	otpCode, err := s.GenerateOTP()
 	if err != nil {
@@ -50,80 +107,116 @@ func (s *OTPService) SendOTP(email, otpType string, userID *uint) (*models.OTPVe
 	// Set expiration time (15 minutes from now)
 	expiresAt := time.Now().Add(15 * time.Minute)
 
-	// Create OTP verification record
+	// Create OTP verification record; the hash is bound to its resource ID
 	otp := &models.OTPVerification{
-		Email:     email,
-		OTPCode:   otpCode,
-		OTPType:   otpType,
-		ExpiresAt: expiresAt,
+		ResourceID: uuid.New().String(),
+		Email:      email,
+		OTPType:    otpType,
+		ExpiresAt:  expiresAt,
 	}
+	otp.CodeHash = s.hashCode(otp.ResourceID, otpCode)
 
 	if userID != nil {
 		otp.UserID = userID
 	}
 
-	// Save to database
-	if err := s.otpRepo.Create(otp); err != nil {
-		return nil, fmt.Errorf("failed to save OTP: %w", err)
-	}
-
-	// Send OTP via email
-	if s.emailService != nil {
+	var notification *Notification
+	if canEmail {
 		// Extract name from email (before @)
 		name := strings.Split(email, "@")[0]
-		if err := s.emailService.SendOTPEmail(email, name, otpCode, otpType); err != nil {
-			// Log error but don't fail the request
-			fmt.Printf("Failed to send OTP email to %s: %v\n", email, err)
+		data, err := s.emailService.OTPEmail(email, name, otpCode, otpType)
+		if err != nil {
+			return nil, err
+		}
+		n := OTPNotification(otpType, data, expiresAt)
+		notification = &n
+	}
+
+	err = s.otpRepo.Transaction(func(tx repository.OTPRepository) error {
+		if err := tx.InvalidateByEmailAndType(email, otpType); err != nil {
+			return fmt.Errorf("failed to invalidate existing OTPs: %w", err)
+		}
+		if err := tx.Create(otp); err != nil {
+			return fmt.Errorf("failed to save OTP: %w", err)
 		}
-	} else {
-		// Fallback: log OTP (for development)
-		fmt.Printf("OTP for %s (%s): %s (expires at: %s)\n", email, otpType, otpCode, expiresAt.Format(time.RFC3339))
+		if notification != nil {
+			if err := s.notifier.Notify(context.Background(), tx, *notification); err != nil {
+				return fmt.Errorf("failed to queue OTP email: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if notification != nil {
+		s.notifier.Flush()
+	}
+	if s.devMode {
+		fmt.Printf("[DEV_MODE] OTP for %s (%s): %s (expires at: %s)\n", email, otpType, otpCode, expiresAt.Format(time.RFC3339))
 	}
 
 	return otp, nil
 }
 
-// VerifyOTP verifies an OTP code
-func (s *OTPService) VerifyOTP(email, otpCode, otpType string) (*models.OTPVerification, error) {
-	// Find the OTP record
-	otp, err := s.otpRepo.FindByEmailAndCode(email, otpCode, otpType)
+// VerifyOTP verifies an OTP code against the challenge it was sent for,
+// which must belong to the email and type. Each wrong guess counts against
+// the code, which is invalidated after maxAttempts of them.
+func (s *OTPService) VerifyOTP(challengeID, email, otpCode, otpType string) (*models.OTPVerification, error) {
+	otp, err := s.otpRepo.FindByResourceID(challengeID)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("invalid OTP code")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidOTP
 		}
 		return nil, fmt.Errorf("failed to find OTP: %w", err)
 	}
-
-	// Check if OTP is already used
-	if otp.IsUsed {
-		return nil, fmt.Errorf("OTP code has already been used")
+	if otp.IsUsed || otp.OTPType != otpType || !strings.EqualFold(otp.Email, email) {
+		return nil, ErrInvalidOTP
 	}
 
 	// Check if OTP is expired
 	if time.Now().After(otp.ExpiresAt) {
-		return nil, fmt.Errorf("OTP code has expired")
+		return nil, ErrOTPExpired
 	}
 
-	// Mark OTP as used
-	otp.IsUsed = true
-	if err := s.otpRepo.Update(otp); err != nil {
+	if !hmac.Equal([]byte(otp.CodeHash), []byte(s.hashCode(otp.ResourceID, otpCode))) {
+		active, err := s.otpRepo.RecordFailedAttempt(otp.ID, s.maxAttempts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update OTP: %w", err)
+		}
+		if active && otp.Attempts+1 >= s.maxAttempts {
+			return nil, ErrOTPAttemptsExceeded
+		}
+		return nil, ErrInvalidOTP
+	}
+
+	// Mark OTP as used; a concurrent request may have spent it first
+	used, err := s.otpRepo.MarkUsed(otp.ID)
+	if err != nil {
 		return nil, fmt.Errorf("failed to update OTP: %w", err)
 	}
+	if !used {
+		return nil, ErrInvalidOTP
+	}
+	otp.IsUsed = true
 
 	return otp, nil
 }
 
-// ResendOTP resends an OTP (invalidates old one and creates new one)
+// ResendOTP resends an OTP; SendOTP invalidates the old one
 func (s *OTPService) ResendOTP(email, otpType string, userID *uint) (*models.OTPVerification, error) {
-	// Invalidate any existing OTPs for this email and type
-	if err := s.otpRepo.InvalidateByEmailAndType(email, otpType); err != nil {
-		return nil, fmt.Errorf("failed to invalidate existing OTPs: %w", err)
-	}
-
-	// Send new OTP
 	return s.SendOTP(email, otpType, userID)
 }
 
+// hashCode is the form a code is stored in: an HMAC-SHA256 under the hash
+// key, bound to the challenge so a hash can't be reused for another record
+func (s *OTPService) hashCode(challengeID, otpCode string) string {
+	mac := hmac.New(sha256.New, s.hashKey)
+	mac.Write([]byte(challengeID + ":" + otpCode))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 // CleanupExpiredOTPs removes expired OTPs from the database
 func (s *OTPService) CleanupExpiredOTPs() error {
 	return s.otpRepo.DeleteExpired()
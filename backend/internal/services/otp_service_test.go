@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"electronics-store/internal/config"
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const testOTPHashKey = "test-otp-hash-key"
+
+// memoryOTPRepository keeps OTP records in memory, with the same conditional
+// updates as the database repository. Transactions run directly against it.
+type memoryOTPRepository struct {
+	repository.OTPRepository
+	otps []*models.OTPVerification
+}
+
+func (r *memoryOTPRepository) Create(otp *models.OTPVerification) error {
+	otp.ID = uint(len(r.otps) + 1)
+	stored := *otp
+	r.otps = append(r.otps, &stored)
+	return nil
+}
+
+func (r *memoryOTPRepository) FindByResourceID(resourceID string) (*models.OTPVerification, error) {
+	for _, otp := range r.otps {
+		if otp.ResourceID == resourceID {
+			found := *otp
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryOTPRepository) RecordFailedAttempt(id uint, maxAttempts int) (bool, error) {
+	otp := r.otps[id-1]
+	if otp.IsUsed {
+		return false, nil
+	}
+	otp.Attempts++
+	if otp.Attempts >= maxAttempts {
+		otp.IsUsed = true
+	}
+	return true, nil
+}
+
+func (r *memoryOTPRepository) MarkUsed(id uint) (bool, error) {
+	otp := r.otps[id-1]
+	if otp.IsUsed {
+		return false, nil
+	}
+	otp.IsUsed = true
+	return true, nil
+}
+
+func (r *memoryOTPRepository) InvalidateByEmailAndType(email, otpType string) error {
+	for _, otp := range r.otps {
+		if otp.Email == email && otp.OTPType == otpType {
+			otp.IsUsed = true
+		}
+	}
+	return nil
+}
+
+func (r *memoryOTPRepository) Transaction(fn func(tx repository.OTPRepository) error) error {
+	return fn(r)
+}
+
+// codeCapturingNotifier remembers the last OTP code it was asked to email
+type codeCapturingNotifier struct {
+	code string
+}
+
+func (n *codeCapturingNotifier) Notify(ctx context.Context, out NotificationOutbox, notification Notification) error {
+	n.code = notification.Emails[0].OTPCode
+	return nil
+}
+
+func (n *codeCapturingNotifier) Flush() {}
+
+func newTestOTPService(t *testing.T, maxAttempts int) (*OTPService, *memoryOTPRepository, *codeCapturingNotifier) {
+	t.Helper()
+	emailService, err := NewEmailService(&config.EmailConfig{})
+	if err != nil {
+		t.Fatalf("NewEmailService: %v", err)
+	}
+	repo := &memoryOTPRepository{}
+	notifier := &codeCapturingNotifier{}
+	otps, err := NewOTPService(repo, emailService, notifier, config.OTPConfig{HashKey: testOTPHashKey, MaxAttempts: maxAttempts}, false)
+	if err != nil {
+		t.Fatalf("NewOTPService: %v", err)
+	}
+	return otps, repo, notifier
+}
+
+func TestOTPStoredAsChallengeHMAC(t *testing.T) {
+	otps, repo, notifier := newTestOTPService(t, 5)
+
+	challenge, err := otps.SendOTP("ada@example.com", "login", nil)
+	if err != nil {
+		t.Fatalf("SendOTP: %v", err)
+	}
+	stored := repo.otps[0]
+	if strings.Contains(stored.CodeHash, notifier.code) {
+		t.Fatalf("stored hash %q contains the code %s", stored.CodeHash, notifier.code)
+	}
+
+	mac := hmac.New(sha256.New, []byte(testOTPHashKey))
+	mac.Write([]byte(challenge.ResourceID + ":" + notifier.code))
+	if want := hex.EncodeToString(mac.Sum(nil)); stored.CodeHash != want {
+		t.Errorf("stored hash = %s, want HMAC-SHA256 of challenge ID and code %s", stored.CodeHash, want)
+	}
+}
+
+func TestVerifyOTPChallenge(t *testing.T) {
+	otps, repo, notifier := newTestOTPService(t, 5)
+
+	ada, err := otps.SendOTP("ada@example.com", "login", nil)
+	if err != nil {
+		t.Fatalf("SendOTP: %v", err)
+	}
+	adaCode := notifier.code
+	grace, err := otps.SendOTP("grace@example.com", "login", nil)
+	if err != nil {
+		t.Fatalf("SendOTP: %v", err)
+	}
+
+	// Copying a known hash to another record doesn't make its code work
+	// there, since the hash is bound to the challenge ID
+	repo.otps[1].CodeHash = repo.otps[0].CodeHash
+
+	tests := []struct {
+		name        string
+		challengeID string
+		email       string
+		code        string
+		wantErr     error
+	}{
+		{"code of another challenge", grace.ResourceID, "grace@example.com", adaCode, ErrInvalidOTP},
+		{"challenge of another email", ada.ResourceID, "grace@example.com", adaCode, ErrInvalidOTP},
+		{"unknown challenge", "no-such-challenge", "ada@example.com", adaCode, ErrInvalidOTP},
+		{"own challenge", ada.ResourceID, "ADA@example.com", adaCode, nil},
+		{"own challenge again", ada.ResourceID, "ada@example.com", adaCode, ErrInvalidOTP},
+	}
+	for _, tt := range tests {
+		_, err := otps.VerifyOTP(tt.challengeID, tt.email, tt.code, "login")
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: VerifyOTP error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestVerifyOTPMaxAttempts(t *testing.T) {
+	otps, repo, notifier := newTestOTPService(t, 3)
+
+	challenge, err := otps.SendOTP("ada@example.com", "login", nil)
+	if err != nil {
+		t.Fatalf("SendOTP: %v", err)
+	}
+
+	// Codes are never below 100000, so 000000 is always wrong
+	tests := []struct {
+		name    string
+		code    string
+		wantErr error
+	}{
+		{"first wrong code", "000000", ErrInvalidOTP},
+		{"second wrong code", "000000", ErrInvalidOTP},
+		{"last wrong code", "000000", ErrOTPAttemptsExceeded},
+		{"right code after the limit", notifier.code, ErrInvalidOTP},
+	}
+	for _, tt := range tests {
+		_, err := otps.VerifyOTP(challenge.ResourceID, "ada@example.com", tt.code, "login")
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: VerifyOTP error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+	if stored := repo.otps[0]; stored.Attempts != 3 || !stored.IsUsed {
+		t.Errorf("stored OTP has %d attempts, used %v; want 3, used", stored.Attempts, stored.IsUsed)
+	}
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"electronics-store/internal/config"
+
+	"github.com/google/uuid"
+)
+
+// Payment providers selectable with PAYMENT_PROVIDER
+const (
+	// PaymentProviderNone, or an empty PAYMENT_PROVIDER, runs the store
+	// without online payments
+	PaymentProviderNone = "none"
+	PaymentProviderMock = "mock"
+)
+
+// placeholderWebhookSecrets are the webhook secrets that shipped as defaults
+// or examples; anyone could sign webhooks with them
+var placeholderWebhookSecrets = []string{"mock-webhook-secret", "change-me-webhook-secret"}
+
+// Gateway transaction statuses
+const (
+	PaymentResultAuthorized = "authorized"
+	PaymentResultCaptured   = "captured"
+	PaymentResultPending    = "pending"
+	PaymentResultDeclined   = "declined"
+	PaymentResultRefunded   = "refunded"
+)
+
+// Webhook event types understood by the payment flow
+const (
+	PaymentEventCaptured = "payment.captured"
+	PaymentEventFailed   = "payment.failed"
+	PaymentEventRefunded = "payment.refunded"
+)
+
+var (
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrInvalidWebhookPayload   = errors.New("invalid webhook payload")
+	ErrUnknownTransaction      = errors.New("unknown payment transaction")
+)
+
+// PaymentGateway is implemented by every payment provider the store can charge through
+type PaymentGateway interface {
+	// Name identifies the provider in stored gateway responses
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (*PaymentResult, error)
+	Capture(ctx context.Context, transactionID string, amount float64) (*PaymentResult, error)
+	// Refund returns part or all of a captured charge; the result carries the
+	// provider's refund ID as its TransactionID
+	Refund(ctx context.Context, transactionID string, amount float64) (*PaymentResult, error)
+	// VerifyWebhook checks the signature of a webhook body and decodes it
+	VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
+
+// NewPaymentGateway returns the configured payment provider, or nil when
+// payments are disabled. It fails when the webhook secret of a provider is
+// missing or a placeholder, and refuses the mock provider outside dev mode
+// since it accepts any payment token.
+func NewPaymentGateway(cfg *config.PaymentConfig, devMode bool) (PaymentGateway, error) {
+	if cfg.Provider == "" || cfg.Provider == PaymentProviderNone {
+		return nil, nil
+	}
+	if cfg.WebhookSecret == "" {
+		return nil, errors.New("PAYMENT_WEBHOOK_SECRET must be set")
+	}
+	for _, placeholder := range placeholderWebhookSecrets {
+		if cfg.WebhookSecret == placeholder {
+			return nil, errors.New("PAYMENT_WEBHOOK_SECRET is still the example value")
+		}
+	}
+
+	switch cfg.Provider {
+	case PaymentProviderMock:
+		if !devMode {
+			return nil, errors.New("the mock payment provider is only available with DEV_MODE=true")
+		}
+		return NewMockPaymentGateway(cfg.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", cfg.Provider)
+	}
+}
+
+type AuthorizeRequest struct {
+	Reference string // our order number
+	Method    string
+	Token     string
+	Amount    float64
+	Currency  string
+}
+
+// PaymentResult is the outcome of a single gateway call
+type PaymentResult struct {
+	TransactionID string         `json:"transaction_id"`
+	Status        string         `json:"status"`
+	Amount        float64        `json:"amount"`
+	Message       string         `json:"message,omitempty"`
+	Raw           map[string]any `json:"raw,omitempty"`
+}
+
+type WebhookEvent struct {
+	ID            string  `json:"id"`
+	Type          string  `json:"type"`
+	TransactionID string  `json:"transaction_id"`
+	Amount        float64 `json:"amount"`
+	// RefundID is the gateway's ID of the refund on payment.refunded events
+	RefundID string `json:"refund_id,omitempty"`
+}
+
+// MockPaymentGateway is an in-process provider for development and end to end
+// testing. Its outcome depends only on the payment token:
+//
+//	tok_decline, tok_fail*  authorization is declined
+//	tok_pending             capture stays pending until a payment.captured webhook arrives
+//	anything else           authorization and capture succeed
+//
+// Transaction IDs are derived from the order reference and token, refund IDs
+// are unique per refund, and webhooks are signed with HMAC-SHA256 of the raw
+// body using the configured secret.
+type MockPaymentGateway struct {
+	webhookSecret string
+}
+
+func NewMockPaymentGateway(webhookSecret string) *MockPaymentGateway {
+	return &MockPaymentGateway{
+		webhookSecret: webhookSecret,
+	}
+}
+
+func (g *MockPaymentGateway) Name() string {
+	return "mock"
+}
+
+func (g *MockPaymentGateway) Authorize(ctx context.Context, req AuthorizeRequest) (*PaymentResult, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("invalid amount: %.2f", req.Amount)
+	}
+
+	result := &PaymentResult{
+		TransactionID: mockTransactionID("ch", req.Reference, req.Token),
+		Status:        PaymentResultAuthorized,
+		Amount:        req.Amount,
+		Raw: map[string]any{
+			"reference": req.Reference,
+			"method":    req.Method,
+			"currency":  req.Currency,
+		},
+	}
+	if req.Token == "tok_decline" || strings.HasPrefix(req.Token, "tok_fail") {
+		result.Status = PaymentResultDeclined
+		result.Message = "card declined"
+	}
+	return result, nil
+}
+
+func (g *MockPaymentGateway) Capture(ctx context.Context, transactionID string, amount float64) (*PaymentResult, error) {
+	if !strings.HasPrefix(transactionID, "mock_ch_") {
+		return nil, ErrUnknownTransaction
+	}
+
+	status := PaymentResultCaptured
+	if strings.HasSuffix(transactionID, "_async") {
+		status = PaymentResultPending
+	}
+	return &PaymentResult{
+		TransactionID: transactionID,
+		Status:        status,
+		Amount:        amount,
+	}, nil
+}
+
+func (g *MockPaymentGateway) Refund(ctx context.Context, transactionID string, amount float64) (*PaymentResult, error) {
+	if !strings.HasPrefix(transactionID, "mock_ch_") {
+		return nil, ErrUnknownTransaction
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("invalid refund amount: %.2f", amount)
+	}
+
+	return &PaymentResult{
+		TransactionID: mockTransactionID("rf", transactionID, uuid.New().String()),
+		Status:        PaymentResultRefunded,
+		Amount:        amount,
+		Raw: map[string]any{
+			"charge_id": transactionID,
+		},
+	}, nil
+}
+
+func (g *MockPaymentGateway) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	if !hmac.Equal([]byte(g.Sign(payload)), []byte(strings.ToLower(signature))) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, ErrInvalidWebhookPayload
+	}
+	if event.Type == "" || event.TransactionID == "" {
+		return nil, ErrInvalidWebhookPayload
+	}
+	return &event, nil
+}
+
+// Sign returns the signature the mock provider expects for a webhook body
+func (g *MockPaymentGateway) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(g.webhookSecret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func mockTransactionID(kind, reference, token string) string {
+	sum := sha256.Sum256([]byte(reference + ":" + token))
+	id := "mock_" + kind + "_" + hex.EncodeToString(sum[:])[:24]
+	if token == "tok_pending" {
+		id += "_async"
+	}
+	return id
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"electronics-store/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitStore keeps the expiring counters rate limits and lockouts are
+// built on
+type RateLimitStore interface {
+	// Incr adds one to the counter at key and returns the new count and how
+	// long the counter has left. A new counter expires after ttl; later
+	// increments don't extend it.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, time.Duration, error)
+	// Get returns the counter at key and how long it has left, or 0 and 0
+	// when it doesn't exist
+	Get(ctx context.Context, key string) (int64, time.Duration, error)
+	// Set overwrites the counter at key and expires it after ttl
+	Set(ctx context.Context, key string, value int64, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// NewRateLimitStore creates the store selected by cfg.Backend
+func NewRateLimitStore(cfg config.RateLimitConfig, redisCfg config.RedisConfig) (RateLimitStore, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		return NewMemoryRateLimitStore(), nil
+	case "redis":
+		return NewRedisRateLimitStore(redisCfg)
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend %q", cfg.Backend)
+	}
+}
+
+type memoryCounter struct {
+	value     int64
+	expiresAt time.Time
+}
+
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	counters  map[string]*memoryCounter
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryRateLimitStore keeps counters in process memory. Every instance
+// counts separately, so use it only when a single instance is running.
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{
+		counters:  make(map[string]*memoryCounter),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (s *memoryRateLimitStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	counter := s.live(key, now)
+	if counter == nil {
+		counter = &memoryCounter{expiresAt: now.Add(ttl)}
+		s.counters[key] = counter
+	}
+	counter.value++
+	return counter.value, counter.expiresAt.Sub(now), nil
+}
+
+func (s *memoryRateLimitStore) Get(ctx context.Context, key string) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	counter := s.live(key, now)
+	if counter == nil {
+		return 0, 0, nil
+	}
+	return counter.value, counter.expiresAt.Sub(now), nil
+}
+
+func (s *memoryRateLimitStore) Set(ctx context.Context, key string, value int64, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counters[key] = &memoryCounter{value: value, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+func (s *memoryRateLimitStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.counters, key)
+	}
+	return nil
+}
+
+// live returns the unexpired counter at key. s.mu must be held.
+func (s *memoryRateLimitStore) live(key string, now time.Time) *memoryCounter {
+	counter, ok := s.counters[key]
+	if !ok {
+		return nil
+	}
+	if !now.Before(counter.expiresAt) {
+		delete(s.counters, key)
+		return nil
+	}
+	return counter
+}
+
+// sweep drops expired counters once a minute so keys that are never seen
+// again don't accumulate. s.mu must be held.
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	for key, counter := range s.counters {
+		if !now.Before(counter.expiresAt) {
+			delete(s.counters, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// redisIncrScript increments a counter and sets its expiry only when the
+// increment created it, in one round trip
+var redisIncrScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+const redisRateLimitPrefix = "ratelimit:"
+
+type redisRateLimitStore struct {
+	client *redis.Client
+}
+
+// NewRedisRateLimitStore keeps counters in Redis so that all instances
+// share them
+func NewRedisRateLimitStore(cfg config.RedisConfig) (RateLimitStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     net.JoinHostPort(cfg.Host, cfg.Port),
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &redisRateLimitStore{client: client}, nil
+}
+
+func (s *redisRateLimitStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, time.Duration, error) {
+	result, err := redisIncrScript.Run(ctx, s.client, []string{redisRateLimitPrefix + key}, ttl.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(result) != 2 {
+		return 0, 0, fmt.Errorf("unexpected redis reply %v", result)
+	}
+	return result[0], time.Duration(result[1]) * time.Millisecond, nil
+}
+
+func (s *redisRateLimitStore) Get(ctx context.Context, key string) (int64, time.Duration, error) {
+	pipe := s.client.Pipeline()
+	get := pipe.Get(ctx, redisRateLimitPrefix+key)
+	ttl := pipe.PTTL(ctx, redisRateLimitPrefix+key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, 0, err
+	}
+
+	value, err := get.Int64()
+	if err == redis.Nil {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	left := ttl.Val()
+	if left < 0 {
+		left = 0
+	}
+	return value, left, nil
+}
+
+func (s *redisRateLimitStore) Set(ctx context.Context, key string, value int64, ttl time.Duration) error {
+	return s.client.Set(ctx, redisRateLimitPrefix+key, value, ttl).Err()
+}
+
+func (s *redisRateLimitStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = redisRateLimitPrefix + key
+	}
+	return s.client.Del(ctx, prefixed...).Err()
+}
+
+// RateLimitRule allows Limit requests per Window for each key. Name keeps
+// the counters of different rules apart; rules sharing a name share a
+// budget.
+type RateLimitRule struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimitResult is the outcome of counting one request against a rule
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // until the window resets
+}
+
+// RateLimiter enforces fixed-window rate limits and temporary account
+// lockouts after repeated failed logins
+type RateLimiter struct {
+	store RateLimitStore
+	cfg   config.RateLimitConfig
+}
+
+func NewRateLimiter(store RateLimitStore, cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+// Allow counts a request for key against rule
+func (l *RateLimiter) Allow(ctx context.Context, rule RateLimitRule, key string) (RateLimitResult, error) {
+	count, left, err := l.store.Incr(ctx, "rule:"+rule.Name+":"+key, rule.Window)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	remaining := rule.Limit - int(count)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return RateLimitResult{
+		Allowed:    count <= int64(rule.Limit),
+		Remaining:  remaining,
+		RetryAfter: left,
+	}, nil
+}
+
+// LockedFor returns how long the account is still locked out, or 0
+func (l *RateLimiter) LockedFor(ctx context.Context, account string) (time.Duration, error) {
+	locked, left, err := l.store.Get(ctx, lockoutKey("locked", account))
+	if err != nil || locked == 0 {
+		return 0, err
+	}
+	return left, nil
+}
+
+// RecordLoginFailure counts a failed login for the account and locks it
+// once LoginMaxFailures is reached within LoginFailureWindow. It returns
+// the length of the lockout it started, or 0.
+func (l *RateLimiter) RecordLoginFailure(ctx context.Context, account string) (time.Duration, error) {
+	failures, _, err := l.store.Incr(ctx, lockoutKey("failures", account), l.cfg.LoginFailureWindow)
+	if err != nil {
+		return 0, err
+	}
+	if failures < int64(l.cfg.LoginMaxFailures) {
+		return 0, nil
+	}
+
+	// Repeat lockouts within a day back off exponentially
+	level, _, err := l.store.Incr(ctx, lockoutKey("level", account), 24*time.Hour)
+	if err != nil {
+		return 0, err
+	}
+	duration := l.cfg.LockoutBase
+	for i := int64(1); i < level && duration < l.cfg.LockoutMax; i++ {
+		duration *= 2
+	}
+	if duration > l.cfg.LockoutMax {
+		duration = l.cfg.LockoutMax
+	}
+
+	if err := l.store.Set(ctx, lockoutKey("locked", account), 1, duration); err != nil {
+		return 0, err
+	}
+	if err := l.store.Delete(ctx, lockoutKey("failures", account)); err != nil {
+		return 0, err
+	}
+	return duration, nil
+}
+
+// RecordLoginSuccess clears the failed logins and backoff of the account
+func (l *RateLimiter) RecordLoginSuccess(ctx context.Context, account string) error {
+	return l.store.Delete(ctx, lockoutKey("failures", account), lockoutKey("level", account))
+}
+
+func lockoutKey(kind, account string) string {
+	return "login:" + kind + ":" + strings.ToLower(strings.TrimSpace(account))
+}
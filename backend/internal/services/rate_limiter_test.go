@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"electronics-store/internal/config"
+)
+
+// testClock is a clock tests move forward by hand
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestMemoryStore() (*memoryRateLimitStore, *testClock) {
+	clock := &testClock{now: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryRateLimitStore().(*memoryRateLimitStore)
+	store.now = clock.Now
+	store.lastSweep = clock.now
+	return store, clock
+}
+
+func TestMemoryRateLimitStoreWindow(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestMemoryStore()
+
+	for want := int64(1); want <= 3; want++ {
+		count, left, err := store.Incr(ctx, "k", time.Minute)
+		if err != nil {
+			t.Fatalf("Incr: %v", err)
+		}
+		if count != want || left != time.Minute {
+			t.Fatalf("Incr = %d, %s; want %d, %s", count, left, want, time.Minute)
+		}
+	}
+
+	// Later increments don't extend the window
+	clock.Advance(40 * time.Second)
+	count, left, err := store.Incr(ctx, "k", time.Minute)
+	if err != nil {
+		t.Fatalf("Incr: %v", err)
+	}
+	if count != 4 || left != 20*time.Second {
+		t.Fatalf("Incr = %d, %s; want 4, 20s", count, left)
+	}
+
+	// The counter resets once the window is over
+	clock.Advance(20 * time.Second)
+	if count, left, _ := store.Get(ctx, "k"); count != 0 || left != 0 {
+		t.Fatalf("Get after the window = %d, %s; want 0, 0", count, left)
+	}
+	count, left, err = store.Incr(ctx, "k", time.Minute)
+	if err != nil {
+		t.Fatalf("Incr: %v", err)
+	}
+	if count != 1 || left != time.Minute {
+		t.Fatalf("Incr after the window = %d, %s; want 1, %s", count, left, time.Minute)
+	}
+}
+
+func TestMemoryRateLimitStoreKeys(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestMemoryStore()
+
+	store.Incr(ctx, "a", time.Minute)
+	store.Incr(ctx, "a", time.Minute)
+	clock.Advance(30 * time.Second)
+	store.Incr(ctx, "b", time.Minute)
+
+	if count, left, _ := store.Get(ctx, "a"); count != 2 || left != 30*time.Second {
+		t.Errorf("Get(a) = %d, %s; want 2, 30s", count, left)
+	}
+	if count, left, _ := store.Get(ctx, "b"); count != 1 || left != time.Minute {
+		t.Errorf("Get(b) = %d, %s; want 1, %s", count, left, time.Minute)
+	}
+
+	// Each key has its own window
+	clock.Advance(30 * time.Second)
+	if count, _, _ := store.Get(ctx, "a"); count != 0 {
+		t.Errorf("Get(a) after its window = %d, want 0", count)
+	}
+	if count, _, _ := store.Get(ctx, "b"); count != 1 {
+		t.Errorf("Get(b) within its window = %d, want 1", count)
+	}
+
+	if err := store.Set(ctx, "c", 7, time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := store.Delete(ctx, "b"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if count, _, _ := store.Get(ctx, "b"); count != 0 {
+		t.Errorf("Get(b) after Delete = %d, want 0", count)
+	}
+	if count, _, _ := store.Get(ctx, "c"); count != 7 {
+		t.Errorf("Get(c) = %d, want 7", count)
+	}
+}
+
+func TestMemoryRateLimitStoreSweep(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestMemoryStore()
+
+	store.Incr(ctx, "gone", time.Second)
+	clock.Advance(2 * time.Minute)
+	store.Incr(ctx, "fresh", time.Minute)
+
+	if _, ok := store.counters["gone"]; ok {
+		t.Error("expired counter was not swept")
+	}
+}
+
+func TestRateLimiterAllow(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestMemoryStore()
+	limiter := NewRateLimiter(store, config.RateLimitConfig{})
+	login := RateLimitRule{Name: "login", Limit: 3, Window: time.Minute}
+	otp := RateLimitRule{Name: "otp", Limit: 3, Window: time.Minute}
+
+	for i := 1; i <= 3; i++ {
+		result, err := limiter.Allow(ctx, login, "203.0.113.7")
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		if !result.Allowed || result.Remaining != 3-i {
+			t.Fatalf("request %d: %+v, want allowed with %d remaining", i, result, 3-i)
+		}
+	}
+	result, _ := limiter.Allow(ctx, login, "203.0.113.7")
+	if result.Allowed || result.Remaining != 0 || result.RetryAfter != time.Minute {
+		t.Fatalf("request over the limit: %+v, want denied, retry after %s", result, time.Minute)
+	}
+
+	// Other keys and other rules have budgets of their own
+	if result, _ := limiter.Allow(ctx, login, "198.51.100.2"); !result.Allowed || result.Remaining != 2 {
+		t.Errorf("other key: %+v, want allowed with 2 remaining", result)
+	}
+	if result, _ := limiter.Allow(ctx, otp, "203.0.113.7"); !result.Allowed || result.Remaining != 2 {
+		t.Errorf("other rule: %+v, want allowed with 2 remaining", result)
+	}
+
+	// The budget is back once the window is over
+	clock.Advance(time.Minute)
+	if result, _ := limiter.Allow(ctx, login, "203.0.113.7"); !result.Allowed || result.Remaining != 2 {
+		t.Errorf("after the window: %+v, want allowed with 2 remaining", result)
+	}
+}
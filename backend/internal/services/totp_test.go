@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed of the RFC 6238 test vectors
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	// The RFC lists 8 digit codes; 6 digit codes are their last six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		if got := totpCode(rfc6238Secret, tt.unix/totpPeriod); got != tt.want {
+			t.Errorf("code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := totpEncoding.EncodeToString(rfc6238Secret)
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		wantOK bool
+		// wantStep is the time step of an accepted code, relative to now
+		wantStep int64
+	}{
+		{"current step", secret, totpCode(rfc6238Secret, current), true, 0},
+		{"previous step", secret, totpCode(rfc6238Secret, current-1), true, -1},
+		{"next step", secret, totpCode(rfc6238Secret, current+1), true, 1},
+		{"two steps behind", secret, totpCode(rfc6238Secret, current-2), false, 0},
+		{"two steps ahead", secret, totpCode(rfc6238Secret, current+2), false, 0},
+		{"lower case secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", totpCode(rfc6238Secret, current), true, 0},
+		{"wrong code", secret, "000000", false, 0},
+		{"too short", secret, totpCode(rfc6238Secret, current)[:5], false, 0},
+		{"too long", secret, totpCode(rfc6238Secret, current) + "0", false, 0},
+		{"invalid secret", "not base32!", totpCode(rfc6238Secret, current), false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, now)
+			if ok != tt.wantOK {
+				t.Fatalf("ValidateTOTP ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && step != current+tt.wantStep {
+				t.Errorf("step = %d, want %d", step, current+tt.wantStep)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/repository"
+)
+
+var (
+	ErrAddressNotFound = errors.New("address not found")
+	ErrInvalidAddress  = errors.New("invalid address")
+)
+
+// AddressUsecase manages a user's address book. Each user has at most one
+// default address per type; the first address of a type becomes its default
+// and deleting a default promotes the most recently updated remaining address
+// of that type. Default changes are made in one transaction.
+type AddressUsecase interface {
+	List(ctx context.Context, userID uint) ([]*models.Address, error)
+	Get(ctx context.Context, userID uint, resourceID string) (*models.Address, error)
+	Create(ctx context.Context, userID uint, req dto.CreateAddressRequest) (*models.Address, error)
+	Update(ctx context.Context, userID uint, resourceID string, req dto.UpdateAddressRequest) (*models.Address, error)
+	Delete(ctx context.Context, userID uint, resourceID string) error
+}
+
+type addressUsecase struct {
+	addressRepo repository.AddressRepository
+}
+
+func NewAddressUsecase(addressRepo repository.AddressRepository) AddressUsecase {
+	return &addressUsecase{
+		addressRepo: addressRepo,
+	}
+}
+
+func (u *addressUsecase) List(ctx context.Context, userID uint) ([]*models.Address, error) {
+	return u.addressRepo.ListByUser(ctx, userID)
+}
+
+func (u *addressUsecase) Get(ctx context.Context, userID uint, resourceID string) (*models.Address, error) {
+	address, err := u.addressRepo.GetForUser(ctx, userID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if address == nil {
+		return nil, ErrAddressNotFound
+	}
+	return address, nil
+}
+
+func (u *addressUsecase) Create(ctx context.Context, userID uint, req dto.CreateAddressRequest) (*models.Address, error) {
+	address := &models.Address{
+		UserID:       userID,
+		Type:         req.Type,
+		FirstName:    strings.TrimSpace(req.FirstName),
+		LastName:     strings.TrimSpace(req.LastName),
+		Company:      strings.TrimSpace(req.Company),
+		AddressLine1: strings.TrimSpace(req.AddressLine1),
+		AddressLine2: strings.TrimSpace(req.AddressLine2),
+		City:         strings.TrimSpace(req.City),
+		State:        strings.TrimSpace(req.State),
+		PostalCode:   strings.TrimSpace(req.PostalCode),
+		Country:      strings.TrimSpace(req.Country),
+		Phone:        strings.TrimSpace(req.Phone),
+		IsDefault:    req.IsDefault,
+	}
+	if address.Type == "" {
+		address.Type = models.AddressTypeShipping
+	}
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	err := u.addressRepo.Transaction(ctx, func(tx repository.AddressRepository) error {
+		if err := tx.LockUser(ctx, userID); err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			current, err := tx.GetDefault(ctx, userID, address.Type)
+			if err != nil {
+				return err
+			}
+			address.IsDefault = current == nil
+		}
+
+		if err := tx.Create(ctx, address); err != nil {
+			return err
+		}
+		if address.IsDefault {
+			return tx.ClearDefault(ctx, userID, address.Type, address.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+func (u *addressUsecase) Update(ctx context.Context, userID uint, resourceID string, req dto.UpdateAddressRequest) (*models.Address, error) {
+	var address *models.Address
+	err := u.addressRepo.Transaction(ctx, func(tx repository.AddressRepository) error {
+		if err := tx.LockUser(ctx, userID); err != nil {
+			return err
+		}
+		var err error
+		address, err = tx.GetForUser(ctx, userID, resourceID)
+		if err != nil {
+			return err
+		}
+		if address == nil {
+			return ErrAddressNotFound
+		}
+
+		previousType, wasDefault := address.Type, address.IsDefault
+		applyAddressUpdate(address, req)
+		if err := validateAddress(address); err != nil {
+			return err
+		}
+
+		typeChanged := address.Type != previousType
+		if req.IsDefault != nil {
+			address.IsDefault = *req.IsDefault
+		} else if typeChanged {
+			// Moving to another type only takes that type's default when it has none
+			current, err := tx.GetDefault(ctx, userID, address.Type)
+			if err != nil {
+				return err
+			}
+			address.IsDefault = current == nil
+		}
+
+		if err := tx.Update(ctx, address); err != nil {
+			return err
+		}
+		if address.IsDefault {
+			if err := tx.ClearDefault(ctx, userID, address.Type, address.ID); err != nil {
+				return err
+			}
+		}
+		if wasDefault && typeChanged {
+			return promoteDefaultAddress(ctx, tx, userID, previousType)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+func (u *addressUsecase) Delete(ctx context.Context, userID uint, resourceID string) error {
+	return u.addressRepo.Transaction(ctx, func(tx repository.AddressRepository) error {
+		if err := tx.LockUser(ctx, userID); err != nil {
+			return err
+		}
+		address, err := tx.GetForUser(ctx, userID, resourceID)
+		if err != nil {
+			return err
+		}
+		if address == nil {
+			return ErrAddressNotFound
+		}
+
+		if err := tx.Delete(ctx, address.ID); err != nil {
+			return err
+		}
+		if address.IsDefault {
+			return promoteDefaultAddress(ctx, tx, userID, address.Type)
+		}
+		return nil
+	})
+}
+
+// promoteDefaultAddress makes the user's most recently updated address of the
+// given type its default, if the user has any
+func promoteDefaultAddress(ctx context.Context, tx repository.AddressRepository, userID uint, addressType string) error {
+	address, err := tx.GetLatest(ctx, userID, addressType)
+	if err != nil || address == nil {
+		return err
+	}
+	address.IsDefault = true
+	return tx.Update(ctx, address)
+}
+
+func applyAddressUpdate(address *models.Address, req dto.UpdateAddressRequest) {
+	if req.Type != nil {
+		address.Type = strings.TrimSpace(*req.Type)
+	}
+	if req.FirstName != nil {
+		address.FirstName = strings.TrimSpace(*req.FirstName)
+	}
+	if req.LastName != nil {
+		address.LastName = strings.TrimSpace(*req.LastName)
+	}
+	if req.Company != nil {
+		address.Company = strings.TrimSpace(*req.Company)
+	}
+	if req.AddressLine1 != nil {
+		address.AddressLine1 = strings.TrimSpace(*req.AddressLine1)
+	}
+	if req.AddressLine2 != nil {
+		address.AddressLine2 = strings.TrimSpace(*req.AddressLine2)
+	}
+	if req.City != nil {
+		address.City = strings.TrimSpace(*req.City)
+	}
+	if req.State != nil {
+		address.State = strings.TrimSpace(*req.State)
+	}
+	if req.PostalCode != nil {
+		address.PostalCode = strings.TrimSpace(*req.PostalCode)
+	}
+	if req.Country != nil {
+		address.Country = strings.TrimSpace(*req.Country)
+	}
+	if req.Phone != nil {
+		address.Phone = strings.TrimSpace(*req.Phone)
+	}
+}
+
+func validateAddress(address *models.Address) error {
+	if address.Type != models.AddressTypeShipping && address.Type != models.AddressTypeBilling {
+		return fmt.Errorf("%w: type must be shipping or billing", ErrInvalidAddress)
+	}
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"first_name", address.FirstName},
+		{"last_name", address.LastName},
+		{"address_line_1", address.AddressLine1},
+		{"city", address.City},
+		{"state", address.State},
+		{"postal_code", address.PostalCode},
+		{"country", address.Country},
+	}
+	for _, field := range required {
+		if field.value == "" {
+			return fmt.Errorf("%w: %s is required", ErrInvalidAddress, field.name)
+		}
+	}
+	return nil
+}
+
+// resolveBillingAddress returns the given address of the user, or their
+// default billing address when none is given, falling back to the shipping
+// address
+func resolveBillingAddress(ctx context.Context, addressRepo repository.AddressRepository, userID uint, resourceID string, shippingAddress *models.Address) (*models.Address, error) {
+	if resourceID != "" {
+		address, err := addressRepo.GetForUser(ctx, userID, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		if address == nil {
+			return nil, ErrAddressNotFound
+		}
+		return address, nil
+	}
+
+	address, err := addressRepo.GetDefault(ctx, userID, models.AddressTypeBilling)
+	if err != nil {
+		return nil, err
+	}
+	if address == nil {
+		return shippingAddress, nil
+	}
+	return address, nil
+}
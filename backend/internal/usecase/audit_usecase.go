@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/repository"
+)
+
+// AuditRequest describes an admin request that changes data. The audit
+// middleware puts it in the request context, code handling the request
+// describes the changes it makes with RecordAudit, and the middleware writes
+// them once the response status is known.
+type AuditRequest struct {
+	ActorID   *uint
+	IPAddress string
+	UserAgent string
+	RequestID string
+	Method    string
+	Path      string
+
+	changes []AuditChange
+}
+
+// Changes returns the changes recorded during the request
+func (r *AuditRequest) Changes() []AuditChange {
+	return r.changes
+}
+
+// AuditChange is one change to an entity. Before and After are snapshots
+// taken with AuditSnapshot; a create has no Before and a delete no After.
+type AuditChange struct {
+	Action     string // e.g. "order.status_update"
+	EntityType string
+	EntityID   string
+	Before     json.RawMessage
+	After      json.RawMessage
+}
+
+type auditRequestKey struct{}
+
+// WithAuditRequest returns a context carrying the audited request
+func WithAuditRequest(ctx context.Context, req *AuditRequest) context.Context {
+	return context.WithValue(ctx, auditRequestKey{}, req)
+}
+
+// RecordAudit adds a change to the audited request in ctx. Outside an
+// audited request it does nothing, so code shared with the storefront can
+// call it unconditionally.
+func RecordAudit(ctx context.Context, change AuditChange) {
+	if req, ok := ctx.Value(auditRequestKey{}).(*AuditRequest); ok {
+		req.changes = append(req.changes, change)
+	}
+}
+
+// AuditSnapshot encodes v for an AuditChange. Take the before snapshot
+// before modifying the entity, since entities are usually changed in place.
+func AuditSnapshot(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// AuditUsecase writes and reads the append-only admin audit log
+type AuditUsecase interface {
+	// Record writes one audit log entry per change of the request, keeping
+	// only the fields that differ between the before and after snapshots
+	Record(ctx context.Context, req *AuditRequest, statusCode int, changes []AuditChange) error
+	List(ctx context.Context, page, limit int, filters map[string]interface{}) ([]*models.AuditLog, int64, error)
+}
+
+type auditUsecase struct {
+	auditLogRepo repository.AuditLogRepository
+}
+
+func NewAuditUsecase(auditLogRepo repository.AuditLogRepository) AuditUsecase {
+	return &auditUsecase{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+func (u *auditUsecase) Record(ctx context.Context, req *AuditRequest, statusCode int, changes []AuditChange) error {
+	for _, change := range changes {
+		before, after := auditDiff(change.Before, change.After)
+		entry := &models.AuditLog{
+			ActorID:    req.ActorID,
+			Action:     change.Action,
+			EntityType: change.EntityType,
+			EntityID:   change.EntityID,
+			Before:     before,
+			After:      after,
+			Method:     req.Method,
+			Path:       req.Path,
+			StatusCode: statusCode,
+			IPAddress:  req.IPAddress,
+			UserAgent:  truncate(req.UserAgent, 255),
+			RequestID:  req.RequestID,
+		}
+		if err := u.auditLogRepo.Create(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *auditUsecase) List(ctx context.Context, page, limit int, filters map[string]interface{}) ([]*models.AuditLog, int64, error) {
+	offset := (page - 1) * limit
+
+	entries, err := u.auditLogRepo.List(ctx, limit, offset, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := u.auditLogRepo.Count(ctx, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// auditDiff reduces two JSON object snapshots to the fields that differ.
+// Snapshots that are missing or not objects are kept whole.
+func auditDiff(before, after json.RawMessage) (json.RawMessage, json.RawMessage) {
+	if before == nil || after == nil {
+		return before, after
+	}
+	var beforeFields, afterFields map[string]interface{}
+	if json.Unmarshal(before, &beforeFields) != nil || json.Unmarshal(after, &afterFields) != nil {
+		return before, after
+	}
+
+	changedBefore := map[string]interface{}{}
+	changedAfter := map[string]interface{}{}
+	for key, value := range beforeFields {
+		if key == "updated_at" {
+			continue
+		}
+		if other, ok := afterFields[key]; !ok || !reflect.DeepEqual(value, other) {
+			changedBefore[key] = value
+		}
+	}
+	for key, value := range afterFields {
+		if key == "updated_at" {
+			continue
+		}
+		if other, ok := beforeFields[key]; !ok || !reflect.DeepEqual(value, other) {
+			changedAfter[key] = value
+		}
+	}
+	return AuditSnapshot(changedBefore), AuditSnapshot(changedAfter)
+}
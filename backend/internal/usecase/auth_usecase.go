@@ -3,10 +3,13 @@ package usecase
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"net/url"
 	"strings"
@@ -18,45 +21,129 @@ import (
 	"electronics-store/internal/services"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 var (
-	ErrUserAlreadyExists    = errors.New("user already exists")
-	ErrInvalidCredentials   = errors.New("invalid credentials")
-	ErrUserNotFound         = errors.New("user not found")
-	ErrInvalidToken         = errors.New("invalid token")
+	ErrUserAlreadyExists        = errors.New("user already exists")
+	ErrInvalidCredentials       = errors.New("invalid credentials")
+	ErrUserNotFound             = errors.New("user not found")
+	ErrInvalidToken             = errors.New("invalid token")
+	ErrRefreshTokenReused       = errors.New("refresh token reuse detected; the session has been revoked")
+	ErrAccountLocked            = errors.New("account temporarily locked after too many failed logins")
+	ErrTwoFactorRequired        = errors.New("two-factor authentication required")
+	ErrTooManyTwoFactorAttempts = errors.New("too many two-factor codes tried; try again later")
 )
 
+const twoFactorChallengeTTL = 5 * time.Minute
+
+// TwoFactorCodeRule caps the two-factor codes tried per account, whether to
+// complete a login or to change two-factor settings; with codes valid for
+// about 90 seconds guessing is hopeless
+var TwoFactorCodeRule = services.RateLimitRule{Name: "2fa-code", Limit: 5, Window: 15 * time.Minute}
+
+// AccountLockedError is returned by Login while the account is locked out.
+// It matches ErrAccountLocked.
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("%s; try again in %s", ErrAccountLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *AccountLockedError) Is(target error) bool {
+	return target == ErrAccountLocked
+}
+
+// TwoFactorChallengeError is returned by the login methods when the first
+// factor was accepted but the account has two-factor authentication
+// enabled. No session is started; the client sends ChallengeToken with a
+// TOTP or recovery code to CompleteTwoFactorLogin. It matches
+// ErrTwoFactorRequired.
+type TwoFactorChallengeError struct {
+	ChallengeToken string
+	ExpiresIn      int // seconds
+}
+
+func (e *TwoFactorChallengeError) Error() string {
+	return ErrTwoFactorRequired.Error()
+}
+
+func (e *TwoFactorChallengeError) Is(target error) bool {
+	return target == ErrTwoFactorRequired
+}
+
+type sessionClientKey struct{}
+
+// SessionClient describes the device a login session is started or
+// refreshed from
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
+
+// WithSessionClient attaches the client device to ctx so sessions created
+// with it record where they came from
+func WithSessionClient(ctx context.Context, client SessionClient) context.Context {
+	return context.WithValue(ctx, sessionClientKey{}, client)
+}
+
+func sessionClientFrom(ctx context.Context) SessionClient {
+	client, _ := ctx.Value(sessionClientKey{}).(SessionClient)
+	return client
+}
+
 type AuthUsecase interface {
 	Register(ctx context.Context, req dto.RegisterRequest) (*models.User, *dto.TokenResponse, error)
 	Login(ctx context.Context, req dto.LoginRequest) (*models.User, *dto.TokenResponse, error)
 	GoogleAuth(ctx context.Context, req dto.GoogleAuthRequest) (*models.User, *dto.TokenResponse, error)
 	GoogleIDTokenAuth(ctx context.Context, req dto.GoogleIDTokenRequest) (*models.User, *dto.TokenResponse, error)
 	ExchangeGoogleCode(ctx context.Context, code string) (*models.User, *dto.TokenResponse, error)
+	// RefreshToken rotates a session: the presented refresh token is spent
+	// and a new token pair for the same session is returned. Presenting an
+	// already rotated token revokes the whole session.
 	RefreshToken(ctx context.Context, refreshToken string) (*dto.TokenResponse, error)
+	// Logout revokes the session the refresh token belongs to. Unknown or
+	// invalid tokens are ignored.
+	Logout(ctx context.Context, refreshToken string) error
+	// CompleteTwoFactorLogin finishes a login that returned a
+	// TwoFactorChallengeError, checking the code against the challenged
+	// account
+	CompleteTwoFactorLogin(ctx context.Context, challengeToken, code string) (*models.User, *dto.TokenResponse, error)
+	// LogoutAll revokes every session of the user
+	LogoutAll(ctx context.Context, userID uint) error
 	GetProfile(ctx context.Context, userID uint) (*models.User, error)
 	UpdateProfile(ctx context.Context, userID uint, req dto.UpdateProfileRequest) (*models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	UpdateUser(ctx context.Context, user *models.User) error
-	GenerateTokens(user *models.User) (*dto.TokenResponse, error)
+	GenerateTokens(ctx context.Context, user *models.User) (*dto.TokenResponse, error)
 }
 
 type authUsecase struct {
 	userRepo        repository.UserRepository
-	jwtSecret       string
-	refreshSecret   string
+	refreshTokenRepo repository.RefreshTokenRepository
+	jwtKeys         *services.JWTKeySet
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
 	googleOAuthService *services.GoogleOAuthService
 	googleClientSecret string
+	rateLimiter     *services.RateLimiter
+	twoFactor       TwoFactorUsecase
 }
 
-func NewAuthUsecase(userRepo repository.UserRepository, jwtSecret, refreshSecret string, googleOAuthService *services.GoogleOAuthService, googleClientSecret string) AuthUsecase {
+func NewAuthUsecase(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, jwtKeys *services.JWTKeySet, accessTokenTTL, refreshTokenTTL time.Duration, googleOAuthService *services.GoogleOAuthService, googleClientSecret string, rateLimiter *services.RateLimiter, twoFactor TwoFactorUsecase) AuthUsecase {
 	return &authUsecase{
 		userRepo:   userRepo,
-		jwtSecret:  jwtSecret,
-		refreshSecret: refreshSecret,
+		refreshTokenRepo: refreshTokenRepo,
+		jwtKeys:    jwtKeys,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
 		googleOAuthService: googleOAuthService,
 		googleClientSecret: googleClientSecret,
+		rateLimiter:     rateLimiter,
+		twoFactor:       twoFactor,
 	}
 }
 
@@ -103,7 +190,7 @@ func (u *authUsecase) Register(ctx context.Context, req dto.RegisterRequest) (*m
 	}
 
 	// Generate tokens
-	tokens, err := u.generateTokens(user.ID)
+	tokens, err := u.generateTokens(ctx, user.ID, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -112,24 +199,45 @@ func (u *authUsecase) Register(ctx context.Context, req dto.RegisterRequest) (*m
 }
 
 func (u *authUsecase) Login(ctx context.Context, req dto.LoginRequest) (*models.User, *dto.TokenResponse, error) {
+	// Refuse locked accounts before spending a bcrypt comparison on them
+	lockedFor, err := u.rateLimiter.LockedFor(ctx, req.Email)
+	if err != nil {
+		log.Printf("login lockout check for %s: %v", req.Email, err)
+	}
+	if lockedFor > 0 {
+		return nil, nil, &AccountLockedError{RetryAfter: lockedFor}
+	}
+
 	// Get user by email
 	user, err := u.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		return nil, nil, err
 	}
+	// Unknown emails count as failures too, so lockouts don't reveal which
+	// accounts exist
 	if user == nil {
-		return nil, nil, ErrInvalidCredentials
+		return nil, nil, u.loginFailed(ctx, req.Email)
 	}
 
 	// Check password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
-		return nil, nil, ErrInvalidCredentials
+		return nil, nil, u.loginFailed(ctx, req.Email)
 	}
 
 	// Check if user is active
 	if !user.IsActive {
-		return nil, nil, ErrInvalidCredentials
+		return nil, nil, u.loginFailed(ctx, req.Email)
+	}
+
+	if err := u.rateLimiter.RecordLoginSuccess(ctx, req.Email); err != nil {
+		log.Printf("login lockout reset for %s: %v", req.Email, err)
+	}
+
+	// Accounts with two-factor authentication finish logging in with
+	// CompleteTwoFactorLogin
+	if err := u.requireSecondFactor(ctx, user); err != nil {
+		return nil, nil, err
 	}
 
 	// Update last login
@@ -138,7 +246,86 @@ func (u *authUsecase) Login(ctx context.Context, req dto.LoginRequest) (*models.
 	u.userRepo.Update(ctx, user)
 
 	// Generate tokens
-	tokens, err := u.generateTokens(user.ID)
+	tokens, err := u.generateTokens(ctx, user.ID, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, tokens, nil
+}
+
+// requireSecondFactor returns a TwoFactorChallengeError for the user if they
+// have two-factor authentication enabled, and nil otherwise
+func (u *authUsecase) requireSecondFactor(ctx context.Context, user *models.User) error {
+	enabled, err := u.twoFactor.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+
+	now := time.Now()
+	challengeToken, err := u.jwtKeys.Sign(jwt.MapClaims{
+		"user_id": user.ID,
+		"jti":     uuid.New().String(),
+		"exp":     now.Add(twoFactorChallengeTTL).Unix(),
+		"iat":     now.Unix(),
+		"type":    "2fa_challenge",
+	})
+	if err != nil {
+		return err
+	}
+	return &TwoFactorChallengeError{
+		ChallengeToken: challengeToken,
+		ExpiresIn:      int(twoFactorChallengeTTL.Seconds()),
+	}
+}
+
+func (u *authUsecase) CompleteTwoFactorLogin(ctx context.Context, challengeToken, code string) (*models.User, *dto.TokenResponse, error) {
+	claims := jwt.MapClaims{}
+	token, err := u.jwtKeys.Parse(challengeToken, claims)
+	if err != nil || !token.Valid {
+		return nil, nil, ErrInvalidToken
+	}
+	if tokenType, _ := claims["type"].(string); tokenType != "2fa_challenge" {
+		return nil, nil, ErrInvalidToken
+	}
+	userIDFloat, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, nil, ErrInvalidToken
+	}
+	userID := uint(userIDFloat)
+
+	// A challenge can be retried until it expires, so attempts are capped
+	// per account rather than per challenge
+	result, err := u.rateLimiter.Allow(ctx, TwoFactorCodeRule, fmt.Sprint(userID))
+	if err != nil {
+		log.Printf("two-factor attempt limit for user %d: %v", userID, err)
+	} else if !result.Allowed {
+		return nil, nil, ErrTooManyTwoFactorAttempts
+	}
+
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, nil, ErrInvalidToken
+	}
+
+	if err := u.twoFactor.Verify(ctx, user.ID, code); err != nil {
+		return nil, nil, err
+	}
+
+	// Update last login
+	now := time.Now()
+	user.LastLoginAt = &now
+	u.userRepo.Update(ctx, user)
+
+	// The session is marked as two-factor authenticated, which the admin API
+	// requires
+	tokens, err := u.generateTokens(ctx, user.ID, []string{services.AuthMethodMFA})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -146,6 +333,20 @@ func (u *authUsecase) Login(ctx context.Context, req dto.LoginRequest) (*models.
 	return user, tokens, nil
 }
 
+// loginFailed records a failed login and returns the error to report: the
+// lockout it started, if any, or ErrInvalidCredentials. A failing limiter
+// store is logged rather than turned into an outage.
+func (u *authUsecase) loginFailed(ctx context.Context, email string) error {
+	lockedFor, err := u.rateLimiter.RecordLoginFailure(ctx, email)
+	if err != nil {
+		log.Printf("login failure for %s not recorded: %v", email, err)
+	}
+	if lockedFor > 0 {
+		return &AccountLockedError{RetryAfter: lockedFor}
+	}
+	return ErrInvalidCredentials
+}
+
 func (u *authUsecase) GoogleAuth(ctx context.Context, req dto.GoogleAuthRequest) (*models.User, *dto.TokenResponse, error) {
 	// Verify the Google token
 	googleUserInfo, err := u.googleOAuthService.VerifyGoogleIDToken(ctx, req.GoogleID)
@@ -240,13 +441,19 @@ func (u *authUsecase) GoogleAuth(ctx context.Context, req dto.GoogleAuthRequest)
 		}
 	}
 
+	// Accounts with two-factor authentication finish logging in with
+	// CompleteTwoFactorLogin
+	if err := u.requireSecondFactor(ctx, user); err != nil {
+		return nil, nil, err
+	}
+
 	// Update last login
 	now := time.Now()
 	user.LastLoginAt = &now
 	u.userRepo.Update(ctx, user)
 
 	// Generate tokens
-	tokens, err := u.generateTokens(user.ID)
+	tokens, err := u.generateTokens(ctx, user.ID, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -348,13 +555,19 @@ func (u *authUsecase) GoogleIDTokenAuth(ctx context.Context, req dto.GoogleIDTok
 		}
 	}
 
+	// Accounts with two-factor authentication finish logging in with
+	// CompleteTwoFactorLogin
+	if err := u.requireSecondFactor(ctx, user); err != nil {
+		return nil, nil, err
+	}
+
 	// Update last login
 	now := time.Now()
 	user.LastLoginAt = &now
 	u.userRepo.Update(ctx, user)
 
 	// Generate tokens
-	tokens, err := u.generateTokens(user.ID)
+	tokens, err := u.generateTokens(ctx, user.ID, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -495,13 +708,19 @@ func (u *authUsecase) ExchangeGoogleCode(ctx context.Context, code string) (*mod
 		}
 	}
 
+	// Accounts with two-factor authentication finish logging in with
+	// CompleteTwoFactorLogin
+	if err := u.requireSecondFactor(ctx, user); err != nil {
+		return nil, nil, err
+	}
+
 	// Update last login
 	now := time.Now()
 	user.LastLoginAt = &now
 	u.userRepo.Update(ctx, user)
 
 	// Generate tokens
-	tokens, err := u.generateTokens(user.ID)
+	tokens, err := u.generateTokens(ctx, user.ID, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -510,36 +729,75 @@ func (u *authUsecase) ExchangeGoogleCode(ctx context.Context, code string) (*mod
 }
 
 func (u *authUsecase) RefreshToken(ctx context.Context, refreshToken string) (*dto.TokenResponse, error) {
-	// Parse and verify the refresh token
-	token, err := jwt.Parse(refreshToken, func(token *jwt.Token) (interface{}, error) {
-		// Make sure token method is HS256
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrInvalidKey
+	claims, err := u.parseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens *dto.TokenResponse
+	var reused bool
+	err = u.refreshTokenRepo.Transaction(ctx, func(tx repository.RefreshTokenRepository) error {
+		stored, err := tx.GetByTokenIDForUpdate(ctx, claims.ID)
+		if err != nil {
+			return err
+		}
+		if stored == nil || !refreshTokenMatches(stored, refreshToken) {
+			return ErrInvalidToken
+		}
+		if stored.RevokedAt != nil {
+			if stored.RevokedReason != models.RefreshTokenRotated {
+				return ErrInvalidToken
+			}
+			// A spent token came back: whoever holds the session may have
+			// stolen it, so end the session for everyone
+			reused = true
+			return tx.RevokeFamily(ctx, stored.FamilyID, models.RefreshTokenReused)
+		}
+		if time.Now().After(stored.ExpiresAt) {
+			return ErrInvalidToken
+		}
+
+		user, err := u.userRepo.GetByID(ctx, stored.UserID)
+		if err != nil {
+			return err
 		}
-		return []byte(u.refreshSecret), nil
+		if user == nil || !user.IsActive {
+			return ErrInvalidToken
+		}
+
+		if err := tx.Revoke(ctx, stored.ID, models.RefreshTokenRotated); err != nil {
+			return err
+		}
+		tokens, err = u.issueTokens(ctx, tx, user, stored.FamilyID, claims.AMR)
+		return err
 	})
-	if err != nil || !token.Valid {
-		return nil, errors.New("invalid refresh token")
+	if err != nil {
+		return nil, err
 	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, errors.New("invalid token claims")
+	if reused {
+		return nil, ErrRefreshTokenReused
 	}
+	return tokens, nil
+}
 
-	userIDf, ok := claims["user_id"].(float64)
-	if !ok {
-		return nil, errors.New("user_id missing in token claims")
+func (u *authUsecase) Logout(ctx context.Context, refreshToken string) error {
+	claims, err := u.parseRefreshToken(refreshToken)
+	if err != nil {
+		return nil
 	}
-	userID := uint(userIDf)
 
-	// Optionally: check expiry and other claims
-	// Now, generate new tokens for this user
-	tokens, err := u.generateTokens(userID)
+	stored, err := u.refreshTokenRepo.GetByTokenID(ctx, claims.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return tokens, nil
+	if stored == nil || !refreshTokenMatches(stored, refreshToken) {
+		return nil
+	}
+	return u.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID, models.RefreshTokenLogout)
+}
+
+func (u *authUsecase) LogoutAll(ctx context.Context, userID uint) error {
+	return u.refreshTokenRepo.RevokeAllForUser(ctx, userID, models.RefreshTokenLogoutAll)
 }
 
 func (u *authUsecase) GetProfile(ctx context.Context, userID uint) (*models.User, error) {
@@ -584,37 +842,90 @@ func (u *authUsecase) UpdateProfile(ctx context.Context, userID uint, req dto.Up
 	return user, nil
 }
 
-func (u *authUsecase) generateTokens(userID uint) (*dto.TokenResponse, error) {
-    // Load user to embed role claims
-    user, err := u.userRepo.GetByID(context.Background(), userID)
-    if err != nil || user == nil {
-        return nil, errors.New("user not found for token generation")
-    }
+// refreshClaims are the claims carried by a refresh token
+type refreshClaims struct {
+	UserID    uint   `json:"user_id"`
+	SessionID string   `json:"sid"`
+	Type      string   `json:"type"`
+	AMR       []string `json:"amr,omitempty"`
+	jwt.RegisteredClaims
+}
 
-    // Create access token with admin claim
-    accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-        "user_id":  userID,
-        "is_admin": user.IsAdmin,
-        "exp":      time.Now().Add(time.Hour).Unix(),
-        "iat":      time.Now().Unix(),
-        "type":     "access",
-    })
+// parseRefreshToken verifies the signature, expiry and type of a refresh token
+func (u *authUsecase) parseRefreshToken(refreshToken string) (*refreshClaims, error) {
+	claims := &refreshClaims{}
+	token, err := u.jwtKeys.Parse(refreshToken, claims)
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	if claims.Type != "refresh" || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// generateTokens starts a new login session for the user. amr lists how the
+// session was authenticated beyond the first factor and is kept on refresh.
+func (u *authUsecase) generateTokens(ctx context.Context, userID uint, amr []string) (*dto.TokenResponse, error) {
+	// Load user to embed role claims
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found for token generation")
+	}
+	return u.issueTokens(ctx, u.refreshTokenRepo, user, uuid.New().String(), amr)
+}
 
-	accessTokenString, err := accessToken.SignedString([]byte(u.jwtSecret))
+// issueTokens signs a new access and refresh token pair for a session and
+// stores the refresh token. Both carry the session's amr claim.
+func (u *authUsecase) issueTokens(ctx context.Context, refreshTokenRepo repository.RefreshTokenRepository, user *models.User, familyID string, amr []string) (*dto.TokenResponse, error) {
+	now := time.Now()
+
+	// Create access token with admin claim
+	accessClaims := jwt.MapClaims{
+		"user_id":  user.ID,
+		"is_admin": user.IsAdmin,
+		"sid":      familyID,
+		"exp":      now.Add(u.accessTokenTTL).Unix(),
+		"iat":      now.Unix(),
+		"type":     "access",
+	}
+	if len(amr) > 0 {
+		accessClaims["amr"] = amr
+	}
+	accessTokenString, err := u.jwtKeys.Sign(accessClaims)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create refresh token
-    refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-        "user_id":  userID,
-        "is_admin": user.IsAdmin,
-        "exp":      time.Now().Add(7 * 24 * time.Hour).Unix(), // 7 days
-        "iat":      time.Now().Unix(),
-        "type":     "refresh",
-    })
+	tokenID := uuid.New().String()
+	expiresAt := now.Add(u.refreshTokenTTL)
+	refreshTokenClaims := jwt.MapClaims{
+		"user_id": user.ID,
+		"sid":     familyID,
+		"jti":     tokenID,
+		"exp":     expiresAt.Unix(),
+		"iat":     now.Unix(),
+		"type":    "refresh",
+	}
+	if len(amr) > 0 {
+		refreshTokenClaims["amr"] = amr
+	}
+	refreshTokenString, err := u.jwtKeys.Sign(refreshTokenClaims)
+	if err != nil {
+		return nil, err
+	}
 
-	refreshTokenString, err := refreshToken.SignedString([]byte(u.refreshSecret))
+	client := sessionClientFrom(ctx)
+	err = refreshTokenRepo.Create(ctx, &models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenID:   tokenID,
+		TokenHash: hashRefreshToken(refreshTokenString),
+		UserAgent: truncate(client.UserAgent, 255),
+		IPAddress: truncate(client.IPAddress, 45),
+		ExpiresAt: expiresAt,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -622,10 +933,28 @@ func (u *authUsecase) generateTokens(userID uint) (*dto.TokenResponse, error) {
 	return &dto.TokenResponse{
 		AccessToken:  accessTokenString,
 		RefreshToken: refreshTokenString,
-		ExpiresIn:    3600, // 1 hour
+		ExpiresIn:    int(u.accessTokenTTL.Seconds()),
+		RefreshExpiresIn: int(u.refreshTokenTTL.Seconds()),
 	}, nil
 }
 
+// hashRefreshToken is the form refresh tokens are stored in
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func refreshTokenMatches(stored *models.RefreshToken, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(stored.TokenHash), []byte(hashRefreshToken(token))) == 1
+}
+
+func truncate(value string, length int) string {
+	if len(value) > length {
+		return value[:length]
+	}
+	return value
+}
+
 func generateRandomToken(length int) (string, error) {
 	bytes := make([]byte, length)
 	if _, err := rand.Read(bytes); err != nil {
@@ -651,9 +980,14 @@ func (u *authUsecase) UpdateUser(ctx context.Context, user *models.User) error {
 	return u.userRepo.Update(ctx, user)
 }
 
-// GenerateTokens generates tokens for a user
-func (u *authUsecase) GenerateTokens(user *models.User) (*dto.TokenResponse, error) {
-	return u.generateTokens(user.ID)
+// GenerateTokens starts a new login session for a user. Like the login
+// methods it returns a TwoFactorChallengeError instead when the user has
+// two-factor authentication enabled.
+func (u *authUsecase) GenerateTokens(ctx context.Context, user *models.User) (*dto.TokenResponse, error) {
+	if err := u.requireSecondFactor(ctx, user); err != nil {
+		return nil, err
+	}
+	return u.generateTokens(ctx, user.ID, nil)
 }
 
 // generateUsernameFromEmail generates a username from an email address
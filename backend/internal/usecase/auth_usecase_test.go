@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"electronics-store/internal/config"
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/repository"
+	"electronics-store/internal/services"
+)
+
+// memoryRefreshTokenRepository keeps refresh tokens in memory. Transactions
+// run directly against it.
+type memoryRefreshTokenRepository struct {
+	tokens []*models.RefreshToken
+}
+
+func (r *memoryRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	token.ID = uint(len(r.tokens) + 1)
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) GetByTokenIDForUpdate(ctx context.Context, tokenID string) (*models.RefreshToken, error) {
+	return r.GetByTokenID(ctx, tokenID)
+}
+
+func (r *memoryRefreshTokenRepository) GetByTokenID(ctx context.Context, tokenID string) (*models.RefreshToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenID == tokenID {
+			return token, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryRefreshTokenRepository) Revoke(ctx context.Context, id uint, reason string) error {
+	for _, token := range r.tokens {
+		if token.ID == id {
+			r.revoke(token, reason)
+		}
+	}
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID, reason string) error {
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			r.revoke(token, reason)
+		}
+	}
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uint, reason string) error {
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			r.revoke(token, reason)
+		}
+	}
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) Transaction(ctx context.Context, fn func(tx repository.RefreshTokenRepository) error) error {
+	return fn(r)
+}
+
+func (r *memoryRefreshTokenRepository) revoke(token *models.RefreshToken, reason string) {
+	now := time.Now()
+	token.RevokedAt = &now
+	token.RevokedReason = reason
+}
+
+// memoryUserRepository looks users up by ID
+type memoryUserRepository struct {
+	repository.UserRepository
+	users []*models.User
+}
+
+func (r *memoryUserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestRefreshTokenReuse(t *testing.T) {
+	ctx := context.Background()
+	jwtKeys, err := services.LoadJWTKeySet(config.JWTConfig{AccessTokenSecret: "test-secret"})
+	if err != nil {
+		t.Fatalf("LoadJWTKeySet: %v", err)
+	}
+	tokens := &memoryRefreshTokenRepository{}
+	users := &memoryUserRepository{users: []*models.User{{ID: testUserID, IsActive: true}}}
+	auth := NewAuthUsecase(users, tokens, jwtKeys, 15*time.Minute, time.Hour, nil, "", nil, nil).(*authUsecase)
+
+	session, err := auth.generateTokens(ctx, testUserID, nil)
+	if err != nil {
+		t.Fatalf("generateTokens: %v", err)
+	}
+	otherSession, err := auth.generateTokens(ctx, testUserID, nil)
+	if err != nil {
+		t.Fatalf("generateTokens: %v", err)
+	}
+
+	rotated, err := auth.RefreshToken(ctx, session.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+
+	// The spent token comes back: the whole session ends
+	if _, err := auth.RefreshToken(ctx, session.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("replayed RefreshToken error = %v, want %v", err, ErrRefreshTokenReused)
+	}
+	familyID := tokens.tokens[0].FamilyID
+	for _, token := range tokens.tokens {
+		if token.FamilyID != familyID {
+			continue
+		}
+		if token.RevokedAt == nil {
+			t.Errorf("token %s of the session is still valid", token.TokenID)
+		}
+	}
+	if latest := tokens.tokens[len(tokens.tokens)-1]; latest.FamilyID != familyID || latest.RevokedReason != models.RefreshTokenReused {
+		t.Errorf("latest token of the session revoked as %q, want %q", latest.RevokedReason, models.RefreshTokenReused)
+	}
+	if _, err := auth.RefreshToken(ctx, rotated.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("RefreshToken with the rotated token error = %v, want %v", err, ErrInvalidToken)
+	}
+
+	// Other sessions of the user are left alone
+	if _, err := auth.RefreshToken(ctx, otherSession.RefreshToken); err != nil {
+		t.Errorf("RefreshToken of another session: %v", err)
+	}
+}
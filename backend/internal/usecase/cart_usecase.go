@@ -0,0 +1,558 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/repository"
+	"electronics-store/internal/services"
+)
+
+var ErrCartItemNotFound = errors.New("cart item not found")
+
+// CartOwner identifies whose cart a request is for: the signed-in user's,
+// or else the guest's whose cart cookie holds GuestToken
+type CartOwner struct {
+	UserID     uint
+	GuestToken string
+}
+
+type CartUsecase interface {
+	// GetCart returns the owner's cart priced at current prices, with every
+	// line checked against the catalog
+	GetCart(ctx context.Context, owner CartOwner) (*dto.CartResponse, error)
+	// AddItem adds the quantity to the cart's line of the product and
+	// variant, creating the line, and for a guest the cart, if needed
+	AddItem(ctx context.Context, owner CartOwner, req dto.AddToCartRequest) (*dto.CartResponse, error)
+	// UpdateItem sets a line's quantity. The line takes the current price,
+	// so a price change it was flagged with is cleared.
+	UpdateItem(ctx context.Context, owner CartOwner, itemID uint, quantity int) (*dto.CartResponse, error)
+	RemoveItem(ctx context.Context, owner CartOwner, itemID uint) (*dto.CartResponse, error)
+	Clear(ctx context.Context, owner CartOwner) (*dto.CartResponse, error)
+	// ApplyDiscount sets the cart's discount code once it quotes against the
+	// cart. The code is redeemed at checkout.
+	ApplyDiscount(ctx context.Context, owner CartOwner, code string) (*dto.CartResponse, error)
+	RemoveDiscount(ctx context.Context, owner CartOwner) (*dto.CartResponse, error)
+
+	// MergeGuestCart moves the lines of the guest cart with the given token
+	// into the user's cart and deletes the guest cart. Quantities of the
+	// same product and variant are added up and capped at the stock left;
+	// lines that can no longer be ordered are dropped.
+	MergeGuestCart(ctx context.Context, userID uint, guestToken string) error
+	// DeleteExpiredGuestCarts removes the guest carts left unused for the
+	// guest cart TTL and returns how many it removed
+	DeleteExpiredGuestCarts(ctx context.Context) (int64, error)
+}
+
+type cartUsecase struct {
+	cartRepo        repository.CartRepository
+	productRepo     repository.ProductRepository
+	variantUsecase  VariantUsecase
+	discountUsecase DiscountUsecase
+	tokens          *services.CartTokenSigner
+	guestTTL        time.Duration
+}
+
+// NewCartUsecase creates the cart usecase. Guest carts are identified by
+// tokens signed with tokens and expire once unused for guestTTL.
+func NewCartUsecase(cartRepo repository.CartRepository, productRepo repository.ProductRepository, variantUsecase VariantUsecase, discountUsecase DiscountUsecase, tokens *services.CartTokenSigner, guestTTL time.Duration) CartUsecase {
+	return &cartUsecase{
+		cartRepo:        cartRepo,
+		productRepo:     productRepo,
+		variantUsecase:  variantUsecase,
+		discountUsecase: discountUsecase,
+		tokens:          tokens,
+		guestTTL:        guestTTL,
+	}
+}
+
+func (u *cartUsecase) GetCart(ctx context.Context, owner CartOwner) (*dto.CartResponse, error) {
+	cart, err := u.cart(ctx, owner, false)
+	if err != nil {
+		return nil, err
+	}
+	return u.buildCart(ctx, cart)
+}
+
+func (u *cartUsecase) AddItem(ctx context.Context, owner CartOwner, req dto.AddToCartRequest) (*dto.CartResponse, error) {
+	if req.Quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	var product *models.Product
+	var err error
+	switch {
+	case req.ProductID != nil:
+		product, err = u.productRepo.GetByID(ctx, *req.ProductID)
+	case req.ProductResourceID != nil:
+		product, err = u.productRepo.GetByResourceID(ctx, *req.ProductResourceID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+	if !product.IsActive {
+		return nil, fmt.Errorf("%w: %s", ErrProductUnavailable, product.Name)
+	}
+
+	variant, err := u.variantUsecase.ValidateCartVariant(ctx, product.ID, req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := u.cart(ctx, owner, false)
+	if err != nil {
+		return nil, err
+	}
+	var item *models.CartItem
+	if cart != nil {
+		if item, err = u.cartRepo.FindItem(ctx, cart.ID, product.ID, req.VariantID); err != nil {
+			return nil, err
+		}
+	}
+
+	quantity := req.Quantity
+	if item != nil {
+		quantity += item.Quantity
+	}
+	if err := checkCartStock(product, variant, quantity); err != nil {
+		return nil, err
+	}
+
+	// A guest's first item creates their cart
+	if cart == nil {
+		if cart, err = u.cart(ctx, owner, true); err != nil {
+			return nil, err
+		}
+	}
+	price := cartItemPrice(product, variant)
+	if item == nil {
+		err = u.cartRepo.CreateItem(ctx, &models.CartItem{
+			CartID:    cart.ID,
+			ProductID: product.ID,
+			VariantID: req.VariantID,
+			Quantity:  quantity,
+			Price:     price,
+		})
+	} else {
+		item.Quantity = quantity
+		item.Price = price
+		err = u.cartRepo.UpdateItem(ctx, item)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u.buildCart(ctx, cart)
+}
+
+func (u *cartUsecase) UpdateItem(ctx context.Context, owner CartOwner, itemID uint, quantity int) (*dto.CartResponse, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	cart, item, err := u.cartItem(ctx, owner, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if cartItemIssue(*item) == dto.CartIssueUnavailable {
+		return nil, fmt.Errorf("%w: %s", ErrProductUnavailable, item.Product.Name)
+	}
+	if err := checkCartStock(&item.Product, item.Variant, quantity); err != nil {
+		return nil, err
+	}
+
+	item.Quantity = quantity
+	item.Price = cartItemPrice(&item.Product, item.Variant)
+	if err := u.cartRepo.UpdateItem(ctx, item); err != nil {
+		return nil, err
+	}
+	return u.buildCart(ctx, cart)
+}
+
+func (u *cartUsecase) RemoveItem(ctx context.Context, owner CartOwner, itemID uint) (*dto.CartResponse, error) {
+	cart, item, err := u.cartItem(ctx, owner, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.cartRepo.DeleteItem(ctx, item.ID); err != nil {
+		return nil, err
+	}
+	return u.buildCart(ctx, cart)
+}
+
+func (u *cartUsecase) Clear(ctx context.Context, owner CartOwner) (*dto.CartResponse, error) {
+	cart, err := u.cart(ctx, owner, false)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return u.buildCart(ctx, nil)
+	}
+	if err := u.cartRepo.ClearItems(ctx, cart.ID); err != nil {
+		return nil, err
+	}
+	return u.buildCart(ctx, cart)
+}
+
+func (u *cartUsecase) ApplyDiscount(ctx context.Context, owner CartOwner, code string) (*dto.CartResponse, error) {
+	cart, err := u.cart(ctx, owner, false)
+	if err != nil {
+		return nil, err
+	}
+	var subtotal float64
+	if cart != nil {
+		items, err := u.cartRepo.ListItems(ctx, cart.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			subtotal += cartItemPrice(&item.Product, item.Variant) * float64(item.Quantity)
+		}
+	}
+
+	discount, _, err := u.discountUsecase.Quote(ctx, code, roundCurrency(subtotal))
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		if cart, err = u.cart(ctx, owner, true); err != nil {
+			return nil, err
+		}
+	}
+	if err := u.cartRepo.SetDiscountCode(ctx, cart.ID, discount.Code); err != nil {
+		return nil, err
+	}
+	cart.DiscountCode = discount.Code
+	return u.buildCart(ctx, cart)
+}
+
+func (u *cartUsecase) RemoveDiscount(ctx context.Context, owner CartOwner) (*dto.CartResponse, error) {
+	cart, err := u.cart(ctx, owner, false)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return u.buildCart(ctx, nil)
+	}
+	if err := u.cartRepo.SetDiscountCode(ctx, cart.ID, ""); err != nil {
+		return nil, err
+	}
+	cart.DiscountCode = ""
+	return u.buildCart(ctx, cart)
+}
+
+func (u *cartUsecase) MergeGuestCart(ctx context.Context, userID uint, guestToken string) error {
+	guestCartID, ok := u.tokens.Verify(guestToken)
+	if !ok {
+		return nil
+	}
+
+	// The guest cart is locked and deleted in the same transaction, so a
+	// concurrent sign-in with the same cookie finds it gone and merges
+	// nothing
+	return u.cartRepo.Transaction(ctx, func(tx repository.CartRepository) error {
+		guest, err := tx.GetGuestForUpdate(ctx, guestCartID)
+		if err != nil || guest == nil {
+			return err
+		}
+		items, err := tx.ListItems(ctx, guest.ID)
+		if err != nil {
+			return err
+		}
+
+		cart, err := tx.GetOrCreate(ctx, userID)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if cartItemIssue(item) == dto.CartIssueUnavailable {
+				continue
+			}
+			existing, err := tx.FindItem(ctx, cart.ID, item.ProductID, item.VariantID)
+			if err != nil {
+				return err
+			}
+
+			quantity := item.Quantity
+			if existing != nil {
+				quantity += existing.Quantity
+			}
+			quantity = cartQuantityInStock(&item.Product, item.Variant, quantity)
+			if quantity <= 0 {
+				continue
+			}
+
+			// The user's line keeps the price it was added at, so a change
+			// since is still flagged
+			if existing != nil {
+				existing.Quantity = quantity
+				err = tx.UpdateItem(ctx, existing)
+			} else {
+				err = tx.CreateItem(ctx, &models.CartItem{
+					CartID:    cart.ID,
+					ProductID: item.ProductID,
+					VariantID: item.VariantID,
+					Quantity:  quantity,
+					Price:     item.Price,
+				})
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if cart.DiscountCode == "" && guest.DiscountCode != "" {
+			if err := tx.SetDiscountCode(ctx, cart.ID, guest.DiscountCode); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(ctx, guest.ID)
+	})
+}
+
+func (u *cartUsecase) DeleteExpiredGuestCarts(ctx context.Context) (int64, error) {
+	return u.cartRepo.DeleteExpiredGuests(ctx, time.Now())
+}
+
+// cart returns the owner's cart. A guest's cart is created only when
+// create is set, and is nil otherwise until they have one. Using a guest
+// cart pushes back its expiry.
+func (u *cartUsecase) cart(ctx context.Context, owner CartOwner, create bool) (*models.Cart, error) {
+	if owner.UserID != 0 {
+		return u.cartRepo.GetOrCreate(ctx, owner.UserID)
+	}
+
+	expiresAt := time.Now().Add(u.guestTTL)
+	if cartID, ok := u.tokens.Verify(owner.GuestToken); ok {
+		cart, err := u.cartRepo.GetGuest(ctx, cartID)
+		if err != nil {
+			return nil, err
+		}
+		if cart != nil {
+			if err := u.cartRepo.ExtendGuest(ctx, cart.ID, expiresAt); err != nil {
+				return nil, err
+			}
+			cart.ExpiresAt = &expiresAt
+			return cart, nil
+		}
+	}
+	if !create {
+		return nil, nil
+	}
+	return u.cartRepo.CreateGuest(ctx, expiresAt)
+}
+
+// cartItem returns the owner's cart and one of its lines
+func (u *cartUsecase) cartItem(ctx context.Context, owner CartOwner, itemID uint) (*models.Cart, *models.CartItem, error) {
+	cart, err := u.cart(ctx, owner, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cart == nil {
+		return nil, nil, ErrCartItemNotFound
+	}
+	item, err := u.cartRepo.GetItem(ctx, cart.ID, itemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item == nil {
+		return nil, nil, ErrCartItemNotFound
+	}
+	return cart, item, nil
+}
+
+// buildCart prices the cart's lines at current prices and checks each one
+// against the catalog. The applied code is quoted again since the cart or
+// the discount may have changed since it was applied. A nil cart is a
+// guest's that doesn't exist yet and is returned empty.
+func (u *cartUsecase) buildCart(ctx context.Context, cart *models.Cart) (*dto.CartResponse, error) {
+	if cart == nil {
+		return &dto.CartResponse{Items: []dto.CartItemResponse{}}, nil
+	}
+	items, err := u.cartRepo.ListItems(ctx, cart.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.CartResponse{
+		ResourceID: cart.ResourceID,
+		Items:      make([]dto.CartItemResponse, 0, len(items)),
+		ItemsCount: len(items),
+	}
+	if cart.UserID == nil {
+		resp.GuestToken = u.tokens.Sign(cart.ResourceID)
+	}
+	for _, item := range items {
+		line := newCartItemResponse(item)
+		resp.Items = append(resp.Items, line)
+		resp.TotalItems += line.Quantity
+		resp.Subtotal += line.Total
+		if line.PriceChanged || !line.Available {
+			resp.HasChanges = true
+		}
+	}
+	resp.Subtotal = roundCurrency(resp.Subtotal)
+	resp.CanCheckout = len(items) > 0
+	for _, line := range resp.Items {
+		if !line.Available {
+			resp.CanCheckout = false
+			break
+		}
+	}
+
+	if cart.DiscountCode != "" {
+		info := &dto.CartDiscountResponse{Code: cart.DiscountCode}
+		discount, amount, err := u.discountUsecase.Quote(ctx, cart.DiscountCode, resp.Subtotal)
+		if discount != nil {
+			info.Name = discount.Name
+			info.Type = discount.Type
+		}
+		if err != nil {
+			info.Message = err.Error()
+		} else {
+			info.Valid = true
+			info.Amount = amount
+			resp.DiscountAmount = amount
+		}
+		resp.Discount = info
+	}
+	resp.Total = roundCurrency(resp.Subtotal - resp.DiscountAmount)
+	return resp, nil
+}
+
+func newCartItemResponse(item models.CartItem) dto.CartItemResponse {
+	price := cartItemPrice(&item.Product, item.Variant)
+	line := dto.CartItemResponse{
+		ID:        item.ID,
+		ProductID: item.ProductID,
+		VariantID: item.VariantID,
+		Quantity:  item.Quantity,
+		Price:     price,
+		Total:     roundCurrency(price * float64(item.Quantity)),
+		Issue:     cartItemIssue(item),
+		Product: dto.CartProductResponse{
+			ID:           item.Product.ID,
+			ResourceID:   item.Product.ResourceID,
+			Name:         item.Product.Name,
+			Image:        item.Product.PrimaryImageURL(),
+			Price:        item.Product.Price,
+			ComparePrice: item.Product.ComparePrice,
+			SKU:          item.Product.SKU,
+		},
+	}
+	line.Available = line.Issue == ""
+
+	// Lines added before prices were recorded have no price to compare
+	if item.Price > 0 && roundCurrency(item.Price) != price {
+		previous := roundCurrency(item.Price)
+		line.PriceChanged = true
+		line.PreviousPrice = &previous
+	}
+	if line.Issue == dto.CartIssueOutOfStock || line.Issue == dto.CartIssueInsufficientStock {
+		stock := cartStock(&item.Product, item.Variant)
+		if stock < 0 {
+			stock = 0
+		}
+		line.AvailableQuantity = &stock
+	}
+
+	if variant := item.Variant; variant != nil {
+		resp := &dto.CartVariantResponse{
+			ID:         variant.ID,
+			ResourceID: variant.ResourceID,
+			Name:       variant.Name,
+			SKU:        variant.SKU,
+			Price:      variant.Price,
+		}
+		if len(variant.OptionValues) > 0 {
+			resp.Options = make(map[string]string, len(variant.OptionValues))
+			for _, value := range variant.OptionValues {
+				if value.Option != nil {
+					resp.Options[value.Option.Name] = value.Value
+				}
+			}
+		}
+		if len(variant.Images) > 0 {
+			resp.Image = variant.Images[0].URL
+		}
+		line.Variant = resp
+	}
+	return line
+}
+
+// cartItemPrice returns the unit price of the product, or of the variant
+// when it has a price of its own
+func cartItemPrice(product *models.Product, variant *models.Variant) float64 {
+	if variant != nil && variant.Price > 0 {
+		return roundCurrency(variant.Price)
+	}
+	return roundCurrency(product.Price)
+}
+
+// cartStock returns the stock the line draws from: the variant's when it
+// has one, the product's otherwise
+func cartStock(product *models.Product, variant *models.Variant) int {
+	if variant != nil {
+		return variant.StockQuantity
+	}
+	return product.StockQuantity
+}
+
+// cartQuantityInStock caps quantity at the stock left, for products whose
+// stock is tracked and that can't be backordered
+func cartQuantityInStock(product *models.Product, variant *models.Variant, quantity int) int {
+	if !product.TrackQuantity || product.AllowBackorder {
+		return quantity
+	}
+	if stock := cartStock(product, variant); quantity > stock {
+		return stock
+	}
+	return quantity
+}
+
+// checkCartStock rejects a quantity above the stock left, for products
+// whose stock is tracked and that can't be backordered
+func checkCartStock(product *models.Product, variant *models.Variant, quantity int) error {
+	if !product.TrackQuantity || product.AllowBackorder {
+		return nil
+	}
+	stock := cartStock(product, variant)
+	if quantity <= stock {
+		return nil
+	}
+	if stock <= 0 {
+		return fmt.Errorf("%w: %s is out of stock", ErrInsufficientStock, product.Name)
+	}
+	return fmt.Errorf("%w: only %d of %s left", ErrInsufficientStock, stock, product.Name)
+}
+
+// cartItemIssue returns why a line can't be ordered as it is, or "" when it
+// can. A product or variant that was removed loads as a zero value.
+func cartItemIssue(item models.CartItem) string {
+	if item.Product.ID == 0 || !item.Product.IsActive {
+		return dto.CartIssueUnavailable
+	}
+	if item.VariantID != nil {
+		variant := item.Variant
+		if variant == nil || variant.ID == 0 || variant.ProductID != item.ProductID || !variant.IsActive {
+			return dto.CartIssueUnavailable
+		}
+	}
+	if !item.Product.TrackQuantity || item.Product.AllowBackorder {
+		return ""
+	}
+	stock := cartStock(&item.Product, item.Variant)
+	switch {
+	case stock <= 0:
+		return dto.CartIssueOutOfStock
+	case stock < item.Quantity:
+		return dto.CartIssueInsufficientStock
+	}
+	return ""
+}
@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/repository"
+	"electronics-store/internal/services"
+)
+
+// memoryCartRepository keeps carts and their lines in memory. It implements
+// the part of CartRepository merging a guest cart uses; calling anything else
+// panics on the nil embedded interface.
+type memoryCartRepository struct {
+	repository.CartRepository
+	carts []*models.Cart
+	items []*models.CartItem
+}
+
+func (r *memoryCartRepository) GetGuestForUpdate(ctx context.Context, resourceID string) (*models.Cart, error) {
+	for _, cart := range r.carts {
+		if cart.ResourceID == resourceID && cart.UserID == nil {
+			return cart, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryCartRepository) GetOrCreate(ctx context.Context, userID uint) (*models.Cart, error) {
+	for _, cart := range r.carts {
+		if cart.UserID != nil && *cart.UserID == userID {
+			return cart, nil
+		}
+	}
+	cart := &models.Cart{ID: uint(len(r.carts) + 1), UserID: &userID}
+	r.carts = append(r.carts, cart)
+	return cart, nil
+}
+
+func (r *memoryCartRepository) ListItems(ctx context.Context, cartID uint) ([]models.CartItem, error) {
+	var items []models.CartItem
+	for _, item := range r.items {
+		if item.CartID == cartID {
+			items = append(items, *item)
+		}
+	}
+	return items, nil
+}
+
+func (r *memoryCartRepository) FindItem(ctx context.Context, cartID, productID uint, variantID *uint) (*models.CartItem, error) {
+	for _, item := range r.items {
+		if item.CartID == cartID && item.ProductID == productID && variantKey(item.VariantID) == variantKey(variantID) {
+			found := *item
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryCartRepository) CreateItem(ctx context.Context, item *models.CartItem) error {
+	item.ID = uint(len(r.items) + 1)
+	created := *item
+	r.items = append(r.items, &created)
+	return nil
+}
+
+func (r *memoryCartRepository) UpdateItem(ctx context.Context, item *models.CartItem) error {
+	for i, stored := range r.items {
+		if stored.ID == item.ID {
+			updated := *item
+			r.items[i] = &updated
+		}
+	}
+	return nil
+}
+
+func (r *memoryCartRepository) SetDiscountCode(ctx context.Context, cartID uint, code string) error {
+	for _, cart := range r.carts {
+		if cart.ID == cartID {
+			cart.DiscountCode = code
+		}
+	}
+	return nil
+}
+
+func (r *memoryCartRepository) Delete(ctx context.Context, cartID uint) error {
+	var carts []*models.Cart
+	for _, cart := range r.carts {
+		if cart.ID != cartID {
+			carts = append(carts, cart)
+		}
+	}
+	var items []*models.CartItem
+	for _, item := range r.items {
+		if item.CartID != cartID {
+			items = append(items, item)
+		}
+	}
+	r.carts, r.items = carts, items
+	return nil
+}
+
+func (r *memoryCartRepository) Transaction(ctx context.Context, fn func(tx repository.CartRepository) error) error {
+	return fn(r)
+}
+
+func TestMergeGuestCart(t *testing.T) {
+	const guestCartID, userCartID uint = 1, 2
+	product := func(stock int) models.Product {
+		return models.Product{ID: 1, Name: "USB-C cable", Price: 25, IsActive: true, TrackQuantity: true, StockQuantity: stock}
+	}
+	backordered := models.Product{ID: 1, Name: "USB-C cable", Price: 25, IsActive: true, TrackQuantity: true, AllowBackorder: true}
+	inactive := models.Product{ID: 1, Name: "USB-C cable", Price: 25, TrackQuantity: true, StockQuantity: 10}
+	blue := &models.Variant{ID: 7, ProductID: 1, IsActive: true, StockQuantity: 10}
+
+	type line struct {
+		variantID *uint
+		quantity  int
+		price     float64
+	}
+	tests := []struct {
+		name      string
+		product   models.Product
+		variant   *models.Variant
+		guestQty  int
+		userQty   int // 0 when the user has no line of the product
+		wantLines []line
+	}{
+		{"new line", product(10), nil, 2, 0, []line{{nil, 2, 20}}},
+		{"quantities add up", product(10), nil, 2, 3, []line{{nil, 5, 25}}},
+		{"sum capped at stock", product(5), nil, 4, 3, []line{{nil, 5, 25}}},
+		{"new line capped at stock", product(2), nil, 4, 0, []line{{nil, 2, 20}}},
+		{"out of stock line is dropped", product(0), nil, 2, 0, nil},
+		{"out of stock leaves the user's line", product(0), nil, 2, 3, []line{{nil, 3, 25}}},
+		{"backorders are not capped", backordered, nil, 4, 3, []line{{nil, 7, 25}}},
+		{"unavailable product is dropped", inactive, nil, 2, 0, nil},
+		{"variant is a line of its own", product(0), blue, 2, 3, []line{{nil, 3, 25}, {ptr(blue.ID), 2, 20}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guestItem := &models.CartItem{ID: 1, CartID: guestCartID, ProductID: 1, Quantity: tt.guestQty, Price: 20, Product: tt.product}
+			if tt.variant != nil {
+				guestItem.VariantID = &tt.variant.ID
+				guestItem.Variant = tt.variant
+			}
+			repo := &memoryCartRepository{
+				carts: []*models.Cart{
+					{ID: guestCartID, ResourceID: "guest-cart", DiscountCode: "WELCOME"},
+					{ID: userCartID, ResourceID: "user-cart", UserID: ptr(testUserID)},
+				},
+				items: []*models.CartItem{guestItem},
+			}
+			if tt.userQty > 0 {
+				repo.items = append(repo.items, &models.CartItem{ID: 2, CartID: userCartID, ProductID: 1, Quantity: tt.userQty, Price: 25, Product: tt.product})
+			}
+
+			tokens, err := services.NewCartTokenSigner("test-cart-secret", false)
+			if err != nil {
+				t.Fatalf("NewCartTokenSigner: %v", err)
+			}
+			carts := NewCartUsecase(repo, nil, nil, nil, tokens, time.Hour)
+
+			if err := carts.MergeGuestCart(context.Background(), testUserID, tokens.Sign("guest-cart")); err != nil {
+				t.Fatalf("MergeGuestCart: %v", err)
+			}
+
+			items, _ := repo.ListItems(context.Background(), userCartID)
+			if len(items) != len(tt.wantLines) {
+				t.Fatalf("user cart has %d lines, want %d", len(items), len(tt.wantLines))
+			}
+			for i, want := range tt.wantLines {
+				got := items[i]
+				if variantKey(got.VariantID) != variantKey(want.variantID) || got.Quantity != want.quantity || got.Price != want.price {
+					t.Errorf("line %d: variant %v, %d at %.2f; want variant %v, %d at %.2f",
+						i, got.VariantID, got.Quantity, got.Price, want.variantID, want.quantity, want.price)
+				}
+			}
+
+			if guest, _ := repo.GetGuestForUpdate(context.Background(), "guest-cart"); guest != nil {
+				t.Error("guest cart was not deleted")
+			}
+			if guestItems, _ := repo.ListItems(context.Background(), guestCartID); len(guestItems) != 0 {
+				t.Errorf("guest cart still has %d lines", len(guestItems))
+			}
+			if code := repo.carts[0].DiscountCode; code != "WELCOME" {
+				t.Errorf("user cart discount code = %q, want the guest cart's WELCOME", code)
+			}
+		})
+	}
+}
+
+func TestMergeGuestCartKeepsUserDiscount(t *testing.T) {
+	repo := &memoryCartRepository{
+		carts: []*models.Cart{
+			{ID: 1, ResourceID: "guest-cart", DiscountCode: "WELCOME"},
+			{ID: 2, ResourceID: "user-cart", UserID: ptr(testUserID), DiscountCode: "LOYAL"},
+		},
+	}
+	tokens, err := services.NewCartTokenSigner("test-cart-secret", false)
+	if err != nil {
+		t.Fatalf("NewCartTokenSigner: %v", err)
+	}
+	carts := NewCartUsecase(repo, nil, nil, nil, tokens, time.Hour)
+
+	// A token that wasn't signed by the store merges nothing
+	if err := carts.MergeGuestCart(context.Background(), testUserID, "guest-cart.forged"); err != nil {
+		t.Fatalf("MergeGuestCart with a forged token: %v", err)
+	}
+	if len(repo.carts) != 2 {
+		t.Fatal("guest cart was deleted for a forged token")
+	}
+
+	if err := carts.MergeGuestCart(context.Background(), testUserID, tokens.Sign("guest-cart")); err != nil {
+		t.Fatalf("MergeGuestCart: %v", err)
+	}
+	if len(repo.carts) != 1 || repo.carts[0].DiscountCode != "LOYAL" {
+		t.Errorf("carts after the merge = %+v, want only the user's with LOYAL", repo.carts)
+	}
+}
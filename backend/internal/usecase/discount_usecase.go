@@ -0,0 +1,261 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/repository"
+)
+
+var (
+	ErrDiscountNotFound      = errors.New("discount not found")
+	ErrDiscountCodeTaken     = errors.New("a discount with this code already exists")
+	ErrInvalidDiscount       = errors.New("invalid discount")
+	ErrDiscountInvalid       = errors.New("discount code is not valid")
+	ErrDiscountNotActive     = errors.New("discount code is not active")
+	ErrDiscountExpired       = errors.New("discount code has expired")
+	ErrDiscountUsageLimit    = errors.New("discount code has reached its usage limit")
+	ErrDiscountMinimumNotMet = errors.New("order does not reach the minimum amount for this discount")
+)
+
+type DiscountUsecase interface {
+	List(ctx context.Context, page, limit int) ([]*models.Discount, int64, error)
+	GetByResourceID(ctx context.Context, resourceID string) (*models.Discount, error)
+	Create(ctx context.Context, req dto.CreateDiscountRequest) (*models.Discount, error)
+	Update(ctx context.Context, resourceID string, req dto.UpdateDiscountRequest) (*models.Discount, error)
+	Delete(ctx context.Context, resourceID string) error
+	// Quote validates a code against a cart subtotal and returns the discount
+	// with the amount it would take off
+	Quote(ctx context.Context, code string, subtotal float64) (*models.Discount, float64, error)
+}
+
+type discountUsecase struct {
+	discountRepo repository.DiscountRepository
+}
+
+func NewDiscountUsecase(discountRepo repository.DiscountRepository) DiscountUsecase {
+	return &discountUsecase{
+		discountRepo: discountRepo,
+	}
+}
+
+func (u *discountUsecase) List(ctx context.Context, page, limit int) ([]*models.Discount, int64, error) {
+	offset := (page - 1) * limit
+
+	discounts, err := u.discountRepo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := u.discountRepo.Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return discounts, total, nil
+}
+
+func (u *discountUsecase) GetByResourceID(ctx context.Context, resourceID string) (*models.Discount, error) {
+	discount, err := u.discountRepo.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if discount == nil {
+		return nil, ErrDiscountNotFound
+	}
+	return discount, nil
+}
+
+func (u *discountUsecase) Create(ctx context.Context, req dto.CreateDiscountRequest) (*models.Discount, error) {
+	discount := &models.Discount{
+		Name:            req.Name,
+		Code:            normalizeDiscountCode(req.Code),
+		Type:            req.Type,
+		Value:           req.Value,
+		MinimumAmount:   req.MinimumAmount,
+		MaximumDiscount: req.MaximumDiscount,
+		UsageLimit:      req.UsageLimit,
+		IsActive:        req.IsActive,
+		StartsAt:        req.StartsAt,
+		ExpiresAt:       req.ExpiresAt,
+	}
+	if err := validateDiscount(discount); err != nil {
+		return nil, err
+	}
+	if err := u.ensureCodeAvailable(ctx, discount.Code, 0); err != nil {
+		return nil, err
+	}
+
+	if err := u.discountRepo.Create(ctx, discount); err != nil {
+		return nil, err
+	}
+	return discount, nil
+}
+
+func (u *discountUsecase) Update(ctx context.Context, resourceID string, req dto.UpdateDiscountRequest) (*models.Discount, error) {
+	discount, err := u.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		discount.Name = *req.Name
+	}
+	if req.Code != nil {
+		code := normalizeDiscountCode(*req.Code)
+		if err := u.ensureCodeAvailable(ctx, code, discount.ID); err != nil {
+			return nil, err
+		}
+		discount.Code = code
+	}
+	if req.Type != nil {
+		discount.Type = *req.Type
+	}
+	if req.Value != nil {
+		discount.Value = *req.Value
+	}
+	if req.MinimumAmount != nil {
+		discount.MinimumAmount = *req.MinimumAmount
+	}
+	if req.IsActive != nil {
+		discount.IsActive = *req.IsActive
+	}
+	if err := updateNullable(&discount.MaximumDiscount, req.MaximumDiscount, req.ClearMaximumDiscount, "maximum_discount"); err != nil {
+		return nil, err
+	}
+	if err := updateNullable(&discount.UsageLimit, req.UsageLimit, req.ClearUsageLimit, "usage_limit"); err != nil {
+		return nil, err
+	}
+	if err := updateNullable(&discount.StartsAt, req.StartsAt, req.ClearStartsAt, "starts_at"); err != nil {
+		return nil, err
+	}
+	if err := updateNullable(&discount.ExpiresAt, req.ExpiresAt, req.ClearExpiresAt, "expires_at"); err != nil {
+		return nil, err
+	}
+
+	if err := validateDiscount(discount); err != nil {
+		return nil, err
+	}
+	if err := u.discountRepo.Update(ctx, discount); err != nil {
+		return nil, err
+	}
+	return discount, nil
+}
+
+func (u *discountUsecase) Delete(ctx context.Context, resourceID string) error {
+	discount, err := u.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+	return u.discountRepo.Delete(ctx, discount.ID)
+}
+
+func (u *discountUsecase) Quote(ctx context.Context, code string, subtotal float64) (*models.Discount, float64, error) {
+	discount, err := u.discountRepo.GetByCode(ctx, normalizeDiscountCode(code))
+	if err != nil {
+		return nil, 0, err
+	}
+	if discount == nil {
+		return nil, 0, ErrDiscountInvalid
+	}
+
+	amount, err := calculateDiscount(discount, subtotal, 0, time.Now())
+	if err != nil {
+		return discount, 0, err
+	}
+	return discount, amount, nil
+}
+
+func (u *discountUsecase) ensureCodeAvailable(ctx context.Context, code string, discountID uint) error {
+	existing, err := u.discountRepo.GetByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != discountID {
+		return ErrDiscountCodeTaken
+	}
+	return nil
+}
+
+// calculateDiscount checks that a discount can be redeemed at the given time
+// for an order of subtotal and returns the amount it takes off. Free shipping
+// discounts take off the shipping cost; no discount exceeds what it applies to.
+func calculateDiscount(discount *models.Discount, subtotal, shipping float64, now time.Time) (float64, error) {
+	if !discount.IsActive {
+		return 0, ErrDiscountNotActive
+	}
+	if discount.StartsAt != nil && now.Before(*discount.StartsAt) {
+		return 0, ErrDiscountNotActive
+	}
+	if discount.ExpiresAt != nil && now.After(*discount.ExpiresAt) {
+		return 0, ErrDiscountExpired
+	}
+	if discount.UsageLimit != nil && discount.UsedCount >= *discount.UsageLimit {
+		return 0, ErrDiscountUsageLimit
+	}
+	if subtotal < discount.MinimumAmount {
+		return 0, ErrDiscountMinimumNotMet
+	}
+
+	var amount float64
+	switch discount.Type {
+	case models.DiscountTypePercentage:
+		amount = subtotal * discount.Value / 100
+	case models.DiscountTypeFixedAmount:
+		amount = discount.Value
+	case models.DiscountTypeFreeShipping:
+		return roundCurrency(shipping), nil
+	default:
+		return 0, ErrDiscountInvalid
+	}
+
+	if discount.MaximumDiscount != nil && amount > *discount.MaximumDiscount {
+		amount = *discount.MaximumDiscount
+	}
+	return roundCurrency(math.Min(amount, subtotal)), nil
+}
+
+func validateDiscount(discount *models.Discount) error {
+	switch discount.Type {
+	case models.DiscountTypePercentage:
+		if discount.Value <= 0 || discount.Value > 100 {
+			return fmt.Errorf("%w: percentage must be between 0 and 100", ErrInvalidDiscount)
+		}
+	case models.DiscountTypeFixedAmount:
+		if discount.Value <= 0 {
+			return fmt.Errorf("%w: amount must be greater than zero", ErrInvalidDiscount)
+		}
+	case models.DiscountTypeFreeShipping:
+	default:
+		return fmt.Errorf("%w: unknown discount type", ErrInvalidDiscount)
+	}
+	if discount.StartsAt != nil && discount.ExpiresAt != nil && !discount.ExpiresAt.After(*discount.StartsAt) {
+		return fmt.Errorf("%w: expires_at must be after starts_at", ErrInvalidDiscount)
+	}
+	return nil
+}
+
+// updateNullable sets an optional discount field to value when one is given
+// and removes it when clear is set. Asking for both is an error.
+func updateNullable[T any](field **T, value *T, clear bool, name string) error {
+	switch {
+	case clear && value != nil:
+		return fmt.Errorf("%w: %s can't be both set and cleared", ErrInvalidDiscount, name)
+	case clear:
+		*field = nil
+	case value != nil:
+		*field = value
+	}
+	return nil
+}
+
+// normalizeDiscountCode stores and matches codes in upper case
+func normalizeDiscountCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+)
+
+func TestDiscountUpdateNullableFields(t *testing.T) {
+	starts := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	expires := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	newExpires := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		req     dto.UpdateDiscountRequest
+		want    func(d *models.Discount) bool
+		wantErr error
+	}{
+		{
+			name: "fields left out are kept",
+			req:  dto.UpdateDiscountRequest{Name: ptr("Spring sale")},
+			want: func(d *models.Discount) bool {
+				return d.MaximumDiscount != nil && d.UsageLimit != nil && d.StartsAt != nil && d.ExpiresAt != nil
+			},
+		},
+		{
+			name: "fields are cleared",
+			req:  dto.UpdateDiscountRequest{ClearMaximumDiscount: true, ClearUsageLimit: true, ClearStartsAt: true, ClearExpiresAt: true},
+			want: func(d *models.Discount) bool {
+				return d.MaximumDiscount == nil && d.UsageLimit == nil && d.StartsAt == nil && d.ExpiresAt == nil
+			},
+		},
+		{
+			name: "fields are replaced",
+			req:  dto.UpdateDiscountRequest{MaximumDiscount: ptr(15.0), ExpiresAt: &newExpires},
+			want: func(d *models.Discount) bool {
+				return *d.MaximumDiscount == 15 && d.ExpiresAt.Equal(newExpires) && *d.UsageLimit == 10
+			},
+		},
+		{
+			name:    "set and cleared at once",
+			req:     dto.UpdateDiscountRequest{UsageLimit: ptr(5), ClearUsageLimit: true},
+			wantErr: ErrInvalidDiscount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discount := &models.Discount{
+				ID:              1,
+				ResourceID:      "discount-1",
+				Code:            "SPRING",
+				Type:            models.DiscountTypePercentage,
+				Value:           20,
+				MaximumDiscount: ptr(25.0),
+				UsageLimit:      ptr(10),
+				IsActive:        true,
+				StartsAt:        &starts,
+				ExpiresAt:       &expires,
+			}
+			discounts := NewDiscountUsecase(&memoryDiscountRepository{discounts: []*models.Discount{discount}})
+
+			updated, err := discounts.Update(context.Background(), "discount-1", tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Update error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+			if !tt.want(updated) {
+				t.Errorf("unexpected discount after update: %+v", updated)
+			}
+		})
+	}
+}
+
+func TestCalculateDiscount(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	yesterday := now.Add(-24 * time.Hour)
+	tomorrow := now.Add(24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		discount models.Discount
+		subtotal float64
+		shipping float64
+		want     float64
+		wantErr  error
+	}{
+		{
+			name:     "percentage",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypePercentage, Value: 15},
+			subtotal: 80,
+			want:     12,
+		},
+		{
+			name:     "percentage is rounded to cents",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypePercentage, Value: 12.5},
+			subtotal: 19.99,
+			want:     2.5,
+		},
+		{
+			name:     "percentage under the cap",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypePercentage, Value: 10, MaximumDiscount: ptr(20.0)},
+			subtotal: 150,
+			want:     15,
+		},
+		{
+			name:     "percentage over the cap",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypePercentage, Value: 10, MaximumDiscount: ptr(20.0)},
+			subtotal: 500,
+			want:     20,
+		},
+		{
+			name:     "fixed amount",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypeFixedAmount, Value: 10},
+			subtotal: 45,
+			want:     10,
+		},
+		{
+			name:     "fixed amount greater than the subtotal",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypeFixedAmount, Value: 50},
+			subtotal: 30,
+			want:     30,
+		},
+		{
+			name:     "free shipping takes off the shipping cost",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypeFreeShipping},
+			subtotal: 30,
+			shipping: 7.5,
+			want:     7.5,
+		},
+		{
+			name:     "free shipping without shipping",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypeFreeShipping},
+			subtotal: 30,
+		},
+		{
+			name:     "subtotal at the minimum",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypeFixedAmount, Value: 5, MinimumAmount: 50},
+			subtotal: 50,
+			want:     5,
+		},
+		{
+			name:     "subtotal under the minimum",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypeFixedAmount, Value: 5, MinimumAmount: 50},
+			subtotal: 49.99,
+			wantErr:  ErrDiscountMinimumNotMet,
+		},
+		{
+			name:     "inactive",
+			discount: models.Discount{Type: models.DiscountTypeFixedAmount, Value: 5},
+			subtotal: 50,
+			wantErr:  ErrDiscountNotActive,
+		},
+		{
+			name:     "not started",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypeFixedAmount, Value: 5, StartsAt: &tomorrow},
+			subtotal: 50,
+			wantErr:  ErrDiscountNotActive,
+		},
+		{
+			name:     "started",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypeFixedAmount, Value: 5, StartsAt: &yesterday, ExpiresAt: &tomorrow},
+			subtotal: 50,
+			want:     5,
+		},
+		{
+			name:     "expired",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypeFixedAmount, Value: 5, ExpiresAt: &yesterday},
+			subtotal: 50,
+			wantErr:  ErrDiscountExpired,
+		},
+		{
+			name:     "usage limit reached",
+			discount: models.Discount{IsActive: true, Type: models.DiscountTypeFixedAmount, Value: 5, UsageLimit: ptr(3), UsedCount: 3},
+			subtotal: 50,
+			wantErr:  ErrDiscountUsageLimit,
+		},
+		{
+			name:     "unknown type",
+			discount: models.Discount{IsActive: true, Type: "buy_one_get_one"},
+			subtotal: 50,
+			wantErr:  ErrDiscountInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calculateDiscount(&tt.discount, tt.subtotal, tt.shipping, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("calculateDiscount: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("discount = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
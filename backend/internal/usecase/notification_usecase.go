@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/repository"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationUsecase is a user's in-app inbox. Notifications are written
+// by services.Notifier; users only read and dismiss them.
+type NotificationUsecase interface {
+	List(ctx context.Context, userID uint, unreadOnly bool, page, limit int) ([]*models.Notification, int64, error)
+	UnreadCount(ctx context.Context, userID uint) (int64, error)
+	MarkRead(ctx context.Context, userID uint, resourceID string) error
+	// MarkAllRead returns how many notifications it marked read
+	MarkAllRead(ctx context.Context, userID uint) (int64, error)
+	Delete(ctx context.Context, userID uint, resourceID string) error
+}
+
+type notificationUsecase struct {
+	notificationRepo repository.NotificationRepository
+}
+
+func NewNotificationUsecase(notificationRepo repository.NotificationRepository) NotificationUsecase {
+	return &notificationUsecase{
+		notificationRepo: notificationRepo,
+	}
+}
+
+func (u *notificationUsecase) List(ctx context.Context, userID uint, unreadOnly bool, page, limit int) ([]*models.Notification, int64, error) {
+	return u.notificationRepo.ListByUser(ctx, userID, unreadOnly, limit, (page-1)*limit)
+}
+
+func (u *notificationUsecase) UnreadCount(ctx context.Context, userID uint) (int64, error) {
+	return u.notificationRepo.CountUnread(ctx, userID)
+}
+
+func (u *notificationUsecase) MarkRead(ctx context.Context, userID uint, resourceID string) error {
+	found, err := u.notificationRepo.MarkRead(ctx, userID, resourceID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (u *notificationUsecase) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+	return u.notificationRepo.MarkAllRead(ctx, userID)
+}
+
+func (u *notificationUsecase) Delete(ctx context.Context, userID uint, resourceID string) error {
+	deleted, err := u.notificationRepo.Delete(ctx, userID, resourceID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
@@ -3,37 +3,115 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 
 	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
 	"electronics-store/internal/repository"
+	"electronics-store/internal/services"
 )
 
 var (
-	ErrOrderNotFound = errors.New("order not found")
+	ErrOrderNotFound      = errors.New("order not found")
+	ErrCartEmpty          = errors.New("cart is empty")
+	ErrProductUnavailable = errors.New("product is not available")
+	ErrInvalidQuantity    = errors.New("quantity must be greater than zero")
+	ErrInsufficientStock  = errors.New("insufficient stock")
+
+	ErrInvalidStatusTransition = errors.New("invalid order status transition")
+	ErrOrderNotCancellable     = errors.New("only pending orders can be cancelled")
+	ErrOrderNotEditable        = errors.New("only pending orders can be changed")
+	ErrOrderActionNotAllowed   = errors.New("customers can only cancel their orders")
+	ErrOrderHasPayment         = errors.New("order has been paid or has a payment in progress")
 )
 
+// cancelUnpaidBatchSize is how many stale orders are looked up at a time
+const cancelUnpaidBatchSize = 100
+
+// Roles recorded as the actor of an order status change
+const (
+	OrderActorCustomer = "customer"
+	OrderActorAdmin    = "admin"
+	OrderActorSystem   = "system"
+)
+
+// orderStatusTransitions lists the statuses an order may move to from each status
+var orderStatusTransitions = map[string][]string{
+	models.OrderStatusPending:    {models.OrderStatusConfirmed, models.OrderStatusProcessing, models.OrderStatusCancelled},
+	models.OrderStatusConfirmed:  {models.OrderStatusProcessing, models.OrderStatusShipped, models.OrderStatusCancelled},
+	models.OrderStatusProcessing: {models.OrderStatusShipped, models.OrderStatusCancelled},
+	models.OrderStatusShipped:    {models.OrderStatusDelivered},
+	models.OrderStatusDelivered:  {models.OrderStatusRefunded},
+	models.OrderStatusCancelled:  {models.OrderStatusRefunded},
+	models.OrderStatusRefunded:   {},
+}
+
+// StatusChange describes a requested order status transition and who requested it
+type StatusChange struct {
+	Status    string
+	Note      string
+	ActorID   *uint
+	ActorRole string
+
+	// RequireStatus, when set, rejects the change unless the order is still in
+	// this status once it is locked (guards against concurrent transitions)
+	RequireStatus string
+	// RequireUnpaid rejects the change if the order has been paid or has a
+	// payment still being processed once it is locked
+	RequireUnpaid bool
+
+	// Shipment details, recorded when the order moves to shipped. The
+	// tracking URL is derived for known carriers when not given.
+	Carrier        string
+	TrackingNumber string
+	TrackingURL    string
+}
+
 type OrderUsecase interface {
 	List(ctx context.Context, userID uint, page, limit int) ([]*models.Order, int64, error)
 	GetByID(ctx context.Context, id uint) (*models.Order, error)
 	GetByResourceID(ctx context.Context, resourceID string) (*models.Order, error)
-	Create(ctx context.Context, order *models.Order) error
-	Update(ctx context.Context, order *models.Order) error
-	Delete(ctx context.Context, id uint) error
+	GetForUser(ctx context.Context, userID uint, resourceID string) (*models.Order, error)
+	Checkout(ctx context.Context, userID uint, req dto.CreateOrderRequest) (*models.Order, error)
+	UpdateForUser(ctx context.Context, userID uint, resourceID string, req dto.UpdateOrderRequest) (*models.Order, error)
+	CancelForUser(ctx context.Context, userID uint, resourceID, reason string) (*models.Order, error)
+	UpdateStatus(ctx context.Context, orderID uint, change StatusChange) (*models.Order, error)
+	// CancelUnpaidOrders cancels orders that have stayed pending and unpaid
+	// for longer than timeout, releasing their stock, and returns how many
+	// it cancelled
+	CancelUnpaidOrders(ctx context.Context, timeout time.Duration) (int, error)
 }
 
 type orderUsecase struct {
-	orderRepo repository.OrderRepository
+	orderRepo       repository.OrderRepository
+	discountRepo    repository.DiscountRepository
+	addressRepo     repository.AddressRepository
+	taxUsecase      TaxUsecase
+	shippingUsecase ShippingUsecase
+	emailService    *services.EmailService
+	notifier        services.Notifier
 }
 
-func NewOrderUsecase(orderRepo repository.OrderRepository) OrderUsecase {
+func NewOrderUsecase(orderRepo repository.OrderRepository, discountRepo repository.DiscountRepository, addressRepo repository.AddressRepository, taxUsecase TaxUsecase, shippingUsecase ShippingUsecase, emailService *services.EmailService, notifier services.Notifier) OrderUsecase {
 	return &orderUsecase{
-		orderRepo: orderRepo,
+		orderRepo:       orderRepo,
+		discountRepo:    discountRepo,
+		addressRepo:     addressRepo,
+		taxUsecase:      taxUsecase,
+		shippingUsecase: shippingUsecase,
+		emailService:    emailService,
+		notifier:        notifier,
 	}
 }
 
 func (u *orderUsecase) List(ctx context.Context, userID uint, page, limit int) ([]*models.Order, int64, error) {
 	offset := (page - 1) * limit
-	
+
 	orders, err := u.orderRepo.List(ctx, userID, limit, offset)
 	if err != nil {
 		return nil, 0, err
@@ -69,14 +147,597 @@ func (u *orderUsecase) GetByResourceID(ctx context.Context, resourceID string) (
 	return order, nil
 }
 
-func (u *orderUsecase) Create(ctx context.Context, order *models.Order) error {
-	return u.orderRepo.Create(ctx, order)
+// GetForUser returns an order only if it belongs to the given user. Orders of
+// other users are reported as not found so their existence is not revealed.
+func (u *orderUsecase) GetForUser(ctx context.Context, userID uint, resourceID string) (*models.Order, error) {
+	order, err := u.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// UpdateForUser applies the limited set of changes a customer may make to
+// their own order: editing notes or cancelling while the order is pending.
+// Payment and fulfilment statuses are only changed by admin flows.
+func (u *orderUsecase) UpdateForUser(ctx context.Context, userID uint, resourceID string, req dto.UpdateOrderRequest) (*models.Order, error) {
+	order, err := u.GetForUser(ctx, userID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Status != nil && *req.Status != order.Status && *req.Status != models.OrderStatusCancelled {
+		return nil, ErrOrderActionNotAllowed
+	}
+
+	if req.Notes != nil && *req.Notes != order.Notes {
+		if order.Status != models.OrderStatusPending {
+			return nil, ErrOrderNotEditable
+		}
+		order.Notes = *req.Notes
+		if err := u.orderRepo.Update(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Status != nil && *req.Status == models.OrderStatusCancelled && order.Status != models.OrderStatusCancelled {
+		return u.CancelForUser(ctx, userID, resourceID, "")
+	}
+
+	return u.GetByID(ctx, order.ID)
+}
+
+// CancelForUser cancels a customer's own order while it is still pending
+// and nothing has been paid for it. Paid orders need a refund instead.
+func (u *orderUsecase) CancelForUser(ctx context.Context, userID uint, resourceID, reason string) (*models.Order, error) {
+	order, err := u.GetForUser(ctx, userID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.OrderStatusPending {
+		return nil, ErrOrderNotCancellable
+	}
+
+	note := "Cancelled by customer"
+	if reason != "" {
+		note = note + ": " + reason
+	}
+	return u.UpdateStatus(ctx, order.ID, StatusChange{
+		Status:        models.OrderStatusCancelled,
+		Note:          note,
+		ActorID:       &userID,
+		ActorRole:     OrderActorCustomer,
+		RequireStatus: models.OrderStatusPending,
+		RequireUnpaid: true,
+	})
+}
+
+// Checkout turns the user's cart into an order. Lines are priced from the
+// catalog, totals are computed here and the cart is cleared, all inside one
+// transaction so a failed checkout leaves both cart and orders untouched.
+// The customer and, for items running low, staff are notified in the same
+// transaction. Orders with nothing to pay are placed paid and confirmed.
+func (u *orderUsecase) Checkout(ctx context.Context, userID uint, req dto.CreateOrderRequest) (*models.Order, error) {
+	var order *models.Order
+
+	address, err := resolveShippingAddress(ctx, u.addressRepo, userID, req.ShippingAddressID)
+	if err != nil {
+		return nil, err
+	}
+	billingAddress, err := resolveBillingAddress(ctx, u.addressRepo, userID, req.BillingAddressID, address)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.orderRepo.Transaction(ctx, func(tx repository.OrderRepository) error {
+		cart, err := tx.GetCartForCheckout(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if cart == nil || len(cart.Items) == 0 {
+			return ErrCartEmpty
+		}
+
+		order = &models.Order{
+			UserID:          userID,
+			Status:          models.OrderStatusPending,
+			PaymentStatus:   models.OrderPaymentPending,
+			Currency:        "USD",
+			Notes:           req.Notes,
+			ShippingAddress: address.Snapshot(),
+			BillingAddress:  billingAddress.Snapshot(),
+		}
+
+		for _, item := range cart.Items {
+			orderItem, err := buildOrderItem(item)
+			if err != nil {
+				return err
+			}
+			order.Subtotal += orderItem.Total
+			order.OrderItems = append(order.OrderItems, orderItem)
+		}
+
+		if err := reserveStock(ctx, tx, order.OrderItems); err != nil {
+			return err
+		}
+		order.InventoryReserved = true
+		lowStock, err := lowStockItems(ctx, tx, order.OrderItems)
+		if err != nil {
+			return err
+		}
+
+		order.Subtotal = roundCurrency(order.Subtotal)
+		shipping, err := u.shippingUsecase.Rate(ctx, address, order.OrderItems, req.ShippingMethodID)
+		if err != nil {
+			return err
+		}
+		order.ShippingCost = shipping.Cost
+		if shipping.Method != nil {
+			order.ShippingMethodID = &shipping.Method.ID
+			order.ShippingMethod = shipping.Method.Name
+		}
+
+		var itemDiscount float64
+		if cart.DiscountCode != "" {
+			discount, err := u.redeemDiscount(ctx, tx, order, cart.DiscountCode)
+			if err != nil {
+				return err
+			}
+			if discount.Type != models.DiscountTypeFreeShipping {
+				itemDiscount = order.DiscountAmount
+			}
+		}
+
+		tax, err := u.taxUsecase.Calculate(ctx, address, order.OrderItems, itemDiscount)
+		if err != nil {
+			return err
+		}
+		for i := range order.OrderItems {
+			order.OrderItems[i].TaxAmount = tax.ItemTaxes[i]
+		}
+		order.Taxes = tax.Taxes
+		order.TaxAmount = tax.Total
+		// Inclusive tax is already part of the subtotal
+		order.Total = roundCurrency(order.Subtotal + tax.Exclusive + order.ShippingCost - order.DiscountAmount)
+		// Orders a discount makes free have nothing to charge, so they are
+		// paid and confirmed as placed
+		free := order.Total <= 0
+		if free {
+			order.Total = 0
+			order.Status = models.OrderStatusConfirmed
+			order.PaymentStatus = models.OrderPaymentPaid
+		}
+
+		if err := tx.Create(ctx, order); err != nil {
+			return err
+		}
+		err = tx.CreateStatusHistory(ctx, &models.OrderStatusHistory{
+			OrderID:   order.ID,
+			ToStatus:  models.OrderStatusPending,
+			ActorID:   &userID,
+			ActorRole: OrderActorCustomer,
+			Note:      "Order placed",
+		})
+		if err != nil {
+			return err
+		}
+		if free {
+			err = tx.CreateStatusHistory(ctx, &models.OrderStatusHistory{
+				OrderID:    order.ID,
+				FromStatus: models.OrderStatusPending,
+				ToStatus:   models.OrderStatusConfirmed,
+				ActorRole:  OrderActorSystem,
+				Note:       "Nothing to pay",
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if err := tx.ClearCart(ctx, cart.ID); err != nil {
+			return err
+		}
+
+		placed, err := tx.GetByID(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+		data, err := u.emailService.OrderConfirmationEmail(placed)
+		if err != nil {
+			return err
+		}
+		if err := u.notifier.Notify(ctx, tx, services.OrderNotification(services.EmailTemplateOrderConfirmation, placed, data)); err != nil {
+			return err
+		}
+		if len(lowStock) == 0 {
+			return nil
+		}
+		alerts, err := u.emailService.LowStockAlertEmails(lowStock)
+		if err != nil {
+			return err
+		}
+		return u.notifier.Notify(ctx, tx, services.LowStockNotification(lowStock, alerts))
+	})
+	if err != nil {
+		return nil, err
+	}
+	u.notifier.Flush()
+
+	return u.GetByID(ctx, order.ID)
+}
+
+// redeemDiscount applies the cart's discount code to a new order and counts
+// the redemption. The usage counter is incremented conditionally so concurrent
+// checkouts can never redeem a code beyond its usage limit.
+func (u *orderUsecase) redeemDiscount(ctx context.Context, tx repository.OrderRepository, order *models.Order, code string) (*models.Discount, error) {
+	discount, err := u.discountRepo.GetByCode(ctx, normalizeDiscountCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if discount == nil {
+		return nil, ErrDiscountInvalid
+	}
+
+	amount, err := calculateDiscount(discount, order.Subtotal, order.ShippingCost, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	ok, err := tx.IncrementDiscountUsage(ctx, discount.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrDiscountUsageLimit
+	}
+
+	order.DiscountAmount = amount
+	order.DiscountCode = discount.Code
+	return discount, nil
+}
+
+// UpdateStatus moves an order to a new status if the transition is allowed,
+// stamps shipment/delivery times and records the change in the status history.
+// Orders that still hold reserved stock give it back when they are cancelled
+// or refunded, and cancelled orders give back the use of their discount code.
+// Customers are notified when their order ships or is delivered.
+func (u *orderUsecase) UpdateStatus(ctx context.Context, orderID uint, change StatusChange) (*models.Order, error) {
+	var from string
+	changed := false
+	err := u.orderRepo.Transaction(ctx, func(tx repository.OrderRepository) error {
+		order, err := tx.GetForUpdate(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			return ErrOrderNotFound
+		}
+
+		from = order.Status
+		if from == "" {
+			from = models.OrderStatusPending
+		}
+		if change.RequireStatus != "" && from != change.RequireStatus {
+			return fmt.Errorf("%w: order is no longer %s", ErrInvalidStatusTransition, change.RequireStatus)
+		}
+		if change.RequireUnpaid {
+			unpaid, err := isUnpaid(ctx, tx, order)
+			if err != nil {
+				return err
+			}
+			if !unpaid {
+				return ErrOrderHasPayment
+			}
+		}
+		if from == change.Status {
+			return nil
+		}
+		if !canTransitionOrderStatus(from, change.Status) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, from, change.Status)
+		}
+
+		now := time.Now()
+		order.Status = change.Status
+		switch change.Status {
+		case models.OrderStatusShipped:
+			order.ShippedAt = &now
+			if change.Carrier != "" {
+				order.Carrier = change.Carrier
+			}
+			if change.TrackingNumber != "" {
+				order.TrackingNumber = change.TrackingNumber
+				order.TrackingURL = trackingURL(order.Carrier, order.TrackingNumber)
+			}
+			if change.TrackingURL != "" {
+				order.TrackingURL = change.TrackingURL
+			}
+		case models.OrderStatusDelivered:
+			order.DeliveredAt = &now
+			if order.ShippedAt == nil {
+				order.ShippedAt = &now
+			}
+		}
+
+		if releasesInventory(change.Status) && order.InventoryReserved {
+			// Lines already restocked by a refund are not returned twice
+			restocked, err := tx.RefundedQuantities(ctx, order.ID, true)
+			if err != nil {
+				return err
+			}
+			for _, item := range order.OrderItems {
+				quantity := item.Quantity - restocked[item.ID]
+				if !item.Product.TrackQuantity || quantity <= 0 {
+					continue
+				}
+				if err := tx.ReleaseStock(ctx, item.ProductID, item.VariantID, quantity); err != nil {
+					return err
+				}
+			}
+			order.InventoryReserved = false
+		}
+		// A cancelled order gives its discount code's use back, so codes
+		// with a usage limit can't be used up by orders nobody pays for
+		if change.Status == models.OrderStatusCancelled && order.DiscountCode != "" {
+			if err := tx.ReleaseDiscountUsage(ctx, order.DiscountCode); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Update(ctx, order); err != nil {
+			return err
+		}
+
+		changed = true
+		err = tx.CreateStatusHistory(ctx, &models.OrderStatusHistory{
+			OrderID:    order.ID,
+			FromStatus: from,
+			ToStatus:   change.Status,
+			ActorID:    change.ActorID,
+			ActorRole:  change.ActorRole,
+			Note:       change.Note,
+		})
+		if err != nil {
+			return err
+		}
+		return u.notifyStatus(ctx, tx, order.ID, change.Status)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		u.notifier.Flush()
+	}
+
+	order, err := u.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		RecordAudit(ctx, AuditChange{
+			Action:     "order.status_update",
+			EntityType: "order",
+			EntityID:   order.ResourceID,
+			Before:     AuditSnapshot(map[string]string{"status": from}),
+			After:      AuditSnapshot(map[string]string{"status": change.Status, "note": change.Note}),
+		})
+	}
+	return order, nil
+}
+
+func (u *orderUsecase) CancelUnpaidOrders(ctx context.Context, timeout time.Duration) (int, error) {
+	createdBefore := time.Now().Add(-timeout)
+	note := fmt.Sprintf("Cancelled automatically: not paid within %s", timeout)
+
+	cancelled := 0
+	var afterID uint
+	for {
+		ids, err := u.orderRepo.ListUnpaidPendingIDs(ctx, createdBefore, afterID, cancelUnpaidBatchSize)
+		if err != nil {
+			return cancelled, err
+		}
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return cancelled, err
+			}
+			_, err := u.UpdateStatus(ctx, id, StatusChange{
+				Status:        models.OrderStatusCancelled,
+				Note:          note,
+				ActorRole:     OrderActorSystem,
+				RequireStatus: models.OrderStatusPending,
+				RequireUnpaid: true,
+			})
+			switch {
+			case err == nil:
+				cancelled++
+			case errors.Is(err, ErrInvalidStatusTransition), errors.Is(err, ErrOrderHasPayment), errors.Is(err, ErrOrderNotFound):
+				// Paid, moved on or deleted since it was listed
+			default:
+				return cancelled, err
+			}
+		}
+		if len(ids) < cancelUnpaidBatchSize {
+			return cancelled, nil
+		}
+		afterID = ids[len(ids)-1]
+	}
 }
 
-func (u *orderUsecase) Update(ctx context.Context, order *models.Order) error {
-	return u.orderRepo.Update(ctx, order)
+// notifyStatus tells the customer their order moved to status, for the
+// statuses they are told about
+func (u *orderUsecase) notifyStatus(ctx context.Context, tx repository.OrderRepository, orderID uint, status string) error {
+	var render func(*models.Order) (services.EmailData, error)
+	var kind string
+	switch status {
+	case models.OrderStatusShipped:
+		render, kind = u.emailService.OrderShippedEmail, services.EmailTemplateOrderShipped
+	case models.OrderStatusDelivered:
+		render, kind = u.emailService.OrderDeliveredEmail, services.EmailTemplateOrderDelivered
+	default:
+		return nil
+	}
+
+	order, err := tx.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	data, err := render(order)
+	if err != nil {
+		return err
+	}
+	return u.notifier.Notify(ctx, tx, services.OrderNotification(kind, order, data))
 }
 
-func (u *orderUsecase) Delete(ctx context.Context, id uint) error {
-	return u.orderRepo.Delete(ctx, id)
-}
\ No newline at end of file
+// buildOrderItem prices a cart line from the current catalog data
+func buildOrderItem(item models.CartItem) (models.OrderItem, error) {
+	if item.Quantity <= 0 {
+		return models.OrderItem{}, ErrInvalidQuantity
+	}
+	if item.Product.ID == 0 || !item.Product.IsActive {
+		return models.OrderItem{}, fmt.Errorf("%w: product %d", ErrProductUnavailable, item.ProductID)
+	}
+
+	price := item.Product.Price
+	if item.VariantID != nil {
+		variant := item.Variant
+		if variant == nil || variant.ProductID != item.ProductID || !variant.IsActive {
+			return models.OrderItem{}, fmt.Errorf("%w: %s", ErrProductUnavailable, item.Product.Name)
+		}
+		if variant.Price > 0 {
+			price = variant.Price
+		}
+	}
+
+	return models.OrderItem{
+		ProductID: item.ProductID,
+		VariantID: item.VariantID,
+		Quantity:  item.Quantity,
+		Price:     roundCurrency(price),
+		Total:     roundCurrency(price * float64(item.Quantity)),
+		Product:   item.Product,
+		Variant:   item.Variant,
+	}, nil
+}
+
+// reserveStock decrements stock for every tracked line. Lines are processed in
+// a fixed product/variant order so concurrent checkouts lock rows consistently.
+func reserveStock(ctx context.Context, tx repository.OrderRepository, items []models.OrderItem) error {
+	lines := make([]models.OrderItem, len(items))
+	copy(lines, items)
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].ProductID != lines[j].ProductID {
+			return lines[i].ProductID < lines[j].ProductID
+		}
+		return variantKey(lines[i].VariantID) < variantKey(lines[j].VariantID)
+	})
+
+	for _, line := range lines {
+		if !line.Product.TrackQuantity {
+			continue
+		}
+		ok, err := tx.ReserveStock(ctx, line.ProductID, line.VariantID, line.Quantity, line.Product.AllowBackorder)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrInsufficientStock, line.Product.Name)
+		}
+	}
+	return nil
+}
+
+// lowStockItems returns the lines whose reservation took their stock to or
+// below the product's low stock threshold. Stock that was already low
+// before the order was alerted about then.
+func lowStockItems(ctx context.Context, tx repository.OrderRepository, items []models.OrderItem) ([]services.LowStockItem, error) {
+	var low []services.LowStockItem
+	for _, item := range items {
+		if !item.Product.TrackQuantity {
+			continue
+		}
+		stock, err := tx.StockLevel(ctx, item.ProductID, item.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		threshold := item.Product.LowStockThreshold
+		if stock > threshold || stock+item.Quantity <= threshold {
+			continue
+		}
+
+		lowItem := services.LowStockItem{
+			Name:      item.Product.Name,
+			SKU:       item.Product.SKU,
+			Stock:     stock,
+			Threshold: threshold,
+		}
+		if item.Variant != nil {
+			lowItem.Variant = item.Variant.Name
+			lowItem.SKU = item.Variant.SKU
+		}
+		low = append(low, lowItem)
+	}
+	return low, nil
+}
+
+// trackingURL links to the tracking page of the carriers we know, or is
+// empty for others
+func trackingURL(carrier, trackingNumber string) string {
+	number := url.QueryEscape(trackingNumber)
+	switch strings.ToLower(strings.TrimSpace(carrier)) {
+	case "ups":
+		return "https://www.ups.com/track?tracknum=" + number
+	case "fedex":
+		return "https://www.fedex.com/fedextrack/?trknbr=" + number
+	case "usps":
+		return "https://tools.usps.com/go/TrackConfirmAction?tLabels=" + number
+	case "dhl":
+		return "https://www.dhl.com/en/express/tracking.html?AWB=" + number
+	default:
+		return ""
+	}
+}
+
+// isUnpaid reports whether nothing has been paid for the order and no
+// payment could still complete
+func isUnpaid(ctx context.Context, tx repository.OrderRepository, order *models.Order) (bool, error) {
+	if order.PaymentStatus != models.OrderPaymentPending && order.PaymentStatus != models.OrderPaymentFailed {
+		return false, nil
+	}
+	payments, err := tx.ListPayments(ctx, order.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, payment := range payments {
+		if payment.Status != models.PaymentStatusFailed && payment.Status != models.PaymentStatusCancelled {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// releasesInventory reports whether moving to status returns stock to the shelf
+func releasesInventory(status string) bool {
+	return status == models.OrderStatusCancelled || status == models.OrderStatusRefunded
+}
+
+// canTransitionOrderStatus reports whether an order may move from one status to another
+func canTransitionOrderStatus(from, to string) bool {
+	for _, next := range orderStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func variantKey(variantID *uint) uint {
+	if variantID == nil {
+		return 0
+	}
+	return *variantID
+}
+
+// roundCurrency rounds an amount to whole cents
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
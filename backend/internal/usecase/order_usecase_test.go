@@ -0,0 +1,603 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"electronics-store/internal/config"
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/repository"
+	"electronics-store/internal/services"
+)
+
+const testUserID uint = 1
+
+// memoryOrderRepository keeps orders and the user's cart in memory. It
+// implements the part of OrderRepository checkout, payment and status
+// changes use; calling anything else panics on the nil embedded interface.
+type memoryOrderRepository struct {
+	repository.OrderRepository
+	cart     *models.Cart
+	orders   map[uint]*models.Order
+	payments map[uint][]*models.Payment
+	history  []*models.OrderStatusHistory
+	released map[uint]int
+	// discounts are the codes checkout redeems and cancelling gives back
+	discounts []*models.Discount
+	// stock and variantStock are the stock levels by product and variant
+	// ID; products and variants without one don't exist
+	stock        map[uint]int
+	variantStock map[uint]int
+}
+
+func newMemoryOrderRepository(orders ...models.Order) *memoryOrderRepository {
+	r := &memoryOrderRepository{
+		orders:   map[uint]*models.Order{},
+		payments: map[uint][]*models.Payment{},
+		released: map[uint]int{},
+
+		stock:        map[uint]int{},
+		variantStock: map[uint]int{},
+	}
+	for i := range orders {
+		r.orders[orders[i].ID] = &orders[i]
+	}
+	return r
+}
+
+func (r *memoryOrderRepository) Create(ctx context.Context, order *models.Order) error {
+	order.ID = uint(len(r.orders) + 1)
+	order.ResourceID = fmt.Sprintf("order-%d", order.ID)
+	return r.Update(ctx, order)
+}
+
+func (r *memoryOrderRepository) GetByID(ctx context.Context, id uint) (*models.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, nil
+	}
+	loaded := *order
+	return &loaded, nil
+}
+
+func (r *memoryOrderRepository) GetByResourceID(ctx context.Context, resourceID string) (*models.Order, error) {
+	for _, order := range r.orders {
+		if order.ResourceID == resourceID {
+			return r.GetByID(ctx, order.ID)
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryOrderRepository) GetForUpdate(ctx context.Context, id uint) (*models.Order, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memoryOrderRepository) Update(ctx context.Context, order *models.Order) error {
+	updated := *order
+	r.orders[order.ID] = &updated
+	return nil
+}
+
+func (r *memoryOrderRepository) GetCartForCheckout(ctx context.Context, userID uint) (*models.Cart, error) {
+	return r.cart, nil
+}
+
+func (r *memoryOrderRepository) ClearCart(ctx context.Context, cartID uint) error {
+	r.cart.Items = nil
+	return nil
+}
+
+func (r *memoryOrderRepository) ListUnpaidPendingIDs(ctx context.Context, createdBefore time.Time, afterID uint, limit int) ([]uint, error) {
+	var ids []uint
+	for id, order := range r.orders {
+		if id > afterID && order.Status == models.OrderStatusPending && order.PaymentStatus == models.OrderPaymentPending && order.CreatedAt.Before(createdBefore) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (r *memoryOrderRepository) IncrementDiscountUsage(ctx context.Context, discountID uint) (bool, error) {
+	for _, discount := range r.discounts {
+		if discount.ID == discountID {
+			if discount.UsageLimit != nil && discount.UsedCount >= *discount.UsageLimit {
+				return false, nil
+			}
+			discount.UsedCount++
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryOrderRepository) ReleaseDiscountUsage(ctx context.Context, code string) error {
+	for _, discount := range r.discounts {
+		if discount.Code == code && discount.UsedCount > 0 {
+			discount.UsedCount--
+		}
+	}
+	return nil
+}
+
+func (r *memoryOrderRepository) ListPayments(ctx context.Context, orderID uint) ([]*models.Payment, error) {
+	return r.payments[orderID], nil
+}
+
+func (r *memoryOrderRepository) CreatePayment(ctx context.Context, payment *models.Payment) error {
+	r.payments[payment.OrderID] = append(r.payments[payment.OrderID], payment)
+	return nil
+}
+
+func (r *memoryOrderRepository) UpdatePayment(ctx context.Context, payment *models.Payment) error {
+	return nil
+}
+
+func (r *memoryOrderRepository) GetPaymentByTransactionID(ctx context.Context, transactionID string) (*models.Payment, error) {
+	for _, payments := range r.payments {
+		for _, payment := range payments {
+			if payment.TransactionID == transactionID {
+				return payment, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryOrderRepository) CreateRefund(ctx context.Context, refund *models.Refund) error {
+	for _, payment := range r.payments[refund.OrderID] {
+		if payment.ID == refund.PaymentID {
+			payment.Refunds = append(payment.Refunds, *refund)
+		}
+	}
+	return nil
+}
+
+func (r *memoryOrderRepository) RefundedQuantities(ctx context.Context, orderID uint, restockedOnly bool) (map[uint]int, error) {
+	return map[uint]int{}, nil
+}
+
+func (r *memoryOrderRepository) ReleaseStock(ctx context.Context, productID uint, variantID *uint, quantity int) error {
+	r.released[productID] += quantity
+	return nil
+}
+
+// ReserveStock mirrors the conditional update of the database repository:
+// when the row is missing or short of stock nothing changes and it reports
+// false, as RowsAffected is then 0
+func (r *memoryOrderRepository) ReserveStock(ctx context.Context, productID uint, variantID *uint, quantity int, allowBackorder bool) (bool, error) {
+	levels, id := r.stock, productID
+	if variantID != nil {
+		levels, id = r.variantStock, *variantID
+	}
+	level, ok := levels[id]
+	if !ok || (!allowBackorder && level < quantity) {
+		return false, nil
+	}
+	levels[id] = level - quantity
+	return true, nil
+}
+
+func (r *memoryOrderRepository) StockLevel(ctx context.Context, productID uint, variantID *uint) (int, error) {
+	if variantID != nil {
+		return r.variantStock[*variantID], nil
+	}
+	return r.stock[productID], nil
+}
+
+func (r *memoryOrderRepository) CreateStatusHistory(ctx context.Context, entry *models.OrderStatusHistory) error {
+	r.history = append(r.history, entry)
+	return nil
+}
+
+func (r *memoryOrderRepository) Transaction(ctx context.Context, fn func(tx repository.OrderRepository) error) error {
+	return fn(r)
+}
+
+// memoryDiscountRepository keeps discounts in memory
+type memoryDiscountRepository struct {
+	repository.DiscountRepository
+	discounts []*models.Discount
+}
+
+func (r *memoryDiscountRepository) GetByResourceID(ctx context.Context, resourceID string) (*models.Discount, error) {
+	for _, discount := range r.discounts {
+		if discount.ResourceID == resourceID {
+			return discount, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryDiscountRepository) Update(ctx context.Context, discount *models.Discount) error {
+	return nil
+}
+
+func (r *memoryDiscountRepository) GetByCode(ctx context.Context, code string) (*models.Discount, error) {
+	for _, discount := range r.discounts {
+		if discount.Code == code {
+			return discount, nil
+		}
+	}
+	return nil, nil
+}
+
+// noAddressRepository is the address book of a user without addresses
+type noAddressRepository struct {
+	repository.AddressRepository
+}
+
+func (noAddressRepository) GetDefault(ctx context.Context, userID uint, addressType string) (*models.Address, error) {
+	return nil, nil
+}
+
+// flatShipping charges the same cost for every order
+type flatShipping struct {
+	ShippingUsecase
+	cost float64
+}
+
+func (s flatShipping) Rate(ctx context.Context, address *models.Address, items []models.OrderItem, methodResourceID string) (*ShippingQuote, error) {
+	return &ShippingQuote{Cost: s.cost}, nil
+}
+
+// noTax charges no tax on any order
+type noTax struct {
+	TaxUsecase
+}
+
+func (noTax) Calculate(ctx context.Context, address *models.Address, items []models.OrderItem, discount float64) (*TaxCalculation, error) {
+	return &TaxCalculation{ItemTaxes: make([]float64, len(items))}, nil
+}
+
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(ctx context.Context, out services.NotificationOutbox, n services.Notification) error {
+	return nil
+}
+
+func (nopNotifier) Flush() {}
+
+func newTestEmailService(t *testing.T) *services.EmailService {
+	t.Helper()
+	emailService, err := services.NewEmailService(&config.EmailConfig{})
+	if err != nil {
+		t.Fatalf("NewEmailService: %v", err)
+	}
+	return emailService
+}
+
+func TestCheckoutWithNothingToPay(t *testing.T) {
+	hundredPercent := &models.Discount{ID: 1, Code: "FREE100", Type: models.DiscountTypePercentage, Value: 100, IsActive: true}
+	fullAmount := &models.Discount{ID: 2, Code: "GIFT50", Type: models.DiscountTypeFixedAmount, Value: 50, IsActive: true}
+	halfOff := &models.Discount{ID: 3, Code: "HALF", Type: models.DiscountTypePercentage, Value: 50, IsActive: true}
+
+	tests := []struct {
+		name              string
+		code              string
+		shipping          float64
+		wantTotal         float64
+		wantStatus        string
+		wantPaymentStatus string
+	}{
+		{"percentage discount with free shipping", "FREE100", 0, 0, models.OrderStatusConfirmed, models.OrderPaymentPaid},
+		{"fixed discount of the subtotal with free shipping", "GIFT50", 0, 0, models.OrderStatusConfirmed, models.OrderPaymentPaid},
+		{"percentage discount with paid shipping", "FREE100", 4.99, 4.99, models.OrderStatusPending, models.OrderPaymentPending},
+		{"partial discount", "HALF", 0, 25, models.OrderStatusPending, models.OrderPaymentPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryOrderRepository()
+			repo.cart = &models.Cart{
+				ID:           1,
+				UserID:       ptr(testUserID),
+				DiscountCode: tt.code,
+				Items: []models.CartItem{{
+					ProductID: 1,
+					Quantity:  2,
+					Product:   models.Product{ID: 1, Name: "USB-C cable", Price: 25, IsActive: true},
+				}},
+			}
+			discounts := &memoryDiscountRepository{discounts: []*models.Discount{hundredPercent, fullAmount, halfOff}}
+			repo.discounts = discounts.discounts
+			orders := NewOrderUsecase(repo, discounts, noAddressRepository{}, noTax{}, flatShipping{cost: tt.shipping}, newTestEmailService(t), nopNotifier{})
+
+			order, err := orders.Checkout(context.Background(), testUserID, dto.CreateOrderRequest{})
+			if err != nil {
+				t.Fatalf("Checkout: %v", err)
+			}
+			if order.Total != tt.wantTotal || order.Status != tt.wantStatus || order.PaymentStatus != tt.wantPaymentStatus {
+				t.Fatalf("order total %.2f, status %q, payment %q; want %.2f, %q, %q",
+					order.Total, order.Status, order.PaymentStatus, tt.wantTotal, tt.wantStatus, tt.wantPaymentStatus)
+			}
+
+			wantHistory := 1
+			if tt.wantStatus == models.OrderStatusConfirmed {
+				wantHistory = 2
+			}
+			if len(repo.history) != wantHistory {
+				t.Fatalf("status history entries = %d, want %d", len(repo.history), wantHistory)
+			}
+			if last := repo.history[len(repo.history)-1]; last.ToStatus != tt.wantStatus {
+				t.Errorf("last status history entry moves to %q, want %q", last.ToStatus, tt.wantStatus)
+			}
+			if tt.wantTotal > 0 {
+				return
+			}
+
+			payments := NewPaymentUsecase(repo, services.NewMockPaymentGateway("test-webhook-secret"), nil, nopNotifier{})
+			_, _, err = payments.PayForUser(context.Background(), testUserID, order.ResourceID, dto.ProcessPaymentRequest{Method: "stripe", PaymentToken: "tok_visa"})
+			if !errors.Is(err, ErrOrderAlreadyPaid) {
+				t.Errorf("PayForUser error = %v, want %v", err, ErrOrderAlreadyPaid)
+			}
+			if len(repo.payments[order.ID]) != 0 {
+				t.Errorf("payments recorded = %d, want none", len(repo.payments[order.ID]))
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestUpdateStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{models.OrderStatusPending, models.OrderStatusConfirmed, true},
+		{models.OrderStatusPending, models.OrderStatusProcessing, true},
+		{models.OrderStatusPending, models.OrderStatusCancelled, true},
+		{models.OrderStatusPending, models.OrderStatusShipped, false},
+		{models.OrderStatusPending, models.OrderStatusDelivered, false},
+		{models.OrderStatusPending, models.OrderStatusRefunded, false},
+		{models.OrderStatusConfirmed, models.OrderStatusShipped, true},
+		{models.OrderStatusConfirmed, models.OrderStatusPending, false},
+		{models.OrderStatusProcessing, models.OrderStatusShipped, true},
+		{models.OrderStatusProcessing, models.OrderStatusConfirmed, false},
+		{models.OrderStatusShipped, models.OrderStatusDelivered, true},
+		{models.OrderStatusShipped, models.OrderStatusCancelled, false},
+		{models.OrderStatusShipped, models.OrderStatusPending, false},
+		{models.OrderStatusDelivered, models.OrderStatusRefunded, true},
+		{models.OrderStatusDelivered, models.OrderStatusPending, false},
+		{models.OrderStatusDelivered, models.OrderStatusShipped, false},
+		{models.OrderStatusDelivered, models.OrderStatusCancelled, false},
+		{models.OrderStatusCancelled, models.OrderStatusRefunded, true},
+		{models.OrderStatusCancelled, models.OrderStatusShipped, false},
+		{models.OrderStatusCancelled, models.OrderStatusPending, false},
+		{models.OrderStatusCancelled, models.OrderStatusConfirmed, false},
+		{models.OrderStatusRefunded, models.OrderStatusPending, false},
+		{models.OrderStatusRefunded, models.OrderStatusDelivered, false},
+		{models.OrderStatusPending, "lost", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			repo := newMemoryOrderRepository(models.Order{ID: 1, ResourceID: "order-1", UserID: testUserID, Status: tt.from})
+			orders := NewOrderUsecase(repo, nil, nil, nil, nil, newTestEmailService(t), nopNotifier{})
+
+			_, err := orders.UpdateStatus(context.Background(), 1, StatusChange{Status: tt.to, ActorRole: OrderActorAdmin})
+			if !tt.allowed {
+				if !errors.Is(err, ErrInvalidStatusTransition) {
+					t.Fatalf("UpdateStatus error = %v, want %v", err, ErrInvalidStatusTransition)
+				}
+				if status := repo.orders[1].Status; status != tt.from {
+					t.Errorf("status = %q after a rejected transition, want %q", status, tt.from)
+				}
+				if len(repo.history) != 0 {
+					t.Errorf("status history entries = %d after a rejected transition, want 0", len(repo.history))
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("UpdateStatus: %v", err)
+			}
+			if status := repo.orders[1].Status; status != tt.to {
+				t.Errorf("status = %q, want %q", status, tt.to)
+			}
+			if len(repo.history) != 1 || repo.history[0].FromStatus != tt.from || repo.history[0].ToStatus != tt.to {
+				t.Errorf("status history = %+v, want one entry from %q to %q", repo.history, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestCancelReleasesDiscountUse(t *testing.T) {
+	pending := func(code string) models.Order {
+		return models.Order{
+			ID:            1,
+			ResourceID:    "order-1",
+			UserID:        testUserID,
+			Status:        models.OrderStatusPending,
+			PaymentStatus: models.OrderPaymentPending,
+			DiscountCode:  code,
+			CreatedAt:     time.Now().Add(-48 * time.Hour),
+		}
+	}
+	customerCancel := func(orders OrderUsecase) error {
+		_, err := orders.CancelForUser(context.Background(), testUserID, "order-1", "changed my mind")
+		return err
+	}
+	adminCancel := func(orders OrderUsecase) error {
+		_, err := orders.UpdateStatus(context.Background(), 1, StatusChange{Status: models.OrderStatusCancelled, ActorRole: OrderActorAdmin})
+		return err
+	}
+	unpaidSweep := func(orders OrderUsecase) error {
+		cancelled, err := orders.CancelUnpaidOrders(context.Background(), 24*time.Hour)
+		if err == nil && cancelled != 1 {
+			return fmt.Errorf("cancelled %d orders, want 1", cancelled)
+		}
+		return err
+	}
+
+	tests := []struct {
+		name     string
+		order    models.Order
+		used     int
+		cancel   func(OrderUsecase) error
+		wantUsed int
+	}{
+		{"customer cancel", pending("SAVE10"), 3, customerCancel, 2},
+		{"admin cancel", pending("SAVE10"), 3, adminCancel, 2},
+		{"unpaid order sweep", pending("SAVE10"), 3, unpaidSweep, 2},
+		{"count already at zero", pending("SAVE10"), 0, adminCancel, 0},
+		{"order without a code", pending(""), 3, adminCancel, 3},
+		{"order with another code", pending("OTHER"), 3, adminCancel, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discount := &models.Discount{ID: 1, Code: "SAVE10", UsedCount: tt.used}
+			repo := newMemoryOrderRepository(tt.order)
+			repo.discounts = []*models.Discount{discount}
+			orders := NewOrderUsecase(repo, nil, nil, nil, nil, newTestEmailService(t), nopNotifier{})
+
+			if err := tt.cancel(orders); err != nil {
+				t.Fatalf("cancel: %v", err)
+			}
+			if discount.UsedCount != tt.wantUsed {
+				t.Errorf("used count = %d, want %d", discount.UsedCount, tt.wantUsed)
+			}
+		})
+	}
+
+	t.Run("refunding a cancelled order", func(t *testing.T) {
+		discount := &models.Discount{ID: 1, Code: "SAVE10", UsedCount: 3}
+		repo := newMemoryOrderRepository(pending("SAVE10"))
+		repo.discounts = []*models.Discount{discount}
+		orders := NewOrderUsecase(repo, nil, nil, nil, nil, newTestEmailService(t), nopNotifier{})
+
+		for _, status := range []string{models.OrderStatusCancelled, models.OrderStatusRefunded} {
+			if _, err := orders.UpdateStatus(context.Background(), 1, StatusChange{Status: status, ActorRole: OrderActorAdmin}); err != nil {
+				t.Fatalf("UpdateStatus(%s): %v", status, err)
+			}
+		}
+		if discount.UsedCount != 2 {
+			t.Errorf("used count = %d, want the use given back once", discount.UsedCount)
+		}
+	})
+
+	t.Run("code at its usage limit", func(t *testing.T) {
+		discount := &models.Discount{ID: 1, Code: "ONCE", Type: models.DiscountTypeFixedAmount, Value: 5, UsageLimit: ptr(1), IsActive: true}
+		repo := newMemoryOrderRepository()
+		repo.discounts = []*models.Discount{discount}
+		newCart := func() *models.Cart {
+			return &models.Cart{
+				ID:           1,
+				UserID:       ptr(testUserID),
+				DiscountCode: "ONCE",
+				Items: []models.CartItem{{
+					ProductID: 1,
+					Quantity:  1,
+					Product:   models.Product{ID: 1, Name: "USB-C cable", Price: 25, IsActive: true},
+				}},
+			}
+		}
+		orders := NewOrderUsecase(repo, &memoryDiscountRepository{discounts: repo.discounts}, noAddressRepository{}, noTax{}, flatShipping{}, newTestEmailService(t), nopNotifier{})
+
+		repo.cart = newCart()
+		order, err := orders.Checkout(context.Background(), testUserID, dto.CreateOrderRequest{})
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		repo.cart = newCart()
+		if _, err := orders.Checkout(context.Background(), testUserID, dto.CreateOrderRequest{}); !errors.Is(err, ErrDiscountUsageLimit) {
+			t.Fatalf("second Checkout error = %v, want %v", err, ErrDiscountUsageLimit)
+		}
+
+		if _, err := orders.CancelForUser(context.Background(), testUserID, order.ResourceID, ""); err != nil {
+			t.Fatalf("CancelForUser: %v", err)
+		}
+		repo.cart = newCart()
+		if _, err := orders.Checkout(context.Background(), testUserID, dto.CreateOrderRequest{}); err != nil {
+			t.Errorf("Checkout after the cancel: %v", err)
+		}
+	})
+}
+
+func TestReserveStock(t *testing.T) {
+	tracked := models.Product{ID: 1, Name: "USB-C cable", TrackQuantity: true}
+	backordered := models.Product{ID: 1, Name: "USB-C cable", TrackQuantity: true, AllowBackorder: true}
+	untracked := models.Product{ID: 1, Name: "USB-C cable"}
+	line := func(product models.Product, variantID *uint, quantity int) models.OrderItem {
+		return models.OrderItem{ProductID: product.ID, VariantID: variantID, Quantity: quantity, Product: product}
+	}
+
+	tests := []struct {
+		name      string
+		item      models.OrderItem
+		stock     int
+		wantErr   error
+		wantStock int
+	}{
+		{"in stock", line(tracked, nil, 2), 5, nil, 3},
+		{"last units", line(tracked, nil, 5), 5, nil, 0},
+		{"short of stock", line(tracked, nil, 6), 5, ErrInsufficientStock, 5},
+		{"out of stock", line(tracked, nil, 1), 0, ErrInsufficientStock, 0},
+		{"backorder", line(backordered, nil, 3), 1, nil, -2},
+		{"backorder when out of stock", line(backordered, nil, 2), 0, nil, -2},
+		{"untracked", line(untracked, nil, 8), 5, nil, 5},
+		{"variant in stock", line(tracked, ptr(uint(7)), 2), 5, nil, 3},
+		{"variant out of stock", line(tracked, ptr(uint(7)), 1), 0, ErrInsufficientStock, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryOrderRepository()
+			repo.stock[1] = tt.stock
+			if tt.item.VariantID != nil {
+				// The product itself has plenty; only the variant's stock counts
+				repo.stock[1] = 100
+				repo.variantStock[7] = tt.stock
+			}
+
+			err := reserveStock(context.Background(), repo, []models.OrderItem{tt.item})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("reserveStock error = %v, want %v", err, tt.wantErr)
+			}
+			got := repo.stock[1]
+			if tt.item.VariantID != nil {
+				got = repo.variantStock[7]
+				if repo.stock[1] != 100 {
+					t.Errorf("product stock = %d, want it untouched", repo.stock[1])
+				}
+			}
+			if got != tt.wantStock {
+				t.Errorf("stock = %d, want %d", got, tt.wantStock)
+			}
+		})
+	}
+}
+
+func TestCheckoutOutOfStock(t *testing.T) {
+	repo := newMemoryOrderRepository()
+	repo.stock[1] = 1
+	repo.cart = &models.Cart{
+		ID:     1,
+		UserID: ptr(testUserID),
+		Items: []models.CartItem{{
+			ProductID: 1,
+			Quantity:  2,
+			Product:   models.Product{ID: 1, Name: "USB-C cable", Price: 25, IsActive: true, TrackQuantity: true},
+		}},
+	}
+	orders := NewOrderUsecase(repo, nil, noAddressRepository{}, noTax{}, flatShipping{}, newTestEmailService(t), nopNotifier{})
+
+	if _, err := orders.Checkout(context.Background(), testUserID, dto.CreateOrderRequest{}); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("Checkout error = %v, want %v", err, ErrInsufficientStock)
+	}
+	if len(repo.orders) != 0 {
+		t.Errorf("orders created = %d, want none", len(repo.orders))
+	}
+	if len(repo.cart.Items) != 1 || repo.stock[1] != 1 {
+		t.Errorf("cart lines %d, stock %d after a failed checkout; want 1, 1", len(repo.cart.Items), repo.stock[1])
+	}
+}
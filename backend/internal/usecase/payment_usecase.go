@@ -0,0 +1,646 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/repository"
+	"electronics-store/internal/services"
+)
+
+var (
+	ErrOrderAlreadyPaid = errors.New("order is already paid")
+	ErrOrderNotPayable  = errors.New("order can no longer be paid")
+	ErrPaymentDeclined  = errors.New("payment was declined")
+	ErrPaymentNotFound  = errors.New("payment not found")
+
+	ErrPaymentsUnavailable = errors.New("online payments are not available")
+
+	ErrPaymentInProgress = errors.New("a payment for this order is already in progress")
+
+	ErrRefundAmountRequired = errors.New("refund amount or items are required")
+	ErrInvalidRefundItem    = errors.New("invalid refund item")
+	ErrPaymentNotRefundable = errors.New("only completed payments can be refunded")
+	ErrRefundExceedsPayment = errors.New("refund exceeds the refundable amount of the payment")
+	ErrRefundFailed         = errors.New("refund was rejected by the payment gateway")
+	ErrRefundInProgress     = errors.New("a refund of this payment is still being issued")
+)
+
+// paymentAttemptTimeout is how long a pending payment blocks further attempts
+// on its order; the gateway calls of an attempt finish well within it
+const paymentAttemptTimeout = 10 * time.Minute
+
+// PaymentUsecase charges and refunds orders through the payment gateway.
+// Without a gateway every method returns ErrPaymentsUnavailable.
+type PaymentUsecase interface {
+	// PayForUser charges the full order total through the payment gateway. A
+	// declined charge is still recorded and returned together with ErrPaymentDeclined;
+	// ErrPaymentInProgress is returned while another attempt is being charged.
+	PayForUser(ctx context.Context, userID uint, resourceID string, req dto.ProcessPaymentRequest) (*models.Order, *models.Payment, error)
+	// HandleWebhook verifies and applies an asynchronous gateway notification
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error
+	// RefundOrder refunds part or all of one payment of an order through the
+	// gateway and emails the customer about it
+	RefundOrder(ctx context.Context, orderID uint, actorID *uint, req dto.CreateRefundRequest) (*models.Order, *models.Refund, error)
+}
+
+type paymentUsecase struct {
+	orderRepo    repository.OrderRepository
+	gateway      services.PaymentGateway
+	emailService *services.EmailService
+	notifier     services.Notifier
+}
+
+func NewPaymentUsecase(orderRepo repository.OrderRepository, gateway services.PaymentGateway, emailService *services.EmailService, notifier services.Notifier) PaymentUsecase {
+	return &paymentUsecase{
+		orderRepo:    orderRepo,
+		gateway:      gateway,
+		emailService: emailService,
+		notifier:     notifier,
+	}
+}
+
+func (u *paymentUsecase) PayForUser(ctx context.Context, userID uint, resourceID string, req dto.ProcessPaymentRequest) (*models.Order, *models.Payment, error) {
+	if u.gateway == nil {
+		return nil, nil, ErrPaymentsUnavailable
+	}
+
+	order, err := u.orderRepo.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if order == nil || order.UserID != userID {
+		return nil, nil, ErrOrderNotFound
+	}
+
+	// The attempt is committed as a pending payment before the gateway is
+	// called, so a charge always has a row to be recorded on and concurrent
+	// attempts on the same order back off instead of charging twice
+	var payment *models.Payment
+	err = u.orderRepo.Transaction(ctx, func(tx repository.OrderRepository) error {
+		locked, err := tx.GetForUpdate(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+		if locked == nil {
+			return ErrOrderNotFound
+		}
+		// Orders with nothing to pay were paid when they were placed
+		if locked.PaymentStatus == models.OrderPaymentPaid || locked.PaymentStatus == models.OrderPaymentPartiallyRefunded || locked.Total <= 0 {
+			return ErrOrderAlreadyPaid
+		}
+		if locked.PaymentStatus == models.OrderPaymentRefunded ||
+			locked.Status == models.OrderStatusCancelled ||
+			locked.Status == models.OrderStatusRefunded {
+			return ErrOrderNotPayable
+		}
+
+		payments, err := tx.ListPayments(ctx, locked.ID)
+		if err != nil {
+			return err
+		}
+		for _, p := range payments {
+			if p.Status != models.PaymentStatusPending {
+				continue
+			}
+			if time.Since(p.CreatedAt) < paymentAttemptTimeout {
+				return ErrPaymentInProgress
+			}
+			// An attempt whose result was never recorded, e.g. because the
+			// server stopped mid-call, must not block the order forever
+			p.Status = models.PaymentStatusCancelled
+			if err := tx.UpdatePayment(ctx, p); err != nil {
+				return err
+			}
+		}
+
+		// gateway_response is a JSON column, so it starts as a response
+		// without results rather than empty
+		payment = &models.Payment{
+			OrderID:         locked.ID,
+			Method:          req.Method,
+			Amount:          locked.Total,
+			Currency:        locked.Currency,
+			Status:          models.PaymentStatusPending,
+			GatewayResponse: gatewayResponse(u.gateway.Name(), nil),
+		}
+		return tx.CreatePayment(ctx, payment)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results, chargeErr := u.charge(ctx, order.OrderNumber, payment, req.PaymentToken)
+
+	err = u.orderRepo.Transaction(ctx, func(tx repository.OrderRepository) error {
+		locked, err := tx.GetForUpdate(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+		if locked == nil {
+			return ErrOrderNotFound
+		}
+
+		status := services.PaymentResultDeclined
+		if len(results) > 0 {
+			result := results[len(results)-1]
+			payment.TransactionID = result.TransactionID
+			if chargeErr == nil {
+				status = result.Status
+			}
+		}
+		payment.GatewayResponse = gatewayResponse(u.gateway.Name(), results)
+		switch status {
+		case services.PaymentResultCaptured:
+			now := time.Now()
+			payment.Status = models.PaymentStatusCompleted
+			payment.ProcessedAt = &now
+			locked.PaymentStatus = models.OrderPaymentPaid
+		case services.PaymentResultPending:
+			payment.Status = models.PaymentStatusProcessing
+			if locked.PaymentStatus != models.OrderPaymentPaid {
+				locked.PaymentStatus = models.OrderPaymentPending
+			}
+		default:
+			payment.Status = models.PaymentStatusFailed
+			if locked.PaymentStatus != models.OrderPaymentPaid {
+				locked.PaymentStatus = models.OrderPaymentFailed
+			}
+		}
+
+		if err := tx.UpdatePayment(ctx, payment); err != nil {
+			return err
+		}
+		return tx.Update(ctx, locked)
+	})
+	if err != nil {
+		if payment.Status == models.PaymentStatusCompleted {
+			return nil, nil, fmt.Errorf("payment %s captured as %s but not recorded: %w", payment.ResourceID, payment.TransactionID, err)
+		}
+		return nil, nil, err
+	}
+	if chargeErr != nil {
+		return nil, nil, chargeErr
+	}
+
+	order, err = u.orderRepo.GetByID(ctx, order.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if payment.Status == models.PaymentStatusFailed {
+		return order, payment, ErrPaymentDeclined
+	}
+	return order, payment, nil
+}
+
+// charge authorizes the payment through the gateway and captures it once
+// authorized. It returns every gateway result, for storing with the payment,
+// even when a later call fails.
+func (u *paymentUsecase) charge(ctx context.Context, reference string, payment *models.Payment, token string) ([]*services.PaymentResult, error) {
+	result, err := u.gateway.Authorize(ctx, services.AuthorizeRequest{
+		Reference: reference,
+		Method:    payment.Method,
+		Token:     token,
+		Amount:    payment.Amount,
+		Currency:  payment.Currency,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to authorize payment: %w", err)
+	}
+	results := []*services.PaymentResult{result}
+	if result.Status != services.PaymentResultAuthorized {
+		return results, nil
+	}
+
+	result, err = u.gateway.Capture(ctx, result.TransactionID, payment.Amount)
+	if err != nil {
+		return results, fmt.Errorf("failed to capture payment: %w", err)
+	}
+	return append(results, result), nil
+}
+
+func (u *paymentUsecase) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	if u.gateway == nil {
+		return ErrPaymentsUnavailable
+	}
+
+	event, err := u.gateway.VerifyWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	payment, err := u.orderRepo.GetPaymentByTransactionID(ctx, event.TransactionID)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		return ErrPaymentNotFound
+	}
+
+	return u.orderRepo.Transaction(ctx, func(tx repository.OrderRepository) error {
+		order, err := tx.GetForUpdate(ctx, payment.OrderID)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			return ErrOrderNotFound
+		}
+		// Re-read the payment now that its order is locked; webhooks may be
+		// delivered more than once and must only be applied once
+		payment, err := tx.GetPaymentByTransactionID(ctx, event.TransactionID)
+		if err != nil {
+			return err
+		}
+		if payment == nil {
+			return ErrPaymentNotFound
+		}
+
+		switch event.Type {
+		case services.PaymentEventCaptured:
+			if payment.Status == models.PaymentStatusCompleted || payment.Status == models.PaymentStatusRefunded {
+				return nil
+			}
+			now := time.Now()
+			payment.Status = models.PaymentStatusCompleted
+			payment.ProcessedAt = &now
+			order.PaymentStatus = models.OrderPaymentPaid
+		case services.PaymentEventFailed:
+			if payment.Status != models.PaymentStatusPending && payment.Status != models.PaymentStatusProcessing {
+				return nil
+			}
+			payment.Status = models.PaymentStatusFailed
+			if order.PaymentStatus != models.OrderPaymentPaid {
+				order.PaymentStatus = models.OrderPaymentFailed
+			}
+		case services.PaymentEventRefunded:
+			return u.recordGatewayRefund(ctx, tx, order, payment.ID, event)
+		default:
+			// Events we do not act on are acknowledged and ignored
+			return nil
+		}
+
+		if err := tx.UpdatePayment(ctx, payment); err != nil {
+			return err
+		}
+		return tx.Update(ctx, order)
+	})
+}
+
+func (u *paymentUsecase) RefundOrder(ctx context.Context, orderID uint, actorID *uint, req dto.CreateRefundRequest) (*models.Order, *models.Refund, error) {
+	if u.gateway == nil {
+		return nil, nil, ErrPaymentsUnavailable
+	}
+
+	// The refund is committed as pending before the gateway is asked to
+	// return the money, so money is never returned without a record of it
+	// and its amount can't be refunded again while the call is in flight
+	var refund *models.Refund
+	var payment *models.Payment
+	var previousPaymentStatus string
+	err := u.orderRepo.Transaction(ctx, func(tx repository.OrderRepository) error {
+		order, err := tx.GetForUpdate(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			return ErrOrderNotFound
+		}
+		previousPaymentStatus = order.PaymentStatus
+
+		payments, err := tx.ListPayments(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+		for _, p := range payments {
+			if p.ResourceID == req.PaymentID {
+				payment = p
+				break
+			}
+		}
+		if payment == nil {
+			return ErrPaymentNotFound
+		}
+		if payment.Status != models.PaymentStatusCompleted {
+			return ErrPaymentNotRefundable
+		}
+
+		items, itemsAmount, err := buildRefundItems(ctx, tx, order, req.Items)
+		if err != nil {
+			return err
+		}
+		amount := itemsAmount
+		if req.Amount != nil {
+			amount = roundCurrency(*req.Amount)
+		}
+		if amount <= 0 {
+			return ErrRefundAmountRequired
+		}
+		refundable := roundCurrency(payment.Amount - refundedAmount(payment, false))
+		if amount > refundable {
+			return fmt.Errorf("%w: %.2f available", ErrRefundExceedsPayment, refundable)
+		}
+
+		refund = &models.Refund{
+			OrderID:         order.ID,
+			PaymentID:       payment.ID,
+			Amount:          amount,
+			Currency:        payment.Currency,
+			Reason:          req.Reason,
+			Status:          models.RefundStatusPending,
+			GatewayResponse: gatewayResponse(u.gateway.Name(), nil),
+			ActorID:         actorID,
+			Items:           items,
+		}
+		return tx.CreateRefund(ctx, refund)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var refundErr error
+	result, err := u.gateway.Refund(ctx, payment.TransactionID, refund.Amount)
+	switch {
+	case err != nil:
+		refundErr = fmt.Errorf("%w: %v", ErrRefundFailed, err)
+	case result.Status != services.PaymentResultRefunded:
+		refundErr = ErrRefundFailed
+	}
+
+	err = u.orderRepo.Transaction(ctx, func(tx repository.OrderRepository) error {
+		order, err := tx.GetForUpdate(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			return ErrOrderNotFound
+		}
+
+		if result != nil {
+			refund.TransactionID = result.TransactionID
+			refund.GatewayResponse = gatewayResponse(u.gateway.Name(), []*services.PaymentResult{result})
+		}
+		if refundErr != nil {
+			refund.Status = models.RefundStatusFailed
+			return tx.UpdateRefund(ctx, refund)
+		}
+		refund.Status = models.RefundStatusCompleted
+
+		// Stock is only returned while the order still holds its reservation;
+		// cancelled orders have already released everything
+		if req.Restock && len(refund.Items) > 0 && order.InventoryReserved {
+			lines := make(map[uint]models.OrderItem, len(order.OrderItems))
+			for _, line := range order.OrderItems {
+				lines[line.ID] = line
+			}
+			for _, item := range refund.Items {
+				line := lines[item.OrderItemID]
+				if !line.Product.TrackQuantity {
+					continue
+				}
+				if err := tx.ReleaseStock(ctx, line.ProductID, line.VariantID, item.Quantity); err != nil {
+					return err
+				}
+			}
+			refund.Restocked = true
+		}
+		if err := tx.UpdateRefund(ctx, refund); err != nil {
+			return err
+		}
+
+		return settleRefunds(ctx, tx, order, payment.ID)
+	})
+	if err != nil {
+		if refundErr == nil {
+			return nil, nil, fmt.Errorf("refund %s issued as %s but not recorded: %w", refund.ResourceID, refund.TransactionID, err)
+		}
+		return nil, nil, err
+	}
+	if refundErr != nil {
+		return nil, nil, refundErr
+	}
+
+	order, err := u.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The money has been returned either way, so a failure to queue the
+	// email is logged rather than reported as a failed refund
+	if err := u.notifyRefund(ctx, order, refund); err != nil {
+		log.Printf("refund %s of order %s: customer not notified: %v", refund.ResourceID, order.OrderNumber, err)
+	}
+
+	RecordAudit(ctx, AuditChange{
+		Action:     "order.refund",
+		EntityType: "order",
+		EntityID:   order.ResourceID,
+		Before:     AuditSnapshot(map[string]interface{}{"payment_status": previousPaymentStatus}),
+		After:      AuditSnapshot(map[string]interface{}{"payment_status": order.PaymentStatus, "refund": refund}),
+	})
+	return order, refund, nil
+}
+
+// recordGatewayRefund records a refund the gateway reports, adding it to
+// the refunds already recorded against the payment. Refunds issued through
+// RefundOrder are reported too and are recognised by their gateway ID.
+func (u *paymentUsecase) recordGatewayRefund(ctx context.Context, tx repository.OrderRepository, order *models.Order, paymentID uint, event *services.WebhookEvent) error {
+	payments, err := tx.ListPayments(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	var payment *models.Payment
+	for _, p := range payments {
+		if p.ID == paymentID {
+			payment = p
+			break
+		}
+	}
+	if payment == nil {
+		return ErrPaymentNotFound
+	}
+
+	refundID := event.RefundID
+	if refundID == "" {
+		refundID = event.ID
+	}
+	if refundID != "" {
+		for _, refund := range payment.Refunds {
+			if refund.TransactionID == refundID {
+				return nil
+			}
+		}
+	}
+	// A refund being issued may be the one reported; the gateway retries
+	// the event once it has been recorded. Refunds left pending by a call
+	// that never finished don't hold it up for good.
+	for _, refund := range payment.Refunds {
+		if refund.Status == models.RefundStatusPending && time.Since(refund.CreatedAt) < paymentAttemptTimeout {
+			return ErrRefundInProgress
+		}
+	}
+
+	remaining := roundCurrency(payment.Amount - refundedAmount(payment, false))
+	if remaining <= 0 {
+		return nil
+	}
+	// Events without an amount refund whatever is left of the payment. More
+	// than is left can't have been refunded, so larger amounts are capped.
+	amount := roundCurrency(event.Amount)
+	if amount <= 0 {
+		amount = remaining
+	}
+	if amount > remaining {
+		log.Printf("payment %s: gateway reported a refund of %.2f but only %.2f was left to refund; recording %.2f", payment.ResourceID, amount, remaining, remaining)
+		amount = remaining
+	}
+
+	response, err := json.Marshal(map[string]any{
+		"provider": u.gateway.Name(),
+		"event":    event,
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.CreateRefund(ctx, &models.Refund{
+		OrderID:         order.ID,
+		PaymentID:       payment.ID,
+		Amount:          amount,
+		Currency:        payment.Currency,
+		Reason:          "Refunded at the payment provider",
+		Status:          models.RefundStatusCompleted,
+		TransactionID:   refundID,
+		GatewayResponse: string(response),
+	})
+	if err != nil {
+		return err
+	}
+	return settleRefunds(ctx, tx, order, payment.ID)
+}
+
+// settleRefunds marks a payment refunded once its completed refunds cover
+// it and sets the order's payment status from the refunds of all its
+// payments
+func settleRefunds(ctx context.Context, tx repository.OrderRepository, order *models.Order, paymentID uint) error {
+	payments, err := tx.ListPayments(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	for _, p := range payments {
+		if p.ID != paymentID || p.Status == models.PaymentStatusRefunded {
+			continue
+		}
+		if roundCurrency(refundedAmount(p, true)) >= roundCurrency(p.Amount) {
+			p.Status = models.PaymentStatusRefunded
+			if err := tx.UpdatePayment(ctx, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	order.PaymentStatus = orderRefundStatus(payments)
+	return tx.Update(ctx, order)
+}
+
+// notifyRefund tells the customer about a completed refund
+func (u *paymentUsecase) notifyRefund(ctx context.Context, order *models.Order, refund *models.Refund) error {
+	data, err := u.emailService.RefundIssuedEmail(order, refund)
+	if err != nil {
+		return err
+	}
+	if err := u.notifier.Notify(ctx, u.orderRepo, services.RefundNotification(order, refund, data)); err != nil {
+		return err
+	}
+	u.notifier.Flush()
+	return nil
+}
+
+// buildRefundItems resolves the requested lines against the order and prices
+// them at the price paid. Quantities already refunded cannot be refunded again.
+func buildRefundItems(ctx context.Context, tx repository.OrderRepository, order *models.Order, requested []dto.RefundItemRequest) ([]models.RefundItem, float64, error) {
+	if len(requested) == 0 {
+		return nil, 0, nil
+	}
+
+	refunded, err := tx.RefundedQuantities(ctx, order.ID, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var items []models.RefundItem
+	var total float64
+	for _, req := range requested {
+		var line *models.OrderItem
+		for i := range order.OrderItems {
+			if order.OrderItems[i].ResourceID == req.OrderItemID {
+				line = &order.OrderItems[i]
+				break
+			}
+		}
+		if line == nil {
+			return nil, 0, fmt.Errorf("%w: unknown order item %s", ErrInvalidRefundItem, req.OrderItemID)
+		}
+		if req.Quantity <= 0 || refunded[line.ID]+req.Quantity > line.Quantity {
+			return nil, 0, fmt.Errorf("%w: only %d of %s can be refunded", ErrInvalidRefundItem, line.Quantity-refunded[line.ID], line.Product.Name)
+		}
+		refunded[line.ID] += req.Quantity
+
+		amount := roundCurrency(line.Price * float64(req.Quantity))
+		items = append(items, models.RefundItem{
+			OrderItemID: line.ID,
+			Quantity:    req.Quantity,
+			Amount:      amount,
+		})
+		total += amount
+	}
+	return items, roundCurrency(total), nil
+}
+
+// refundedAmount sums the refunds issued against a payment. Failed refunds
+// never count; pending ones do unless completedOnly is set, as their amount
+// is spoken for until the gateway answers.
+func refundedAmount(payment *models.Payment, completedOnly bool) float64 {
+	var total float64
+	for _, refund := range payment.Refunds {
+		if refund.Status == models.RefundStatusFailed {
+			continue
+		}
+		if completedOnly && refund.Status != models.RefundStatusCompleted {
+			continue
+		}
+		total += refund.Amount
+	}
+	return roundCurrency(total)
+}
+
+// orderRefundStatus derives the order payment status once a refund was issued
+func orderRefundStatus(payments []*models.Payment) string {
+	var paid, refunded float64
+	for _, payment := range payments {
+		if payment.Status != models.PaymentStatusCompleted && payment.Status != models.PaymentStatusRefunded {
+			continue
+		}
+		paid += payment.Amount
+		refunded += refundedAmount(payment, true)
+	}
+	if roundCurrency(refunded) >= roundCurrency(paid) {
+		return models.OrderPaymentRefunded
+	}
+	return models.OrderPaymentPartiallyRefunded
+}
+
+// gatewayResponse serializes the raw gateway results for payments.gateway_response
+func gatewayResponse(provider string, results []*services.PaymentResult) string {
+	data, err := json.Marshal(map[string]any{
+		"provider": provider,
+		"results":  results,
+	})
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
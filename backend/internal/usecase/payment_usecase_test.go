@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/services"
+)
+
+func TestHandleWebhookRefund(t *testing.T) {
+	tests := []struct {
+		name              string
+		refunded          float64 // already refunded before the event
+		eventAmount       float64
+		wantRecorded      float64
+		wantPaymentStatus string
+	}{
+		{"partial refund", 0, 40, 40, models.OrderPaymentPartiallyRefunded},
+		{"refund of the rest", 40, 60, 60, models.OrderPaymentRefunded},
+		{"refund without an amount", 40, 0, 60, models.OrderPaymentRefunded},
+		{"refund beyond the payment", 0, 250, 100, models.OrderPaymentRefunded},
+		{"refund beyond what is left", 40, 100, 60, models.OrderPaymentRefunded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryOrderRepository(models.Order{ID: 1, ResourceID: "order-1", UserID: testUserID, Total: 100, PaymentStatus: models.OrderPaymentPaid})
+			payment := &models.Payment{ID: 1, OrderID: 1, Amount: 100, Status: models.PaymentStatusCompleted, TransactionID: "mock_ch_1"}
+			if tt.refunded > 0 {
+				payment.Refunds = []models.Refund{{OrderID: 1, PaymentID: 1, Amount: tt.refunded, Status: models.RefundStatusCompleted, TransactionID: "mock_rf_0"}}
+			}
+			repo.payments[1] = []*models.Payment{payment}
+
+			gateway := services.NewMockPaymentGateway("test-webhook-secret")
+			payload, err := json.Marshal(services.WebhookEvent{
+				ID:            "evt_1",
+				Type:          services.PaymentEventRefunded,
+				TransactionID: payment.TransactionID,
+				Amount:        tt.eventAmount,
+				RefundID:      "mock_rf_1",
+			})
+			if err != nil {
+				t.Fatalf("marshal event: %v", err)
+			}
+
+			payments := NewPaymentUsecase(repo, gateway, nil, nopNotifier{})
+			if err := payments.HandleWebhook(context.Background(), payload, gateway.Sign(payload)); err != nil {
+				t.Fatalf("HandleWebhook: %v", err)
+			}
+
+			last := payment.Refunds[len(payment.Refunds)-1]
+			if last.TransactionID != "mock_rf_1" || last.Amount != tt.wantRecorded {
+				t.Errorf("recorded refund %s of %.2f, want mock_rf_1 of %.2f", last.TransactionID, last.Amount, tt.wantRecorded)
+			}
+			if total := refundedAmount(payment, true); total > payment.Amount {
+				t.Errorf("refunded %.2f of a %.2f payment", total, payment.Amount)
+			}
+			if status := repo.orders[1].PaymentStatus; status != tt.wantPaymentStatus {
+				t.Errorf("order payment status = %q, want %q", status, tt.wantPaymentStatus)
+			}
+		})
+	}
+}
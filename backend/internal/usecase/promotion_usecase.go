@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/repository"
+)
+
+var (
+	ErrPromotionNotFound = errors.New("promotion not found")
+	ErrInvalidPromotion  = errors.New("invalid promotion")
+)
+
+type PromotionUsecase interface {
+	List(ctx context.Context, page, limit int, filters map[string]interface{}) ([]*models.Promotion, int64, error)
+	// ListActive returns the promotions that should be shown right now,
+	// optionally narrowed to a type and position
+	ListActive(ctx context.Context, promotionType, position string) ([]*models.Promotion, error)
+	GetByResourceID(ctx context.Context, resourceID string) (*models.Promotion, error)
+	Create(ctx context.Context, req dto.CreatePromotionRequest) (*models.Promotion, error)
+	Update(ctx context.Context, resourceID string, req dto.UpdatePromotionRequest) (*models.Promotion, error)
+	Delete(ctx context.Context, resourceID string) error
+}
+
+type promotionUsecase struct {
+	promotionRepo repository.PromotionRepository
+}
+
+func NewPromotionUsecase(promotionRepo repository.PromotionRepository) PromotionUsecase {
+	return &promotionUsecase{
+		promotionRepo: promotionRepo,
+	}
+}
+
+func (u *promotionUsecase) List(ctx context.Context, page, limit int, filters map[string]interface{}) ([]*models.Promotion, int64, error) {
+	offset := (page - 1) * limit
+
+	promotions, err := u.promotionRepo.List(ctx, limit, offset, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := u.promotionRepo.Count(ctx, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return promotions, total, nil
+}
+
+func (u *promotionUsecase) ListActive(ctx context.Context, promotionType, position string) ([]*models.Promotion, error) {
+	return u.promotionRepo.ListActive(ctx, promotionType, position, time.Now())
+}
+
+func (u *promotionUsecase) GetByResourceID(ctx context.Context, resourceID string) (*models.Promotion, error) {
+	promotion, err := u.promotionRepo.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if promotion == nil {
+		return nil, ErrPromotionNotFound
+	}
+	return promotion, nil
+}
+
+func (u *promotionUsecase) Create(ctx context.Context, req dto.CreatePromotionRequest) (*models.Promotion, error) {
+	promotion := &models.Promotion{
+		Title:       req.Title,
+		Description: req.Description,
+		Image:       req.Image,
+		LinkURL:     req.LinkURL,
+		Type:        req.Type,
+		Position:    req.Position,
+		IsActive:    req.IsActive,
+		StartsAt:    req.StartsAt,
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := validatePromotion(promotion); err != nil {
+		return nil, err
+	}
+
+	if err := u.promotionRepo.Create(ctx, promotion); err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+func (u *promotionUsecase) Update(ctx context.Context, resourceID string, req dto.UpdatePromotionRequest) (*models.Promotion, error) {
+	promotion, err := u.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		promotion.Title = *req.Title
+	}
+	if req.Description != nil {
+		promotion.Description = *req.Description
+	}
+	if req.Image != nil {
+		promotion.Image = *req.Image
+	}
+	if req.LinkURL != nil {
+		promotion.LinkURL = *req.LinkURL
+	}
+	if req.Type != nil {
+		promotion.Type = *req.Type
+	}
+	if req.Position != nil {
+		promotion.Position = *req.Position
+	}
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+	if req.StartsAt != nil {
+		promotion.StartsAt = req.StartsAt
+	}
+	if req.ExpiresAt != nil {
+		promotion.ExpiresAt = req.ExpiresAt
+	}
+
+	if err := validatePromotion(promotion); err != nil {
+		return nil, err
+	}
+	if err := u.promotionRepo.Update(ctx, promotion); err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+func (u *promotionUsecase) Delete(ctx context.Context, resourceID string) error {
+	promotion, err := u.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+	return u.promotionRepo.Delete(ctx, promotion.ID)
+}
+
+func validatePromotion(promotion *models.Promotion) error {
+	if promotion.StartsAt != nil && promotion.ExpiresAt != nil && !promotion.ExpiresAt.After(*promotion.StartsAt) {
+		return fmt.Errorf("%w: expires_at must be after starts_at", ErrInvalidPromotion)
+	}
+	return nil
+}
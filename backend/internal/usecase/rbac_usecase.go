@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRoleNotFound        = errors.New("role not found")
+	ErrRoleAssignForbidden = errors.New("not allowed to change this role")
+	ErrLastSuperAdmin      = errors.New("cannot remove the last super admin")
+)
+
+// RBACUsecase resolves what staff users may do in the admin API. Permissions
+// are read from the database on every check, so role changes take effect on
+// the next request rather than when the user's access token is reissued.
+type RBACUsecase interface {
+	// HasPermission reports whether the user holds permission through any of
+	// their roles
+	HasPermission(ctx context.Context, userID uint, permission string) (bool, error)
+	ListRoles(ctx context.Context) ([]*models.Role, error)
+	GetUserRoles(ctx context.Context, userID uint) ([]*models.Role, error)
+	// SetUserRoles replaces the roles of a user. Only super admins may grant
+	// or revoke the super admin role, and the last super admin cannot lose it.
+	SetUserRoles(ctx context.Context, actorID uint, user *models.User, roleNames []string) ([]*models.Role, error)
+	// SyncDefaultRoles creates the built-in permissions and roles that are
+	// missing and grants the built-in roles their default permissions
+	SyncDefaultRoles(ctx context.Context) error
+}
+
+type rbacUsecase struct {
+	roleRepo repository.RoleRepository
+}
+
+func NewRBACUsecase(roleRepo repository.RoleRepository) RBACUsecase {
+	return &rbacUsecase{
+		roleRepo: roleRepo,
+	}
+}
+
+func (u *rbacUsecase) HasPermission(ctx context.Context, userID uint, permission string) (bool, error) {
+	return u.roleRepo.HasPermission(ctx, userID, permission)
+}
+
+func (u *rbacUsecase) ListRoles(ctx context.Context) ([]*models.Role, error) {
+	return u.roleRepo.List(ctx)
+}
+
+func (u *rbacUsecase) GetUserRoles(ctx context.Context, userID uint) ([]*models.Role, error) {
+	return u.roleRepo.GetUserRoles(ctx, userID)
+}
+
+func (u *rbacUsecase) SetUserRoles(ctx context.Context, actorID uint, user *models.User, roleNames []string) ([]*models.Role, error) {
+	names := make([]string, 0, len(roleNames))
+	seen := make(map[string]bool, len(roleNames))
+	for _, name := range roleNames {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	roles, err := u.roleRepo.GetByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) != len(names) {
+		found := make(map[string]bool, len(roles))
+		for _, role := range roles {
+			found[role.Name] = true
+		}
+		for _, name := range names {
+			if !found[name] {
+				return nil, fmt.Errorf("%w: %s", ErrRoleNotFound, name)
+			}
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+
+	var previous []string
+	err = u.roleRepo.Transaction(ctx, func(tx repository.RoleRepository) error {
+		current, err := tx.GetUserRoles(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		previous = roleNameList(current)
+		wasSuperAdmin := hasRole(current, models.RoleSuperAdmin)
+		isSuperAdmin := hasRole(roles, models.RoleSuperAdmin)
+
+		if wasSuperAdmin || isSuperAdmin {
+			allowed, err := tx.HasPermission(ctx, actorID, models.PermissionAll)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return fmt.Errorf("%w: %s", ErrRoleAssignForbidden, models.RoleSuperAdmin)
+			}
+		}
+		if wasSuperAdmin && !isSuperAdmin {
+			count, err := tx.CountUsersWithRole(ctx, models.RoleSuperAdmin)
+			if err != nil {
+				return err
+			}
+			if count <= 1 {
+				return ErrLastSuperAdmin
+			}
+		}
+
+		return tx.SetUserRoles(ctx, user.ID, roles)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	RecordAudit(ctx, AuditChange{
+		Action:     "user.roles_update",
+		EntityType: "user",
+		EntityID:   user.ResourceID,
+		Before:     AuditSnapshot(map[string]interface{}{"roles": previous, "is_admin": len(previous) > 0}),
+		After:      AuditSnapshot(map[string]interface{}{"roles": roleNameList(roles), "is_admin": len(roles) > 0}),
+	})
+	return roles, nil
+}
+
+func (u *rbacUsecase) SyncDefaultRoles(ctx context.Context) error {
+	return u.roleRepo.Transaction(ctx, func(tx repository.RoleRepository) error {
+		permissions, err := tx.EnsurePermissions(ctx, models.DefaultPermissions)
+		if err != nil {
+			return err
+		}
+
+		for _, defaults := range models.DefaultRoles {
+			role, err := tx.GetByName(ctx, defaults.Name)
+			if err != nil {
+				return err
+			}
+			if role == nil {
+				role = &models.Role{
+					ResourceID:  uuid.New().String(),
+					Name:        defaults.Name,
+					Description: defaults.Description,
+				}
+				if err := tx.Create(ctx, role); err != nil {
+					return err
+				}
+			}
+
+			granted := make(map[string]bool, len(role.Permissions))
+			for _, permission := range role.Permissions {
+				granted[permission.Name] = true
+			}
+			var missing []models.Permission
+			for _, name := range defaults.Permissions {
+				if !granted[name] {
+					missing = append(missing, permissions[name])
+				}
+			}
+			if err := tx.GrantPermissions(ctx, role.ID, missing); err != nil {
+				return fmt.Errorf("grant %s permissions: %w", defaults.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+func roleNameList(roles []*models.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+func hasRole(roles []*models.Role, name string) bool {
+	for _, role := range roles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
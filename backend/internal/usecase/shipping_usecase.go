@@ -0,0 +1,451 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/repository"
+)
+
+var (
+	ErrShippingZoneNotFound      = errors.New("shipping zone not found")
+	ErrShippingMethodNotFound    = errors.New("shipping method not found")
+	ErrInvalidShippingMethod     = errors.New("invalid shipping method")
+	ErrShippingAddressRequired   = errors.New("a shipping address is required")
+	ErrShippingMethodRequired    = errors.New("a shipping method is required")
+	ErrShippingMethodUnavailable = errors.New("shipping method is not available for this order")
+)
+
+// dimensionalWeightDivisor converts a package volume in cm³ to a billable
+// weight in kg, as carriers do for light but bulky parcels
+const dimensionalWeightDivisor = 5000
+
+// ShippingQuote is the price of one shipping method for a shipment
+type ShippingQuote struct {
+	Zone   *models.ShippingZone
+	Method *models.ShippingMethod
+	Cost   float64
+}
+
+// ShippingQuotes lists the methods available for a cart or order
+type ShippingQuotes struct {
+	RequiresShipping bool
+	Weight           float64 // billable weight in kg
+	Options          []ShippingQuote
+}
+
+type ShippingUsecase interface {
+	ListZones(ctx context.Context) ([]*models.ShippingZone, error)
+	GetZone(ctx context.Context, resourceID string) (*models.ShippingZone, error)
+	CreateZone(ctx context.Context, req dto.CreateShippingZoneRequest) (*models.ShippingZone, error)
+	UpdateZone(ctx context.Context, resourceID string, req dto.UpdateShippingZoneRequest) (*models.ShippingZone, error)
+	DeleteZone(ctx context.Context, resourceID string) error
+	CreateMethod(ctx context.Context, zoneResourceID string, req dto.CreateShippingMethodRequest) (*models.ShippingMethod, error)
+	UpdateMethod(ctx context.Context, resourceID string, req dto.UpdateShippingMethodRequest) (*models.ShippingMethod, error)
+	DeleteMethod(ctx context.Context, resourceID string) error
+
+	// QuoteCart prices the available methods for the user's cart shipped to
+	// one of their addresses, or their default address when addressID is empty
+	QuoteCart(ctx context.Context, userID uint, addressID string) (*ShippingQuotes, error)
+	// Quote prices the available methods for order lines shipped to address
+	Quote(ctx context.Context, address *models.Address, items []models.OrderItem) (*ShippingQuotes, error)
+	// Rate prices the chosen method for order lines. Orders without shippable
+	// lines get an empty quote and need neither address nor method.
+	Rate(ctx context.Context, address *models.Address, items []models.OrderItem, methodResourceID string) (*ShippingQuote, error)
+}
+
+type shippingUsecase struct {
+	shippingRepo repository.ShippingRepository
+	orderRepo    repository.OrderRepository
+	addressRepo  repository.AddressRepository
+}
+
+func NewShippingUsecase(shippingRepo repository.ShippingRepository, orderRepo repository.OrderRepository, addressRepo repository.AddressRepository) ShippingUsecase {
+	return &shippingUsecase{
+		shippingRepo: shippingRepo,
+		orderRepo:    orderRepo,
+		addressRepo:  addressRepo,
+	}
+}
+
+func (u *shippingUsecase) ListZones(ctx context.Context) ([]*models.ShippingZone, error) {
+	return u.shippingRepo.ListZones(ctx)
+}
+
+func (u *shippingUsecase) GetZone(ctx context.Context, resourceID string) (*models.ShippingZone, error) {
+	zone, err := u.shippingRepo.GetZoneByResourceID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if zone == nil {
+		return nil, ErrShippingZoneNotFound
+	}
+	return zone, nil
+}
+
+func (u *shippingUsecase) CreateZone(ctx context.Context, req dto.CreateShippingZoneRequest) (*models.ShippingZone, error) {
+	zone := &models.ShippingZone{
+		Name:     req.Name,
+		Regions:  buildShippingRegions(req.Regions),
+		IsActive: true,
+	}
+	if req.IsActive != nil {
+		zone.IsActive = *req.IsActive
+	}
+
+	if err := u.shippingRepo.CreateZone(ctx, zone); err != nil {
+		return nil, err
+	}
+	return u.GetZone(ctx, zone.ResourceID)
+}
+
+func (u *shippingUsecase) UpdateZone(ctx context.Context, resourceID string, req dto.UpdateShippingZoneRequest) (*models.ShippingZone, error) {
+	zone, err := u.GetZone(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		zone.Name = *req.Name
+	}
+	if req.IsActive != nil {
+		zone.IsActive = *req.IsActive
+	}
+	replaceRegions := req.Regions != nil
+	if replaceRegions {
+		zone.Regions = buildShippingRegions(req.Regions)
+	}
+
+	if err := u.shippingRepo.UpdateZone(ctx, zone, replaceRegions); err != nil {
+		return nil, err
+	}
+	return u.GetZone(ctx, resourceID)
+}
+
+func (u *shippingUsecase) DeleteZone(ctx context.Context, resourceID string) error {
+	zone, err := u.GetZone(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+	return u.shippingRepo.DeleteZone(ctx, zone.ID)
+}
+
+func (u *shippingUsecase) CreateMethod(ctx context.Context, zoneResourceID string, req dto.CreateShippingMethodRequest) (*models.ShippingMethod, error) {
+	zone, err := u.GetZone(ctx, zoneResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	method := &models.ShippingMethod{
+		ZoneID:        zone.ID,
+		Name:          req.Name,
+		Type:          req.Type,
+		Rate:          req.Rate,
+		FreeThreshold: req.FreeThreshold,
+		Tiers:         buildShippingRateTiers(req.Tiers),
+		EstimatedDays: req.EstimatedDays,
+		SortOrder:     req.SortOrder,
+		IsActive:      true,
+	}
+	if req.IsActive != nil {
+		method.IsActive = *req.IsActive
+	}
+	if err := validateShippingMethod(method); err != nil {
+		return nil, err
+	}
+
+	if err := u.shippingRepo.CreateMethod(ctx, method); err != nil {
+		return nil, err
+	}
+	return method, nil
+}
+
+func (u *shippingUsecase) UpdateMethod(ctx context.Context, resourceID string, req dto.UpdateShippingMethodRequest) (*models.ShippingMethod, error) {
+	method, err := u.getMethod(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		method.Name = *req.Name
+	}
+	if req.Type != nil {
+		method.Type = *req.Type
+	}
+	if req.Rate != nil {
+		method.Rate = *req.Rate
+	}
+	if req.FreeThreshold != nil {
+		method.FreeThreshold = req.FreeThreshold
+	}
+	if req.EstimatedDays != nil {
+		method.EstimatedDays = *req.EstimatedDays
+	}
+	if req.SortOrder != nil {
+		method.SortOrder = *req.SortOrder
+	}
+	if req.IsActive != nil {
+		method.IsActive = *req.IsActive
+	}
+	replaceTiers := req.Tiers != nil
+	if replaceTiers {
+		method.Tiers = buildShippingRateTiers(req.Tiers)
+	}
+
+	if err := validateShippingMethod(method); err != nil {
+		return nil, err
+	}
+	if err := u.shippingRepo.UpdateMethod(ctx, method, replaceTiers); err != nil {
+		return nil, err
+	}
+	return u.getMethod(ctx, resourceID)
+}
+
+func (u *shippingUsecase) DeleteMethod(ctx context.Context, resourceID string) error {
+	method, err := u.getMethod(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+	return u.shippingRepo.DeleteMethod(ctx, method.ID)
+}
+
+func (u *shippingUsecase) QuoteCart(ctx context.Context, userID uint, addressID string) (*ShippingQuotes, error) {
+	address, err := resolveShippingAddress(ctx, u.addressRepo, userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := u.orderRepo.GetCartForCheckout(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil || len(cart.Items) == 0 {
+		return nil, ErrCartEmpty
+	}
+
+	items := make([]models.OrderItem, 0, len(cart.Items))
+	for _, cartItem := range cart.Items {
+		item, err := buildOrderItem(cartItem)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return u.Quote(ctx, address, items)
+}
+
+func (u *shippingUsecase) Quote(ctx context.Context, address *models.Address, items []models.OrderItem) (*ShippingQuotes, error) {
+	weight, subtotal, requiresShipping := measureShipment(items)
+	quotes := &ShippingQuotes{
+		RequiresShipping: requiresShipping,
+		Weight:           weight,
+		Options:          []ShippingQuote{},
+	}
+	if !requiresShipping || address == nil {
+		return quotes, nil
+	}
+
+	zones, err := u.shippingRepo.ListZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	zone := matchShippingZone(zones, address)
+	if zone == nil {
+		return quotes, nil
+	}
+
+	for i := range zone.Methods {
+		method := &zone.Methods[i]
+		if !method.IsActive {
+			continue
+		}
+		cost, ok := rateShippingMethod(method, weight, subtotal)
+		if !ok {
+			continue
+		}
+		quotes.Options = append(quotes.Options, ShippingQuote{
+			Zone:   zone,
+			Method: method,
+			Cost:   cost,
+		})
+	}
+	return quotes, nil
+}
+
+func (u *shippingUsecase) Rate(ctx context.Context, address *models.Address, items []models.OrderItem, methodResourceID string) (*ShippingQuote, error) {
+	if _, _, requiresShipping := measureShipment(items); !requiresShipping {
+		return &ShippingQuote{}, nil
+	}
+	if address == nil {
+		return nil, ErrShippingAddressRequired
+	}
+	if methodResourceID == "" {
+		return nil, ErrShippingMethodRequired
+	}
+
+	quotes, err := u.Quote(ctx, address, items)
+	if err != nil {
+		return nil, err
+	}
+	for i := range quotes.Options {
+		if quotes.Options[i].Method.ResourceID == methodResourceID {
+			return &quotes.Options[i], nil
+		}
+	}
+	return nil, ErrShippingMethodUnavailable
+}
+
+func (u *shippingUsecase) getMethod(ctx context.Context, resourceID string) (*models.ShippingMethod, error) {
+	method, err := u.shippingRepo.GetMethodByResourceID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if method == nil {
+		return nil, ErrShippingMethodNotFound
+	}
+	return method, nil
+}
+
+// measureShipment returns the billable weight and merchandise value of the
+// lines that need shipping. Each unit is billed at the greater of its actual
+// and dimensional weight; digital products and products that don't require
+// shipping are skipped.
+func measureShipment(items []models.OrderItem) (weight, subtotal float64, requiresShipping bool) {
+	for _, item := range items {
+		product := item.Product
+		if product.IsDigital || !product.RequiresShipping {
+			continue
+		}
+		requiresShipping = true
+
+		unitWeight := product.Weight
+		if item.Variant != nil && item.Variant.Weight > 0 {
+			unitWeight = item.Variant.Weight
+		}
+		dimensional := product.Length * product.Width * product.Height / dimensionalWeightDivisor
+		weight += math.Max(unitWeight, dimensional) * float64(item.Quantity)
+		subtotal += item.Total
+	}
+	return math.Round(weight*100) / 100, roundCurrency(subtotal), requiresShipping
+}
+
+// matchShippingZone picks the active zone for a destination, preferring a
+// region naming the state over one naming the whole country, and either over
+// the "*" catch-all
+func matchShippingZone(zones []*models.ShippingZone, address *models.Address) *models.ShippingZone {
+	country := normalizeRegion(address.Country)
+	state := normalizeRegion(address.State)
+
+	var best *models.ShippingZone
+	bestScore := 0
+	for _, zone := range zones {
+		if !zone.IsActive {
+			continue
+		}
+		for _, region := range zone.Regions {
+			score := 0
+			switch {
+			case region.Country == models.ShippingRegionAnyCountry:
+				score = 1
+			case region.Country != country:
+				continue
+			case region.State == "":
+				score = 2
+			case region.State == state:
+				score = 3
+			}
+			if score > bestScore {
+				best, bestScore = zone, score
+			}
+		}
+	}
+	return best
+}
+
+// rateShippingMethod prices a method for a shipment; ok is false when the
+// method cannot carry it, e.g. no weight tier covers the weight
+func rateShippingMethod(method *models.ShippingMethod, weight, subtotal float64) (float64, bool) {
+	switch method.Type {
+	case models.ShippingMethodFlatRate:
+		return roundCurrency(method.Rate), true
+	case models.ShippingMethodFreeOver:
+		if method.FreeThreshold != nil && subtotal >= *method.FreeThreshold {
+			return 0, true
+		}
+		return roundCurrency(method.Rate), true
+	case models.ShippingMethodWeightBased:
+		for _, tier := range method.Tiers {
+			if weight >= tier.MinWeight && (tier.MaxWeight == nil || weight < *tier.MaxWeight) {
+				return roundCurrency(tier.Rate), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func validateShippingMethod(method *models.ShippingMethod) error {
+	switch method.Type {
+	case models.ShippingMethodFlatRate:
+	case models.ShippingMethodFreeOver:
+		if method.FreeThreshold == nil {
+			return fmt.Errorf("%w: free_threshold is required for free_over methods", ErrInvalidShippingMethod)
+		}
+	case models.ShippingMethodWeightBased:
+		if len(method.Tiers) == 0 {
+			return fmt.Errorf("%w: weight_based methods need at least one tier", ErrInvalidShippingMethod)
+		}
+		for _, tier := range method.Tiers {
+			if tier.MaxWeight != nil && *tier.MaxWeight <= tier.MinWeight {
+				return fmt.Errorf("%w: tier max_weight must be greater than min_weight", ErrInvalidShippingMethod)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown method type", ErrInvalidShippingMethod)
+	}
+	return nil
+}
+
+func buildShippingRegions(regions []dto.ShippingRegionRequest) []models.ShippingZoneRegion {
+	result := make([]models.ShippingZoneRegion, 0, len(regions))
+	for _, region := range regions {
+		result = append(result, models.ShippingZoneRegion{
+			Country: normalizeRegion(region.Country),
+			State:   normalizeRegion(region.State),
+		})
+	}
+	return result
+}
+
+func buildShippingRateTiers(tiers []dto.ShippingRateTierRequest) []models.ShippingRateTier {
+	result := make([]models.ShippingRateTier, 0, len(tiers))
+	for _, tier := range tiers {
+		result = append(result, models.ShippingRateTier{
+			MinWeight: tier.MinWeight,
+			MaxWeight: tier.MaxWeight,
+			Rate:      tier.Rate,
+		})
+	}
+	return result
+}
+
+// resolveShippingAddress returns the given address of the user, or their
+// default shipping address when none is given. A user without a default
+// shipping address gets nil.
+func resolveShippingAddress(ctx context.Context, addressRepo repository.AddressRepository, userID uint, resourceID string) (*models.Address, error) {
+	if resourceID == "" {
+		return addressRepo.GetDefault(ctx, userID, models.AddressTypeShipping)
+	}
+
+	address, err := addressRepo.GetForUser(ctx, userID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if address == nil {
+		return nil, ErrAddressNotFound
+	}
+	return address, nil
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/repository"
+)
+
+// memoryShippingRepository serves a fixed set of zones
+type memoryShippingRepository struct {
+	repository.ShippingRepository
+	zones []*models.ShippingZone
+}
+
+func (r *memoryShippingRepository) ListZones(ctx context.Context) ([]*models.ShippingZone, error) {
+	return r.zones, nil
+}
+
+// shippableLine is one unit of a product weighing weight kg
+func shippableLine(weight, total float64) models.OrderItem {
+	return models.OrderItem{Quantity: 1, Price: total, Total: total, Product: models.Product{Weight: weight, RequiresShipping: true}}
+}
+
+func TestRateShippingMethod(t *testing.T) {
+	freeOver := func(threshold *float64) *models.ShippingMethod {
+		return &models.ShippingMethod{Type: models.ShippingMethodFreeOver, Rate: 7.5, FreeThreshold: threshold}
+	}
+	byWeight := &models.ShippingMethod{
+		Type: models.ShippingMethodWeightBased,
+		Tiers: []models.ShippingRateTier{
+			{MinWeight: 0, MaxWeight: ptr(2.0), Rate: 5},
+			{MinWeight: 2, MaxWeight: ptr(10.0), Rate: 12},
+		},
+	}
+	openEnded := &models.ShippingMethod{
+		Type: models.ShippingMethodWeightBased,
+		Tiers: []models.ShippingRateTier{
+			{MinWeight: 1, MaxWeight: ptr(5.0), Rate: 8},
+			{MinWeight: 5, Rate: 20},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		method   *models.ShippingMethod
+		weight   float64
+		subtotal float64
+		wantCost float64
+		wantOK   bool
+	}{
+		{"flat rate", &models.ShippingMethod{Type: models.ShippingMethodFlatRate, Rate: 4.99}, 30, 10, 4.99, true},
+
+		{"below the free shipping threshold", freeOver(ptr(50.0)), 1, 49.99, 7.5, true},
+		{"at the free shipping threshold", freeOver(ptr(50.0)), 1, 50, 0, true},
+		{"above the free shipping threshold", freeOver(ptr(50.0)), 1, 50.01, 0, true},
+		{"no free shipping threshold", freeOver(nil), 1, 1000, 7.5, true},
+
+		{"first tier", byWeight, 0.5, 10, 5, true},
+		{"tier minimum is inclusive", byWeight, 2, 10, 12, true},
+		{"tier maximum is exclusive", byWeight, 9.99, 10, 12, true},
+		{"over the last tier", byWeight, 10, 10, 0, false},
+		{"under the first tier", openEnded, 0.5, 10, 0, false},
+		{"open-ended last tier", openEnded, 500, 10, 20, true},
+
+		{"no tiers", &models.ShippingMethod{Type: models.ShippingMethodWeightBased}, 1, 10, 0, false},
+		{"unknown type", &models.ShippingMethod{Type: "pigeon", Rate: 1}, 1, 10, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost, ok := rateShippingMethod(tt.method, tt.weight, tt.subtotal)
+			if ok != tt.wantOK || cost != tt.wantCost {
+				t.Errorf("rateShippingMethod = %v, %v; want %v, %v", cost, ok, tt.wantCost, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestShippingQuoteWithoutMatchingTier(t *testing.T) {
+	zone := &models.ShippingZone{
+		Name:     "Domestic",
+		IsActive: true,
+		Regions:  []models.ShippingZoneRegion{{Country: "US"}},
+		Methods: []models.ShippingMethod{
+			{
+				ResourceID: "parcel",
+				Type:       models.ShippingMethodWeightBased,
+				IsActive:   true,
+				Tiers:      []models.ShippingRateTier{{MinWeight: 0, MaxWeight: ptr(20.0), Rate: 9}},
+			},
+			{
+				ResourceID:    "freight",
+				Type:          models.ShippingMethodFreeOver,
+				Rate:          60,
+				FreeThreshold: ptr(1000.0),
+				IsActive:      true,
+			},
+		},
+	}
+	uc := NewShippingUsecase(&memoryShippingRepository{zones: []*models.ShippingZone{zone}}, nil, nil)
+	ctx := context.Background()
+	address := &models.Address{Country: "us", State: "CA"}
+
+	tests := []struct {
+		name        string
+		items       []models.OrderItem
+		wantMethods []string
+		wantCosts   []float64
+	}{
+		{"within the parcel tiers", []models.OrderItem{shippableLine(5, 200)}, []string{"parcel", "freight"}, []float64{9, 60}},
+		{"too heavy for a parcel", []models.OrderItem{shippableLine(25, 200)}, []string{"freight"}, []float64{60}},
+		{"too heavy for a parcel, free freight", []models.OrderItem{shippableLine(25, 1000)}, []string{"freight"}, []float64{0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quotes, err := uc.Quote(ctx, address, tt.items)
+			if err != nil {
+				t.Fatalf("Quote: %v", err)
+			}
+			if len(quotes.Options) != len(tt.wantMethods) {
+				t.Fatalf("got %d options, want %d", len(quotes.Options), len(tt.wantMethods))
+			}
+			for i, option := range quotes.Options {
+				if option.Method.ResourceID != tt.wantMethods[i] || option.Cost != tt.wantCosts[i] {
+					t.Errorf("option %d = %s at %v, want %s at %v", i, option.Method.ResourceID, option.Cost, tt.wantMethods[i], tt.wantCosts[i])
+				}
+			}
+		})
+	}
+
+	t.Run("rating a method without a matching tier", func(t *testing.T) {
+		_, err := uc.Rate(ctx, address, []models.OrderItem{shippableLine(25, 200)}, "parcel")
+		if !errors.Is(err, ErrShippingMethodUnavailable) {
+			t.Errorf("Rate error = %v, want %v", err, ErrShippingMethodUnavailable)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/repository"
+	"electronics-store/internal/services"
+)
+
+var ErrProductInStock = errors.New("product is in stock")
+
+// stockAlertBatchSize is how many alerts are looked up at a time
+const stockAlertBatchSize = 100
+
+type StockAlertUsecase interface {
+	// Subscribe asks for an email once an out of stock product can be
+	// ordered again
+	Subscribe(ctx context.Context, userID uint, productResourceID string) error
+	Unsubscribe(ctx context.Context, userID uint, productResourceID string) error
+	IsSubscribed(ctx context.Context, userID uint, productResourceID string) (bool, error)
+	// NotifyRestocked notifies the user of every alert whose product can be
+	// ordered again, removes those alerts and returns how many it notified
+	NotifyRestocked(ctx context.Context) (int, error)
+}
+
+type stockAlertUsecase struct {
+	stockAlertRepo repository.StockAlertRepository
+	productRepo    repository.ProductRepository
+	emailService   *services.EmailService
+	notifier       services.Notifier
+}
+
+func NewStockAlertUsecase(stockAlertRepo repository.StockAlertRepository, productRepo repository.ProductRepository, emailService *services.EmailService, notifier services.Notifier) StockAlertUsecase {
+	return &stockAlertUsecase{
+		stockAlertRepo: stockAlertRepo,
+		productRepo:    productRepo,
+		emailService:   emailService,
+		notifier:       notifier,
+	}
+}
+
+func (u *stockAlertUsecase) Subscribe(ctx context.Context, userID uint, productResourceID string) error {
+	product, err := u.getProduct(ctx, productResourceID)
+	if err != nil {
+		return err
+	}
+	if isOrderable(product) {
+		return ErrProductInStock
+	}
+	return u.stockAlertRepo.Create(ctx, &models.StockAlert{
+		UserID:    userID,
+		ProductID: product.ID,
+	})
+}
+
+func (u *stockAlertUsecase) Unsubscribe(ctx context.Context, userID uint, productResourceID string) error {
+	product, err := u.getProduct(ctx, productResourceID)
+	if err != nil {
+		return err
+	}
+	return u.stockAlertRepo.Delete(ctx, userID, product.ID)
+}
+
+func (u *stockAlertUsecase) IsSubscribed(ctx context.Context, userID uint, productResourceID string) (bool, error) {
+	product, err := u.getProduct(ctx, productResourceID)
+	if err != nil {
+		return false, err
+	}
+	return u.stockAlertRepo.Exists(ctx, userID, product.ID)
+}
+
+func (u *stockAlertUsecase) NotifyRestocked(ctx context.Context) (int, error) {
+	queued := 0
+	defer func() {
+		if queued > 0 {
+			u.notifier.Flush()
+		}
+	}()
+
+	var afterID uint
+	for {
+		alerts, err := u.stockAlertRepo.ListOrderable(ctx, afterID, stockAlertBatchSize)
+		if err != nil {
+			return queued, err
+		}
+		for _, alert := range alerts {
+			if err := ctx.Err(); err != nil {
+				return queued, err
+			}
+			data, err := u.emailService.BackInStockEmail(&alert.User, &alert.Product)
+			if err != nil {
+				return queued, err
+			}
+			sent := false
+			err = u.stockAlertRepo.Transaction(ctx, func(tx repository.StockAlertRepository) error {
+				// Whoever removes the alert sends the notification, so a
+				// concurrent run can't send it twice
+				deleted, err := tx.DeleteByID(ctx, alert.ID)
+				if err != nil || !deleted {
+					return err
+				}
+				sent = true
+				return u.notifier.Notify(ctx, tx, services.BackInStockNotification(alert.UserID, &alert.Product, data))
+			})
+			if err != nil {
+				return queued, err
+			}
+			if sent {
+				queued++
+			}
+		}
+		if len(alerts) < stockAlertBatchSize {
+			return queued, nil
+		}
+		afterID = alerts[len(alerts)-1].ID
+	}
+}
+
+func (u *stockAlertUsecase) getProduct(ctx context.Context, resourceID string) (*models.Product, error) {
+	product, err := u.productRepo.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil || !product.IsActive {
+		return nil, ErrProductNotFound
+	}
+	return product, nil
+}
+
+// isOrderable reports whether a product can be added to an order right now
+func isOrderable(product *models.Product) bool {
+	return !product.TrackQuantity || product.AllowBackorder || product.StockQuantity > 0
+}
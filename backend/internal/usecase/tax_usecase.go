@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/repository"
+)
+
+var (
+	ErrTaxRuleNotFound = errors.New("tax rule not found")
+	ErrInvalidTaxRule  = errors.New("invalid tax rule")
+)
+
+// TaxCalculation is the tax owed on a set of order lines
+type TaxCalculation struct {
+	ItemTaxes []float64         // tax per line, in the order the lines were given
+	Taxes     []models.OrderTax // one entry per applied rule
+	Total     float64           // all tax, including tax contained in inclusive prices
+	Exclusive float64           // tax charged on top of the line prices
+}
+
+type TaxUsecase interface {
+	List(ctx context.Context, page, limit int, filters map[string]interface{}) ([]*models.TaxRule, int64, error)
+	GetByResourceID(ctx context.Context, resourceID string) (*models.TaxRule, error)
+	Create(ctx context.Context, req dto.CreateTaxRuleRequest) (*models.TaxRule, error)
+	Update(ctx context.Context, resourceID string, req dto.UpdateTaxRuleRequest) (*models.TaxRule, error)
+	Delete(ctx context.Context, resourceID string) error
+	// Calculate computes the tax on order lines shipped to address. discount is
+	// the order-level discount on the lines; it is spread over them in
+	// proportion to their totals before tax is applied. A nil address yields
+	// no tax.
+	Calculate(ctx context.Context, address *models.Address, items []models.OrderItem, discount float64) (*TaxCalculation, error)
+}
+
+type taxUsecase struct {
+	taxRuleRepo repository.TaxRuleRepository
+}
+
+func NewTaxUsecase(taxRuleRepo repository.TaxRuleRepository) TaxUsecase {
+	return &taxUsecase{
+		taxRuleRepo: taxRuleRepo,
+	}
+}
+
+func (u *taxUsecase) List(ctx context.Context, page, limit int, filters map[string]interface{}) ([]*models.TaxRule, int64, error) {
+	offset := (page - 1) * limit
+
+	rules, err := u.taxRuleRepo.List(ctx, limit, offset, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := u.taxRuleRepo.Count(ctx, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return rules, total, nil
+}
+
+func (u *taxUsecase) GetByResourceID(ctx context.Context, resourceID string) (*models.TaxRule, error) {
+	rule, err := u.taxRuleRepo.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, ErrTaxRuleNotFound
+	}
+	return rule, nil
+}
+
+func (u *taxUsecase) Create(ctx context.Context, req dto.CreateTaxRuleRequest) (*models.TaxRule, error) {
+	rule := &models.TaxRule{
+		Name:      req.Name,
+		Country:   normalizeRegion(req.Country),
+		State:     normalizeRegion(req.State),
+		Rate:      req.Rate,
+		Inclusive: req.Inclusive,
+		IsActive:  true,
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	if err := validateTaxRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := u.taxRuleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (u *taxUsecase) Update(ctx context.Context, resourceID string, req dto.UpdateTaxRuleRequest) (*models.TaxRule, error) {
+	rule, err := u.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.Country != nil {
+		rule.Country = normalizeRegion(*req.Country)
+	}
+	if req.State != nil {
+		rule.State = normalizeRegion(*req.State)
+	}
+	if req.Rate != nil {
+		rule.Rate = *req.Rate
+	}
+	if req.Inclusive != nil {
+		rule.Inclusive = *req.Inclusive
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := validateTaxRule(rule); err != nil {
+		return nil, err
+	}
+	if err := u.taxRuleRepo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (u *taxUsecase) Delete(ctx context.Context, resourceID string) error {
+	rule, err := u.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+	return u.taxRuleRepo.Delete(ctx, rule.ID)
+}
+
+func (u *taxUsecase) Calculate(ctx context.Context, address *models.Address, items []models.OrderItem, discount float64) (*TaxCalculation, error) {
+	if address == nil {
+		return calculateTax(nil, items, discount), nil
+	}
+
+	rules, err := u.taxRuleRepo.ListForRegion(ctx, normalizeRegion(address.Country), normalizeRegion(address.State))
+	if err != nil {
+		return nil, err
+	}
+	return calculateTax(rules, items, discount), nil
+}
+
+// calculateTax applies rules to every taxable line. Inclusive rules extract
+// the tax already contained in the price; exclusive rules are charged on the
+// price net of inclusive tax. Amounts are rounded per line and rule.
+func calculateTax(rules []*models.TaxRule, items []models.OrderItem, discount float64) *TaxCalculation {
+	calc := &TaxCalculation{
+		ItemTaxes: make([]float64, len(items)),
+	}
+	if len(rules) == 0 {
+		return calc
+	}
+
+	var subtotal, inclusiveRate float64
+	for _, item := range items {
+		subtotal += item.Total
+	}
+	for _, rule := range rules {
+		if rule.Inclusive {
+			inclusiveRate += rule.Rate
+		}
+	}
+
+	taxes := make([]models.OrderTax, len(rules))
+	for i, rule := range rules {
+		ruleID := rule.ID
+		taxes[i] = models.OrderTax{
+			TaxRuleID: &ruleID,
+			Name:      rule.Name,
+			Country:   rule.Country,
+			State:     rule.State,
+			Rate:      rule.Rate,
+			Inclusive: rule.Inclusive,
+		}
+	}
+
+	for i, item := range items {
+		if !item.Product.Taxable || subtotal <= 0 {
+			continue
+		}
+		base := item.Total - discount*item.Total/subtotal
+		if base <= 0 {
+			continue
+		}
+		net := base * 100 / (100 + inclusiveRate)
+
+		for j, rule := range rules {
+			var amount float64
+			if rule.Inclusive {
+				amount = roundCurrency(base * rule.Rate / (100 + inclusiveRate))
+			} else {
+				amount = roundCurrency(net * rule.Rate / 100)
+				calc.Exclusive += amount
+			}
+			taxes[j].TaxableAmount += net
+			taxes[j].Amount += amount
+			calc.ItemTaxes[i] += amount
+			calc.Total += amount
+		}
+		calc.ItemTaxes[i] = roundCurrency(calc.ItemTaxes[i])
+	}
+
+	for _, tax := range taxes {
+		if tax.Amount == 0 {
+			continue
+		}
+		tax.TaxableAmount = roundCurrency(tax.TaxableAmount)
+		tax.Amount = roundCurrency(tax.Amount)
+		calc.Taxes = append(calc.Taxes, tax)
+	}
+	calc.Total = roundCurrency(calc.Total)
+	calc.Exclusive = roundCurrency(calc.Exclusive)
+	return calc
+}
+
+func validateTaxRule(rule *models.TaxRule) error {
+	if rule.Country == "" {
+		return fmt.Errorf("%w: country is required", ErrInvalidTaxRule)
+	}
+	if rule.Rate < 0 || rule.Rate > 100 {
+		return fmt.Errorf("%w: rate must be between 0 and 100", ErrInvalidTaxRule)
+	}
+	return nil
+}
+
+// normalizeRegion stores and matches countries and states in upper case so
+// tax rules and shipping zones match addresses regardless of how they were typed
+func normalizeRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/repository"
+)
+
+// memoryTaxRuleRepository matches rules to a region the way the database
+// query does: every active rule of the country that is country-wide or for
+// the given state
+type memoryTaxRuleRepository struct {
+	repository.TaxRuleRepository
+	rules []*models.TaxRule
+}
+
+func (r *memoryTaxRuleRepository) ListForRegion(ctx context.Context, country, state string) ([]*models.TaxRule, error) {
+	var rules []*models.TaxRule
+	for _, rule := range r.rules {
+		if rule.IsActive && rule.Country == country && (rule.State == "" || rule.State == state) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func taxableLine(total float64) models.OrderItem {
+	return models.OrderItem{Quantity: 1, Price: total, Total: total, Product: models.Product{Taxable: true}}
+}
+
+func TestCalculateTax(t *testing.T) {
+	salesTax := &models.TaxRule{ID: 1, Name: "Sales tax", Country: "US", Rate: 10}
+	vat := &models.TaxRule{ID: 2, Name: "VAT", Country: "DE", Rate: 20, Inclusive: true}
+	levy := &models.TaxRule{ID: 3, Name: "Levy", Country: "DE", Rate: 5}
+
+	tests := []struct {
+		name          string
+		rules         []*models.TaxRule
+		items         []models.OrderItem
+		discount      float64
+		wantItemTaxes []float64
+		wantTotal     float64
+		wantExclusive float64
+	}{
+		{
+			name:          "no rules",
+			items:         []models.OrderItem{taxableLine(100)},
+			wantItemTaxes: []float64{0},
+		},
+		{
+			name:          "exclusive rate is charged on top",
+			rules:         []*models.TaxRule{salesTax},
+			items:         []models.OrderItem{taxableLine(100)},
+			wantItemTaxes: []float64{10},
+			wantTotal:     10,
+			wantExclusive: 10,
+		},
+		{
+			name:          "inclusive rate is taken out of the price",
+			rules:         []*models.TaxRule{vat},
+			items:         []models.OrderItem{taxableLine(100)},
+			wantItemTaxes: []float64{16.67},
+			wantTotal:     16.67,
+		},
+		{
+			name:          "exclusive rate applies to the price net of inclusive tax",
+			rules:         []*models.TaxRule{vat, levy},
+			items:         []models.OrderItem{taxableLine(100)},
+			wantItemTaxes: []float64{20.84},
+			wantTotal:     20.84,
+			wantExclusive: 4.17,
+		},
+		{
+			name:          "untaxed products",
+			rules:         []*models.TaxRule{salesTax},
+			items:         []models.OrderItem{taxableLine(100), {Quantity: 1, Price: 50, Total: 50}},
+			wantItemTaxes: []float64{10, 0},
+			wantTotal:     10,
+			wantExclusive: 10,
+		},
+		{
+			name:          "discount is spread over the lines",
+			rules:         []*models.TaxRule{salesTax},
+			items:         []models.OrderItem{taxableLine(60), taxableLine(40)},
+			discount:      10,
+			wantItemTaxes: []float64{5.4, 3.6},
+			wantTotal:     9,
+			wantExclusive: 9,
+		},
+		{
+			name:          "discount of the whole order",
+			rules:         []*models.TaxRule{salesTax},
+			items:         []models.OrderItem{taxableLine(60), taxableLine(40)},
+			discount:      100,
+			wantItemTaxes: []float64{0, 0},
+		},
+		{
+			name:          "amounts are rounded per line",
+			rules:         []*models.TaxRule{salesTax},
+			items:         []models.OrderItem{taxableLine(0.35), taxableLine(0.35), taxableLine(0.35)},
+			wantItemTaxes: []float64{0.04, 0.04, 0.04},
+			wantTotal:     0.12,
+			wantExclusive: 0.12,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := calculateTax(tt.rules, tt.items, tt.discount)
+
+			if len(calc.ItemTaxes) != len(tt.wantItemTaxes) {
+				t.Fatalf("item taxes = %v, want %v", calc.ItemTaxes, tt.wantItemTaxes)
+			}
+			for i, want := range tt.wantItemTaxes {
+				if calc.ItemTaxes[i] != want {
+					t.Errorf("item taxes = %v, want %v", calc.ItemTaxes, tt.wantItemTaxes)
+					break
+				}
+			}
+			if calc.Total != tt.wantTotal || calc.Exclusive != tt.wantExclusive {
+				t.Errorf("total %.2f, exclusive %.2f; want %.2f, %.2f", calc.Total, calc.Exclusive, tt.wantTotal, tt.wantExclusive)
+			}
+
+			var ruleTotal float64
+			for _, tax := range calc.Taxes {
+				ruleTotal += tax.Amount
+			}
+			if roundCurrency(ruleTotal) != calc.Total {
+				t.Errorf("taxes per rule add up to %.2f, want %.2f", ruleTotal, calc.Total)
+			}
+		})
+	}
+}
+
+func TestTaxCalculateRegion(t *testing.T) {
+	repo := &memoryTaxRuleRepository{rules: []*models.TaxRule{
+		{ID: 1, Name: "Federal", Country: "US", Rate: 5, IsActive: true},
+		{ID: 2, Name: "California", Country: "US", State: "CA", Rate: 3, IsActive: true},
+		{ID: 3, Name: "New York", Country: "US", State: "NY", Rate: 4, IsActive: true},
+		{ID: 4, Name: "Retired", Country: "US", Rate: 50},
+	}}
+	taxes := NewTaxUsecase(repo)
+
+	tests := []struct {
+		name      string
+		address   *models.Address
+		wantTotal float64
+	}{
+		{"no address", nil, 0},
+		{"state with its own rule", &models.Address{Country: "US", State: "CA"}, 8},
+		{"state and country typed in lower case", &models.Address{Country: " us", State: "ny "}, 9},
+		{"state without its own rule falls back to country-wide rules", &models.Address{Country: "US", State: "TX"}, 5},
+		{"address without a state", &models.Address{Country: "US"}, 5},
+		{"country without rules", &models.Address{Country: "FR"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc, err := taxes.Calculate(context.Background(), tt.address, []models.OrderItem{taxableLine(100)}, 0)
+			if err != nil {
+				t.Fatalf("Calculate: %v", err)
+			}
+			if calc.Total != tt.wantTotal {
+				t.Errorf("total = %.2f, want %.2f", calc.Total, tt.wantTotal)
+			}
+		})
+	}
+}
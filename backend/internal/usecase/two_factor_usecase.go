@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/repository"
+	"electronics-store/internal/services"
+)
+
+var (
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor enrollment has not been started")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrTwoFactorMandatory      = errors.New("two-factor authentication is mandatory for admin accounts")
+)
+
+const (
+	// twoFactorIssuer labels the account in authenticator apps
+	twoFactorIssuer   = "Electronics Store"
+	recoveryCodeCount = 10
+)
+
+// TwoFactorEnrollment is what an authenticator app needs to add an account
+type TwoFactorEnrollment struct {
+	Secret string
+	URI    string // otpauth:// URI, usually shown as a QR code
+}
+
+// TwoFactorUsecase manages TOTP two-factor authentication (RFC 6238).
+// Wherever a code is asked for, one of the user's recovery codes is
+// accepted instead.
+type TwoFactorUsecase interface {
+	// IsEnabled reports whether the user has confirmed two-factor
+	// authentication
+	IsEnabled(ctx context.Context, userID uint) (bool, error)
+	// Status reports whether two-factor authentication is enabled and how
+	// many unused recovery codes the user has left
+	Status(ctx context.Context, userID uint) (bool, int64, error)
+	// Enroll starts enrollment with a new secret, replacing any pending one.
+	// Nothing changes at login until the enrollment is confirmed.
+	Enroll(ctx context.Context, user *models.User) (*TwoFactorEnrollment, error)
+	// Confirm enables two-factor authentication once the user proves their
+	// authenticator works, and returns their recovery codes. The codes are
+	// stored hashed and can't be shown again.
+	Confirm(ctx context.Context, userID uint, code string) ([]string, error)
+	// Verify checks a code for a user who has two-factor authentication
+	// enabled. A code is accepted only once.
+	Verify(ctx context.Context, userID uint, code string) error
+	// RegenerateRecoveryCodes replaces the user's recovery codes
+	RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error)
+	// Disable turns two-factor authentication off. Admins can't disable it.
+	Disable(ctx context.Context, user *models.User, code string) error
+}
+
+type twoFactorUsecase struct {
+	twoFactorRepo repository.TwoFactorRepository
+}
+
+func NewTwoFactorUsecase(twoFactorRepo repository.TwoFactorRepository) TwoFactorUsecase {
+	return &twoFactorUsecase{
+		twoFactorRepo: twoFactorRepo,
+	}
+}
+
+func (u *twoFactorUsecase) IsEnabled(ctx context.Context, userID uint) (bool, error) {
+	twoFactor, err := u.twoFactorRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return twoFactor.Enabled(), nil
+}
+
+func (u *twoFactorUsecase) Status(ctx context.Context, userID uint) (bool, int64, error) {
+	twoFactor, err := u.twoFactorRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return false, 0, err
+	}
+	if !twoFactor.Enabled() {
+		return false, 0, nil
+	}
+	remaining, err := u.twoFactorRepo.CountUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return false, 0, err
+	}
+	return true, remaining, nil
+}
+
+func (u *twoFactorUsecase) Enroll(ctx context.Context, user *models.User) (*TwoFactorEnrollment, error) {
+	twoFactor, err := u.twoFactorRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.Enabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if twoFactor == nil {
+		twoFactor = &models.TwoFactorAuth{UserID: user.ID}
+	}
+
+	secret, err := services.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	twoFactor.Secret = secret
+	twoFactor.LastUsedStep = 0
+	if err := u.twoFactorRepo.Save(ctx, twoFactor); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorEnrollment{
+		Secret: secret,
+		URI:    services.TOTPURI(twoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+func (u *twoFactorUsecase) Confirm(ctx context.Context, userID uint, code string) ([]string, error) {
+	var recoveryCodes []string
+	err := u.twoFactorRepo.Transaction(ctx, func(tx repository.TwoFactorRepository) error {
+		twoFactor, err := tx.GetByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if twoFactor == nil {
+			return ErrTwoFactorNotEnrolled
+		}
+		if twoFactor.Enabled() {
+			return ErrTwoFactorAlreadyEnabled
+		}
+
+		// Only the authenticator can confirm; there are no recovery codes yet
+		step, ok := services.ValidateTOTP(twoFactor.Secret, strings.TrimSpace(code), time.Now())
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		now := time.Now()
+		twoFactor.ConfirmedAt = &now
+		twoFactor.LastUsedStep = step
+		if err := tx.Save(ctx, twoFactor); err != nil {
+			return err
+		}
+
+		recoveryCodes, err = replaceRecoveryCodes(ctx, tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+func (u *twoFactorUsecase) Verify(ctx context.Context, userID uint, code string) error {
+	twoFactor, err := u.twoFactorRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !twoFactor.Enabled() {
+		return ErrTwoFactorNotEnabled
+	}
+	return u.checkCode(ctx, twoFactor, code)
+}
+
+func (u *twoFactorUsecase) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+	if err := u.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	return replaceRecoveryCodes(ctx, u.twoFactorRepo, userID)
+}
+
+func (u *twoFactorUsecase) Disable(ctx context.Context, user *models.User, code string) error {
+	if user.IsAdmin {
+		return ErrTwoFactorMandatory
+	}
+	if err := u.Verify(ctx, user.ID, code); err != nil {
+		return err
+	}
+	return u.twoFactorRepo.DeleteByUserID(ctx, user.ID)
+}
+
+// checkCode accepts a current TOTP code that hasn't been used yet or an
+// unused recovery code, spending it
+func (u *twoFactorUsecase) checkCode(ctx context.Context, twoFactor *models.TwoFactorAuth, code string) error {
+	code = strings.TrimSpace(code)
+	if step, ok := services.ValidateTOTP(twoFactor.Secret, code, time.Now()); ok {
+		advanced, err := u.twoFactorRepo.AdvanceLastUsedStep(ctx, twoFactor.ID, step)
+		if err != nil {
+			return err
+		}
+		if !advanced {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := u.twoFactorRepo.UseRecoveryCode(ctx, twoFactor.UserID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// replaceRecoveryCodes generates a new set of recovery codes for the user,
+// stores their hashes and returns the codes
+func replaceRecoveryCodes(ctx context.Context, twoFactorRepo repository.TwoFactorRepository, userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		// 64 random bits, so the unsalted hashes can't be brute-forced
+		raw := make([]byte, 8)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		encoded := hex.EncodeToString(raw)
+		codes[i] = encoded[:4] + "-" + encoded[4:8] + "-" + encoded[8:12] + "-" + encoded[12:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	if err := twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode is the form recovery codes are stored in. Case, dashes
+// and spaces don't matter when a code is typed back in.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,518 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"electronics-store/internal/domain/models"
+	"electronics-store/internal/dto"
+	"electronics-store/internal/repository"
+)
+
+var (
+	ErrProductOptionNotFound = errors.New("product option not found")
+	ErrInvalidProductOption  = errors.New("invalid product option")
+	ErrProductOptionInUse    = errors.New("option value is used by a variant")
+	ErrProductHasVariants    = errors.New("product already has variants; delete them before adding an option")
+	ErrVariantNotFound       = errors.New("variant not found")
+	ErrInvalidVariantOptions = errors.New("invalid variant options")
+	ErrDuplicateVariant      = errors.New("a variant with these options already exists")
+	ErrVariantSKUTaken       = errors.New("variant SKU already exists")
+	ErrVariantRequired       = errors.New("choose a variant of this product")
+)
+
+// variantNameSeparator joins the option values of a variant into its name
+const variantNameSeparator = " / "
+
+// VariantUsecase manages a product's options, such as Color or Storage, and
+// its variants, each one combination of option values with its own SKU,
+// price, stock and images. Products are addressed by resource ID.
+type VariantUsecase interface {
+	ListOptions(ctx context.Context, productResourceID string) ([]*models.ProductOption, error)
+	// CreateOption adds an option to a product that has no variants yet
+	CreateOption(ctx context.Context, productResourceID string, req dto.CreateProductOptionRequest) (*models.ProductOption, error)
+	UpdateOption(ctx context.Context, productResourceID, resourceID string, req dto.UpdateProductOptionRequest) (*models.ProductOption, error)
+	// DeleteOption removes an option none of whose values a variant uses
+	DeleteOption(ctx context.Context, productResourceID, resourceID string) error
+
+	ListVariants(ctx context.Context, productResourceID string) ([]*models.Variant, error)
+	GetVariant(ctx context.Context, productResourceID, resourceID string) (*models.Variant, error)
+	CreateVariant(ctx context.Context, productResourceID string, req dto.CreateVariantRequest) (*models.Variant, error)
+	UpdateVariant(ctx context.Context, productResourceID, resourceID string, req dto.UpdateVariantRequest) (*models.Variant, error)
+	DeleteVariant(ctx context.Context, productResourceID, resourceID string) error
+
+	// ValidateCartVariant checks that a cart line of the product may have
+	// the variant: it must be an active variant of that product, and a
+	// product with options can only be bought as one of its variants. It
+	// returns the variant, or nil when none was given.
+	ValidateCartVariant(ctx context.Context, productID uint, variantID *uint) (*models.Variant, error)
+}
+
+type variantUsecase struct {
+	variantRepo repository.VariantRepository
+	productRepo repository.ProductRepository
+}
+
+func NewVariantUsecase(variantRepo repository.VariantRepository, productRepo repository.ProductRepository) VariantUsecase {
+	return &variantUsecase{
+		variantRepo: variantRepo,
+		productRepo: productRepo,
+	}
+}
+
+func (u *variantUsecase) ListOptions(ctx context.Context, productResourceID string) ([]*models.ProductOption, error) {
+	product, err := u.getProduct(ctx, productResourceID)
+	if err != nil {
+		return nil, err
+	}
+	return u.variantRepo.ListOptions(ctx, product.ID)
+}
+
+func (u *variantUsecase) CreateOption(ctx context.Context, productResourceID string, req dto.CreateProductOptionRequest) (*models.ProductOption, error) {
+	product, err := u.getProduct(ctx, productResourceID)
+	if err != nil {
+		return nil, err
+	}
+	// Existing variants would have no value for the new option
+	count, err := u.variantRepo.CountVariants(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrProductHasVariants
+	}
+
+	options, err := u.variantRepo.ListOptions(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if err := checkOptionName(options, name, 0); err != nil {
+		return nil, err
+	}
+	values, err := normalizeOptionValues(req.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	option := &models.ProductOption{
+		ProductID: product.ID,
+		Name:      name,
+		Position:  req.Position,
+	}
+	for i, value := range values {
+		option.Values = append(option.Values, models.ProductOptionValue{Value: value, Position: i})
+	}
+	if err := u.variantRepo.CreateOption(ctx, option); err != nil {
+		return nil, err
+	}
+	return u.getOption(ctx, product.ID, option.ResourceID)
+}
+
+func (u *variantUsecase) UpdateOption(ctx context.Context, productResourceID, resourceID string, req dto.UpdateProductOptionRequest) (*models.ProductOption, error) {
+	product, err := u.getProduct(ctx, productResourceID)
+	if err != nil {
+		return nil, err
+	}
+	option, err := u.getOption(ctx, product.ID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		options, err := u.variantRepo.ListOptions(ctx, product.ID)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSpace(*req.Name)
+		if err := checkOptionName(options, name, option.ID); err != nil {
+			return nil, err
+		}
+		option.Name = name
+	}
+	if req.Position != nil {
+		option.Position = *req.Position
+	}
+
+	// Values keep their IDs, and so their variants, when they stay; only
+	// values no variant uses can be removed
+	var removed []uint
+	if req.Values != nil {
+		values, err := normalizeOptionValues(req.Values)
+		if err != nil {
+			return nil, err
+		}
+		existing := make(map[string]models.ProductOptionValue, len(option.Values))
+		for _, value := range option.Values {
+			existing[strings.ToLower(value.Value)] = value
+		}
+		kept := make([]models.ProductOptionValue, 0, len(values))
+		for i, value := range values {
+			key := strings.ToLower(value)
+			current, ok := existing[key]
+			if !ok {
+				current = models.ProductOptionValue{OptionID: option.ID}
+			}
+			delete(existing, key)
+			current.Value = value
+			current.Position = i
+			kept = append(kept, current)
+		}
+		for _, value := range existing {
+			removed = append(removed, value.ID)
+		}
+		inUse, err := u.variantRepo.OptionValuesInUse(ctx, removed)
+		if err != nil {
+			return nil, err
+		}
+		if inUse {
+			return nil, ErrProductOptionInUse
+		}
+		option.Values = kept
+	}
+
+	if err := u.variantRepo.UpdateOption(ctx, option, removed); err != nil {
+		return nil, err
+	}
+	return u.getOption(ctx, product.ID, resourceID)
+}
+
+func (u *variantUsecase) DeleteOption(ctx context.Context, productResourceID, resourceID string) error {
+	product, err := u.getProduct(ctx, productResourceID)
+	if err != nil {
+		return err
+	}
+	option, err := u.getOption(ctx, product.ID, resourceID)
+	if err != nil {
+		return err
+	}
+
+	valueIDs := make([]uint, 0, len(option.Values))
+	for _, value := range option.Values {
+		valueIDs = append(valueIDs, value.ID)
+	}
+	inUse, err := u.variantRepo.OptionValuesInUse(ctx, valueIDs)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrProductOptionInUse
+	}
+	return u.variantRepo.DeleteOption(ctx, option.ID)
+}
+
+func (u *variantUsecase) ListVariants(ctx context.Context, productResourceID string) ([]*models.Variant, error) {
+	product, err := u.getProduct(ctx, productResourceID)
+	if err != nil {
+		return nil, err
+	}
+	return u.variantRepo.ListVariants(ctx, product.ID)
+}
+
+func (u *variantUsecase) GetVariant(ctx context.Context, productResourceID, resourceID string) (*models.Variant, error) {
+	product, err := u.getProduct(ctx, productResourceID)
+	if err != nil {
+		return nil, err
+	}
+	return u.getVariant(ctx, product.ID, resourceID)
+}
+
+func (u *variantUsecase) CreateVariant(ctx context.Context, productResourceID string, req dto.CreateVariantRequest) (*models.Variant, error) {
+	product, err := u.getProduct(ctx, productResourceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkSKU(ctx, req.SKU, 0); err != nil {
+		return nil, err
+	}
+
+	variant := &models.Variant{
+		ProductID:     product.ID,
+		Name:          strings.TrimSpace(req.Name),
+		SKU:           req.SKU,
+		Price:         req.Price,
+		ComparePrice:  req.ComparePrice,
+		CostPrice:     req.CostPrice,
+		StockQuantity: req.StockQuantity,
+		Weight:        req.Weight,
+		IsActive:      true,
+		Images:        buildVariantImages(req.Images),
+	}
+	if req.IsActive != nil {
+		variant.IsActive = *req.IsActive
+	}
+	if err := u.setVariantOptions(ctx, variant, req.Options); err != nil {
+		return nil, err
+	}
+	if variant.Name == "" {
+		return nil, fmt.Errorf("%w: a variant of a product without options needs a name", ErrInvalidVariantOptions)
+	}
+
+	if err := u.variantRepo.CreateVariant(ctx, variant); err != nil {
+		return nil, err
+	}
+	return u.getVariant(ctx, product.ID, variant.ResourceID)
+}
+
+func (u *variantUsecase) UpdateVariant(ctx context.Context, productResourceID, resourceID string, req dto.UpdateVariantRequest) (*models.Variant, error) {
+	product, err := u.getProduct(ctx, productResourceID)
+	if err != nil {
+		return nil, err
+	}
+	variant, err := u.getVariant(ctx, product.ID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SKU != nil {
+		if err := u.checkSKU(ctx, *req.SKU, variant.ID); err != nil {
+			return nil, err
+		}
+		variant.SKU = *req.SKU
+	}
+	if req.Price != nil {
+		variant.Price = *req.Price
+	}
+	if req.ComparePrice != nil {
+		variant.ComparePrice = *req.ComparePrice
+	}
+	if req.CostPrice != nil {
+		variant.CostPrice = *req.CostPrice
+	}
+	if req.StockQuantity != nil {
+		variant.StockQuantity = *req.StockQuantity
+	}
+	if req.Weight != nil {
+		variant.Weight = *req.Weight
+	}
+	if req.IsActive != nil {
+		variant.IsActive = *req.IsActive
+	}
+	replaceOptions := req.Options != nil
+	if replaceOptions {
+		// The name follows the new values unless one is given
+		variant.Name = ""
+		if err := u.setVariantOptions(ctx, variant, req.Options); err != nil {
+			return nil, err
+		}
+	}
+	if req.Name != nil && strings.TrimSpace(*req.Name) != "" {
+		variant.Name = strings.TrimSpace(*req.Name)
+	}
+	if variant.Name == "" {
+		return nil, fmt.Errorf("%w: a variant of a product without options needs a name", ErrInvalidVariantOptions)
+	}
+	replaceImages := req.Images != nil
+	if replaceImages {
+		variant.Images = buildVariantImages(req.Images)
+	}
+
+	if err := u.variantRepo.UpdateVariant(ctx, variant, replaceOptions, replaceImages); err != nil {
+		return nil, err
+	}
+	return u.getVariant(ctx, product.ID, resourceID)
+}
+
+func (u *variantUsecase) DeleteVariant(ctx context.Context, productResourceID, resourceID string) error {
+	product, err := u.getProduct(ctx, productResourceID)
+	if err != nil {
+		return err
+	}
+	variant, err := u.getVariant(ctx, product.ID, resourceID)
+	if err != nil {
+		return err
+	}
+	return u.variantRepo.DeleteVariant(ctx, variant.ID)
+}
+
+func (u *variantUsecase) ValidateCartVariant(ctx context.Context, productID uint, variantID *uint) (*models.Variant, error) {
+	if variantID == nil {
+		options, err := u.variantRepo.ListOptions(ctx, productID)
+		if err != nil {
+			return nil, err
+		}
+		if len(options) > 0 {
+			return nil, ErrVariantRequired
+		}
+		return nil, nil
+	}
+
+	variant, err := u.variantRepo.GetVariantByID(ctx, *variantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil || variant.ProductID != productID || !variant.IsActive {
+		return nil, ErrVariantNotFound
+	}
+	return variant, nil
+}
+
+// setVariantOptions sets the option values of a variant from option names
+// to values. A product with options needs a value of each; one without
+// takes none. The variant gets the values as its name unless it has one.
+func (u *variantUsecase) setVariantOptions(ctx context.Context, variant *models.Variant, selected map[string]string) error {
+	options, err := u.variantRepo.ListOptions(ctx, variant.ProductID)
+	if err != nil {
+		return err
+	}
+	if len(selected) != len(options) {
+		if len(options) == 0 {
+			return fmt.Errorf("%w: the product has no options", ErrInvalidVariantOptions)
+		}
+		return fmt.Errorf("%w: give a value of each of the product's %d options", ErrInvalidVariantOptions, len(options))
+	}
+
+	byName := make(map[string]string, len(selected))
+	for name, value := range selected {
+		byName[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	values := make([]models.ProductOptionValue, 0, len(options))
+	names := make([]string, 0, len(options))
+	for _, option := range options {
+		value, ok := byName[strings.ToLower(option.Name)]
+		if !ok {
+			return fmt.Errorf("%w: missing a value of %s", ErrInvalidVariantOptions, option.Name)
+		}
+		found := false
+		for _, candidate := range option.Values {
+			if strings.EqualFold(candidate.Value, value) {
+				values = append(values, candidate)
+				names = append(names, candidate.Value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %q is not a value of %s", ErrInvalidVariantOptions, value, option.Name)
+		}
+	}
+	if len(values) == 0 {
+		variant.OptionValues = nil
+		return nil
+	}
+
+	// No two variants of a product may be the same combination
+	variants, err := u.variantRepo.ListVariants(ctx, variant.ProductID)
+	if err != nil {
+		return err
+	}
+	key := optionValuesKey(values)
+	for _, other := range variants {
+		if other.ID != variant.ID && optionValuesKey(other.OptionValues) == key {
+			return ErrDuplicateVariant
+		}
+	}
+
+	variant.OptionValues = values
+	if variant.Name == "" {
+		variant.Name = strings.Join(names, variantNameSeparator)
+	}
+	return nil
+}
+
+func (u *variantUsecase) checkSKU(ctx context.Context, sku string, variantID uint) error {
+	existing, err := u.variantRepo.GetVariantBySKU(ctx, sku)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != variantID {
+		return ErrVariantSKUTaken
+	}
+	return nil
+}
+
+func (u *variantUsecase) getProduct(ctx context.Context, resourceID string) (*models.Product, error) {
+	product, err := u.productRepo.GetByResourceID(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+	return product, nil
+}
+
+func (u *variantUsecase) getOption(ctx context.Context, productID uint, resourceID string) (*models.ProductOption, error) {
+	option, err := u.variantRepo.GetOption(ctx, productID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if option == nil {
+		return nil, ErrProductOptionNotFound
+	}
+	return option, nil
+}
+
+func (u *variantUsecase) getVariant(ctx context.Context, productID uint, resourceID string) (*models.Variant, error) {
+	variant, err := u.variantRepo.GetVariant(ctx, productID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil {
+		return nil, ErrVariantNotFound
+	}
+	return variant, nil
+}
+
+// VariantOrderable reports whether a variant of product can be added to an
+// order right now
+func VariantOrderable(product *models.Product, variant *models.Variant) bool {
+	return variant.IsActive && (isOrderable(product) || variant.StockQuantity > 0)
+}
+
+// checkOptionName rejects a name another option of the product has
+func checkOptionName(options []*models.ProductOption, name string, optionID uint) error {
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidProductOption)
+	}
+	for _, other := range options {
+		if other.ID != optionID && strings.EqualFold(other.Name, name) {
+			return fmt.Errorf("%w: the product already has an option %s", ErrInvalidProductOption, other.Name)
+		}
+	}
+	return nil
+}
+
+// normalizeOptionValues trims values and rejects empty or repeated ones
+func normalizeOptionValues(values []string) ([]string, error) {
+	seen := make(map[string]bool, len(values))
+	normalized := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil, fmt.Errorf("%w: values can't be empty", ErrInvalidProductOption)
+		}
+		key := strings.ToLower(value)
+		if seen[key] {
+			return nil, fmt.Errorf("%w: %q is listed twice", ErrInvalidProductOption, value)
+		}
+		seen[key] = true
+		normalized = append(normalized, value)
+	}
+	return normalized, nil
+}
+
+// optionValuesKey identifies a combination of option values
+func optionValuesKey(values []models.ProductOptionValue) string {
+	ids := make([]int, 0, len(values))
+	for _, value := range values {
+		ids = append(ids, int(value.ID))
+	}
+	sort.Ints(ids)
+	return fmt.Sprint(ids)
+}
+
+func buildVariantImages(images []dto.VariantImageRequest) []models.VariantImage {
+	result := make([]models.VariantImage, 0, len(images))
+	for _, image := range images {
+		result = append(result, models.VariantImage{
+			URL:       image.URL,
+			Alt:       image.Alt,
+			SortOrder: image.SortOrder,
+		})
+	}
+	return result
+}
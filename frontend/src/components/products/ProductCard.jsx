@@ -44,10 +44,6 @@ const ProductCard = ({ product, className = '' }) => {
   const handleAddToCart = async (e) => {
     e.preventDefault()
     e.stopPropagation()
-    if (!user) {
-      toast.error('Please login to add items to cart')
-      return
-    }
     try {
       setIsAddingToCart(true)
       await addToCart(product, 1, null)
@@ -102,7 +102,8 @@ export const CartProvider = ({ children }) => {
   const [state, dispatch] = useReducer(cartReducer, initialState)
   const { isAuthenticated } = useAuth();
 
-  // Load or clear cart when auth state changes
+  // Reload the cart when auth state changes. Guests have a cart too, kept by
+  // the cart_token cookie; signing in merges it into the account's cart.
   useEffect(() => {
     const loadCart = async () => {
       try {
@@ -114,11 +115,7 @@ export const CartProvider = ({ children }) => {
         dispatch({ type: 'CART_ERROR', payload: error.message })
       }
     }
-    if (isAuthenticated) {
-      loadCart()
-    } else {
-      dispatch({ type: 'CLEAR_CART' })
-    }
+    loadCart()
   }, [isAuthenticated])
 
   // Add item to cart with optimistic update
@@ -10,6 +10,20 @@ import Button from '../../../components/ui/Button'
 import { formatPrice } from '../../../utils/format'
 import CartItemSkeleton from '../../../components/cart/CartItemSkeleton'
 
+// Why a line can't be ordered as it is, from the issue the cart API flags
+const issueLabel = (item) => {
+  switch (item.issue) {
+    case 'unavailable':
+      return 'No longer available'
+    case 'out_of_stock':
+      return 'Out of stock'
+    case 'insufficient_stock':
+      return `Only ${item.available_quantity} left`
+    default:
+      return 'Unavailable'
+  }
+}
+
 const CartItems = () => {
   const { items, removeFromCart, updateQuantity, isLoading } = useCart()
   const { wishlistItems, addToWishlist, removeFromWishlist } = useWishlist()
@@ -165,11 +179,23 @@ const CartItems = () => {
                         )}
                       </div>
 
+                      {item.price_changed && item.previous_price != null && (
+                        <p className="text-sm text-amber-700 mb-2">
+                          Price changed from {formatPrice(item.previous_price)} since you added this item
+                        </p>
+                      )}
+
                       {/* Stock Status */}
                       <div className="flex items-center gap-2 text-sm">
-                        <span className="inline-flex items-center px-2.5 py-0.5 rounded-full text-xs font-medium bg-green-100 text-green-800">
-                          In Stock
-                        </span>
+                        {item.available === false ? (
+                          <span className="inline-flex items-center px-2.5 py-0.5 rounded-full text-xs font-medium bg-red-100 text-red-800">
+                            {issueLabel(item)}
+                          </span>
+                        ) : (
+                          <span className="inline-flex items-center px-2.5 py-0.5 rounded-full text-xs font-medium bg-green-100 text-green-800">
+                            In Stock
+                          </span>
+                        )}
                       </div>
                     </div>
 
@@ -72,12 +72,6 @@ const ProductInfo = ({ productId }) => {
   const hasVariants = product.variants && product.variants.length > 0
 
   const handleAddToCart = async () => {
-    if (!user) {
-      toast.error('Please login to add items to cart')
-      navigate('/auth/login')
-      return
-    }
-
     if (product.stock === 0) {
       toast.error('Product is out of stock')
       return
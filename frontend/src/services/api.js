@@ -77,7 +77,10 @@ api.interceptors.response.use(
 // Auth API
 export const authAPI = {
     login: (credentials) => api.post('/auth/login', credentials).then(res => res.data),
-    register: (userData) => api.post('/auth/register', userData).then(res => res.data),
+    register: (userData) => api.post('/auth/register', userData).then(res => {
+        rememberOTPChallenge('email_verification', res.data.otp_challenge_id)
+        return res.data
+    }),
     logout: () => api.post('/auth/logout').then(res => res.data),
     getProfile: () => api.get('/auth/profile').then(res => res.data),
     updateProfile: (profileData) => api.put('/auth/profile', profileData).then(res => res.data),
@@ -86,9 +89,27 @@ export const authAPI = {
     googleIDTokenAuth: (idToken) => api.post('/auth/google/id-token', { id_token: idToken }).then(res => res.data),
 
     // OTP API
-    sendOTP: (email, type) => api.post('/auth/send-otp', { email, type }).then(res => res.data),
-    verifyOTP: (email, code, type) => api.post('/auth/verify-otp', { email, otp_code: code, type }).then(res => res.data),
-    resendOTP: (email, type) => api.post('/auth/resend-otp', { email, type }).then(res => res.data),
+    sendOTP: (email, type) => api.post('/auth/send-otp', { email, type }).then(res => {
+        rememberOTPChallenge(type, res.data.challenge_id)
+        return res.data
+    }),
+    verifyOTP: (email, code, type) => api.post('/auth/verify-otp', {
+        challenge_id: otpChallenge(type), email, otp_code: code, type,
+    }).then(res => res.data),
+    resendOTP: (email, type) => api.post('/auth/resend-otp', { email, type }).then(res => {
+        rememberOTPChallenge(type, res.data.challenge_id)
+        return res.data
+    }),
+}
+
+// A code is verified against the challenge ID returned when it was sent;
+// only the latest code of each type is valid, so one ID per type is kept
+function rememberOTPChallenge(type, challengeId) {
+    if (challengeId) sessionStorage.setItem(`otp_challenge:${type}`, challengeId)
+}
+
+function otpChallenge(type) {
+    return sessionStorage.getItem(`otp_challenge:${type}`) || ''
 }
 
 export async function sendForgotPasswordOTP(email) {
@@ -98,7 +119,9 @@ export async function sendForgotPasswordOTP(email) {
         body: JSON.stringify({ email, type: 'password_reset' }),
     });
     if (!res.ok) throw await res.json();
-    return await res.json();
+    const data = await res.json();
+    rememberOTPChallenge('password_reset', data.challenge_id);
+    return data;
 }
 
 export async function submitResetPassword({ email, otp_code, new_password, confirm_password }) {
@@ -106,6 +129,7 @@ export async function submitResetPassword({ email, otp_code, new_password, confi
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify({
+            challenge_id: otpChallenge('password_reset'),
             email,
             otp_code,
             new_password,
@@ -129,6 +153,9 @@ export const productsAPI = {
     }).then(res => res.data),
     getFeaturedProducts: () => api.get('/products/featured').then(res => res.data),
     getRelatedProducts: (productId) => api.get(`/products/${productId}/related`).then(res => res.data),
+    getStockAlert: (productId) => api.get(`/products/${productId}/stock-alert`).then(res => res.data),
+    subscribeStockAlert: (productId) => api.post(`/products/${productId}/stock-alert`).then(res => res.data),
+    unsubscribeStockAlert: (productId) => api.delete(`/products/${productId}/stock-alert`).then(res => res.data),
 }
 
 // Cart API
@@ -146,7 +173,7 @@ export const ordersAPI = {
     getOrder: (id) => api.get(`/orders/${id}`).then(res => res.data),
     createOrder: (orderData) => api.post('/orders', orderData).then(res => res.data),
     cancelOrder: (id) => api.post(`/orders/${id}/cancel`).then(res => res.data),
-    processPayment: (orderId, paymentData) => api.post(`/orders/${orderId}/payment`, paymentData).then(res => res.data),
+    processPayment: (orderId, paymentData) => api.post(`/orders/${orderId}/pay`, paymentData).then(res => res.data),
 }
 
 // Wishlist API
@@ -156,6 +183,15 @@ export const wishlistAPI = {
     removeFromWishlist: (productId) => api.delete(`/wishlist/${productId}`).then(res => res.data),
 }
 
+// Notifications API (in-app inbox)
+export const notificationsAPI = {
+    getNotifications: (params) => api.get('/me/notifications', { params }).then(res => res.data),
+    getUnreadCount: () => api.get('/me/notifications/unread-count').then(res => res.data),
+    markRead: (id) => api.post(`/me/notifications/${id}/read`).then(res => res.data),
+    markAllRead: () => api.post('/me/notifications/read-all').then(res => res.data),
+    deleteNotification: (id) => api.delete(`/me/notifications/${id}`).then(res => res.data),
+}
+
 // Reviews API
 export const reviewsAPI = {
     getProductReviews: (productId, params) => api.get(`/products/${productId}/reviews`, { params }).then(res => res.data),
@@ -177,9 +213,21 @@ export const adminAPI = {
     updateProduct: (id, productData) => api.put(`/admin/products/${id}`, productData).then(res => res.data),
     deleteProduct: (id) => api.delete(`/admin/products/${id}`).then(res => res.data),
 
+    // Product options (e.g. Color: Black, White) and variants made of their values
+    getProductOptions: (productId) => api.get(`/admin/products/${productId}/options`).then(res => res.data),
+    createProductOption: (productId, optionData) => api.post(`/admin/products/${productId}/options`, optionData).then(res => res.data),
+    updateProductOption: (productId, optionId, optionData) => api.put(`/admin/products/${productId}/options/${optionId}`, optionData).then(res => res.data),
+    deleteProductOption: (productId, optionId) => api.delete(`/admin/products/${productId}/options/${optionId}`).then(res => res.data),
+    getVariants: (productId) => api.get(`/admin/products/${productId}/variants`).then(res => res.data),
+    // variantData.options maps option names to values, e.g. { Color: 'Black', Storage: '256GB' }
+    createVariant: (productId, variantData) => api.post(`/admin/products/${productId}/variants`, variantData).then(res => res.data),
+    updateVariant: (productId, variantId, variantData) => api.put(`/admin/products/${productId}/variants/${variantId}`, variantData).then(res => res.data),
+    deleteVariant: (productId, variantId) => api.delete(`/admin/products/${productId}/variants/${variantId}`).then(res => res.data),
+
     // Orders
     getAdminOrders: (params) => api.get('/admin/orders', { params }).then(res => res.data),
-    updateOrderStatus: (id, status) => api.put(`/admin/orders/${id}/status`, { status }).then(res => res.data),
+    // shipment: { carrier, tracking_number, tracking_url } when marking an order shipped
+    updateOrderStatus: (id, status, shipment = {}) => api.put(`/admin/orders/${id}/status`, { status, ...shipment }).then(res => res.data),
 
     // Users
     getUsers: (params) => api.get('/admin/users', { params }).then(res => res.data),